@@ -1,13 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+	"memory-bot/internal/infrastructure/job"
 	"memory-bot/internal/infrastructure/messaging/telegram"
+	"memory-bot/internal/infrastructure/persistence/inmemory"
 	"memory-bot/internal/infrastructure/persistence/sqlite"
 	"memory-bot/internal/infrastructure/scheduler"
 	"memory-bot/internal/infrastructure/search/strategy"
@@ -27,43 +37,184 @@ func main() {
 		log.Println("No .env file found or error loading it, using environment variables")
 	}
 
+	migrateOnly := flag.Bool("migrate-only", false, "apply pending database migrations and exit")
+	migrateStatus := flag.Bool("migrate-status", false, "show the database schema version and pending migrations, then exit")
+	inMemory := flag.Bool("in-memory", false, "keep all data in process memory (demo mode, nothing is persisted)")
+	restore := flag.String("restore", "", "verify a backup snapshot and restore it as the database, then exit (stop the bot first)")
+	doctor := flag.Bool("doctor", false, "check the database for inconsistencies, then exit")
+	repair := flag.String("repair", "", "with -doctor: repair the findings of these checks (comma-separated, or \"all\")")
+	flag.Parse()
+
+	if *restore != "" {
+		if err := runRestore(*restore, config.DBPath()); err != nil {
+			log.Fatalf("Restore failed: %v", err)
+		}
+		return
+	}
+
+	if *doctor {
+		problems, err := runDoctor(config.DBPath(), *repair)
+		if err != nil {
+			log.Fatalf("Doctor failed: %v", err)
+		}
+		if problems > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *migrateOnly || *migrateStatus {
+		if err := runMigrations(config.DBPath(), *migrateOnly); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	if *inMemory {
+		cfg.DBPath = sqlite.InMemoryPath
+		log.Println("⚠️  In-memory mode: memories are lost when the bot stops")
+	}
 
 	// Initialize database connection
 	dbConn, err := sqlite.NewConnection(cfg.DBPath)
+	if errors.Is(err, sqlite.ErrSchemaTooNew) {
+		log.Fatalf("Refusing to start: %v", err)
+	}
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer dbConn.Close()
 
-	// Initialize encryptor if encryption key is provided
-	var encryptor *encryption.Encryptor
-	if cfg.EncryptionKey != "" {
-		encryptor = encryption.NewEncryptor(cfg.EncryptionKey)
+	// Rebuild the search index if the configured tokenizer changed
+	searchIndex, err := sqlite.NewSearchIndex(dbConn, cfg.FTSTokenizer)
+	if err != nil {
+		log.Fatalf("Invalid search configuration: %v", err)
+	}
+	if _, err := searchIndex.EnsureTokenizer(context.Background()); err != nil {
+		log.Fatalf("Failed to reindex search: %v", err)
+	}
+
+	// Derive the encryption keys and check them against the stored data
+	encryptor, err := sqlite.LoadKeyring(context.Background(), dbConn, cfg.EncryptionKey, cfg.EncryptionOldKeys)
+	if err != nil {
+		log.Fatalf("Failed to load encryption keys: %v", err)
+	}
+	if encryptor != nil {
 		log.Println("🔒 Encryption enabled for sensitive memory data")
+		if ids := encryptor.KeyIDs(); len(ids) > 1 {
+			log.Printf("🔑 Encrypting with key %s; previous keys %s still decrypt", ids[0], strings.Join(ids[1:], ", "))
+		}
 	} else {
 		log.Println("⚠️  Warning: Encryption is disabled. Set ENCRYPTION_KEY environment variable to enable encryption.")
 	}
+	fieldPolicy, err := sqlite.ParseFieldPolicy(cfg.EncryptedFields)
+	if err != nil {
+		log.Fatalf("Invalid encryption configuration: %v", err)
+	}
+	if encryptor != nil {
+		log.Printf("🔒 Encrypted memory fields: %s", fieldPolicy)
+	}
+
+	// Initialize repositories
+	var memoryRepo repository.MemoryRepository
+	var sqliteRepo *sqlite.MemoryRepository
+	var transactor repository.Transactor = dbConn
+	var vaultRepo repository.VaultRepository
+	if *inMemory {
+		inMemoryRepo := inmemory.NewMemoryRepository()
+		memoryRepo, transactor = inMemoryRepo, inMemoryRepo
+	} else {
+		sqliteMemoryRepo := sqlite.NewMemoryRepository(dbConn, encryptor, fieldPolicy)
+
+		// Vault keys are shared between the vault and memory repositories and never stored
+		vaultSessions := encryption.NewVaultSessions(time.Duration(cfg.VaultTimeout) * time.Minute)
+		sqliteMemoryRepo.UseVault(vaultSessions)
+		vaultRepo = sqlite.NewVaultRepository(dbConn, vaultSessions)
+
+		// Replace plaintext search data with blind index tokens
+		if _, err := sqliteMemoryRepo.MigrateSearchTokens(context.Background()); err != nil {
+			log.Fatalf("Failed to migrate search index: %v", err)
+		}
+		memoryRepo = sqliteMemoryRepo
+		sqliteRepo = sqliteMemoryRepo
+	}
 
-	// Initialize repository
-	memoryRepo := sqlite.NewMemoryRepository(dbConn, encryptor)
+	savedSearchRepo := sqlite.NewSavedSearchRepository(dbConn, encryptor)
+	tagRepo := sqlite.NewTagRepository(dbConn, encryptor, fieldPolicy)
+	queryHistoryRepo := sqlite.NewQueryHistoryRepository(dbConn, encryptor)
+	userSettingsRepo := sqlite.NewUserSettingsRepository(dbConn)
+	synonymRepo := sqlite.NewSynonymRepository(dbConn)
+	pendingDeletionRepo := sqlite.NewPendingDeletionRepository(dbConn)
+
+	if sqliteRepo != nil {
+		// Build the normalized tag index from the legacy tags column
+		if _, err := tagRepo.MigrateTags(context.Background()); err != nil {
+			log.Fatalf("Failed to migrate tags: %v", err)
+		}
+
+		// Encrypt or decrypt stored fields when ENCRYPTED_FIELDS changed
+		if _, err := sqliteRepo.MigrateFieldEncryption(context.Background()); err != nil {
+			log.Fatalf("Failed to migrate encrypted fields: %v", err)
+		}
+	}
+
+	// Create bot API for scheduler and notifications
+	botAPI, err := createTelegramBotAPI(cfg.TelegramBotToken)
+	if err != nil {
+		log.Fatalf("Failed to create bot API for scheduler: %v", err)
+	}
 
 	// Initialize use cases
-	saveMemoryUC := usecase.NewSaveMemoryUseCase(memoryRepo)
+	saveMemoryUC := usecase.NewSaveMemoryUseCase(memoryRepo, transactor)
 	getRecentUC := usecase.NewGetRecentMemoriesUseCase(memoryRepo)
 	getStatsUC := usecase.NewGetStatsUseCase(memoryRepo)
 	reviewMemoryUC := usecase.NewReviewMemoryUseCase(memoryRepo)
+	findRelatedUC := usecase.NewFindRelatedMemoriesUseCase(memoryRepo)
+	reindexSearchUC := usecase.NewReindexSearchUseCase(searchIndex)
+	manageTagsUC := usecase.NewManageTagsUseCase(tagRepo, memoryRepo)
+	manageSynonymsUC := usecase.NewManageSynonymsUseCase(synonymRepo)
+	manageTrashUC := usecase.NewManageTrashUseCase(memoryRepo, cfg.TrashRetention)
+	editMemoryUC := usecase.NewEditMemoryUseCase(memoryRepo)
+	manageSecretsUC := usecase.NewManageSecretsUseCase(memoryRepo, pendingDeletionRepo)
+	backupDatabaseUC := usecase.NewBackupDatabaseUseCase(
+		sqlite.NewBackupRepository(dbConn, cfg.BackupDir), cfg.BackupKeep, cfg.BackupRetention)
+	diagnoseDatabaseUC := usecase.NewDiagnoseDatabaseUseCase(sqlite.NewDoctorRepository(dbConn, encryptor, fieldPolicy))
+	var manageVaultUC *usecase.ManageVaultUseCase
+	if vaultRepo != nil {
+		manageVaultUC = usecase.NewManageVaultUseCase(vaultRepo)
+	}
+	var rotateKeyUC *usecase.RotateKeyUseCase
+	if encryptor != nil {
+		rotateKeyUC = usecase.NewRotateKeyUseCase(sqlite.NewKeyRotationRepository(dbConn, encryptor, fieldPolicy))
+	}
+
+	// Initialize search strategies (picked per query by the factory)
+	searchStrategies := strategy.NewStrategyFactory(memoryRepo)
+	searchMemoryUC := usecase.NewSearchMemoryUseCase(searchStrategies)
+	explainSearchUC := usecase.NewExplainSearchUseCase(memoryRepo, searchStrategies)
 
-	// Initialize search strategy (Smart Search)
-	searchStrategy := strategy.NewSmartSearchStrategy(memoryRepo)
-	searchMemoryUC := usecase.NewSearchMemoryUseCase(searchStrategy)
+	// Tags, saved searches, query history and synonyms live in SQLite, next to the
+	// memories; in in-memory mode they would be kept apart from them, so these features are off
+	var queryHistoryUC *usecase.QueryHistoryUseCase
+	var watchMemoryUC *usecase.WatchMemoryUseCase
+	if !*inMemory {
+		// Query history observes searches of users who opted in
+		queryHistoryUC = usecase.NewQueryHistoryUseCase(queryHistoryRepo, userSettingsRepo)
+		searchMemoryUC.Subscribe(queryHistoryUC)
+
+		// Saved searches observe new memories (Observer Pattern)
+		watchMemoryUC = usecase.NewWatchMemoryUseCase(savedSearchRepo, memoryRepo, telegram.NewWatchNotifier(botAPI))
+		saveMemoryUC.Subscribe(watchMemoryUC)
+	}
 
 	// Initialize command registry
 	registry := command.NewCommandRegistry()
+	admins := command.NewAdminPolicy(cfg.AdminUserIDs)
 
 	// Register commands
 	registry.Register(command.NewStartCommand())
@@ -72,24 +223,76 @@ func main() {
 	registry.Register(command.NewSearchCommand(searchMemoryUC))
 	registry.Register(command.NewRecentCommand(getRecentUC))
 	registry.Register(command.NewStatsCommand(getStatsUC))
+	registry.Register(command.NewRelatedCommand(findRelatedUC))
+	registry.Register(command.NewEditCommand(editMemoryUC))
+	registry.Register(command.NewRevisionsCommand(editMemoryUC))
+	registry.Register(command.NewSecretCommand(saveMemoryUC, manageSecretsUC, time.Duration(cfg.SecretReveal)*time.Second))
+	registry.Register(command.NewDeleteCommand(manageTrashUC))
+	registry.Register(command.NewTrashCommand(manageTrashUC))
+	registry.Register(command.NewRestoreCommand(manageTrashUC))
+	registry.Register(command.NewExplainCommand(explainSearchUC))
+	registry.Register(command.NewReindexCommand(reindexSearchUC, admins))
+	if !*inMemory {
+		registry.Register(command.NewWatchCommand(watchMemoryUC))
+		registry.Register(command.NewWatchesCommand(watchMemoryUC))
+		registry.Register(command.NewTagsCommand(manageTagsUC))
+		registry.Register(command.NewRenameTagCommand(manageTagsUC))
+		registry.Register(command.NewMergeTagCommand(manageTagsUC))
+		registry.Register(command.NewTagAliasCommand(manageTagsUC))
+		registry.Register(command.NewHistoryCommand(queryHistoryUC))
+		registry.Register(command.NewSynonymCommand(manageSynonymsUC, admins))
+		registry.Register(command.NewZeroResultsCommand(queryHistoryUC, admins))
+		registry.Register(command.NewBackupCommand(backupDatabaseUC, admins))
+		registry.Register(command.NewDoctorCommand(diagnoseDatabaseUC, admins))
+		registry.Register(command.NewUnlockCommand(manageVaultUC))
+		registry.Register(command.NewLockCommand(manageVaultUC))
+		if rotateKeyUC != nil {
+			registry.Register(command.NewKeyRotationCommand(rotateKeyUC, admins))
+		}
+	}
 
 	// Create Telegram bot
-	bot, err := telegram.NewBot(cfg.TelegramBotToken, registry, saveMemoryUC)
+	bot, err := telegram.NewBot(cfg.TelegramBotToken, registry, saveMemoryUC, editMemoryUC, searchMemoryUC, queryHistoryUC)
 	if err != nil {
 		log.Fatalf("Failed to create bot: %v", err)
 	}
 
-	// Create bot API for scheduler
-	botAPI, err := createTelegramBotAPI(cfg.TelegramBotToken)
-	if err != nil {
-		log.Fatalf("Failed to create bot API for scheduler: %v", err)
-	}
-
 	// Initialize spaced repetition scheduler
 	sr := scheduler.NewSpacedRepetitionScheduler(botAPI, reviewMemoryUC, cfg.ReviewIntervals)
 	sr.Start()
 	defer sr.Stop()
 
+	// Purge memories that have been in the trash longer than the retention period
+	purger := scheduler.NewTrashPurgeScheduler(manageTrashUC)
+	purger.Start()
+	defer purger.Stop()
+
+	// Snapshot the database on a schedule (not in in-memory mode, where nothing persists)
+	if !*inMemory && cfg.BackupInterval > 0 {
+		backups := scheduler.NewBackupScheduler(backupDatabaseUC, time.Duration(cfg.BackupInterval)*time.Hour)
+		backups.Start()
+		defer backups.Stop()
+	}
+
+	// Delete revealed secret memories a restart left in the chats
+	revealDeleter := scheduler.NewRevealDeletionScheduler(botAPI, manageSecretsUC)
+	revealDeleter.Start()
+	defer revealDeleter.Stop()
+
+	// Lock vaults whose session timed out
+	if manageVaultUC != nil {
+		vaultLocker := scheduler.NewVaultLockScheduler(botAPI, manageVaultUC)
+		vaultLocker.Start()
+		defer vaultLocker.Stop()
+	}
+
+	// Move data still encrypted with a previous key to the primary key, resuming after restarts
+	if !*inMemory && rotateKeyUC != nil {
+		keyRotation := job.NewKeyRotationJob(rotateKeyUC)
+		keyRotation.Start()
+		defer keyRotation.Stop()
+	}
+
 	// Handle graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -113,3 +316,120 @@ func createTelegramBotAPI(token string) (*tgbotapi.BotAPI, error) {
 	}
 	return api, nil
 }
+
+// runMigrations prints the schema status of the database and optionally applies pending migrations
+func runMigrations(dbPath string, apply bool) error {
+	conn, err := sqlite.OpenConnection(dbPath)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	migrator, err := sqlite.NewMigrator(conn)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if apply {
+		applied, err := migrator.Migrate(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Applied %d migration(s)", applied)
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Database %s: schema version %d (latest %d)", dbPath, status.Current, status.Latest)
+	for _, migration := range status.Pending {
+		log.Printf("  pending: %04d_%s", migration.Version, migration.Name)
+	}
+	return nil
+}
+
+// runRestore swaps a verified backup snapshot in as the database
+func runRestore(snapshotPath, dbPath string) error {
+	asidePath, err := sqlite.RestoreSnapshot(context.Background(), snapshotPath, dbPath)
+	if err != nil {
+		return err
+	}
+
+	if asidePath != "" {
+		log.Printf("Previous database kept as %s", asidePath)
+	}
+	log.Printf("Restored %s from %s; pending migrations are applied when the bot starts", dbPath, snapshotPath)
+	return nil
+}
+
+// runDoctor checks the database and optionally repairs checks; returns the number of problems left
+func runDoctor(dbPath, repair string) (int, error) {
+	conn, err := sqlite.OpenConnection(dbPath)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	// The checks expect the current schema
+	migrator, err := sqlite.NewMigrator(conn)
+	if err != nil {
+		return 0, err
+	}
+	status, err := migrator.Status(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	if len(status.Pending) > 0 {
+		return 0, fmt.Errorf("schema version %d is behind %d; apply the migrations first with -migrate-only", status.Current, status.Latest)
+	}
+
+	encryptor, err := sqlite.LoadKeyring(context.Background(), conn, config.EncryptionKey(), config.EncryptionOldKeys())
+	if err != nil {
+		return 0, err
+	}
+	policy, err := sqlite.ParseFieldPolicy(config.EncryptedFields())
+	if err != nil {
+		return 0, err
+	}
+	doctor := usecase.NewDiagnoseDatabaseUseCase(sqlite.NewDoctorRepository(conn, encryptor, policy))
+
+	ctx := context.Background()
+	var report *entity.DoctorReport
+	if repair == "" {
+		report, err = doctor.Execute(ctx)
+	} else {
+		var output *usecase.RepairDatabaseOutput
+		output, err = doctor.Repair(ctx, strings.Split(repair, ","))
+		if output != nil {
+			for name, repaired := range output.Repaired {
+				log.Printf("🔧 %s: repaired %d", name, repaired)
+			}
+			report = output.Report
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("Database %s checked", dbPath)
+	for _, check := range report.Checks {
+		switch {
+		case check.Skipped != "":
+			log.Printf("⏭  %s: skipped, %s", check.Name, check.Skipped)
+		case len(check.Findings) == 0:
+			log.Printf("✅ %s", check.Name)
+		default:
+			log.Printf("❌ %s: %d problem(s)", check.Name, len(check.Findings))
+			for _, finding := range check.Findings {
+				log.Printf("     %s", finding)
+			}
+			if check.Repairable() {
+				log.Printf("   repair (-doctor -repair=%s): %s", check.Name, check.Repair)
+			}
+		}
+	}
+	return report.Problems(), nil
+}
@@ -6,4 +6,7 @@ require (
 	github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1
 	github.com/joho/godotenv v1.5.1
 	github.com/mattn/go-sqlite3 v1.14.18
+	golang.org/x/crypto v0.17.0
 )
+
+require golang.org/x/sys v0.15.0 // indirect
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// BackupDatabaseOutput represents the result of a backup run
+type BackupDatabaseOutput struct {
+	Snapshot *entity.BackupSnapshot
+	Removed  int // Old snapshots removed by rotation
+}
+
+// BackupDatabaseUseCase takes database snapshots and rotates old ones
+// The newest `keep` snapshots are kept; with a retention period, older snapshots
+// are removed even within that count. The newest snapshot is never removed.
+type BackupDatabaseUseCase struct {
+	repo          repository.BackupRepository
+	keep          int
+	retentionDays int
+}
+
+// NewBackupDatabaseUseCase creates a new backup use case
+// retentionDays 0 keeps snapshots regardless of their age
+func NewBackupDatabaseUseCase(repo repository.BackupRepository, keep, retentionDays int) *BackupDatabaseUseCase {
+	return &BackupDatabaseUseCase{
+		repo:          repo,
+		keep:          keep,
+		retentionDays: retentionDays,
+	}
+}
+
+// Execute takes a verified snapshot and removes the snapshots that fell out of rotation
+func (uc *BackupDatabaseUseCase) Execute(ctx context.Context) (*BackupDatabaseOutput, error) {
+	snapshot, err := uc.repo.CreateSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Database snapshot written to %s (%d bytes)", snapshot.Path, snapshot.Size)
+
+	removed, err := uc.rotate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BackupDatabaseOutput{
+		Snapshot: snapshot,
+		Removed:  removed,
+	}, nil
+}
+
+// Latest returns the newest snapshot, or ErrNoBackup if there is none
+func (uc *BackupDatabaseUseCase) Latest(ctx context.Context) (*entity.BackupSnapshot, error) {
+	snapshots, err := uc.repo.ListSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, entity.ErrNoBackup
+	}
+	return snapshots[0], nil
+}
+
+// rotate removes snapshots beyond the kept count or older than the retention period
+func (uc *BackupDatabaseUseCase) rotate(ctx context.Context) (int, error) {
+	snapshots, err := uc.repo.ListSnapshots(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -uc.retentionDays)
+	removed := 0
+	for i, snapshot := range snapshots {
+		if i == 0 {
+			continue
+		}
+		expired := uc.retentionDays > 0 && snapshot.CreatedAt.Before(cutoff)
+		if i < uc.keep && !expired {
+			continue
+		}
+
+		if err := uc.repo.DeleteSnapshot(ctx, snapshot); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		log.Printf("Removed %d old database snapshot(s)", removed)
+	}
+	return removed, nil
+}
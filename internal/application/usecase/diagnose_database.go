@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// RepairAll selects every check with repairable findings
+const RepairAll = "all"
+
+// RepairDatabaseOutput represents the result of a repair run
+type RepairDatabaseOutput struct {
+	Repaired map[string]int // Check -> repaired rows, for the checks that were repaired
+	Report   *entity.DoctorReport
+}
+
+// DiagnoseDatabaseUseCase checks the store for inconsistencies and repairs them on request
+type DiagnoseDatabaseUseCase struct {
+	repo repository.DoctorRepository
+}
+
+// NewDiagnoseDatabaseUseCase creates a new diagnose use case
+func NewDiagnoseDatabaseUseCase(repo repository.DoctorRepository) *DiagnoseDatabaseUseCase {
+	return &DiagnoseDatabaseUseCase{
+		repo: repo,
+	}
+}
+
+// Execute runs every check and reports the findings; nothing is changed
+func (uc *DiagnoseDatabaseUseCase) Execute(ctx context.Context) (*entity.DoctorReport, error) {
+	return uc.repo.Diagnose(ctx)
+}
+
+// Repair repairs the named checks ("all" for every repairable one) and checks again
+// Checks without findings or without an automatic repair are skipped
+func (uc *DiagnoseDatabaseUseCase) Repair(ctx context.Context, checks []string) (*RepairDatabaseOutput, error) {
+	report, err := uc.repo.Diagnose(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range checks {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == RepairAll {
+			for _, check := range report.Checks {
+				selected[check.Name] = true
+			}
+			continue
+		}
+		if _, ok := report.Check(name); !ok {
+			return nil, entity.ErrUnknownCheck
+		}
+		selected[name] = true
+	}
+
+	output := &RepairDatabaseOutput{Repaired: make(map[string]int)}
+	for _, check := range report.Checks {
+		if !selected[check.Name] || !check.Repairable() {
+			continue
+		}
+		repaired, err := uc.repo.Repair(ctx, check.Name)
+		if err != nil {
+			return nil, err
+		}
+		output.Repaired[check.Name] = repaired
+	}
+
+	// Report what is left
+	output.Report, err = uc.repo.Diagnose(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
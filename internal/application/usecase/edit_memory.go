@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+	"memory-bot/internal/domain/service"
+)
+
+// EditMemoryInput represents the input for editing a memory
+type EditMemoryInput struct {
+	UserID   int64
+	MemoryID int
+	Content  string
+	Source   string // entity.RevisionSourceEdit or entity.RevisionSourceMessage
+}
+
+// EditMemoryOutput represents the output after editing a memory
+type EditMemoryOutput struct {
+	Memory   *entity.Memory
+	Revision *entity.MemoryRevision
+}
+
+// EditMemoryUseCase changes the content of saved memories and keeps their revision history
+// Tags and emotional weight are derived from the new content like on save
+type EditMemoryUseCase struct {
+	repo              repository.MemoryRepository
+	sentimentAnalyzer *service.SentimentAnalyzer
+	secretDetector    *service.SecretDetector
+}
+
+// NewEditMemoryUseCase creates a new edit memory use case
+func NewEditMemoryUseCase(repo repository.MemoryRepository) *EditMemoryUseCase {
+	return &EditMemoryUseCase{
+		repo:              repo,
+		sentimentAnalyzer: service.NewSentimentAnalyzer(),
+		secretDetector:    service.NewSecretDetector(),
+	}
+}
+
+// Get returns a memory owned by the user
+func (uc *EditMemoryUseCase) Get(ctx context.Context, memoryID int, userID int64) (*entity.Memory, error) {
+	memory, err := uc.repo.FindByID(ctx, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	if memory.UserID != userID {
+		return nil, entity.ErrUnauthorized
+	}
+	return memory, nil
+}
+
+// Execute replaces the content of a memory and records a revision
+func (uc *EditMemoryUseCase) Execute(ctx context.Context, input EditMemoryInput) (*EditMemoryOutput, error) {
+	memory, err := uc.Get(ctx, input.MemoryID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.apply(ctx, memory, input.Content, input.Source, 0)
+}
+
+// EditFromMessage applies an edit of the Telegram message a memory was saved from
+// Returns entity.ErrMemoryNotFound if no memory came from the message
+func (uc *EditMemoryUseCase) EditFromMessage(ctx context.Context, userID, chatID int64, messageID int, content string) (*EditMemoryOutput, error) {
+	memory, err := uc.repo.FindBySourceMessage(ctx, chatID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if memory.UserID != userID {
+		return nil, entity.ErrUnauthorized
+	}
+	return uc.apply(ctx, memory, content, entity.RevisionSourceMessage, 0)
+}
+
+// Revisions returns the history of a memory, oldest first
+func (uc *EditMemoryUseCase) Revisions(ctx context.Context, memoryID int, userID int64) ([]*entity.MemoryRevision, error) {
+	memory, err := uc.Get(ctx, memoryID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.revisionsOf(ctx, memory)
+}
+
+// Rollback restores the content of an earlier revision as a new revision
+func (uc *EditMemoryUseCase) Rollback(ctx context.Context, memoryID int, userID int64, number int) (*EditMemoryOutput, error) {
+	memory, err := uc.Get(ctx, memoryID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := uc.revisionsOf(ctx, memory)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, revision := range revisions {
+		if revision.Number == number {
+			return uc.apply(ctx, memory, revision.Content, entity.RevisionSourceRollback, number)
+		}
+	}
+	return nil, entity.ErrRevisionNotFound
+}
+
+// revisionsOf loads the recorded revisions of a memory
+// A memory that was never edited has a single revision: its current content
+func (uc *EditMemoryUseCase) revisionsOf(ctx context.Context, memory *entity.Memory) ([]*entity.MemoryRevision, error) {
+	if memory.Locked {
+		return nil, entity.ErrVaultLocked
+	}
+	revisions, err := uc.repo.FindRevisions(ctx, memory.ID, memory.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		revisions = []*entity.MemoryRevision{{
+			MemoryID:        memory.ID,
+			Number:          1,
+			Content:         memory.Content,
+			Tags:            memory.Tags,
+			EmotionalWeight: memory.EmotionalWeight,
+			Source:          entity.RevisionSourceOriginal,
+			CreatedAt:       memory.CreatedAt,
+		}}
+	}
+	return revisions, nil
+}
+
+// apply sets the new content, recomputes tags and emotional weight and stores the revision
+// Vaulted memories can only be changed while the vault is unlocked (ErrVaultLocked otherwise).
+func (uc *EditMemoryUseCase) apply(ctx context.Context, memory *entity.Memory, content, source string, restoredFrom int) (*EditMemoryOutput, error) {
+	if memory.Locked {
+		return nil, entity.ErrVaultLocked
+	}
+	previous := memory.Content
+	memory.SetContent(content)
+	if err := memory.Validate(); err != nil {
+		return nil, err
+	}
+	if memory.Content == previous {
+		return nil, entity.ErrContentUnchanged
+	}
+
+	memory.EmotionalWeight = uc.sentimentAnalyzer.Analyze(memory.Content)
+
+	// An edit can make a memory secret, but only /secret off makes it public again
+	memory.Secret = memory.Secret || memory.HasSecretTag() || uc.secretDetector.IsSecret(memory.Content)
+
+	revision, err := uc.repo.UpdateContent(ctx, memory, source, restoredFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EditMemoryOutput{
+		Memory:   memory,
+		Revision: revision,
+	}, nil
+}
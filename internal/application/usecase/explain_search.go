@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+	"memory-bot/internal/domain/service"
+	"memory-bot/internal/infrastructure/search/strategy"
+	"strings"
+	"time"
+)
+
+// ExplainSearchOutput describes how a query is interpreted and answered
+type ExplainSearchOutput struct {
+	Query       string
+	Tag         string                    // Set when the query is a single hashtag
+	Period      string                    // Set when the query is a date expression
+	ContextCue  string                    // Time/day cue detected in the query ("" if none)
+	Explanation *entity.SearchExplanation // nil unless a full-text strategy handles the query
+	Strategy    string                    // Name of the strategy the query is routed to
+	MatchedStep string                    // Strategy step that produced the results
+	ResultCount int
+}
+
+// ExplainSearchUseCase shows how a search query is processed (synonyms, steps, results)
+type ExplainSearchUseCase struct {
+	memoryRepo     repository.MemoryRepository
+	strategies     strategy.SearchStrategyFactory
+	contextService *service.ContextualMetadataService
+}
+
+// NewExplainSearchUseCase creates a new explain search use case
+func NewExplainSearchUseCase(memoryRepo repository.MemoryRepository, strategies strategy.SearchStrategyFactory) *ExplainSearchUseCase {
+	return &ExplainSearchUseCase{
+		memoryRepo:     memoryRepo,
+		strategies:     strategies,
+		contextService: service.NewContextualMetadataService(),
+	}
+}
+
+// Execute explains the query and runs it once to report the matching step
+func (uc *ExplainSearchUseCase) Execute(ctx context.Context, userID int64, query string, limit int) (*ExplainSearchOutput, error) {
+	searchStrategy, searchQuery := uc.strategies.CreateStrategy(strategy.SearchQuery{
+		UserID:  userID,
+		Keyword: strings.TrimSpace(query),
+		Limit:   limit,
+		Trace:   &strategy.SearchTrace{},
+	})
+	query = searchQuery.Keyword
+
+	output := &ExplainSearchOutput{
+		Query:    query,
+		Strategy: searchStrategy.Name(),
+	}
+
+	// Only the full-text strategies expand the query into an FTS expression
+	switch searchStrategy.(type) {
+	case *strategy.TagSearchStrategy:
+		output.Tag = entity.NormalizeTag(query)
+
+	case *strategy.DateBrowseStrategy:
+		if period, ok := service.ParseDateExpression(query, time.Now()); ok {
+			output.Period = period.Description
+		}
+
+	case *strategy.SmartSearchStrategy, *strategy.FuzzySearchStrategy:
+		explanation, err := uc.memoryRepo.ExplainQuery(ctx, userID, query)
+		if err != nil {
+			return nil, err
+		}
+		output.Explanation = explanation
+
+		if contextData, ok := uc.contextService.ExtractContextCue(query); ok {
+			output.ContextCue = uc.contextService.GetContextDescription(contextData)
+		}
+	}
+
+	page, err := searchStrategy.Search(ctx, searchQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	output.MatchedStep = searchQuery.Trace.Step
+	output.ResultCount = len(page.Memories)
+
+	return output, nil
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// FindRelatedMemoriesInput represents the input for "more like this" retrieval
+type FindRelatedMemoriesInput struct {
+	UserID   int64
+	MemoryID int
+	Limit    int
+}
+
+// FindRelatedMemoriesOutput represents the related memories found
+type FindRelatedMemoriesOutput struct {
+	Memories []*entity.Memory
+}
+
+// FindRelatedMemoriesUseCase handles retrieving memories similar to a given memory
+// Biological principle: Associative recall - one memory cues related memories
+type FindRelatedMemoriesUseCase struct {
+	repo repository.MemoryRepository
+}
+
+// NewFindRelatedMemoriesUseCase creates a new find related memories use case
+func NewFindRelatedMemoriesUseCase(repo repository.MemoryRepository) *FindRelatedMemoriesUseCase {
+	return &FindRelatedMemoriesUseCase{
+		repo: repo,
+	}
+}
+
+// Execute retrieves related memories
+func (uc *FindRelatedMemoriesUseCase) Execute(ctx context.Context, input FindRelatedMemoriesInput) (*FindRelatedMemoriesOutput, error) {
+	memories, err := uc.repo.FindRelated(ctx, input.UserID, input.MemoryID, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FindRelatedMemoriesOutput{
+		Memories: memories,
+	}, nil
+}
@@ -10,11 +10,14 @@ import (
 type GetRecentMemoriesInput struct {
 	UserID int64
 	Limit  int
+	Cursor repository.Cursor // NextCursor of the previous page; empty for the newest memories
 }
 
 // GetRecentMemoriesOutput represents the output
 type GetRecentMemoriesOutput struct {
-	Memories []*entity.Memory
+	Memories   []*entity.Memory
+	HasMore    bool
+	NextCursor repository.Cursor
 }
 
 // GetRecentMemoriesUseCase handles retrieving recent memories
@@ -29,14 +32,19 @@ func NewGetRecentMemoriesUseCase(repo repository.MemoryRepository) *GetRecentMem
 	}
 }
 
-// Execute retrieves recent memories
+// Execute retrieves one page of recent memories, newest first
 func (uc *GetRecentMemoriesUseCase) Execute(ctx context.Context, input GetRecentMemoriesInput) (*GetRecentMemoriesOutput, error) {
-	memories, err := uc.repo.GetRecent(ctx, input.UserID, input.Limit)
+	page, err := uc.repo.GetRecent(ctx, input.UserID, repository.PageOptions{
+		Limit:  input.Limit,
+		Cursor: input.Cursor,
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &GetRecentMemoriesOutput{
-		Memories: memories,
+		Memories:   page.Memories,
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
 	}, nil
 }
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// ManageSecretsUseCase marks memories as secret and reveals their content to the owner
+// Secret memories are masked wherever memories are listed; their content is only shown on request
+// and the message revealing it is deleted again, even if the bot restarts in between.
+type ManageSecretsUseCase struct {
+	repo      repository.MemoryRepository
+	deletions repository.PendingDeletionRepository
+}
+
+// NewManageSecretsUseCase creates a new secrets use case
+func NewManageSecretsUseCase(repo repository.MemoryRepository, deletions repository.PendingDeletionRepository) *ManageSecretsUseCase {
+	return &ManageSecretsUseCase{
+		repo:      repo,
+		deletions: deletions,
+	}
+}
+
+// SetSecret marks a memory owned by the user as secret or not
+func (uc *ManageSecretsUseCase) SetSecret(ctx context.Context, memoryID int, userID int64, secret bool) error {
+	return uc.repo.SetSecret(ctx, memoryID, userID, secret)
+}
+
+// Reveal returns a memory owned by the user with its content
+// Returns entity.ErrVaultLocked for a vaulted memory while the vault is locked
+func (uc *ManageSecretsUseCase) Reveal(ctx context.Context, memoryID int, userID int64) (*entity.Memory, error) {
+	memory, err := uc.repo.FindByID(ctx, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	if memory.UserID != userID {
+		return nil, entity.ErrUnauthorized
+	}
+	if memory.Locked {
+		return nil, entity.ErrVaultLocked
+	}
+	return memory, nil
+}
+
+// ScheduleRevealDeletion records that the message revealing a memory must be deleted at the given time
+func (uc *ManageSecretsUseCase) ScheduleRevealDeletion(ctx context.Context, chatID int64, messageID int, at time.Time) (*entity.PendingDeletion, error) {
+	deletion := &entity.PendingDeletion{
+		ChatID:    chatID,
+		MessageID: messageID,
+		DeleteAt:  at,
+	}
+	if err := uc.deletions.Add(ctx, deletion); err != nil {
+		return nil, err
+	}
+	return deletion, nil
+}
+
+// DueRevealDeletions returns the reveal messages that should have been deleted by now
+func (uc *ManageSecretsUseCase) DueRevealDeletions(ctx context.Context) ([]*entity.PendingDeletion, error) {
+	return uc.deletions.FindDue(ctx, time.Now())
+}
+
+// RevealDeleted forgets a reveal message once it is deleted
+func (uc *ManageSecretsUseCase) RevealDeleted(ctx context.Context, id int) error {
+	return uc.deletions.Remove(ctx, id)
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// ManageSynonymsUseCase handles the user's and the global search synonyms
+type ManageSynonymsUseCase struct {
+	synonymRepo repository.SynonymRepository
+}
+
+// NewManageSynonymsUseCase creates a new manage synonyms use case
+func NewManageSynonymsUseCase(synonymRepo repository.SynonymRepository) *ManageSynonymsUseCase {
+	return &ManageSynonymsUseCase{
+		synonymRepo: synonymRepo,
+	}
+}
+
+// Add stores terms as synonyms of each other; sets sharing a term are merged
+// Use entity.GlobalSynonymOwner as userID for synonyms that apply to every user
+func (uc *ManageSynonymsUseCase) Add(ctx context.Context, userID int64, terms []string) (*entity.SynonymSet, error) {
+	return uc.synonymRepo.Add(ctx, entity.NewSynonymSet(userID, terms))
+}
+
+// Remove removes a term from the owner's synonym sets
+func (uc *ManageSynonymsUseCase) Remove(ctx context.Context, userID int64, term string) error {
+	return uc.synonymRepo.RemoveTerm(ctx, userID, term)
+}
+
+// List returns the user's synonym sets followed by the global ones
+func (uc *ManageSynonymsUseCase) List(ctx context.Context, userID int64) ([]*entity.SynonymSet, error) {
+	return uc.synonymRepo.FindForUser(ctx, userID)
+}
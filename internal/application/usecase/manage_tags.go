@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// BrowseTagsOutput represents one level of the tag hierarchy
+type BrowseTagsOutput struct {
+	Tag      string // "" for the top level
+	Count    int    // Memories below Tag (0 for the top level)
+	Children []entity.TagCount
+}
+
+// ManageTagsUseCase handles browsing, renaming, merging and aliasing tags
+type ManageTagsUseCase struct {
+	tagRepo    repository.TagRepository
+	memoryRepo repository.MemoryRepository
+}
+
+// NewManageTagsUseCase creates a new manage tags use case
+func NewManageTagsUseCase(tagRepo repository.TagRepository, memoryRepo repository.MemoryRepository) *ManageTagsUseCase {
+	return &ManageTagsUseCase{
+		tagRepo:    tagRepo,
+		memoryRepo: memoryRepo,
+	}
+}
+
+// Browse returns the sub-tags of tag ("" for the top level) with memory counts
+func (uc *ManageTagsUseCase) Browse(ctx context.Context, userID int64, tag string) (*BrowseTagsOutput, error) {
+	tag = entity.NormalizeTag(tag)
+
+	children, err := uc.tagRepo.ListTags(ctx, userID, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &BrowseTagsOutput{
+		Tag:      tag,
+		Children: children,
+	}
+
+	if tag != "" {
+		output.Count, err = uc.tagRepo.CountTag(ctx, userID, tag)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return output, nil
+}
+
+// Memories returns the newest memories carrying the tag or one of its sub-tags
+func (uc *ManageTagsUseCase) Memories(ctx context.Context, userID int64, tag string, limit int) ([]*entity.Memory, error) {
+	page, err := uc.memoryRepo.SearchByTag(ctx, userID, tag, repository.SearchOptions{Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return page.Memories, nil
+}
+
+// Rename renames a tag; returns the number of affected memories
+func (uc *ManageTagsUseCase) Rename(ctx context.Context, userID int64, from, to string) (int, error) {
+	return uc.tagRepo.Rename(ctx, userID, from, to)
+}
+
+// Merge merges source into target; returns the number of affected memories
+func (uc *ManageTagsUseCase) Merge(ctx context.Context, userID int64, source, target string) (int, error) {
+	return uc.tagRepo.Merge(ctx, userID, source, target)
+}
+
+// SetAlias makes alias an alternative name for tag; returns the stored alias and the number of merged memories
+func (uc *ManageTagsUseCase) SetAlias(ctx context.Context, userID int64, alias, tag string) (*entity.TagAlias, int, error) {
+	tagAlias := &entity.TagAlias{
+		UserID: userID,
+		Alias:  alias,
+		Tag:    tag,
+	}
+
+	merged, err := uc.tagRepo.SetAlias(ctx, tagAlias)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tagAlias, merged, nil
+}
+
+// RemoveAlias deletes an alias of the user
+func (uc *ManageTagsUseCase) RemoveAlias(ctx context.Context, userID int64, alias string) error {
+	return uc.tagRepo.DeleteAlias(ctx, userID, alias)
+}
+
+// ListAliases returns all aliases of the user
+func (uc *ManageTagsUseCase) ListAliases(ctx context.Context, userID int64) ([]*entity.TagAlias, error) {
+	return uc.tagRepo.FindAliases(ctx, userID)
+}
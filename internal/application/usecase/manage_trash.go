@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// TrashListLimit is the maximum number of deleted memories shown by /trash
+const TrashListLimit = 20
+
+// ManageTrashUseCase moves memories to the trash, restores them and purges expired ones
+// Deleted memories are hidden from every read path and the search index until restored
+type ManageTrashUseCase struct {
+	memoryRepo repository.MemoryRepository
+	retention  time.Duration
+}
+
+// NewManageTrashUseCase creates a new trash use case keeping deleted memories for retentionDays
+func NewManageTrashUseCase(memoryRepo repository.MemoryRepository, retentionDays int) *ManageTrashUseCase {
+	return &ManageTrashUseCase{
+		memoryRepo: memoryRepo,
+		retention:  time.Duration(retentionDays) * 24 * time.Hour,
+	}
+}
+
+// Delete moves a memory owned by the user (and its sub-memories) to the trash
+func (uc *ManageTrashUseCase) Delete(ctx context.Context, id int, userID int64) error {
+	return uc.memoryRepo.Delete(ctx, id, userID)
+}
+
+// Restore brings a memory back from the trash and returns how many memories were restored
+func (uc *ManageTrashUseCase) Restore(ctx context.Context, id int, userID int64) (int, error) {
+	return uc.memoryRepo.Restore(ctx, id, userID)
+}
+
+// List returns the user's deleted memories, most recently deleted first
+func (uc *ManageTrashUseCase) List(ctx context.Context, userID int64) ([]*entity.Memory, error) {
+	return uc.memoryRepo.FindDeleted(ctx, userID, TrashListLimit)
+}
+
+// PurgeExpiresAt returns when a deleted memory will be purged
+func (uc *ManageTrashUseCase) PurgeExpiresAt(memory *entity.Memory) time.Time {
+	if memory.DeletedAt == nil {
+		return time.Time{}
+	}
+	return memory.DeletedAt.Add(uc.retention)
+}
+
+// Purge permanently removes memories that have been in the trash longer than the retention period
+func (uc *ManageTrashUseCase) Purge(ctx context.Context) (int, error) {
+	purged, err := uc.memoryRepo.PurgeDeleted(ctx, time.Now().Add(-uc.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	if purged > 0 {
+		log.Printf("Purged %d memories from the trash", purged)
+	}
+	return purged, nil
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// UnlockVaultOutput represents the output after unlocking a vault
+type UnlockVaultOutput struct {
+	Created bool
+	Until   time.Time
+}
+
+// VaultStatus describes the vault of a user
+type VaultStatus struct {
+	Exists   bool
+	Unlocked bool
+	Until    time.Time
+}
+
+// ManageVaultUseCase unlocks and locks the personal vaults of users
+// While a vault is unlocked, new memories of its owner are vaulted with the passphrase-derived key
+type ManageVaultUseCase struct {
+	vaultRepo repository.VaultRepository
+}
+
+// NewManageVaultUseCase creates a new vault use case
+func NewManageVaultUseCase(vaultRepo repository.VaultRepository) *ManageVaultUseCase {
+	return &ManageVaultUseCase{
+		vaultRepo: vaultRepo,
+	}
+}
+
+// Unlock opens the user's vault with passphrase, creating the vault on first use
+// A new vault needs a passphrase of at least entity.MinVaultPassphraseLength characters.
+func (uc *ManageVaultUseCase) Unlock(ctx context.Context, userID int64, passphrase string) (*UnlockVaultOutput, error) {
+	exists, err := uc.vaultRepo.Exists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists && utf8.RuneCountInString(passphrase) < entity.MinVaultPassphraseLength {
+		return nil, entity.ErrWeakPassphrase
+	}
+
+	until, err := uc.vaultRepo.Unlock(ctx, userID, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UnlockVaultOutput{
+		Created: !exists,
+		Until:   until,
+	}, nil
+}
+
+// Lock locks the user's vault; reports whether it was unlocked
+func (uc *ManageVaultUseCase) Lock(userID int64) bool {
+	return uc.vaultRepo.Lock(userID)
+}
+
+// Status reports whether the user has a vault and until when it is unlocked
+func (uc *ManageVaultUseCase) Status(ctx context.Context, userID int64) (*VaultStatus, error) {
+	exists, err := uc.vaultRepo.Exists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	until, unlocked := uc.vaultRepo.UnlockedUntil(userID)
+	return &VaultStatus{
+		Exists:   exists,
+		Unlocked: unlocked,
+		Until:    until,
+	}, nil
+}
+
+// ExpireSessions locks the vaults whose session timed out and returns their users
+func (uc *ManageVaultUseCase) ExpireSessions() []int64 {
+	expired := uc.vaultRepo.ExpireSessions()
+	if len(expired) > 0 {
+		log.Printf("🔒 Locked %d vault(s) after their session timed out", len(expired))
+	}
+	return expired
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// QueryHistoryUseCase records searches of opted-in users and serves suggestions
+// and zero-result analytics. It observes SearchMemoryUseCase.
+type QueryHistoryUseCase struct {
+	historyRepo  repository.QueryHistoryRepository
+	settingsRepo repository.UserSettingsRepository
+}
+
+// NewQueryHistoryUseCase creates a new query history use case
+func NewQueryHistoryUseCase(
+	historyRepo repository.QueryHistoryRepository,
+	settingsRepo repository.UserSettingsRepository,
+) *QueryHistoryUseCase {
+	return &QueryHistoryUseCase{
+		historyRepo:  historyRepo,
+		settingsRepo: settingsRepo,
+	}
+}
+
+// OnSearch records the first page of a search if the user opted in
+func (uc *QueryHistoryUseCase) OnSearch(ctx context.Context, input SearchMemoryInput, output *SearchMemoryOutput) error {
+	if input.SkipHistory || input.Cursor != "" {
+		return nil
+	}
+
+	enabled, err := uc.IsEnabled(ctx, input.UserID)
+	if err != nil || !enabled {
+		return err
+	}
+
+	entry := entity.NewQueryLogEntry(input.UserID, input.Keyword, len(output.Memories), output.MatchedStep)
+	return uc.historyRepo.Record(ctx, entry)
+}
+
+// IsEnabled reports whether the user opted in to query history
+func (uc *QueryHistoryUseCase) IsEnabled(ctx context.Context, userID int64) (bool, error) {
+	settings, err := uc.settingsRepo.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return settings.QueryHistory, nil
+}
+
+// SetEnabled turns query history on or off; turning it off deletes the stored history
+func (uc *QueryHistoryUseCase) SetEnabled(ctx context.Context, userID int64, enabled bool) error {
+	settings, err := uc.settingsRepo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	settings.QueryHistory = enabled
+	if err := uc.settingsRepo.Save(ctx, settings); err != nil {
+		return err
+	}
+
+	if !enabled {
+		return uc.historyRepo.DeleteByUser(ctx, userID)
+	}
+	return nil
+}
+
+// Clear deletes the user's stored history
+func (uc *QueryHistoryUseCase) Clear(ctx context.Context, userID int64) error {
+	return uc.historyRepo.DeleteByUser(ctx, userID)
+}
+
+// Suggestions returns up to limit queries for autocomplete: recent ones first, then frequent ones
+func (uc *QueryHistoryUseCase) Suggestions(ctx context.Context, userID int64, limit int) ([]string, error) {
+	enabled, err := uc.IsEnabled(ctx, userID)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	recent, err := uc.historyRepo.Recent(ctx, userID, (limit+1)/2)
+	if err != nil {
+		return nil, err
+	}
+
+	frequent, err := uc.historyRepo.Frequent(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []string{}
+	seen := make(map[string]bool)
+	add := func(query string) {
+		key := entity.NormalizeQuery(query)
+		if len(suggestions) < limit && key != "" && !seen[key] {
+			seen[key] = true
+			suggestions = append(suggestions, query)
+		}
+	}
+
+	for _, query := range recent {
+		add(query)
+	}
+	for _, stat := range frequent {
+		add(stat.Query)
+	}
+
+	return suggestions, nil
+}
+
+// ZeroResultReportOutput represents the admin report on failing searches
+type ZeroResultReportOutput struct {
+	Queries []entity.QueryStat
+	Steps   []entity.StepStat
+}
+
+// ZeroResultReport returns the queries that most often return nothing and
+// how searches are distributed over the strategy steps
+func (uc *QueryHistoryUseCase) ZeroResultReport(ctx context.Context, limit int) (*ZeroResultReportOutput, error) {
+	queries, err := uc.historyRepo.ZeroResults(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := uc.historyRepo.StepStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ZeroResultReportOutput{
+		Queries: queries,
+		Steps:   steps,
+	}, nil
+}
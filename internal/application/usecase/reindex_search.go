@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"memory-bot/internal/domain/repository"
+	"time"
+)
+
+// ReindexSearchOutput represents the result of a reindex run
+type ReindexSearchOutput struct {
+	IndexedMemories int
+	Tokenizer       string
+	Duration        time.Duration
+}
+
+// ReindexSearchUseCase handles on-demand rebuilding of the search index
+type ReindexSearchUseCase struct {
+	index repository.SearchIndexRepository
+}
+
+// NewReindexSearchUseCase creates a new reindex search use case
+func NewReindexSearchUseCase(index repository.SearchIndexRepository) *ReindexSearchUseCase {
+	return &ReindexSearchUseCase{
+		index: index,
+	}
+}
+
+// Execute rebuilds the search index
+func (uc *ReindexSearchUseCase) Execute(ctx context.Context) (*ReindexSearchOutput, error) {
+	start := time.Now()
+
+	count, err := uc.index.Reindex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReindexSearchOutput{
+		IndexedMemories: count,
+		Tokenizer:       uc.index.Tokenizer(),
+		Duration:        time.Since(start),
+	}, nil
+}
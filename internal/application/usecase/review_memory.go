@@ -9,11 +9,15 @@ import (
 // ReviewMemoryInput represents the input for reviewing memories
 type ReviewMemoryInput struct {
 	Intervals []int
+	Limit     int
+	Cursor    repository.Cursor // NextCursor of the previous batch; empty for the first
 }
 
 // ReviewMemoryOutput represents memories that need review
 type ReviewMemoryOutput struct {
-	Memories []*entity.Memory
+	Memories   []*entity.Memory
+	HasMore    bool
+	NextCursor repository.Cursor
 }
 
 // ReviewMemoryUseCase handles the spaced repetition review logic
@@ -28,15 +32,20 @@ func NewReviewMemoryUseCase(repo repository.MemoryRepository) *ReviewMemoryUseCa
 	}
 }
 
-// Execute retrieves memories that need review
+// Execute retrieves one batch of memories that need review, longest unreviewed first
 func (uc *ReviewMemoryUseCase) Execute(ctx context.Context, input ReviewMemoryInput) (*ReviewMemoryOutput, error) {
-	memories, err := uc.repo.GetForReview(ctx, input.Intervals)
+	page, err := uc.repo.GetForReview(ctx, input.Intervals, repository.PageOptions{
+		Limit:  input.Limit,
+		Cursor: input.Cursor,
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &ReviewMemoryOutput{
-		Memories: memories,
+		Memories:   page.Memories,
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
 	}, nil
 }
 
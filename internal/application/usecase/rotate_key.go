@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// keyRotationBatchSize is the number of rows re-encrypted per transaction
+const keyRotationBatchSize = 200
+
+// RotateKeyUseCase re-encrypts stored data with the primary encryption key
+// Each batch is committed with its checkpoint, so a run stopped by a crash or
+// shutdown continues where it left off the next time it is executed.
+type RotateKeyUseCase struct {
+	repo    repository.KeyRotationRepository
+	running atomic.Bool
+}
+
+// NewRotateKeyUseCase creates a new key rotation use case
+func NewRotateKeyUseCase(repo repository.KeyRotationRepository) *RotateKeyUseCase {
+	return &RotateKeyUseCase{
+		repo: repo,
+	}
+}
+
+// Progress reports how far the rotation to the primary key has come
+func (uc *RotateKeyUseCase) Progress(ctx context.Context) (*entity.KeyRotationProgress, error) {
+	return uc.repo.Progress(ctx)
+}
+
+// Running reports whether a rotation run is in progress
+func (uc *RotateKeyUseCase) Running() bool {
+	return uc.running.Load()
+}
+
+// Execute re-encrypts batch by batch until every row uses the primary key or ctx is done
+// Returns ErrRotationRunning if another run is in progress
+func (uc *RotateKeyUseCase) Execute(ctx context.Context) (*entity.KeyRotationProgress, error) {
+	if !uc.running.CompareAndSwap(false, true) {
+		return nil, entity.ErrRotationRunning
+	}
+	defer uc.running.Store(false)
+
+	progress, err := uc.repo.Progress(ctx)
+	if err != nil || progress.Complete {
+		return progress, err
+	}
+
+	log.Printf("🔑 Re-encrypting stored data with key %s (%d/%d rows done)", progress.KeyID, progress.Processed, progress.Total)
+	logged := progress.Percent() / 10
+	for !progress.Complete {
+		if err := ctx.Err(); err != nil {
+			log.Printf("Key rotation paused at %d/%d rows", progress.Processed, progress.Total)
+			return progress, err
+		}
+
+		progress, err = uc.repo.ReencryptBatch(ctx, keyRotationBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		// Report every 10%
+		if step := progress.Percent() / 10; step > logged {
+			logged = step
+			log.Printf("🔑 Key rotation: %d/%d rows (%d%%)", progress.Processed, progress.Total, progress.Percent())
+		}
+	}
+
+	log.Printf("✅ Key rotation complete: stored data uses key %s", progress.KeyID)
+	return progress, nil
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"log"
 	"memory-bot/internal/domain/entity"
 	"memory-bot/internal/domain/repository"
 	"memory-bot/internal/domain/service"
@@ -13,6 +14,13 @@ type SaveMemoryInput struct {
 	UserID  int64
 	ChatID  int64
 	Content string
+
+	// MessageID is the Telegram message the content came from (0 if none);
+	// edits of that message update the memory
+	MessageID int
+
+	// Secret saves the memory as secret (/secret); #secret and credentials in the content do too
+	Secret bool
 }
 
 // SaveMemoryOutput represents the output after saving a memory
@@ -21,29 +29,47 @@ type SaveMemoryOutput struct {
 	Tags            []string
 	EmotionalWeight float64
 	Context         string
+	Secret          bool
+}
+
+// MemorySavedObserver is notified after a memory has been stored (Observer Pattern)
+type MemorySavedObserver interface {
+	// OnMemorySaved is called with the stored memory, including its new ID
+	OnMemorySaved(ctx context.Context, memory *entity.Memory) error
 }
 
 // SaveMemoryUseCase handles the business logic for saving memories
 // Simulates the Hippocampus encoding new memories with emotional and contextual tags
 type SaveMemoryUseCase struct {
 	repo              repository.MemoryRepository
+	transactor        repository.Transactor
 	sentimentAnalyzer *service.SentimentAnalyzer
 	contextService    *service.ContextualMetadataService
+	secretDetector    *service.SecretDetector
+	observers         []MemorySavedObserver
 }
 
 // NewSaveMemoryUseCase creates a new save memory use case
-func NewSaveMemoryUseCase(repo repository.MemoryRepository) *SaveMemoryUseCase {
+func NewSaveMemoryUseCase(repo repository.MemoryRepository, transactor repository.Transactor) *SaveMemoryUseCase {
 	return &SaveMemoryUseCase{
 		repo:              repo,
+		transactor:        transactor,
 		sentimentAnalyzer: service.NewSentimentAnalyzer(),
 		contextService:    service.NewContextualMetadataService(),
+		secretDetector:    service.NewSecretDetector(),
 	}
 }
 
+// Subscribe registers an observer that is notified about every saved memory
+func (uc *SaveMemoryUseCase) Subscribe(observer MemorySavedObserver) {
+	uc.observers = append(uc.observers, observer)
+}
+
 // Execute saves a new memory with biological encoding
 func (uc *SaveMemoryUseCase) Execute(ctx context.Context, input SaveMemoryInput) (*SaveMemoryOutput, error) {
 	// 1. Create new memory entity (Sensory Input Processing)
 	memory := entity.NewMemory(input.UserID, input.ChatID, input.Content)
+	memory.SourceMessageID = input.MessageID
 
 	// 2. Emotional Encoding (The Amygdala's role)
 	// Analyze emotional content and tag the memory
@@ -56,21 +82,39 @@ func (uc *SaveMemoryUseCase) Execute(ctx context.Context, input SaveMemoryInput)
 	memory.DayOfWeek = contextData.DayOfWeek
 	memory.ChatSource = contextData.ChatSource
 
+	// Secret memories are masked when listed and kept out of the plaintext search index
+	memory.Secret = input.Secret || memory.HasSecretTag() || uc.secretDetector.IsSecret(memory.Content)
+
 	// 4. Validate
 	if err := memory.Validate(); err != nil {
 		return nil, err
 	}
 
 	// 5. Consolidation (Save to Cortex - long-term storage)
-	id, err := uc.repo.Save(ctx, memory)
+	// The memory, its tags and search index entries are committed together
+	var id int64
+	err := uc.transactor.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = uc.repo.Save(ctx, memory)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	memory.ID = int(id)
+
+	// 6. Notify observers (saved searches, etc.) after the commit - failures never fail the save
+	for _, observer := range uc.observers {
+		if err := observer.OnMemorySaved(ctx, memory); err != nil {
+			log.Printf("Memory saved observer failed for memory %d: %v", id, err)
+		}
+	}
 
 	return &SaveMemoryOutput{
 		MemoryID:        id,
 		Tags:            memory.Tags,
 		EmotionalWeight: memory.EmotionalWeight,
 		Context:         uc.contextService.GetContextDescription(contextData),
+		Secret:          memory.Secret,
 	}, nil
 }
@@ -2,62 +2,87 @@ package usecase
 
 import (
 	"context"
+	"log"
 	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
 	"memory-bot/internal/infrastructure/search/strategy"
 )
 
 // SearchMemoryInput represents the input for searching memories
 type SearchMemoryInput struct {
-	UserID  int64
-	Keyword string
-	Limit   int
-	Offset  int
+	UserID      int64
+	Keyword     string
+	Limit       int
+	Cursor      repository.Cursor // NextCursor of the previous page; empty for the first page
+	SkipHistory bool              // Set for as-you-type searches (inline mode) that shouldn't be logged
 }
 
 // SearchMemoryOutput represents the output after searching memories
 type SearchMemoryOutput struct {
-	Memories []*entity.Memory
-	Total    int
-	HasMore  bool
+	Memories    []*entity.Memory
+	Total       int
+	HasMore     bool
+	NextCursor  repository.Cursor // Continues the search after this page
+	MatchedStep string            // Strategy step that produced the results
+	Strategy    string            // Name of the strategy that ran the search
+}
+
+// SearchObserver is notified after a search has run (Observer Pattern)
+type SearchObserver interface {
+	// OnSearch is called with the input and output of every search
+	OnSearch(ctx context.Context, input SearchMemoryInput, output *SearchMemoryOutput) error
 }
 
 // SearchMemoryUseCase handles the business logic for searching memories
 type SearchMemoryUseCase struct {
-	strategy strategy.SearchStrategy
+	strategies strategy.SearchStrategyFactory
+	observers  []SearchObserver
 }
 
 // NewSearchMemoryUseCase creates a new search memory use case
-func NewSearchMemoryUseCase(searchStrategy strategy.SearchStrategy) *SearchMemoryUseCase {
+func NewSearchMemoryUseCase(strategies strategy.SearchStrategyFactory) *SearchMemoryUseCase {
 	return &SearchMemoryUseCase{
-		strategy: searchStrategy,
+		strategies: strategies,
 	}
 }
 
-// Execute searches for memories using the configured strategy
+// Subscribe registers an observer that is notified about every search
+func (uc *SearchMemoryUseCase) Subscribe(observer SearchObserver) {
+	uc.observers = append(uc.observers, observer)
+}
+
+// Execute searches for memories using the strategy the factory picks for the keyword
 func (uc *SearchMemoryUseCase) Execute(ctx context.Context, input SearchMemoryInput) (*SearchMemoryOutput, error) {
 	// Create search query
-	query := strategy.SearchQuery{
+	searchStrategy, query := uc.strategies.CreateStrategy(strategy.SearchQuery{
 		UserID:  input.UserID,
 		Keyword: input.Keyword,
-		Limit:   input.Limit + 1, // Fetch one extra to check if there are more
-		Offset:  input.Offset,
-	}
+		Limit:   input.Limit,
+		Cursor:  input.Cursor,
+		Trace:   &strategy.SearchTrace{},
+	})
 
 	// Execute search
-	memories, err := uc.strategy.Search(ctx, query)
+	page, err := searchStrategy.Search(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if there are more results
-	hasMore := len(memories) > input.Limit
-	if hasMore {
-		memories = memories[:input.Limit]
+	output := &SearchMemoryOutput{
+		Memories:    page.Memories,
+		Total:       len(page.Memories),
+		HasMore:     page.HasMore,
+		NextCursor:  page.NextCursor,
+		MatchedStep: query.Trace.Step,
+		Strategy:    searchStrategy.Name(),
+	}
+
+	// Notify observers (failures must not break the search)
+	for _, observer := range uc.observers {
+		if err := observer.OnSearch(ctx, input, output); err != nil {
+			log.Printf("Search observer failed for user %d: %v", input.UserID, err)
+		}
 	}
 
-	return &SearchMemoryOutput{
-		Memories: memories,
-		Total:    len(memories),
-		HasMore:  hasMore,
-	}, nil
+	return output, nil
 }
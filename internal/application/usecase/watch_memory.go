@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// WatchNotifier delivers notifications about new memories matching a saved search
+type WatchNotifier interface {
+	// NotifyWatchMatch tells the watch owner that a memory matched the watch
+	NotifyWatchMatch(ctx context.Context, watch *entity.SavedSearch, memory *entity.Memory) error
+}
+
+// AddWatchInput represents the input for creating a saved search
+type AddWatchInput struct {
+	UserID int64
+	ChatID int64
+	Name   string
+	Query  string
+}
+
+// WatchMemoryUseCase manages saved searches and notifies users about new matches
+// It observes SaveMemoryUseCase and matches new memories through the repository's FTS
+type WatchMemoryUseCase struct {
+	watchRepo  repository.SavedSearchRepository
+	memoryRepo repository.MemoryRepository
+	notifier   WatchNotifier
+}
+
+// NewWatchMemoryUseCase creates a new watch memory use case
+func NewWatchMemoryUseCase(
+	watchRepo repository.SavedSearchRepository,
+	memoryRepo repository.MemoryRepository,
+	notifier WatchNotifier,
+) *WatchMemoryUseCase {
+	return &WatchMemoryUseCase{
+		watchRepo:  watchRepo,
+		memoryRepo: memoryRepo,
+		notifier:   notifier,
+	}
+}
+
+// Add stores a new saved search for the user
+func (uc *WatchMemoryUseCase) Add(ctx context.Context, input AddWatchInput) (*entity.SavedSearch, error) {
+	watch := entity.NewSavedSearch(input.UserID, input.ChatID, input.Name, input.Query)
+	if err := watch.Validate(); err != nil {
+		return nil, err
+	}
+
+	id, err := uc.watchRepo.Save(ctx, watch)
+	if err != nil {
+		return nil, err
+	}
+	watch.ID = int(id)
+
+	return watch, nil
+}
+
+// List returns all saved searches of the user
+func (uc *WatchMemoryUseCase) List(ctx context.Context, userID int64) ([]*entity.SavedSearch, error) {
+	return uc.watchRepo.FindByUser(ctx, userID)
+}
+
+// Remove deletes a saved search owned by the user
+func (uc *WatchMemoryUseCase) Remove(ctx context.Context, id int, userID int64) error {
+	return uc.watchRepo.Delete(ctx, id, userID)
+}
+
+// OnMemorySaved checks a newly saved memory against the owner's saved searches
+func (uc *WatchMemoryUseCase) OnMemorySaved(ctx context.Context, memory *entity.Memory) error {
+	watches, err := uc.watchRepo.FindByUser(ctx, memory.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, watch := range watches {
+		matches, err := uc.memoryRepo.MatchesQuery(ctx, memory.ID, memory.UserID, watch.Query)
+		if err != nil {
+			log.Printf("Error matching memory %d against watch %d: %v", memory.ID, watch.ID, err)
+			continue
+		}
+		if !matches {
+			continue
+		}
+
+		if err := uc.notifier.NotifyWatchMatch(ctx, watch, memory); err != nil {
+			log.Printf("Error notifying watch %d for memory %d: %v", watch.ID, memory.ID, err)
+		}
+	}
+
+	return nil
+}
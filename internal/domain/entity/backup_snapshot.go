@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+// BackupSnapshot is a verified copy of the database
+type BackupSnapshot struct {
+	Path      string
+	Size      int64 // Bytes
+	CreatedAt time.Time
+}
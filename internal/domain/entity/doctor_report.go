@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Doctor checks, in the order they run
+const (
+	CheckIntegrity  = "integrity"   // SQLite pages and indexes (PRAGMA integrity_check)
+	CheckFTS        = "fts"         // FTS5 index structure ('integrity-check')
+	CheckFTSOrphans = "fts_orphans" // Index entries without a live memory
+	CheckFTSMissing = "fts_missing" // Live memories missing from the index
+	CheckParents    = "parents"     // parent_id pointing at a missing or another user's memory
+	CheckCiphertext = "ciphertext"  // Encrypted values that fail to decrypt with every configured key
+	CheckTags       = "tags"        // Tag index rows or tag tokens that don't match the stored tags
+)
+
+// DoctorChecks lists the checks in the order they run
+func DoctorChecks() []string {
+	return []string{CheckIntegrity, CheckFTS, CheckFTSOrphans, CheckFTSMissing, CheckParents, CheckCiphertext, CheckTags}
+}
+
+// DoctorFinding is one problem found by a check
+type DoctorFinding struct {
+	Table  string
+	RowID  int // 0 when the problem is not about one row
+	Detail string
+}
+
+// String describes the finding ("memories #12: parent #40 does not exist")
+func (f DoctorFinding) String() string {
+	if f.RowID == 0 {
+		return f.Detail
+	}
+	return fmt.Sprintf("%s #%d: %s", f.Table, f.RowID, f.Detail)
+}
+
+// DoctorCheck is the outcome of one check
+type DoctorCheck struct {
+	Name     string
+	Findings []DoctorFinding
+	Repair   string // What the automatic repair does; empty without one
+	Skipped  string // Why the check did not run; empty if it ran
+}
+
+// Repairable reports whether the findings can be repaired automatically
+func (c *DoctorCheck) Repairable() bool {
+	return len(c.Findings) > 0 && c.Repair != ""
+}
+
+// DoctorReport is the outcome of all checks
+type DoctorReport struct {
+	Checks    []*DoctorCheck
+	CheckedAt time.Time
+}
+
+// Problems returns the number of findings over all checks
+func (r *DoctorReport) Problems() int {
+	problems := 0
+	for _, check := range r.Checks {
+		problems += len(check.Findings)
+	}
+	return problems
+}
+
+// Check returns the outcome of the named check
+func (r *DoctorReport) Check(name string) (*DoctorCheck, bool) {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return nil, false
+}
@@ -10,4 +10,18 @@ var (
 	ErrMemoryNotFound     = errors.New("memory not found")
 	ErrUnauthorized       = errors.New("unauthorized access to memory")
 	ErrInvalidSearchQuery = errors.New("invalid search query")
+	ErrInvalidTag         = errors.New("invalid tag")
+	ErrTagNotFound        = errors.New("tag not found")
+	ErrTagExists          = errors.New("tag already exists")
+	ErrInvalidSynonym     = errors.New("a synonym set needs at least two different terms")
+	ErrSynonymNotFound    = errors.New("synonym not found")
+	ErrContentUnchanged   = errors.New("memory content is unchanged")
+	ErrRevisionNotFound   = errors.New("revision not found")
+	ErrNoBackup           = errors.New("no backup snapshot available")
+	ErrInvalidCursor      = errors.New("invalid page cursor")
+	ErrUnknownCheck       = errors.New("unknown doctor check")
+	ErrRotationRunning    = errors.New("key rotation is already running")
+	ErrVaultLocked        = errors.New("vault is locked")
+	ErrWrongPassphrase    = errors.New("wrong vault passphrase")
+	ErrWeakPassphrase     = errors.New("vault passphrase is too short")
 )
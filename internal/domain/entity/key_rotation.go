@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// KeyRotationProgress reports how far stored data has been re-encrypted with the primary key
+type KeyRotationProgress struct {
+	KeyID        string   // Primary key the rows are moved to
+	PreviousKeys []string // Other keys that can still decrypt
+	Processed    int      // Rows already re-encrypted
+	Total        int
+	Complete     bool
+	UpdatedAt    time.Time // Last checkpoint; zero before the first batch
+}
+
+// Percent returns the share of re-encrypted rows
+func (p *KeyRotationProgress) Percent() int {
+	if p.Complete || p.Total == 0 {
+		return 100
+	}
+	return p.Processed * 100 / p.Total
+}
+
+// Pending reports whether rows may still use a previous key, an older envelope
+// format or no encryption at all
+func (p *KeyRotationProgress) Pending() bool {
+	return !p.Complete
+}
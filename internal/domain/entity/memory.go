@@ -30,8 +30,27 @@ type Memory struct {
 
 	// Memory Chunking (Hierarchical memory organization)
 	ParentID *int64 // Points to parent memory for sub-memories (nil for root memories)
+
+	// Trash
+	DeletedAt *time.Time // When the memory was moved to the trash (nil for live memories)
+
+	// Editing
+	SourceMessageID int // Telegram message the memory was saved from (0 if unknown)
+
+	// Vault
+	Vaulted bool // Content is wrapped with the owner's vault key
+	Locked  bool // Content that can't be read: vaulted while the vault is locked (Content is LockedContent) or damaged (UndecryptableContent)
+
+	// Secret memories are masked in listings and kept out of the plaintext search index
+	Secret bool
 }
 
+// LockedContent stands in for the content of a vaulted memory while its vault is locked
+const LockedContent = "🔒 Locked in your vault. Use /unlock to read it."
+
+// UndecryptableContent stands in for the content of a listed memory whose stored value does not decrypt
+const UndecryptableContent = "⚠️ This memory can't be decrypted."
+
 // NewMemory creates a new Memory entity with validation
 func NewMemory(userID, chatID int64, content string) *Memory {
 	memory := &Memory{
@@ -82,15 +101,17 @@ func (m *Memory) MarkAsReviewed() {
 	m.ReviewCount++
 }
 
-// extractTags extracts hashtags from the memory content
+// extractTags extracts normalized, de-duplicated hashtags from the memory content
 func (m *Memory) extractTags() []string {
 	var tags []string
+	seen := make(map[string]bool)
 	words := strings.Fields(m.Content)
 
 	for _, word := range words {
 		if strings.HasPrefix(word, "#") {
-			tag := strings.TrimPrefix(word, "#")
-			if tag != "" {
+			tag := NormalizeTag(word)
+			if tag != "" && !seen[tag] {
+				seen[tag] = true
 				tags = append(tags, tag)
 			}
 		}
@@ -99,6 +120,12 @@ func (m *Memory) extractTags() []string {
 	return tags
 }
 
+// SetContent replaces the content and extracts the tags again
+func (m *Memory) SetContent(content string) {
+	m.Content = strings.TrimSpace(content)
+	m.Tags = m.extractTags()
+}
+
 // GetTagsString returns tags as a space-separated string for storage
 func (m *Memory) GetTagsString() string {
 	return strings.Join(m.Tags, " ")
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// Revision sources
+const (
+	RevisionSourceOriginal = "original" // The content as first saved
+	RevisionSourceEdit     = "edit"     // Changed with /edit
+	RevisionSourceMessage  = "message"  // The Telegram message the memory came from was edited
+	RevisionSourceRollback = "rollback" // An earlier revision was restored
+)
+
+// MemoryRevision is one version of a memory's content
+// Revision 1 is the original content; it is recorded when the memory is first edited
+type MemoryRevision struct {
+	ID              int
+	MemoryID        int
+	Number          int
+	Content         string
+	Tags            []string
+	EmotionalWeight float64
+	Source          string
+	RestoredFrom    int // Revision number restored by a rollback (0 otherwise)
+	CreatedAt       time.Time
+}
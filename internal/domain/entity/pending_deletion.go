@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// PendingDeletion is a bot message to delete from a chat once it is due
+// Revealed secret memories are deleted this way after a few seconds.
+type PendingDeletion struct {
+	ID        int
+	ChatID    int64
+	MessageID int
+	DeleteAt  time.Time
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// QueryLogEntry records one search a user ran (stored only if the user opted in)
+type QueryLogEntry struct {
+	ID          int
+	UserID      int64
+	Query       string
+	ResultCount int
+	MatchedStep string // Search strategy step that produced the results ("" if none)
+	CreatedAt   time.Time
+}
+
+// NewQueryLogEntry creates a new QueryLogEntry entity
+func NewQueryLogEntry(userID int64, query string, resultCount int, matchedStep string) *QueryLogEntry {
+	return &QueryLogEntry{
+		UserID:      userID,
+		Query:       strings.TrimSpace(query),
+		ResultCount: resultCount,
+		MatchedStep: matchedStep,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Validate checks if the query log entry is valid
+func (e *QueryLogEntry) Validate() error {
+	if e.UserID == 0 {
+		return ErrInvalidUserID
+	}
+	if e.Query == "" {
+		return ErrInvalidSearchQuery
+	}
+	return nil
+}
+
+// QueryStat aggregates the runs of one (normalized) query
+type QueryStat struct {
+	Query    string
+	Count    int
+	Users    int
+	LastSeen time.Time
+}
+
+// StepStat counts the searches answered by one strategy step
+type StepStat struct {
+	Step  string
+	Count int
+}
+
+// NormalizeQuery folds case and whitespace so equivalent queries group together
+func NormalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
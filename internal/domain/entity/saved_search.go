@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// SavedSearch represents a watched query that notifies the user about new matches
+type SavedSearch struct {
+	ID        int
+	UserID    int64
+	ChatID    int64 // Chat that receives the match notifications
+	Name      string
+	Query     string
+	CreatedAt time.Time
+}
+
+// NewSavedSearch creates a new SavedSearch entity
+// The query doubles as the name when no explicit name is given
+func NewSavedSearch(userID, chatID int64, name, query string) *SavedSearch {
+	query = strings.TrimSpace(query)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = query
+	}
+
+	return &SavedSearch{
+		UserID:    userID,
+		ChatID:    chatID,
+		Name:      name,
+		Query:     query,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Validate checks if the saved search is valid
+func (s *SavedSearch) Validate() error {
+	if s.UserID == 0 {
+		return ErrInvalidUserID
+	}
+	if s.ChatID == 0 {
+		return ErrInvalidChatID
+	}
+	if s.Query == "" {
+		return ErrInvalidSearchQuery
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+package entity
+
+// SearchExplanation describes how a search query is turned into a full-text expression
+type SearchExplanation struct {
+	Expression string              // Full-text expression sent to the index
+	Expansions map[string][]string // Query term -> synonyms it was expanded with
+	BlindIndex bool                // Expression terms are converted to blind tokens before matching
+}
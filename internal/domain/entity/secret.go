@@ -0,0 +1,29 @@
+package entity
+
+import "strings"
+
+// SecretTag marks a memory as secret when used as a hashtag (#secret, #secret/wifi)
+const SecretTag = "secret"
+
+// HasSecretTag reports whether the memory is tagged #secret or one of its sub-tags
+func (m *Memory) HasSecretTag() bool {
+	for _, tag := range m.Tags {
+		if tag == SecretTag || strings.HasPrefix(tag, SecretTag+TagSeparator) {
+			return true
+		}
+	}
+	return false
+}
+
+// Masked returns the content to show in listings: secret memories only show their tags
+func (m *Memory) Masked() string {
+	if !m.Secret || m.Locked {
+		return m.Content
+	}
+
+	masked := "🤫 Secret memory"
+	if len(m.Tags) > 0 {
+		masked += " #" + strings.Join(m.Tags, " #")
+	}
+	return masked
+}
@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// GlobalSynonymOwner is the user ID of synonym sets that apply to every user
+const GlobalSynonymOwner int64 = 0
+
+// SynonymSet is a group of interchangeable search terms ("k8s", "kube", "kubernetes")
+// Every term of a set expands to all the others, so a set works in both directions
+type SynonymSet struct {
+	ID        int
+	UserID    int64 // GlobalSynonymOwner for sets shared by all users
+	Terms     []string
+	CreatedAt time.Time
+}
+
+// NewSynonymSet creates a new SynonymSet entity with normalized, de-duplicated terms
+func NewSynonymSet(userID int64, terms []string) *SynonymSet {
+	set := &SynonymSet{
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+
+	seen := make(map[string]bool)
+	for _, term := range terms {
+		term = NormalizeSynonymTerm(term)
+		if term != "" && !seen[term] {
+			seen[term] = true
+			set.Terms = append(set.Terms, term)
+		}
+	}
+
+	return set
+}
+
+// IsGlobal reports whether the set applies to every user
+func (s *SynonymSet) IsGlobal() bool {
+	return s.UserID == GlobalSynonymOwner
+}
+
+// Validate checks if the synonym set is valid
+func (s *SynonymSet) Validate() error {
+	if len(s.Terms) < 2 {
+		return ErrInvalidSynonym
+	}
+	return nil
+}
+
+// NormalizeSynonymTerm folds case and whitespace of a term; quotes and wildcards are dropped
+func NormalizeSynonymTerm(term string) string {
+	term = strings.Map(func(r rune) rune {
+		if r == '"' || r == '*' {
+			return -1
+		}
+		return r
+	}, term)
+	return strings.Join(strings.Fields(strings.ToLower(term)), " ")
+}
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TagSeparator separates the levels of a hierarchical tag (#work/projectx)
+const TagSeparator = "/"
+
+// The zero width joiners select conjunct forms in Sinhala and Devanagari tags
+const (
+	zeroWidthJoiner    = '\u200d'
+	zeroWidthNonJoiner = '\u200c'
+)
+
+// TagCount is a tag with the number of memories carrying it (or one of its sub-tags)
+type TagCount struct {
+	Name        string // Full tag path, e.g. "work/projectx"
+	Count       int
+	HasChildren bool
+}
+
+// Label returns the last level of the tag path
+func (t TagCount) Label() string {
+	if i := strings.LastIndex(t.Name, TagSeparator); i >= 0 {
+		return t.Name[i+1:]
+	}
+	return t.Name
+}
+
+// TagAlias maps an alternative tag name onto a canonical tag for one user
+type TagAlias struct {
+	UserID int64
+	Alias  string
+	Tag    string
+}
+
+// NormalizeTag converts a raw hashtag into its canonical form
+// Case is folded, the leading # and surrounding punctuation are stripped
+// ("#Work," -> "work") and empty levels are removed ("#a//b/" -> "a/b").
+// Combining marks and the zero width joiners are kept, as Sinhala and Devanagari
+// write vowel signs and conjuncts with them ("#ගෙදර", "#काम", "#ශ්‍රී").
+func NormalizeTag(raw string) string {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	raw = strings.TrimLeft(raw, "#")
+
+	var levels []string
+	for _, level := range strings.Split(raw, TagSeparator) {
+		level = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.M, r) || r == zeroWidthJoiner || r == zeroWidthNonJoiner || r == '_' || r == '-' {
+				return r
+			}
+			return -1
+		}, level)
+		level = strings.Trim(level, "_-")
+		if level != "" {
+			levels = append(levels, level)
+		}
+	}
+
+	return strings.Join(levels, TagSeparator)
+}
+
+// TagParent returns the parent of a hierarchical tag ("" for top-level tags)
+func TagParent(tag string) string {
+	if i := strings.LastIndex(tag, TagSeparator); i >= 0 {
+		return tag[:i]
+	}
+	return ""
+}
+
+// IsTagOrDescendant reports whether tag equals ancestor or lives below it
+func IsTagOrDescendant(tag, ancestor string) bool {
+	return tag == ancestor || strings.HasPrefix(tag, ancestor+TagSeparator)
+}
+
+// ResolveTagAlias maps a tag through the user's aliases
+// The longest aliased prefix wins, so with "proj" -> "work/projectx" the tag
+// "proj/docs" becomes "work/projectx/docs"
+func ResolveTagAlias(tag string, aliases map[string]string) string {
+	prefix := tag
+	for prefix != "" {
+		if target, ok := aliases[prefix]; ok {
+			return target + strings.TrimPrefix(tag, prefix)
+		}
+		prefix = TagParent(prefix)
+	}
+	return tag
+}
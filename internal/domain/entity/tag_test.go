@@ -0,0 +1,41 @@
+package entity
+
+import "testing"
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"#Work,", "work"},
+		{"#a//b/", "a/b"},
+		{"#_draft-", "draft"},
+		{"#Café", "café"},
+
+		// Sinhala vowel signs are spacing marks (Mc) or non-spacing marks (Mn)
+		{"#ගෙදර", "ගෙදර"},
+		{"#වැඩ", "වැඩ"},
+		{"#ගෙදර/වැඩ.", "ගෙදර/වැඩ"},
+		{"#ශ්‍රී", "ශ්‍රී"},
+
+		// Devanagari vowel signs and virama
+		{"#काम", "काम"},
+		{"#परिवार", "परिवार"},
+		{"#हिन्दी!", "हिन्दी"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeTag(tt.raw); got != tt.want {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeTagKeepsDistinctTags(t *testing.T) {
+	// Tags that differ only in their vowel signs must not collide
+	for _, pair := range [][2]string{{"#ගෙදර", "#ගදර"}, {"#වැඩ", "#වඩ"}, {"#काम", "#कम"}} {
+		if a, b := NormalizeTag(pair[0]), NormalizeTag(pair[1]); a == b {
+			t.Errorf("NormalizeTag(%q) and NormalizeTag(%q) are both %q", pair[0], pair[1], a)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package entity
+
+// UserSettings holds per-user preferences
+type UserSettings struct {
+	UserID int64
+
+	// QueryHistory enables recording searches for suggestions (opt-in)
+	QueryHistory bool
+}
+
+// NewUserSettings returns the default settings for a user
+func NewUserSettings(userID int64) *UserSettings {
+	return &UserSettings{
+		UserID: userID,
+	}
+}
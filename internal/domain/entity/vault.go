@@ -0,0 +1,4 @@
+package entity
+
+// MinVaultPassphraseLength is the shortest passphrase accepted when a vault is created
+const MinVaultPassphraseLength = 8
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"memory-bot/internal/domain/entity"
+)
+
+// BackupRepository writes and manages snapshots of the database
+type BackupRepository interface {
+	// CreateSnapshot writes a consistent copy of the live database and checks its integrity
+	CreateSnapshot(ctx context.Context) (*entity.BackupSnapshot, error)
+
+	// ListSnapshots returns the stored snapshots, newest first
+	ListSnapshots(ctx context.Context) ([]*entity.BackupSnapshot, error)
+
+	// DeleteSnapshot removes a snapshot
+	DeleteSnapshot(ctx context.Context, snapshot *entity.BackupSnapshot) error
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+
+	"memory-bot/internal/domain/entity"
+)
+
+// Listings that page with a cursor; a cursor only continues the listing that issued it
+const (
+	ListingSearch = "search"
+	ListingTiers  = "tiers"
+	ListingTag    = "tag"
+	ListingDate   = "date"
+	ListingRecent = "recent"
+	ListingReview = "review"
+)
+
+// listings numbers the listings in encoded cursors; append only, as cursors outlive restarts
+var listings = []string{ListingSearch, ListingTiers, ListingTag, ListingDate, ListingRecent, ListingReview}
+
+// MaxCursorLength is the longest cursor Telegram accepts as an inline query offset
+const MaxCursorLength = 64
+
+// Cursor is an opaque position in a listing; the empty cursor starts at the first page
+// It encodes the listing in one byte, the ID as a varint and each key as 8 bytes, so a
+// position with up to four keys fits in MaxCursorLength.
+type Cursor string
+
+// CursorPosition is the decoded position: the sort keys and ID of the last memory of a page
+// The ID breaks ties between memories with equal keys.
+type CursorPosition struct {
+	Listing string
+	Keys    []float64
+	ID      int
+}
+
+// PageOptions selects one page of a listing
+type PageOptions struct {
+	Limit  int // Negative for no limit
+	Cursor Cursor
+}
+
+// MemoryPage is one page of a listing
+type MemoryPage struct {
+	Memories   []*entity.Memory
+	NextCursor Cursor // Continues after the last memory (empty without more pages)
+	HasMore    bool
+}
+
+// NewCursor encodes a position
+func NewCursor(position CursorPosition) Cursor {
+	raw := []byte{listingCode(position.Listing)}
+	raw = binary.AppendVarint(raw, int64(position.ID))
+	for _, key := range position.Keys {
+		raw = binary.BigEndian.AppendUint64(raw, math.Float64bits(key))
+	}
+	return Cursor(base64.RawURLEncoding.EncodeToString(raw))
+}
+
+// listingCode returns the number of a listing in encoded cursors
+// An unknown listing gets a number no cursor decodes with.
+func listingCode(listing string) byte {
+	for i, name := range listings {
+		if name == listing {
+			return byte(i)
+		}
+	}
+	return math.MaxUint8
+}
+
+// Position decodes the cursor of a listing with the given number of sort keys
+// It returns nil for the empty cursor and ErrInvalidCursor for a malformed cursor
+// or one issued by another listing.
+func (c Cursor) Position(listing string, keys int) (*CursorPosition, error) {
+	if c == "" {
+		return nil, nil
+	}
+
+	position, ok := c.decode()
+	if !ok || position.Listing != listing || len(position.Keys) != keys {
+		return nil, entity.ErrInvalidCursor
+	}
+	return position, nil
+}
+
+// Listing returns the listing that issued the cursor ("" if empty or malformed)
+func (c Cursor) Listing() string {
+	position, ok := c.decode()
+	if !ok {
+		return ""
+	}
+	return position.Listing
+}
+
+// decode parses the cursor
+func (c Cursor) decode() (*CursorPosition, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(string(c))
+	if err != nil || len(raw) < 2 || int(raw[0]) >= len(listings) {
+		return nil, false
+	}
+
+	id, n := binary.Varint(raw[1:])
+	if n <= 0 || id < math.MinInt || id > math.MaxInt {
+		return nil, false
+	}
+	keys := raw[1+n:]
+	if len(keys)%8 != 0 {
+		return nil, false
+	}
+
+	position := &CursorPosition{Listing: listings[raw[0]], ID: int(id)}
+	for ; len(keys) > 0; keys = keys[8:] {
+		position.Keys = append(position.Keys, math.Float64frombits(binary.BigEndian.Uint64(keys)))
+	}
+	return position, true
+}
+
+// NewMemoryPage builds a page from memories fetched in listing order with one memory
+// beyond the limit, which only tells that there are more; keys returns the sort keys
+// of the i-th memory for the cursor of the next page
+func NewMemoryPage(listing string, memories []*entity.Memory, limit int, keys func(i int) []float64) *MemoryPage {
+	page := &MemoryPage{Memories: memories}
+	if limit < 0 || len(memories) <= limit {
+		return page
+	}
+
+	page.Memories = memories[:limit]
+	page.HasMore = true
+	if limit > 0 {
+		last := limit - 1
+		page.NextCursor = NewCursor(CursorPosition{Listing: listing, Keys: keys(last), ID: page.Memories[last].ID})
+	}
+	return page
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"memory-bot/internal/domain/entity"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	// Keys with the longest decimal forms, which made text cursors too long for Telegram
+	long := []float64{-math.MaxFloat64, -1.2345678901234567e-300, math.SmallestNonzeroFloat64, 0.1 + 0.2}
+
+	tests := []CursorPosition{
+		{Listing: ListingSearch, Keys: long[:1], ID: math.MaxInt},
+		{Listing: ListingTiers, Keys: long[:2], ID: math.MaxInt},
+		{Listing: ListingTiers, Keys: []float64{2, -13.718281828459045}, ID: 1},
+		{Listing: ListingTag, Keys: []float64{2460384.8923611}, ID: 42},
+		{Listing: ListingDate, Keys: []float64{0}, ID: 0},
+		{Listing: ListingRecent, Keys: []float64{math.Inf(-1)}, ID: 7},
+		{Listing: ListingReview, Keys: long[2:3], ID: -1},
+	}
+
+	for _, want := range tests {
+		cursor := NewCursor(want)
+		if len(cursor) > MaxCursorLength {
+			t.Errorf("%s cursor is %d bytes, over %d", want.Listing, len(cursor), MaxCursorLength)
+		}
+
+		got, err := cursor.Position(want.Listing, len(want.Keys))
+		if err != nil {
+			t.Errorf("%s: Position: %v", want.Listing, err)
+			continue
+		}
+		if got.ID != want.ID || len(got.Keys) != len(want.Keys) {
+			t.Errorf("%s: got %+v, want %+v", want.Listing, got, want)
+			continue
+		}
+		for i := range want.Keys {
+			if got.Keys[i] != want.Keys[i] {
+				t.Errorf("%s: key %d = %v, want %v", want.Listing, i, got.Keys[i], want.Keys[i])
+			}
+		}
+	}
+}
+
+func TestCursorMaxKeysFitTelegram(t *testing.T) {
+	keys := []float64{-math.MaxFloat64, -math.MaxFloat64, -math.MaxFloat64, -math.MaxFloat64}
+	if cursor := NewCursor(CursorPosition{Listing: ListingTiers, Keys: keys, ID: math.MinInt}); len(cursor) > MaxCursorLength {
+		t.Errorf("four keys: %d bytes, over %d", len(cursor), MaxCursorLength)
+	}
+}
+
+func TestCursorRejectsOtherListingsAndGarbage(t *testing.T) {
+	cursor := NewCursor(CursorPosition{Listing: ListingTiers, Keys: []float64{1, 2}, ID: 3})
+
+	for _, tt := range []struct {
+		name    string
+		cursor  Cursor
+		listing string
+		keys    int
+	}{
+		{"other listing", cursor, ListingSearch, 2},
+		{"other key count", cursor, ListingTiers, 1},
+		{"text cursor of older versions", Cursor("dGllcnM6MjoxLjU6Mw"), ListingTiers, 2},
+		{"not base64", Cursor("not a cursor!"), ListingTiers, 2},
+		{"unknown listing", NewCursor(CursorPosition{Listing: "unknown", ID: 1}), "unknown", 0},
+	} {
+		if _, err := tt.cursor.Position(tt.listing, tt.keys); !errors.Is(err, entity.ErrInvalidCursor) {
+			t.Errorf("%s: error = %v, want ErrInvalidCursor", tt.name, err)
+		}
+	}
+	if listing := cursor.Listing(); listing != ListingTiers {
+		t.Errorf("Listing() = %q", listing)
+	}
+}
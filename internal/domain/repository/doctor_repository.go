@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"memory-bot/internal/domain/entity"
+)
+
+// DoctorRepository checks the consistency of the whole store and repairs what it can
+type DoctorRepository interface {
+	// Diagnose runs every check without changing anything
+	Diagnose(ctx context.Context) (*entity.DoctorReport, error)
+
+	// Repair runs the automatic repair of one check; returns the number of repaired rows
+	// Returns ErrUnknownCheck for a check that does not exist
+	Repair(ctx context.Context, check string) (int, error)
+}
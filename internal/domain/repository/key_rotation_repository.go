@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"memory-bot/internal/domain/entity"
+)
+
+// KeyRotationRepository re-encrypts stored data with the primary encryption key
+// Progress is checkpointed after every batch, so an interrupted rotation resumes.
+type KeyRotationRepository interface {
+	// Progress reports the re-encryption progress for the primary key
+	Progress(ctx context.Context) (*entity.KeyRotationProgress, error)
+
+	// ReencryptBatch re-encrypts up to limit rows after the checkpoint in one transaction
+	ReencryptBatch(ctx context.Context, limit int) (*entity.KeyRotationProgress, error)
+}
@@ -2,15 +2,24 @@ package repository
 
 import (
 	"context"
+	"time"
+
 	"memory-bot/internal/domain/entity"
 	"memory-bot/internal/domain/service"
 )
 
 // SearchOptions defines options for memory search
 type SearchOptions struct {
-	Limit         int
-	Offset        int
+	Limit         int                     // Negative for no limit
+	Cursor        Cursor                  // Continues a previous page of the same listing
 	ContextFilter *service.ContextualData // For SQL-level contextual filtering
+	ExactPhrase   bool                    // Match the query as one phrase (no wildcards or synonyms)
+}
+
+// SearchTier is one step of a tiered search; earlier tiers rank higher
+type SearchTier struct {
+	Step    string   // Reported when the best result comes from this tier
+	Queries []string // Queries prepared the same way as for Search; matching any of them is enough
 }
 
 // MemoryRepository defines the interface for memory data access
@@ -22,21 +31,64 @@ type MemoryRepository interface {
 	// FindByID retrieves a memory by its ID
 	FindByID(ctx context.Context, id int) (*entity.Memory, error)
 
-	// Search performs a search query with the given options
-	Search(ctx context.Context, userID int64, query string, opts SearchOptions) ([]*entity.Memory, error)
+	// Search performs a search query with the given options, best ranked first
+	Search(ctx context.Context, userID int64, query string, opts SearchOptions) (*MemoryPage, error)
+
+	// SearchTiers runs several queries in one pass and merges them without duplicates,
+	// ordered by tier first; it also returns the step of the first result of the page
+	SearchTiers(ctx context.Context, userID int64, tiers []SearchTier, opts SearchOptions) (*MemoryPage, string, error)
+
+	// SearchByTag retrieves memories carrying the tag or one of its sub-tags (aliases are resolved)
+	SearchByTag(ctx context.Context, userID int64, tag string, opts SearchOptions) (*MemoryPage, error)
+
+	// FindByDateRange retrieves memories created in [from, to), newest first
+	FindByDateRange(ctx context.Context, userID int64, from, to time.Time, opts SearchOptions) (*MemoryPage, error)
+
+	// ExplainQuery describes the full-text expression Search would use for the query
+	ExplainQuery(ctx context.Context, userID int64, query string) (*entity.SearchExplanation, error)
+
+	// MatchesQuery reports whether a memory matches an FTS query the same way Search would
+	MatchesQuery(ctx context.Context, memoryID int, userID int64, query string) (bool, error)
 
-	// GetRecent retrieves the most recent memories for a user
-	GetRecent(ctx context.Context, userID int64, limit int) ([]*entity.Memory, error)
+	// FindRelated retrieves memories similar to the given one (the source itself is excluded)
+	FindRelated(ctx context.Context, userID int64, memoryID int, limit int) ([]*entity.Memory, error)
 
-	// GetForReview retrieves memories that need review based on intervals
-	GetForReview(ctx context.Context, intervals []int) ([]*entity.Memory, error)
+	// GetRecent retrieves the most recent memories for a user, newest first
+	GetRecent(ctx context.Context, userID int64, opts PageOptions) (*MemoryPage, error)
+
+	// GetForReview retrieves memories that need review based on intervals, longest unreviewed first
+	GetForReview(ctx context.Context, intervals []int, opts PageOptions) (*MemoryPage, error)
 
 	// Update updates an existing memory
 	Update(ctx context.Context, memory *entity.Memory) error
 
-	// Delete removes a memory (with authorization check)
+	// UpdateContent stores edited content, tags and emotional weight and records the change
+	// as a new revision (the original content becomes revision 1 on the first edit)
+	UpdateContent(ctx context.Context, memory *entity.Memory, source string, restoredFrom int) (*entity.MemoryRevision, error)
+
+	// SetSecret marks a live memory of the user as secret or not
+	// Secret content is masked in listings and kept out of the plaintext search index
+	SetSecret(ctx context.Context, id int, userID int64, secret bool) error
+
+	// FindRevisions returns the recorded revisions of a user's memory, oldest first
+	FindRevisions(ctx context.Context, memoryID int, userID int64) ([]*entity.MemoryRevision, error)
+
+	// FindBySourceMessage finds the memory saved from a Telegram message
+	FindBySourceMessage(ctx context.Context, chatID int64, messageID int) (*entity.Memory, error)
+
+	// Delete moves a memory and its sub-memories to the trash (with authorization check)
 	Delete(ctx context.Context, id int, userID int64) error
 
+	// Restore brings a memory back from the trash together with the sub-memories
+	// trashed along with it, and returns how many memories were restored
+	Restore(ctx context.Context, id int, userID int64) (int, error)
+
+	// FindDeleted lists the memories in a user's trash, most recently deleted first
+	FindDeleted(ctx context.Context, userID int64, limit int) ([]*entity.Memory, error)
+
+	// PurgeDeleted permanently removes memories trashed before the cutoff and returns how many
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
+
 	// Count returns the total number of memories for a user
 	Count(ctx context.Context, userID int64) (int, error)
 
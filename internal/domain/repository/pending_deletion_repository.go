@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"memory-bot/internal/domain/entity"
+	"time"
+)
+
+// PendingDeletionRepository defines the interface for the messages the bot still has to delete
+type PendingDeletionRepository interface {
+	// Add stores a message to delete and sets its ID
+	Add(ctx context.Context, deletion *entity.PendingDeletion) error
+
+	// FindDue returns the deletions due at the given time, oldest first
+	FindDue(ctx context.Context, now time.Time) ([]*entity.PendingDeletion, error)
+
+	// Remove forgets a deletion once the message was deleted (or can no longer be)
+	Remove(ctx context.Context, id int) error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"memory-bot/internal/domain/entity"
+)
+
+// QueryHistoryRepository defines the interface for search query history data access
+type QueryHistoryRepository interface {
+	// Record stores a query log entry
+	Record(ctx context.Context, entry *entity.QueryLogEntry) error
+
+	// Recent retrieves the user's most recent distinct queries
+	Recent(ctx context.Context, userID int64, limit int) ([]string, error)
+
+	// Frequent retrieves the user's most frequent queries
+	Frequent(ctx context.Context, userID int64, limit int) ([]entity.QueryStat, error)
+
+	// ZeroResults retrieves the queries that most often returned nothing (all users)
+	ZeroResults(ctx context.Context, limit int) ([]entity.QueryStat, error)
+
+	// StepStats counts searches per matched strategy step (all users)
+	StepStats(ctx context.Context) ([]entity.StepStat, error)
+
+	// DeleteByUser removes the whole history of a user
+	DeleteByUser(ctx context.Context, userID int64) error
+}
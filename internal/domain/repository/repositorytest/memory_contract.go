@@ -0,0 +1,897 @@
+// Package repositorytest holds contract tests shared by the repository implementations
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+	"memory-bot/internal/domain/service"
+)
+
+const (
+	// alice and bob are the users of the fixtures
+	alice int64 = 1001
+	bob   int64 = 2002
+
+	// timeTolerance absorbs the precision lost by stores that keep whole seconds
+	timeTolerance = time.Second
+)
+
+// memoryContract lists the cases of the MemoryRepository contract
+var memoryContract = []struct {
+	name string
+	run  func(t *testing.T, repo repository.MemoryRepository)
+}{
+	{"SaveAndFindByID", testSaveAndFindByID},
+	{"Search", testSearch},
+	{"SearchOptions", testSearchOptions},
+	{"SearchExactPhrase", testSearchExactPhrase},
+	{"SearchTiers", testSearchTiers},
+	{"SearchByTag", testSearchByTag},
+	{"FindByDateRange", testFindByDateRange},
+	{"MatchesQuery", testMatchesQuery},
+	{"FindRelated", testFindRelated},
+	{"GetRecent", testGetRecent},
+	{"GetForReview", testGetForReview},
+	{"Pagination", testPagination},
+	{"Update", testUpdate},
+	{"UpdateContent", testUpdateContent},
+	{"Secret", testSecret},
+	{"TrashAndRestore", testTrashAndRestore},
+	{"PurgeDeleted", testPurgeDeleted},
+	{"Consolidation", testConsolidation},
+}
+
+// TestMemoryRepository runs the MemoryRepository contract against an implementation
+// newRepo must return an empty repository for every call
+func TestMemoryRepository(t *testing.T, newRepo func(t *testing.T) repository.MemoryRepository) {
+	runMemoryContract(t, newRepo, nil)
+}
+
+// TestBlindIndexedMemoryRepository runs the contract against an implementation that
+// searches encrypted content through blind index tokens. Those carry no word order,
+// so exact phrases match their words anywhere and that case is left out.
+func TestBlindIndexedMemoryRepository(t *testing.T, newRepo func(t *testing.T) repository.MemoryRepository) {
+	runMemoryContract(t, newRepo, map[string]bool{"SearchExactPhrase": true})
+}
+
+func runMemoryContract(t *testing.T, newRepo func(t *testing.T) repository.MemoryRepository, skip map[string]bool) {
+	for _, tt := range memoryContract {
+		if skip[tt.name] {
+			continue
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+func testSaveAndFindByID(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	parent := save(t, repo, memoryAt(alice, "Kickoff for #Work/ProjectX and #work", daysAgo(1)))
+	m := memoryAt(alice, "Notes from the #work/projectx kickoff", daysAgo(1))
+	m.EmotionalWeight = 0.4
+	m.TimeOfDay = "Morning"
+	m.DayOfWeek = "Monday"
+	parentID := int64(parent)
+	m.ParentID = &parentID
+	id := save(t, repo, m)
+
+	if id == parent {
+		t.Fatalf("Save returned the same ID %d twice", id)
+	}
+
+	found, err := repo.FindByID(ctx, id)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.ID != id || found.UserID != alice || found.ChatID != m.ChatID {
+		t.Errorf("FindByID = id %d user %d chat %d, want %d %d %d",
+			found.ID, found.UserID, found.ChatID, id, alice, m.ChatID)
+	}
+	if found.Content != m.Content {
+		t.Errorf("Content = %q, want %q", found.Content, m.Content)
+	}
+	assertTimeNear(t, "CreatedAt", found.CreatedAt, m.CreatedAt)
+	if found.EmotionalWeight != 0.4 || found.TimeOfDay != "Morning" || found.DayOfWeek != "Monday" {
+		t.Errorf("context fields = %.1f %q %q, want 0.4 Morning Monday",
+			found.EmotionalWeight, found.TimeOfDay, found.DayOfWeek)
+	}
+	if found.ParentID == nil || *found.ParentID != parentID {
+		t.Errorf("ParentID = %v, want %d", found.ParentID, parentID)
+	}
+
+	first, err := repo.FindByID(ctx, parent)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	assertStrings(t, "Tags", first.Tags, []string{"work/projectx", "work"})
+
+	if _, err := repo.FindByID(ctx, id+1000); !errors.Is(err, entity.ErrMemoryNotFound) {
+		t.Errorf("FindByID(unknown) error = %v, want ErrMemoryNotFound", err)
+	}
+	if _, err := repo.Save(ctx, entity.NewMemory(alice, chatOf(alice), "   ")); !errors.Is(err, entity.ErrEmptyContent) {
+		t.Errorf("Save(empty) error = %v, want ErrEmptyContent", err)
+	}
+}
+
+func testSearch(t *testing.T, repo repository.MemoryRepository) {
+	cluster := save(t, repo, memoryAt(alice, "Deploy the kubernetes cluster on friday", daysAgo(2)))
+	dashboard := save(t, repo, memoryAt(alice, "Kubernetes dashboard password rotation", daysAgo(1)))
+	save(t, repo, memoryAt(alice, "Grocery list: apples and bread", daysAgo(1)))
+	save(t, repo, memoryAt(bob, "Kubernetes cluster of bob", daysAgo(1)))
+
+	assertSameIDs(t, "prefix", search(t, repo, alice, "kube"), cluster, dashboard)
+	assertSameIDs(t, "all words", search(t, repo, alice, "kubernetes cluster"), cluster)
+	assertSameIDs(t, "case", search(t, repo, alice, "DASHBOARD"), dashboard)
+	assertSameIDs(t, "no match", search(t, repo, alice, "holiday"))
+	if got := search(t, repo, bob, "dashboard"); len(got) != 0 {
+		t.Errorf("Search leaked %d memories of another user", len(got))
+	}
+}
+
+func testSearchOptions(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	morning := memoryAt(alice, "Standup meeting about the release", daysAgo(2))
+	morning.TimeOfDay = "Morning"
+	morningID := save(t, repo, morning)
+	evening := memoryAt(alice, "Dinner meeting with the team", daysAgo(1))
+	evening.TimeOfDay = "Evening"
+	save(t, repo, evening)
+
+	first, err := repo.Search(ctx, alice, "meeting", repository.SearchOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(first.Memories) != 1 || !first.HasMore || first.NextCursor == "" {
+		t.Errorf("Limit 1 returned %d memories (has more: %v)", len(first.Memories), first.HasMore)
+	}
+	all, err := repo.Search(ctx, alice, "meeting", repository.SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(all.Memories) != 2 || all.HasMore || all.NextCursor != "" {
+		t.Errorf("Limit 10 returned %d memories (has more: %v)", len(all.Memories), all.HasMore)
+	}
+
+	filtered, err := repo.Search(ctx, alice, "meeting", repository.SearchOptions{
+		Limit:         10,
+		ContextFilter: &service.ContextualData{TimeOfDay: "Morning"},
+	})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	assertSameIDs(t, "context filter", filtered.Memories, morningID)
+}
+
+func testSearchExactPhrase(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	inOrder := save(t, repo, memoryAt(alice, "Draft the release notes today", daysAgo(1)))
+	shuffled := save(t, repo, memoryAt(alice, "Notes about the next release", daysAgo(1)))
+
+	exact, err := repo.Search(ctx, alice, "release notes", repository.SearchOptions{Limit: 10, ExactPhrase: true})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	assertSameIDs(t, "exact phrase", exact.Memories, inOrder)
+	assertSameIDs(t, "words", search(t, repo, alice, "release notes"), inOrder, shuffled)
+}
+
+func testSearchTiers(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	both := save(t, repo, memoryAt(alice, "The deploy plan for the migration", daysAgo(3)))
+	one := save(t, repo, memoryAt(alice, "Plan to deploy after lunch", daysAgo(1)))
+	save(t, repo, memoryAt(alice, "Unrelated shopping note", daysAgo(1)))
+
+	tiers := []repository.SearchTier{
+		{Step: "and", Queries: []string{"deploy migration"}},
+		{Step: "partial", Queries: []string{"deploy*"}},
+	}
+	memories, step, err := repo.SearchTiers(ctx, alice, tiers, repository.SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchTiers: %v", err)
+	}
+	assertIDs(t, "tier order", memories.Memories, both, one)
+	if step != "and" {
+		t.Errorf("step = %q, want and", step)
+	}
+
+	fallback := []repository.SearchTier{
+		{Step: "and", Queries: []string{"lunch holiday"}},
+		{Step: "partial", Queries: []string{"lunch*", "holiday*"}},
+	}
+	memories, step, err = repo.SearchTiers(ctx, alice, fallback, repository.SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchTiers: %v", err)
+	}
+	assertIDs(t, "fallback tier", memories.Memories, one)
+	if step != "partial" {
+		t.Errorf("step = %q, want partial", step)
+	}
+}
+
+func testSearchByTag(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	project := save(t, repo, memoryAt(alice, "Standup #work/projectx", daysAgo(3)))
+	work := save(t, repo, memoryAt(alice, "Team lunch #work", daysAgo(2)))
+	save(t, repo, memoryAt(alice, "Clean the garage #home", daysAgo(1)))
+	save(t, repo, memoryAt(alice, "Homework for #workshop", daysAgo(1)))
+	save(t, repo, memoryAt(bob, "Bob at #work", daysAgo(1)))
+
+	byTag := func(tag string) []*entity.Memory {
+		memories, err := repo.SearchByTag(ctx, alice, tag, repository.SearchOptions{Limit: 10})
+		if err != nil {
+			t.Fatalf("SearchByTag(%s): %v", tag, err)
+		}
+		return memories.Memories
+	}
+	assertIDs(t, "parent tag", byTag("#work"), work, project)
+	assertIDs(t, "sub-tag", byTag("Work/ProjectX"), project)
+
+	if _, err := repo.SearchByTag(ctx, alice, "#", repository.SearchOptions{Limit: 10}); !errors.Is(err, entity.ErrInvalidTag) {
+		t.Errorf("SearchByTag(#) error = %v, want ErrInvalidTag", err)
+	}
+}
+
+func testFindByDateRange(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	old := save(t, repo, memoryAt(alice, "Three days ago", daysAgo(3)))
+	middle := save(t, repo, memoryAt(alice, "Two days ago", daysAgo(2)))
+	recent := save(t, repo, memoryAt(alice, "An hour ago", time.Now().Add(-time.Hour)))
+	save(t, repo, memoryAt(bob, "Bob two days ago", daysAgo(2)))
+
+	between := func(from, to time.Time) []*entity.Memory {
+		memories, err := repo.FindByDateRange(ctx, alice, from, to, repository.SearchOptions{Limit: 10})
+		if err != nil {
+			t.Fatalf("FindByDateRange: %v", err)
+		}
+		return memories.Memories
+	}
+	assertIDs(t, "window", between(daysAgo(2).Add(-time.Hour), daysAgo(1)), middle)
+	assertIDs(t, "all", between(daysAgo(10), time.Now().Add(time.Hour)), recent, middle, old)
+}
+
+func testMatchesQuery(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+	id := save(t, repo, memoryAt(alice, "Renew the passport before june", daysAgo(1)))
+
+	tests := []struct {
+		user  int64
+		query string
+		want  bool
+	}{
+		{alice, "passport", true},
+		{alice, "pass june", true},
+		{alice, "visa", false},
+		{bob, "passport", false},
+	}
+	for _, tt := range tests {
+		got, err := repo.MatchesQuery(ctx, id, tt.user, tt.query)
+		if err != nil {
+			t.Fatalf("MatchesQuery(%q): %v", tt.query, err)
+		}
+		if got != tt.want {
+			t.Errorf("MatchesQuery(user %d, %q) = %v, want %v", tt.user, tt.query, got, tt.want)
+		}
+	}
+}
+
+func testFindRelated(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	source := save(t, repo, memoryAt(alice, "Upgrade the kubernetes cluster #infra", daysAgo(3)))
+	sibling := save(t, repo, memoryAt(alice, "Kubernetes cluster costs are rising", daysAgo(2)))
+	tagged := save(t, repo, memoryAt(alice, "Rotate certificates #infra", daysAgo(2)))
+	save(t, repo, memoryAt(alice, "Birthday cake recipe", daysAgo(1)))
+	save(t, repo, memoryAt(bob, "Bob's kubernetes cluster", daysAgo(1)))
+
+	related, err := repo.FindRelated(ctx, alice, source, 10)
+	if err != nil {
+		t.Fatalf("FindRelated: %v", err)
+	}
+	assertSameIDs(t, "related", related, sibling, tagged)
+
+	if _, err := repo.FindRelated(ctx, bob, source, 10); !errors.Is(err, entity.ErrUnauthorized) {
+		t.Errorf("FindRelated(other user) error = %v, want ErrUnauthorized", err)
+	}
+	if _, err := repo.FindRelated(ctx, alice, source+1000, 10); !errors.Is(err, entity.ErrMemoryNotFound) {
+		t.Errorf("FindRelated(unknown) error = %v, want ErrMemoryNotFound", err)
+	}
+}
+
+func testGetRecent(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	save(t, repo, memoryAt(alice, "Oldest", daysAgo(3)))
+	middle := save(t, repo, memoryAt(alice, "Middle", daysAgo(2)))
+	newest := save(t, repo, memoryAt(alice, "Newest", daysAgo(1)))
+	save(t, repo, memoryAt(bob, "Bob's newest", time.Now()))
+
+	recent, err := repo.GetRecent(ctx, alice, repository.PageOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("GetRecent: %v", err)
+	}
+	assertIDs(t, "recent", recent.Memories, newest, middle)
+
+	count, err := repo.Count(ctx, alice)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Count = %d, want 3", count)
+	}
+}
+
+func testGetForReview(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	older := save(t, repo, memoryAt(alice, "Created five days ago", daysAgo(5)))
+	due := save(t, repo, memoryAt(bob, "Created two days ago", daysAgo(2)))
+	save(t, repo, memoryAt(alice, "Created just now", time.Now()))
+
+	reviewedAt := time.Now()
+	reviewed := memoryAt(alice, "Created long ago but reviewed just now", daysAgo(20))
+	reviewed.LastReviewed = &reviewedAt
+	reviewed.ReviewCount = 1
+	reviewedID := save(t, repo, reviewed)
+	reviewed.ID = reviewedID
+	if err := repo.Update(ctx, reviewed); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	memories, err := repo.GetForReview(ctx, []int{1, 7}, repository.PageOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("GetForReview: %v", err)
+	}
+	assertIDs(t, "due for review", memories.Memories, older, due)
+
+	memories, err = repo.GetForReview(ctx, []int{3}, repository.PageOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("GetForReview: %v", err)
+	}
+	assertIDs(t, "longer interval", memories.Memories, older)
+}
+
+func testPagination(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	// Memories created at the same moment are ordered by ID
+	tied := daysAgo(4)
+	var ids []int
+	for i, createdAt := range []time.Time{daysAgo(5), tied, tied, tied, daysAgo(3), daysAgo(2)} {
+		content := fmt.Sprintf("Weekly report %d #report", i)
+		if i == 4 {
+			content = "Urgent weekly report #report"
+		}
+		ids = append(ids, save(t, repo, memoryAt(alice, content, createdAt)))
+	}
+	save(t, repo, memoryAt(bob, "Bob's weekly report #report", time.Now()))
+	newestFirst := []int{ids[5], ids[4], ids[3], ids[2], ids[1], ids[0]}
+	oldestFirst := []int{ids[0], ids[1], ids[2], ids[3], ids[4], ids[5]}
+
+	tiers := []repository.SearchTier{
+		{Step: "urgent", Queries: []string{"urgent"}},
+		{Step: "weekly", Queries: []string{"weekly"}},
+	}
+	listings := []struct {
+		name   string
+		list   func(opts repository.SearchOptions) (*repository.MemoryPage, error)
+		want   []int
+		ranked bool // Equal ranks may come in any order
+	}{
+		{"Search", func(opts repository.SearchOptions) (*repository.MemoryPage, error) {
+			return repo.Search(ctx, alice, "weekly report", opts)
+		}, newestFirst, true},
+		{"SearchTiers", func(opts repository.SearchOptions) (*repository.MemoryPage, error) {
+			page, _, err := repo.SearchTiers(ctx, alice, tiers, opts)
+			return page, err
+		}, newestFirst, true},
+		{"SearchByTag", func(opts repository.SearchOptions) (*repository.MemoryPage, error) {
+			return repo.SearchByTag(ctx, alice, "#report", opts)
+		}, newestFirst, false},
+		{"FindByDateRange", func(opts repository.SearchOptions) (*repository.MemoryPage, error) {
+			return repo.FindByDateRange(ctx, alice, daysAgo(10), time.Now(), opts)
+		}, newestFirst, false},
+		{"GetRecent", func(opts repository.SearchOptions) (*repository.MemoryPage, error) {
+			return repo.GetRecent(ctx, alice, repository.PageOptions{Limit: opts.Limit, Cursor: opts.Cursor})
+		}, newestFirst, false},
+		{"GetForReview", func(opts repository.SearchOptions) (*repository.MemoryPage, error) {
+			return repo.GetForReview(ctx, []int{1}, repository.PageOptions{Limit: opts.Limit, Cursor: opts.Cursor})
+		}, oldestFirst, false},
+	}
+
+	for _, listing := range listings {
+		// Walk the listing with one memory per page
+		var got []*entity.Memory
+		opts := repository.SearchOptions{Limit: 1}
+		for {
+			if len(got) > len(listing.want) {
+				t.Fatalf("%s: cursor does not advance", listing.name)
+			}
+			page, err := listing.list(opts)
+			if err != nil {
+				t.Fatalf("%s: %v", listing.name, err)
+			}
+			got = append(got, page.Memories...)
+			if page.HasMore != (page.NextCursor != "") {
+				t.Errorf("%s: HasMore = %v with cursor %q", listing.name, page.HasMore, page.NextCursor)
+			}
+			if !page.HasMore {
+				break
+			}
+			opts.Cursor = page.NextCursor
+		}
+
+		if listing.ranked {
+			assertSameIDs(t, listing.name+" pages", got, listing.want...)
+		} else {
+			assertIDs(t, listing.name+" pages", got, listing.want...)
+		}
+	}
+
+	// The better tier stays first across pages
+	page, _, err := repo.SearchTiers(ctx, alice, tiers, repository.SearchOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("SearchTiers: %v", err)
+	}
+	assertIDs(t, "first tier page", page.Memories, ids[4])
+
+	// A cursor only continues the listing that issued it
+	recent, err := repo.GetRecent(ctx, alice, repository.PageOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("GetRecent: %v", err)
+	}
+	if _, err := repo.Search(ctx, alice, "weekly", repository.SearchOptions{Limit: 1, Cursor: recent.NextCursor}); !errors.Is(err, entity.ErrInvalidCursor) {
+		t.Errorf("Search with a GetRecent cursor: error = %v, want ErrInvalidCursor", err)
+	}
+	if _, err := repo.GetRecent(ctx, alice, repository.PageOptions{Limit: 1, Cursor: "not-a-cursor"}); !errors.Is(err, entity.ErrInvalidCursor) {
+		t.Errorf("GetRecent with a malformed cursor: error = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func testUpdate(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	m := memoryAt(alice, "Review me", daysAgo(2))
+	m.ID = save(t, repo, m)
+
+	reviewedAt := time.Now().Add(-time.Minute)
+	m.LastReviewed = &reviewedAt
+	m.ReviewCount = 2
+	if err := repo.Update(ctx, m); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	found, err := repo.FindByID(ctx, m.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.ReviewCount != 2 {
+		t.Errorf("ReviewCount = %d, want 2", found.ReviewCount)
+	}
+	if found.LastReviewed == nil {
+		t.Fatal("LastReviewed is nil after Update")
+	}
+	assertTimeNear(t, "LastReviewed", *found.LastReviewed, reviewedAt)
+}
+
+func testUpdateContent(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	original := memoryAt(alice, "Buy milk #errands", daysAgo(1))
+	original.SourceMessageID = 42
+	id := save(t, repo, original)
+
+	bySource, err := repo.FindBySourceMessage(ctx, original.ChatID, 42)
+	if err != nil {
+		t.Fatalf("FindBySourceMessage: %v", err)
+	}
+	if bySource.ID != id {
+		t.Errorf("FindBySourceMessage = %d, want %d", bySource.ID, id)
+	}
+	if _, err := repo.FindBySourceMessage(ctx, original.ChatID, 43); !errors.Is(err, entity.ErrMemoryNotFound) {
+		t.Errorf("FindBySourceMessage(unknown) error = %v, want ErrMemoryNotFound", err)
+	}
+
+	edited, err := repo.FindByID(ctx, id)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	edited.SetContent("Buy oat milk #groceries")
+	edited.EmotionalWeight = 0.2
+
+	revision, err := repo.UpdateContent(ctx, edited, entity.RevisionSourceEdit, 0)
+	if err != nil {
+		t.Fatalf("UpdateContent: %v", err)
+	}
+	if revision.Number != 2 || revision.Source != entity.RevisionSourceEdit {
+		t.Errorf("revision = #%d %s, want #2 %s", revision.Number, revision.Source, entity.RevisionSourceEdit)
+	}
+
+	found, err := repo.FindByID(ctx, id)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.Content != "Buy oat milk #groceries" || found.EmotionalWeight != 0.2 {
+		t.Errorf("after edit = %q %.1f", found.Content, found.EmotionalWeight)
+	}
+	assertStrings(t, "Tags", found.Tags, []string{"groceries"})
+	assertSameIDs(t, "new content", search(t, repo, alice, "oat"), id)
+	assertSameIDs(t, "old tag", tagSearch(t, repo, alice, "errands"))
+	assertSameIDs(t, "new tag", tagSearch(t, repo, alice, "groceries"), id)
+
+	revisions, err := repo.FindRevisions(ctx, id, alice)
+	if err != nil {
+		t.Fatalf("FindRevisions: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("FindRevisions returned %d revisions, want 2", len(revisions))
+	}
+	first := revisions[0]
+	if first.Number != 1 || first.Source != entity.RevisionSourceOriginal || first.Content != "Buy milk #errands" {
+		t.Errorf("revision 1 = #%d %s %q", first.Number, first.Source, first.Content)
+	}
+	assertStrings(t, "revision 1 tags", first.Tags, []string{"errands"})
+	if revisions[1].Content != "Buy oat milk #groceries" {
+		t.Errorf("revision 2 content = %q", revisions[1].Content)
+	}
+
+	edited.SetContent(first.Content)
+	rollback, err := repo.UpdateContent(ctx, edited, entity.RevisionSourceRollback, 1)
+	if err != nil {
+		t.Fatalf("UpdateContent(rollback): %v", err)
+	}
+	if rollback.Number != 3 || rollback.RestoredFrom != 1 {
+		t.Errorf("rollback = #%d from %d, want #3 from 1", rollback.Number, rollback.RestoredFrom)
+	}
+
+	if revisions, err := repo.FindRevisions(ctx, id, bob); err != nil || len(revisions) != 0 {
+		t.Errorf("FindRevisions(other user) = %d revisions, %v", len(revisions), err)
+	}
+
+	stranger := *edited
+	stranger.UserID = bob
+	if _, err := repo.UpdateContent(ctx, &stranger, entity.RevisionSourceEdit, 0); !errors.Is(err, entity.ErrMemoryNotFound) {
+		t.Errorf("UpdateContent(other user) error = %v, want ErrMemoryNotFound", err)
+	}
+}
+
+func testSecret(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	secret := memoryAt(alice, "Router login admin8889 #wifi", daysAgo(1))
+	secret.Secret = true
+	id := save(t, repo, secret)
+	public := save(t, repo, memoryAt(alice, "Router reboot fixed it #wifi", daysAgo(1)))
+
+	found, err := repo.FindByID(ctx, id)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if !found.Secret || found.Content != "Router login admin8889 #wifi" {
+		t.Errorf("FindByID = %q secret %t", found.Content, found.Secret)
+	}
+
+	// Secret content stays out of the search index; tags still match
+	assertSameIDs(t, "secret words", search(t, repo, alice, "router"), public)
+	assertSameIDs(t, "secret tag", tagSearch(t, repo, alice, "wifi"), id, public)
+
+	found.SetContent("Router login admin9999 #wifi")
+	if _, err := repo.UpdateContent(ctx, found, entity.RevisionSourceEdit, 0); err != nil {
+		t.Fatalf("UpdateContent: %v", err)
+	}
+	assertSameIDs(t, "edited secret words", search(t, repo, alice, "admin9999"))
+
+	if err := repo.SetSecret(ctx, id, alice, false); err != nil {
+		t.Fatalf("SetSecret(false): %v", err)
+	}
+	assertSameIDs(t, "public words", search(t, repo, alice, "admin9999"), id)
+	if found, err := repo.FindByID(ctx, id); err != nil || found.Secret {
+		t.Errorf("after SetSecret(false) = secret %t, %v", found.Secret, err)
+	}
+
+	if err := repo.SetSecret(ctx, id, alice, true); err != nil {
+		t.Fatalf("SetSecret(true): %v", err)
+	}
+	assertSameIDs(t, "secret again", search(t, repo, alice, "admin9999"))
+
+	if err := repo.SetSecret(ctx, id, bob, false); !errors.Is(err, entity.ErrUnauthorized) {
+		t.Errorf("SetSecret(other user) error = %v, want ErrUnauthorized", err)
+	}
+	if err := repo.SetSecret(ctx, 9999, alice, true); !errors.Is(err, entity.ErrMemoryNotFound) {
+		t.Errorf("SetSecret(unknown) error = %v, want ErrMemoryNotFound", err)
+	}
+}
+
+func testTrashAndRestore(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	parent := save(t, repo, memoryAt(alice, "Trip planning #travel", daysAgo(2)))
+	child := memoryAt(alice, "Book the trip hotel", daysAgo(2))
+	parentID := int64(parent)
+	child.ParentID = &parentID
+	childID := save(t, repo, child)
+	other := save(t, repo, memoryAt(alice, "Trip photos", daysAgo(1)))
+
+	if err := repo.Delete(ctx, parent, bob); !errors.Is(err, entity.ErrUnauthorized) {
+		t.Errorf("Delete(other user) error = %v, want ErrUnauthorized", err)
+	}
+	if err := repo.Delete(ctx, parent, alice); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.Delete(ctx, parent, alice); !errors.Is(err, entity.ErrMemoryNotFound) {
+		t.Errorf("Delete(trashed) error = %v, want ErrMemoryNotFound", err)
+	}
+
+	for _, id := range []int{parent, childID} {
+		if _, err := repo.FindByID(ctx, id); !errors.Is(err, entity.ErrMemoryNotFound) {
+			t.Errorf("FindByID(%d) in trash error = %v, want ErrMemoryNotFound", id, err)
+		}
+	}
+	assertSameIDs(t, "search skips trash", search(t, repo, alice, "trip"), other)
+	assertSameIDs(t, "tags skip trash", tagSearch(t, repo, alice, "travel"))
+	if count, _ := repo.Count(ctx, alice); count != 1 {
+		t.Errorf("Count with trash = %d, want 1", count)
+	}
+
+	trash, err := repo.FindDeleted(ctx, alice, 10)
+	if err != nil {
+		t.Fatalf("FindDeleted: %v", err)
+	}
+	assertSameIDs(t, "trash", trash, parent, childID)
+	for _, m := range trash {
+		if m.DeletedAt == nil {
+			t.Errorf("trashed memory %d has no DeletedAt", m.ID)
+		}
+	}
+	if trash, _ := repo.FindDeleted(ctx, bob, 10); len(trash) != 0 {
+		t.Errorf("FindDeleted leaked %d memories of another user", len(trash))
+	}
+
+	if _, err := repo.Restore(ctx, parent, bob); !errors.Is(err, entity.ErrUnauthorized) {
+		t.Errorf("Restore(other user) error = %v, want ErrUnauthorized", err)
+	}
+	restored, err := repo.Restore(ctx, parent, alice)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored != 2 {
+		t.Errorf("Restore = %d, want 2", restored)
+	}
+	if _, err := repo.Restore(ctx, parent, alice); !errors.Is(err, entity.ErrMemoryNotFound) {
+		t.Errorf("Restore(live) error = %v, want ErrMemoryNotFound", err)
+	}
+
+	found, err := repo.FindByID(ctx, childID)
+	if err != nil {
+		t.Fatalf("FindByID after restore: %v", err)
+	}
+	if found.ParentID == nil || *found.ParentID != parentID {
+		t.Errorf("restored ParentID = %v, want %d", found.ParentID, parentID)
+	}
+	assertSameIDs(t, "search after restore", search(t, repo, alice, "trip"), parent, childID, other)
+}
+
+func testPurgeDeleted(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	parent := save(t, repo, memoryAt(alice, "Old project notes", daysAgo(40)))
+	child := memoryAt(alice, "Old project budget", daysAgo(40))
+	parentID := int64(parent)
+	child.ParentID = &parentID
+	childID := save(t, repo, child)
+	kept := save(t, repo, memoryAt(alice, "Current project notes", daysAgo(1)))
+
+	// A sub-memory that stays when its parent is purged
+	survivor := memoryAt(alice, "Project lessons learned", daysAgo(1))
+	survivor.ParentID = &parentID
+	survivorID := save(t, repo, survivor)
+	if err := repo.Delete(ctx, survivorID, alice); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.Restore(ctx, survivorID, alice); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	edit, err := repo.FindByID(ctx, parent)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	edit.SetContent("Old project notes, revised")
+	if _, err := repo.UpdateContent(ctx, edit, entity.RevisionSourceEdit, 0); err != nil {
+		t.Fatalf("UpdateContent: %v", err)
+	}
+
+	if err := repo.Delete(ctx, parent, alice); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	purged, err := repo.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeDeleted: %v", err)
+	}
+	if purged != 0 {
+		t.Errorf("PurgeDeleted before the deletion purged %d memories", purged)
+	}
+
+	purged, err = repo.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("PurgeDeleted: %v", err)
+	}
+	if purged != 3 {
+		t.Errorf("PurgeDeleted = %d, want 3", purged)
+	}
+
+	if trash, _ := repo.FindDeleted(ctx, alice, 10); len(trash) != 0 {
+		t.Errorf("trash has %d memories after purge", len(trash))
+	}
+	if _, err := repo.Restore(ctx, childID, alice); !errors.Is(err, entity.ErrMemoryNotFound) {
+		t.Errorf("Restore(purged) error = %v, want ErrMemoryNotFound", err)
+	}
+	if revisions, _ := repo.FindRevisions(ctx, parent, alice); len(revisions) != 0 {
+		t.Errorf("purged memory still has %d revisions", len(revisions))
+	}
+	assertSameIDs(t, "after purge", search(t, repo, alice, "project"), kept)
+}
+
+func testConsolidation(t *testing.T, repo repository.MemoryRepository) {
+	ctx := context.Background()
+
+	fresh := save(t, repo, memoryAt(alice, "Fresh memory", daysAgo(1)))
+	newest := save(t, repo, memoryAt(bob, "Newest memory", time.Now().Add(-time.Hour)))
+	save(t, repo, memoryAt(alice, "Old memory", daysAgo(10)))
+
+	reviewedAt := time.Now()
+	consolidated := memoryAt(alice, "Reviewed twice", daysAgo(2))
+	consolidated.ID = save(t, repo, consolidated)
+	consolidated.LastReviewed = &reviewedAt
+	consolidated.ReviewCount = 2
+	if err := repo.Update(ctx, consolidated); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	fragile, err := repo.GetFragileMemories(ctx)
+	if err != nil {
+		t.Fatalf("GetFragileMemories: %v", err)
+	}
+	assertIDs(t, "fragile", fragile, newest, fresh)
+
+	m := fragile[1]
+	m.PriorityScore = 0.7
+	m.LastConsolidated = time.Now().Add(-time.Minute)
+	if err := repo.UpdateConsolidation(ctx, m); err != nil {
+		t.Fatalf("UpdateConsolidation: %v", err)
+	}
+
+	found, err := repo.FindByID(ctx, fresh)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.PriorityScore != 0.7 {
+		t.Errorf("PriorityScore = %.1f, want 0.7", found.PriorityScore)
+	}
+	assertTimeNear(t, "LastConsolidated", found.LastConsolidated, m.LastConsolidated)
+}
+
+// Helpers
+
+// memoryAt builds a memory of the user created at the given time
+func memoryAt(userID int64, content string, createdAt time.Time) *entity.Memory {
+	m := entity.NewMemory(userID, chatOf(userID), content)
+	m.CreatedAt = createdAt
+	m.LastConsolidated = createdAt
+	return m
+}
+
+// chatOf returns the private chat of a fixture user
+func chatOf(userID int64) int64 {
+	return userID * 10
+}
+
+// daysAgo returns the time n days before now
+func daysAgo(n int) time.Time {
+	return time.Now().Add(-time.Duration(n) * 24 * time.Hour)
+}
+
+func save(t *testing.T, repo repository.MemoryRepository, m *entity.Memory) int {
+	t.Helper()
+	id, err := repo.Save(context.Background(), m)
+	if err != nil {
+		t.Fatalf("Save(%q): %v", m.Content, err)
+	}
+	return int(id)
+}
+
+func search(t *testing.T, repo repository.MemoryRepository, userID int64, query string) []*entity.Memory {
+	t.Helper()
+	memories, err := repo.Search(context.Background(), userID, query, repository.SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("Search(%q): %v", query, err)
+	}
+	return memories.Memories
+}
+
+func tagSearch(t *testing.T, repo repository.MemoryRepository, userID int64, tag string) []*entity.Memory {
+	t.Helper()
+	memories, err := repo.SearchByTag(context.Background(), userID, tag, repository.SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchByTag(%q): %v", tag, err)
+	}
+	return memories.Memories
+}
+
+func idsOf(memories []*entity.Memory) []int {
+	ids := make([]int, len(memories))
+	for i, m := range memories {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+// assertIDs checks the IDs and their order
+func assertIDs(t *testing.T, what string, memories []*entity.Memory, want ...int) {
+	t.Helper()
+	got := idsOf(memories)
+	if len(got) != len(want) {
+		t.Errorf("%s: got IDs %v, want %v", what, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s: got IDs %v, want %v", what, got, want)
+			return
+		}
+	}
+}
+
+// assertSameIDs checks the IDs in any order
+func assertSameIDs(t *testing.T, what string, memories []*entity.Memory, want ...int) {
+	t.Helper()
+	got := idsOf(memories)
+	sort.Ints(got)
+	sorted := append([]int{}, want...)
+	sort.Ints(sorted)
+	if len(got) != len(sorted) {
+		t.Errorf("%s: got IDs %v, want %v", what, got, sorted)
+		return
+	}
+	for i := range got {
+		if got[i] != sorted[i] {
+			t.Errorf("%s: got IDs %v, want %v", what, got, sorted)
+			return
+		}
+	}
+}
+
+func assertStrings(t *testing.T, what string, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", what, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s = %v, want %v", what, got, want)
+			return
+		}
+	}
+}
+
+func assertTimeNear(t *testing.T, what string, got, want time.Time) {
+	t.Helper()
+	if diff := got.Sub(want); diff > timeTolerance || diff < -timeTolerance {
+		t.Errorf("%s = %v, want %v", what, got, want)
+	}
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"memory-bot/internal/domain/entity"
+)
+
+// SavedSearchRepository defines the interface for saved search (watch) data access
+type SavedSearchRepository interface {
+	// Save stores a new saved search
+	Save(ctx context.Context, search *entity.SavedSearch) (int64, error)
+
+	// FindByUser retrieves all saved searches of a user
+	FindByUser(ctx context.Context, userID int64) ([]*entity.SavedSearch, error)
+
+	// Delete removes a saved search (with authorization check)
+	Delete(ctx context.Context, id int, userID int64) error
+}
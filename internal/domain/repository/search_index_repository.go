@@ -0,0 +1,13 @@
+package repository
+
+import "context"
+
+// SearchIndexRepository defines maintenance operations on the full-text search index
+type SearchIndexRepository interface {
+	// Reindex rebuilds the search index from the stored memories
+	// Returns the number of indexed memories
+	Reindex(ctx context.Context) (int, error)
+
+	// Tokenizer returns the name of the tokenizer the index is built with
+	Tokenizer() string
+}
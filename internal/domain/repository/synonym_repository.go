@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"memory-bot/internal/domain/entity"
+)
+
+// SynonymRepository defines the interface for search synonym data access
+type SynonymRepository interface {
+	// Add stores a synonym set; sets of the same owner sharing a term are merged into one
+	Add(ctx context.Context, set *entity.SynonymSet) (*entity.SynonymSet, error)
+
+	// FindForUser retrieves the user's own synonym sets followed by the global ones
+	FindForUser(ctx context.Context, userID int64) ([]*entity.SynonymSet, error)
+
+	// RemoveTerm removes a term from the owner's sets; sets left with one term are deleted
+	RemoveTerm(ctx context.Context, userID int64, term string) error
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"memory-bot/internal/domain/entity"
+)
+
+// TagRepository defines the interface for the normalized tag index
+// Tags are hierarchical ("work/projectx"); operations on a tag include its sub-tags
+type TagRepository interface {
+	// ListTags returns the direct children of parent ("" for top-level tags) with memory counts
+	ListTags(ctx context.Context, userID int64, parent string) ([]entity.TagCount, error)
+
+	// CountTag returns the number of memories carrying the tag or one of its sub-tags
+	CountTag(ctx context.Context, userID int64, tag string) (int, error)
+
+	// Rename renames a tag (and its sub-tags); fails with ErrTagExists if the new name is in use
+	Rename(ctx context.Context, userID int64, from, to string) (int, error)
+
+	// Merge moves all memories of source (and its sub-tags) onto target
+	Merge(ctx context.Context, userID int64, source, target string) (int, error)
+
+	// SetAlias stores an alias and moves memories already tagged with the alias onto its tag
+	SetAlias(ctx context.Context, alias *entity.TagAlias) (int, error)
+
+	// DeleteAlias removes an alias (with authorization check)
+	DeleteAlias(ctx context.Context, userID int64, alias string) error
+
+	// FindAliases retrieves all aliases of a user
+	FindAliases(ctx context.Context, userID int64) ([]*entity.TagAlias, error)
+}
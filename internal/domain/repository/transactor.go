@@ -0,0 +1,11 @@
+package repository
+
+import "context"
+
+// Transactor runs several repository operations as one unit of work
+type Transactor interface {
+	// WithTx calls fn with a context carrying the transaction; repository calls made with
+	// that context commit together when fn returns nil and are rolled back otherwise.
+	// A WithTx nested inside another one joins the outer transaction.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
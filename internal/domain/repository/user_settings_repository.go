@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"memory-bot/internal/domain/entity"
+)
+
+// UserSettingsRepository defines the interface for user settings data access
+type UserSettingsRepository interface {
+	// Get retrieves the settings of a user (defaults if none were stored)
+	Get(ctx context.Context, userID int64) (*entity.UserSettings, error)
+
+	// Save stores the settings of a user
+	Save(ctx context.Context, settings *entity.UserSettings) error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// VaultRepository defines the interface for personal vaults
+// A vault key is derived from the user's passphrase and only held in memory
+// while the vault is unlocked; memories saved meanwhile are wrapped with it.
+type VaultRepository interface {
+	// Exists reports whether the user has created a vault
+	Exists(ctx context.Context, userID int64) (bool, error)
+
+	// Unlock derives the vault key and starts a session, creating the vault on first use
+	// Returns ErrWrongPassphrase if the passphrase does not open the existing vault
+	Unlock(ctx context.Context, userID int64, passphrase string) (time.Time, error)
+
+	// Lock ends the session; reports whether the vault was unlocked
+	Lock(userID int64) bool
+
+	// UnlockedUntil returns when the session of an unlocked vault ends
+	UnlockedUntil(userID int64) (time.Time, bool)
+
+	// ExpireSessions locks the vaults whose session timed out and returns their users
+	ExpireSessions() []int64
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"strings"
+	"time"
+)
+
+// DateRange is a period of time; To is exclusive
+type DateRange struct {
+	From        time.Time
+	To          time.Time
+	Description string // Human readable period, e.g. "March 2024"
+}
+
+// dateLayouts are the absolute date formats understood by ParseDateExpression
+var dateLayouts = []struct {
+	layout string
+	months int // Length of the period in months (0 = one day)
+}{
+	{"2006-01-02", 0},
+	{"2006/01/02", 0},
+	{"02.01.2006", 0},
+	{"2006-01", 1},
+	{"January 2006", 1},
+	{"Jan 2006", 1},
+}
+
+// ParseDateExpression recognizes queries that consist of a date expression only
+// Examples: "today", "yesterday", "last week", "this month", "2024-03-15", "2024-03", "March 2024"
+// Weeks start on Monday; periods are in the location of now.
+func ParseDateExpression(expr string, now time.Time) (DateRange, bool) {
+	expr = strings.Join(strings.Fields(strings.ToLower(expr)), " ")
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	switch expr {
+	case "today":
+		return DateRange{From: today, To: today.AddDate(0, 0, 1), Description: "today"}, true
+	case "yesterday":
+		return DateRange{From: today.AddDate(0, 0, -1), To: today, Description: "yesterday"}, true
+	case "this week":
+		return DateRange{From: weekStart, To: weekStart.AddDate(0, 0, 7), Description: "this week"}, true
+	case "last week":
+		return DateRange{From: weekStart.AddDate(0, 0, -7), To: weekStart, Description: "last week"}, true
+	case "this month":
+		return DateRange{From: monthStart, To: monthStart.AddDate(0, 1, 0), Description: "this month"}, true
+	case "last month":
+		return DateRange{From: monthStart.AddDate(0, -1, 0), To: monthStart, Description: "last month"}, true
+	}
+
+	for _, format := range dateLayouts {
+		start, err := time.ParseInLocation(format.layout, expr, now.Location())
+		if err != nil {
+			continue
+		}
+
+		if format.months == 0 {
+			return DateRange{From: start, To: start.AddDate(0, 0, 1), Description: start.Format("2006-01-02")}, true
+		}
+		return DateRange{From: start, To: start.AddDate(0, format.months, 0), Description: start.Format("January 2006")}, true
+	}
+
+	return DateRange{}, false
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// KeywordExtractor picks the most distinctive terms of a memory using TF-IDF
+// Used for "more like this" retrieval: terms that are frequent in one memory
+// but rare across the user's other memories describe it best
+type KeywordExtractor struct {
+	stopWords map[string]bool
+	minLength int
+}
+
+// NewKeywordExtractor creates a new keyword extractor with an English stop word list
+func NewKeywordExtractor() *KeywordExtractor {
+	stopWords := map[string]bool{}
+	for _, word := range strings.Fields(`a about after again all also am an and any are as at be because been
+		before being but by can could did do does doing done for from had has have having he her here
+		him his how i if in into is it its just me more most my no not now of off on once only or other
+		our out over own same she should so some such than that the their them then there these they
+		this those through to too under until up very was we were what when where which while who why
+		will with would you your yours today tomorrow yesterday`) {
+		stopWords[word] = true
+	}
+
+	return &KeywordExtractor{
+		stopWords: stopWords,
+		minLength: 3,
+	}
+}
+
+// Tokenize splits text into lowercase terms, dropping hashtags, stop words and short tokens
+// Dots are kept inside terms to match the FTS5 tokenizer (tokenchars '.')
+func (e *KeywordExtractor) Tokenize(text string) []string {
+	var terms []string
+
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		// Tags are weighted separately
+		if strings.HasPrefix(word, "#") {
+			continue
+		}
+
+		for _, term := range strings.FieldsFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+		}) {
+			term = strings.Trim(term, ".")
+			if len([]rune(term)) < e.minLength || e.stopWords[term] {
+				continue
+			}
+			terms = append(terms, term)
+		}
+	}
+
+	return terms
+}
+
+// DocumentFrequencies counts in how many documents each term appears
+func (e *KeywordExtractor) DocumentFrequencies(documents [][]string) map[string]int {
+	docFreq := make(map[string]int)
+
+	for _, doc := range documents {
+		seen := make(map[string]bool, len(doc))
+		for _, term := range doc {
+			if !seen[term] {
+				seen[term] = true
+				docFreq[term]++
+			}
+		}
+	}
+
+	return docFreq
+}
+
+// TopTerms returns up to n terms of doc ordered by descending TF-IDF weight
+// docFreq and corpusSize describe the corpus the document belongs to
+func (e *KeywordExtractor) TopTerms(doc []string, docFreq map[string]int, corpusSize, n int) []string {
+	if len(doc) == 0 || n <= 0 {
+		return nil
+	}
+
+	termFreq := make(map[string]int)
+	for _, term := range doc {
+		termFreq[term]++
+	}
+
+	type weightedTerm struct {
+		term   string
+		weight float64
+	}
+
+	weighted := make([]weightedTerm, 0, len(termFreq))
+	for term, freq := range termFreq {
+		tf := float64(freq) / float64(len(doc))
+		// Smoothed IDF keeps terms that occur in every document slightly positive
+		idf := math.Log(float64(1+corpusSize)/float64(1+docFreq[term])) + 1.0
+		weighted = append(weighted, weightedTerm{term: term, weight: tf * idf})
+	}
+
+	sort.Slice(weighted, func(i, j int) bool {
+		if weighted[i].weight != weighted[j].weight {
+			return weighted[i].weight > weighted[j].weight
+		}
+		return weighted[i].term < weighted[j].term
+	})
+
+	if len(weighted) > n {
+		weighted = weighted[:n]
+	}
+
+	terms := make([]string, len(weighted))
+	for i, w := range weighted {
+		terms[i] = w.term
+	}
+	return terms
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"sort"
+
+	"memory-bot/internal/domain/entity"
+)
+
+// SynonymExpander looks up the synonyms of search terms
+// A term found in several sets (e.g. a user set and a global set) expands to all of them
+type SynonymExpander struct {
+	synonyms map[string][]string
+}
+
+// NewSynonymExpander creates an expander from the synonym sets that apply to a user
+func NewSynonymExpander(sets []*entity.SynonymSet) *SynonymExpander {
+	related := make(map[string]map[string]bool)
+
+	for _, set := range sets {
+		for _, term := range set.Terms {
+			if related[term] == nil {
+				related[term] = make(map[string]bool)
+			}
+			for _, other := range set.Terms {
+				if other != term {
+					related[term][other] = true
+				}
+			}
+		}
+	}
+
+	synonyms := make(map[string][]string, len(related))
+	for term, others := range related {
+		list := make([]string, 0, len(others))
+		for other := range others {
+			list = append(list, other)
+		}
+		sort.Strings(list)
+		synonyms[term] = list
+	}
+
+	return &SynonymExpander{
+		synonyms: synonyms,
+	}
+}
+
+// Synonyms returns the synonyms of a term (without the term itself); safe on a nil expander
+func (e *SynonymExpander) Synonyms(term string) []string {
+	if e == nil {
+		return nil
+	}
+	return e.synonyms[entity.NormalizeSynonymTerm(term)]
+}
@@ -0,0 +1,82 @@
+package service
+
+import "strings"
+
+// DiffOp is the kind of change of a diff segment
+type DiffOp int
+
+const (
+	DiffEqual  DiffOp = iota // Text present in both versions
+	DiffDelete               // Text only in the old version
+	DiffInsert               // Text only in the new version
+)
+
+// DiffSegment is a run of words with the same DiffOp
+type DiffSegment struct {
+	Op   DiffOp
+	Text string
+}
+
+// maxDiffCells bounds the LCS table; longer texts are shown as fully replaced
+const maxDiffCells = 1 << 20
+
+// DiffWords compares two texts word by word (longest common subsequence)
+// Whitespace is normalized to single spaces in the returned segments
+func DiffWords(before, after string) []DiffSegment {
+	a := strings.Fields(before)
+	b := strings.Fields(after)
+
+	if len(a)*len(b) > maxDiffCells {
+		return appendDiff(appendDiff(nil, DiffDelete, a...), DiffInsert, b...)
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var segments []DiffSegment
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			segments = appendDiff(segments, DiffEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			segments = appendDiff(segments, DiffDelete, a[i])
+			i++
+		default:
+			segments = appendDiff(segments, DiffInsert, b[j])
+			j++
+		}
+	}
+	segments = appendDiff(segments, DiffDelete, a[i:]...)
+	return appendDiff(segments, DiffInsert, b[j:]...)
+}
+
+// appendDiff adds words to the last segment if it has the same op
+func appendDiff(segments []DiffSegment, op DiffOp, words ...string) []DiffSegment {
+	if len(words) == 0 {
+		return segments
+	}
+
+	text := strings.Join(words, " ")
+	if n := len(segments); n > 0 && segments[n-1].Op == op {
+		segments[n-1].Text += " " + text
+		return segments
+	}
+	return append(segments, DiffSegment{Op: op, Text: text})
+}
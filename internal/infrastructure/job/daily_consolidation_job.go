@@ -2,6 +2,7 @@ package job
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"time"
 
@@ -13,13 +14,15 @@ import (
 // Biological principle: During sleep, the brain strengthens new memories
 // and transfers them from short-term (Hippocampus) to long-term storage (Cortex)
 type DailyConsolidationJob struct {
-	repo repository.MemoryRepository
+	repo       repository.MemoryRepository
+	transactor repository.Transactor
 }
 
 // NewDailyConsolidationJob creates a new consolidation job
-func NewDailyConsolidationJob(repo repository.MemoryRepository) *DailyConsolidationJob {
+func NewDailyConsolidationJob(repo repository.MemoryRepository, transactor repository.Transactor) *DailyConsolidationJob {
 	return &DailyConsolidationJob{
-		repo: repo,
+		repo:       repo,
+		transactor: transactor,
 	}
 }
 
@@ -44,16 +47,21 @@ func (j *DailyConsolidationJob) Execute() error {
 	log.Printf("Found %d fragile memories for consolidation", len(fragileMemories))
 
 	// 2. Apply consolidation to each memory
-	consolidated := 0
-	for _, memory := range fragileMemories {
-		if err := j.consolidateMemory(ctx, memory); err != nil {
-			log.Printf("Error consolidating memory %d: %v", memory.ID, err)
-			continue
+	// All memories are strengthened in one transaction, so a failed run leaves none half-done
+	err = j.transactor.WithTx(ctx, func(ctx context.Context) error {
+		for _, memory := range fragileMemories {
+			if err := j.consolidateMemory(ctx, memory); err != nil {
+				return fmt.Errorf("failed to consolidate memory %d: %w", memory.ID, err)
+			}
 		}
-		consolidated++
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error consolidating memories, nothing was changed: %v", err)
+		return err
 	}
 
-	log.Printf("✅ Consolidation complete: %d/%d memories strengthened", consolidated, len(fragileMemories))
+	log.Printf("✅ Consolidation complete: %d memories strengthened", len(fragileMemories))
 	return nil
 }
 
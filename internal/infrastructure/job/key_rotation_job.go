@@ -0,0 +1,60 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+)
+
+// KeyRotationJob re-encrypts stored data with the primary key in the background
+// after ENCRYPTION_KEY was rotated (the old key moved to ENCRYPTION_OLD_KEYS), after
+// an upgrade of the envelope format, and once when encryption is first turned on
+// (which encrypts the rows stored in plaintext)
+type KeyRotationJob struct {
+	useCase *usecase.RotateKeyUseCase
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewKeyRotationJob creates a new key rotation job
+func NewKeyRotationJob(useCase *usecase.RotateKeyUseCase) *KeyRotationJob {
+	return &KeyRotationJob{
+		useCase: useCase,
+	}
+}
+
+// Start resumes or starts the re-encryption unless every row already uses the primary key
+func (j *KeyRotationJob) Start() {
+	progress, err := j.useCase.Progress(context.Background())
+	if err != nil {
+		log.Printf("Error reading key rotation progress: %v", err)
+		return
+	}
+	if !progress.Pending() {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	j.done = make(chan struct{})
+
+	go func() {
+		defer close(j.done)
+		if _, err := j.useCase.Execute(ctx); err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, entity.ErrRotationRunning) {
+			log.Printf("Key rotation failed, it resumes on the next start: %v", err)
+		}
+	}()
+}
+
+// Stop interrupts a running re-encryption after the current batch
+func (j *KeyRotationJob) Stop() {
+	if j.cancel == nil {
+		return
+	}
+	log.Println("Stopping key rotation")
+	j.cancel()
+	<-j.done
+}
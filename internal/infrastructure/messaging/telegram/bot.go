@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
 	"memory-bot/internal/presentation/handler/command"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -14,18 +15,28 @@ import (
 
 // Bot represents the Telegram bot adapter
 type Bot struct {
-	api          *tgbotapi.BotAPI
-	registry     *command.CommandRegistry
-	saveUseCase  *usecase.SaveMemoryUseCase
-	userStates   map[int64]string
-	userMessages map[int64]*tgbotapi.Message
+	api            *tgbotapi.BotAPI
+	registry       *command.CommandRegistry
+	saveUseCase    *usecase.SaveMemoryUseCase
+	editUseCase    *usecase.EditMemoryUseCase
+	searchUseCase  *usecase.SearchMemoryUseCase
+	historyUseCase *usecase.QueryHistoryUseCase
+	userStates     map[int64]string
+	userMessages   map[int64]*tgbotapi.Message
+	suggestionsOn  map[int64]bool // Users currently shown the query suggestion keyboard
 }
 
+// searchSuggestionCount is the number of past queries offered on the search keyboard
+const searchSuggestionCount = 6
+
 // NewBot creates a new Telegram bot instance
 func NewBot(
 	token string,
 	registry *command.CommandRegistry,
 	saveUseCase *usecase.SaveMemoryUseCase,
+	editUseCase *usecase.EditMemoryUseCase,
+	searchUseCase *usecase.SearchMemoryUseCase,
+	historyUseCase *usecase.QueryHistoryUseCase,
 ) (*Bot, error) {
 	api, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
@@ -35,11 +46,15 @@ func NewBot(
 	log.Printf("Authorized on account %s", api.Self.UserName)
 
 	bot := &Bot{
-		api:          api,
-		registry:     registry,
-		saveUseCase:  saveUseCase,
-		userStates:   make(map[int64]string),
-		userMessages: make(map[int64]*tgbotapi.Message),
+		api:            api,
+		registry:       registry,
+		saveUseCase:    saveUseCase,
+		editUseCase:    editUseCase,
+		searchUseCase:  searchUseCase,
+		historyUseCase: historyUseCase,
+		userStates:     make(map[int64]string),
+		userMessages:   make(map[int64]*tgbotapi.Message),
+		suggestionsOn:  make(map[int64]bool),
 	}
 
 	// Set bot commands menu
@@ -83,8 +98,12 @@ func (b *Bot) Start() {
 	for update := range updates {
 		if update.Message != nil {
 			b.handleMessage(update.Message)
+		} else if update.EditedMessage != nil {
+			b.handleEditedMessage(update.EditedMessage)
 		} else if update.CallbackQuery != nil {
 			b.handleCallbackQuery(update.CallbackQuery)
+		} else if update.InlineQuery != nil {
+			b.handleInlineQuery(update.InlineQuery)
 		}
 	}
 }
@@ -94,17 +113,29 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 	ctx := context.Background()
 
 	if message.IsCommand() {
+		// A new command abandons interactive flows such as /edit
+		b.registry.CancelPendingInput(message.From.ID)
 		b.handleCommand(ctx, message)
 		return
 	}
 
 	userID := message.From.ID
 
+	// Interactive commands waiting for this message (e.g. the new text for /edit)
+	if cmd, ok := b.registry.PendingInput(userID); ok {
+		delete(b.userStates, userID)
+		if err := cmd.HandleInput(ctx, b.api, message); err != nil {
+			log.Printf("Error handling input for %s: %v", cmd.Name(), err)
+		}
+		return
+	}
+
 	// Check if user has a pending action
 	if state, exists := b.userStates[userID]; exists {
 		switch state {
 		case "search":
 			delete(b.userStates, userID)
+			b.removeSuggestionKeyboard(message.Chat.ID, userID, message.Text)
 			// Execute search command
 			if searchCmd, ok := b.registry.Get("search"); ok {
 				searchMsg := &tgbotapi.Message{
@@ -142,6 +173,34 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 	b.api.Send(msg)
 }
 
+// handleEditedMessage updates the memory saved from an edited message
+// Edits of messages that were not saved as memories are ignored
+func (b *Bot) handleEditedMessage(message *tgbotapi.Message) {
+	ctx := context.Background()
+
+	content := message.Text
+	if message.IsCommand() {
+		if message.Command() != "save" {
+			return
+		}
+		content = message.CommandArguments()
+	}
+
+	output, err := b.editUseCase.EditFromMessage(ctx, message.From.ID, message.Chat.ID, message.MessageID, content)
+	switch err {
+	case nil:
+		b.api.Send(command.EditedMessage(message.Chat.ID, output))
+	case entity.ErrMemoryNotFound, entity.ErrUnauthorized, entity.ErrContentUnchanged:
+	case entity.ErrEmptyContent:
+		b.sendMessage(message.Chat.ID, "❌ A memory cannot be empty. The memory was not changed.")
+	case entity.ErrVaultLocked:
+		b.sendMessage(message.Chat.ID, "🔒 This memory is in your vault. Use /unlock, then edit the message again.")
+	default:
+		log.Printf("Error updating memory from edited message: %v", err)
+		b.sendMessage(message.Chat.ID, "❌ Failed to update the memory from your edited message.")
+	}
+}
+
 // handleCommand handles bot commands
 func (b *Bot) handleCommand(ctx context.Context, message *tgbotapi.Message) {
 	cmdName := message.Command()
@@ -221,7 +280,13 @@ func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 		b.handleActionButton(ctx, query, parts[1])
 
 	default:
-		b.api.Send(tgbotapi.NewCallback(query.ID, "Unknown action"))
+		// Commands can own their callbacks (e.g. "unwatch:<id>")
+		err := b.registry.ExecuteCallback(ctx, b.api, query)
+		if err == command.ErrCommandNotFound {
+			b.api.Send(tgbotapi.NewCallback(query.ID, "Unknown action"))
+		} else if err != nil {
+			log.Printf("Error handling callback %s: %v", query.Data, err)
+		}
 	}
 }
 
@@ -243,6 +308,10 @@ func (b *Bot) handleActionButton(ctx context.Context, query *tgbotapi.CallbackQu
 		response := "🔍 *Search Memories*\n\nJust type your search keywords and send. I'll find matching memories!\n\n*Examples:*\n• `Milan` - find memories with \"Milan\"\n• `doctor health` - find memories with both words\n• `#work` - find all work memories\n\n*Tips:*\n• Use partial words (\"tele\" finds \"telegram\")\n• Multiple words search together\n• No need to use /search command"
 		msg := tgbotapi.NewMessage(chatID, response)
 		msg.ParseMode = "Markdown"
+		if keyboard := b.suggestionKeyboard(ctx, query.From.ID); keyboard != nil {
+			msg.ReplyMarkup = keyboard
+			b.suggestionsOn[query.From.ID] = true
+		}
 		b.api.Send(msg)
 		b.api.Send(tgbotapi.NewCallback(query.ID, "Send search keywords as next message"))
 
@@ -274,9 +343,10 @@ func (b *Bot) handleActionButton(ctx context.Context, query *tgbotapi.CallbackQu
 // saveMemoryFromText saves a memory from a text message
 func (b *Bot) saveMemoryFromText(ctx context.Context, message *tgbotapi.Message) {
 	input := usecase.SaveMemoryInput{
-		UserID:  message.From.ID,
-		ChatID:  message.Chat.ID,
-		Content: message.Text,
+		UserID:    message.From.ID,
+		ChatID:    message.Chat.ID,
+		Content:   message.Text,
+		MessageID: message.MessageID,
 	}
 
 	output, err := b.saveUseCase.Execute(ctx, input)
@@ -290,8 +360,59 @@ func (b *Bot) saveMemoryFromText(ctx context.Context, message *tgbotapi.Message)
 	if len(output.Tags) > 0 {
 		response += fmt.Sprintf(" Tags: %s", strings.Join(output.Tags, ", "))
 	}
+	if output.Secret {
+		response += "\n🤫 Saved as secret: masked in search, /recent and reviews. Consider deleting your message."
+	}
 
-	b.sendMessage(message.Chat.ID, response)
+	// Offer related memories right after saving
+	msg := tgbotapi.NewMessage(message.Chat.ID, response)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔗 Related Memories", fmt.Sprintf("related:%d", output.MemoryID)),
+		),
+	)
+	b.api.Send(msg)
+}
+
+// suggestionKeyboard builds a reply keyboard of the user's recent and frequent queries
+// Returns nil if the user has no history (or did not opt in), or history is off (in-memory mode)
+func (b *Bot) suggestionKeyboard(ctx context.Context, userID int64) *tgbotapi.ReplyKeyboardMarkup {
+	if b.historyUseCase == nil {
+		return nil
+	}
+	suggestions, err := b.historyUseCase.Suggestions(ctx, userID, searchSuggestionCount)
+	if err != nil {
+		log.Printf("Error loading search suggestions for user %d: %v", userID, err)
+		return nil
+	}
+	if len(suggestions) == 0 {
+		return nil
+	}
+
+	rows := [][]tgbotapi.KeyboardButton{}
+	for i := 0; i < len(suggestions); i += 2 {
+		row := tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(suggestions[i]))
+		if i+1 < len(suggestions) {
+			row = append(row, tgbotapi.NewKeyboardButton(suggestions[i+1]))
+		}
+		rows = append(rows, row)
+	}
+
+	keyboard := tgbotapi.NewOneTimeReplyKeyboard(rows...)
+	keyboard.InputFieldPlaceholder = "Search keywords"
+	return &keyboard
+}
+
+// removeSuggestionKeyboard hides the suggestion keyboard once the user searched
+func (b *Bot) removeSuggestionKeyboard(chatID, userID int64, keyword string) {
+	if !b.suggestionsOn[userID] {
+		return
+	}
+	delete(b.suggestionsOn, userID)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🔍 Searching for \"%s\"...", keyword))
+	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(false)
+	b.api.Send(msg)
 }
 
 // sendMessage is a helper function to send text messages
@@ -0,0 +1,115 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// inlinePageSize is the number of results returned per inline page
+	inlinePageSize = 10
+
+	// inlineCacheSeconds is how long Telegram may cache results for the querying user
+	inlineCacheSeconds = 30
+
+	// inlineTitleLength is the maximum title length shown in the result list
+	inlineTitleLength = 60
+)
+
+// handleInlineQuery answers `@bot keyword` queries typed in any chat
+// Results are personal (IsPersonal) so Telegram never shows them to other users
+func (b *Bot) handleInlineQuery(query *tgbotapi.InlineQuery) {
+	ctx := context.Background()
+
+	keyword := strings.TrimSpace(query.Query)
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       []interface{}{},
+		CacheTime:     inlineCacheSeconds,
+		IsPersonal:    true,
+	}
+
+	if keyword == "" {
+		answer.SwitchPMText = "Type keywords to search your memories"
+		answer.SwitchPMParameter = "inline"
+		b.answerInlineQuery(answer)
+		return
+	}
+
+	// Inline queries arrive on every keystroke, so they are not logged
+	// Telegram echoes back the NextOffset (the cursor) of the previous page
+	input := usecase.SearchMemoryInput{
+		UserID:      query.From.ID,
+		Keyword:     keyword,
+		Limit:       inlinePageSize,
+		Cursor:      repository.Cursor(query.Offset),
+		SkipHistory: true,
+	}
+
+	output, err := b.searchUseCase.Execute(ctx, input)
+	if errors.Is(err, entity.ErrInvalidCursor) {
+		b.answerInlineQuery(answer) // A stale offset ends the list
+		return
+	}
+	if err != nil {
+		log.Printf("Error handling inline query for user %d: %v", query.From.ID, err)
+		b.answerInlineQuery(answer)
+		return
+	}
+
+	for _, mem := range output.Memories {
+		answer.Results = append(answer.Results, newInlineMemoryResult(mem))
+	}
+
+	if output.HasMore {
+		answer.NextOffset = inlineOffset(output.NextCursor)
+	}
+
+	b.answerInlineQuery(answer)
+}
+
+// inlineOffset returns the cursor as the offset Telegram echoes back for the next page
+// Telegram rejects the whole answer for an offset over 64 bytes, so a longer cursor
+// ends the list instead.
+func inlineOffset(cursor repository.Cursor) string {
+	if len(cursor) > repository.MaxCursorLength {
+		log.Printf("⚠️ Inline cursor of %d bytes is too long, results end here", len(cursor))
+		return ""
+	}
+	return string(cursor)
+}
+
+// answerInlineQuery sends the inline answer to Telegram
+func (b *Bot) answerInlineQuery(answer tgbotapi.InlineConfig) {
+	if _, err := b.api.Request(answer); err != nil {
+		log.Printf("Error answering inline query: %v", err)
+	}
+}
+
+// newInlineMemoryResult builds an article result that inserts the memory content
+// Secret memories are inserted masked, inline results can't be revealed and deleted later.
+func newInlineMemoryResult(mem *entity.Memory) tgbotapi.InlineQueryResultArticle {
+	title := strings.Join(strings.Fields(mem.Masked()), " ")
+	if runes := []rune(title); len(runes) > inlineTitleLength {
+		title = string(runes[:inlineTitleLength]) + "..."
+	}
+
+	description := mem.CreatedAt.Format("2006-01-02 03:04 PM")
+	if len(mem.Tags) > 0 {
+		description += " • #" + strings.Join(mem.Tags, " #")
+	}
+
+	article := tgbotapi.NewInlineQueryResultArticle(fmt.Sprintf("memory-%d", mem.ID), title, mem.Masked())
+	article.Description = description
+
+	return article
+}
@@ -0,0 +1,25 @@
+package telegram
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"memory-bot/internal/domain/repository"
+)
+
+func TestInlineOffsetFitsTelegram(t *testing.T) {
+	// The tiers listing has the most keys; these have the longest decimal forms
+	cursor := repository.NewCursor(repository.CursorPosition{
+		Listing: repository.ListingTiers,
+		Keys:    []float64{-math.MaxFloat64, -1.2345678901234567e-300},
+		ID:      math.MaxInt,
+	})
+	if offset := inlineOffset(cursor); offset != string(cursor) || len(offset) > 64 {
+		t.Errorf("inlineOffset = %q (%d bytes)", offset, len(offset))
+	}
+
+	if offset := inlineOffset(repository.Cursor(strings.Repeat("A", 65))); offset != "" {
+		t.Errorf("inlineOffset of an overlong cursor = %q, want none", offset)
+	}
+}
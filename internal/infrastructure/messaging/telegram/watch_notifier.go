@@ -0,0 +1,54 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// WatchNotifier sends saved search match notifications through Telegram
+type WatchNotifier struct {
+	api *tgbotapi.BotAPI
+}
+
+// NewWatchNotifier creates a new Telegram watch notifier
+func NewWatchNotifier(api *tgbotapi.BotAPI) *WatchNotifier {
+	return &WatchNotifier{
+		api: api,
+	}
+}
+
+// NotifyWatchMatch sends the matching memory together with the watch name
+func (n *WatchNotifier) NotifyWatchMatch(ctx context.Context, watch *entity.SavedSearch, memory *entity.Memory) error {
+	if _, err := n.api.Send(watchMatchMessage(watch, memory)); err != nil {
+		return fmt.Errorf("failed to send watch notification: %w", err)
+	}
+	return nil
+}
+
+// watchMatchMessage builds the notification of a watch match
+// The watch name and memory text are user input and are escaped for Markdown.
+func watchMatchMessage(watch *entity.SavedSearch, memory *entity.Memory) tgbotapi.MessageConfig {
+	text := fmt.Sprintf(
+		"🔔 *New match for watch:* %s\n\n%s\n\n🆔 Memory #%d – %s",
+		markdown.Escape(watch.Name),
+		markdown.Escape(memory.Masked()),
+		memory.ID,
+		memory.CreatedAt.Format("2006-01-02 03:04 PM"),
+	)
+
+	msg := tgbotapi.NewMessage(watch.ChatID, text)
+	msg.ParseMode = "Markdown"
+	if memory.Secret {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("👁 Reveal", fmt.Sprintf("secret:%d", memory.ID)),
+			),
+		)
+	}
+	return msg
+}
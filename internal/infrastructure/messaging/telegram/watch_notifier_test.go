@@ -0,0 +1,44 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+)
+
+func TestWatchMatchMessageEscapesMarkdown(t *testing.T) {
+	watch := entity.NewSavedSearch(1, 42, "deploy_prod", "#deploy_prod")
+	created := time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		secret bool
+		want   string
+	}{
+		{"public", false, "🔔 *New match for watch:* deploy\\_prod\n\n" +
+			"Restart db\\_backup after \\*every\\* \\`deploy\\` \\[runbook] #deploy\\_prod\n\n🆔 Memory #7 – 2024-03-15 09:30 AM"},
+		{"secret", true, "🔔 *New match for watch:* deploy\\_prod\n\n" +
+			"🤫 Secret memory #deploy\\_prod\n\n🆔 Memory #7 – 2024-03-15 09:30 AM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := entity.NewMemory(1, 42, "Restart db_backup after *every* `deploy` [runbook] #deploy_prod")
+			memory.ID = 7
+			memory.CreatedAt = created
+			memory.Secret = tt.secret
+
+			msg := watchMatchMessage(watch, memory)
+			if msg.Text != tt.want {
+				t.Errorf("text = %q, want %q", msg.Text, tt.want)
+			}
+			if msg.ChatID != 42 || msg.ParseMode != "Markdown" {
+				t.Errorf("chat %d, parse mode %q", msg.ChatID, msg.ParseMode)
+			}
+			if (msg.ReplyMarkup != nil) != tt.secret {
+				t.Errorf("reveal button: %v", msg.ReplyMarkup)
+			}
+		})
+	}
+}
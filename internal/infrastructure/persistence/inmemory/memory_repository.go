@@ -0,0 +1,707 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+	"memory-bot/internal/domain/service"
+)
+
+const (
+	// fragileWindow is how long a new memory counts as fragile
+	fragileWindow = 7 * 24 * time.Hour
+
+	// relatedTermCount and relatedTagCount mirror the SQLite related search
+	relatedTermCount = 8
+	relatedTagCount  = 3
+)
+
+// MemoryRepository keeps memories in process memory (ephemeral demo runs and tests)
+// Search is a simple token match instead of FTS5: words match as prefixes, all words
+// are required, and results are ranked by matched terms plus emotional weight,
+// priority and recency like the SQLite ranking. Synonyms and tag aliases are not applied,
+// and content is kept in plaintext.
+type MemoryRepository struct {
+	mu             sync.RWMutex
+	memories       map[int]*entity.Memory
+	revisions      map[int][]*entity.MemoryRevision
+	nextID         int
+	nextRevisionID int
+
+	keywordExtractor *service.KeywordExtractor
+	now              func() time.Time
+}
+
+// NewMemoryRepository creates an empty in-memory memory repository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		memories:         make(map[int]*entity.Memory),
+		revisions:        make(map[int][]*entity.MemoryRevision),
+		keywordExtractor: service.NewKeywordExtractor(),
+		now:              time.Now,
+	}
+}
+
+// Save stores a new memory
+func (r *MemoryRepository) Save(ctx context.Context, memory *entity.Memory) (int64, error) {
+	if err := memory.Validate(); err != nil {
+		return 0, err
+	}
+	memory.Tags = normalizeTags(memory.Tags)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	stored := clone(memory)
+	stored.ID = r.nextID
+	stored.DeletedAt = nil
+	r.memories[stored.ID] = stored
+
+	return int64(stored.ID), nil
+}
+
+// FindByID retrieves a memory by its ID
+func (r *MemoryRepository) FindByID(ctx context.Context, id int) (*entity.Memory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.live(id)
+	if !ok {
+		return nil, entity.ErrMemoryNotFound
+	}
+	return clone(m), nil
+}
+
+// Search returns the user's memories matching all words of the query, best ranked first
+func (r *MemoryRepository) Search(ctx context.Context, userID int64, query string, opts repository.SearchOptions) (*repository.MemoryPage, error) {
+	q := parseQuery(query, opts.ExactPhrase)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []keyed
+	for _, m := range r.userMemories(userID, opts.ContextFilter) {
+		if score := q.score(m); score > 0 {
+			ranked := r.ranked(m, float64(score))
+			matches = append(matches, keyed{memory: ranked, keys: []float64{ranked.Rank}})
+		}
+	}
+
+	return pageAfter(repository.ListingSearch, matches, 1, false, pageOptions(opts))
+}
+
+// SearchTiers returns the matches of all tiers without duplicates, ordered by tier first
+func (r *MemoryRepository) SearchTiers(ctx context.Context, userID int64, tiers []repository.SearchTier, opts repository.SearchOptions) (*repository.MemoryPage, string, error) {
+	queries := make([][]query, len(tiers))
+	for i, tier := range tiers {
+		for _, raw := range tier.Queries {
+			queries[i] = append(queries[i], parseQuery(raw, opts.ExactPhrase))
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Descending -tier orders by tier first, like the SQLite statement
+	var hits []keyed
+	for _, m := range r.userMemories(userID, nil) {
+	tiers:
+		for i := range tiers {
+			for _, q := range queries[i] {
+				if score := q.score(m); score > 0 {
+					ranked := r.ranked(m, float64(score))
+					hits = append(hits, keyed{memory: ranked, keys: []float64{-float64(i), ranked.Rank}})
+					break tiers
+				}
+			}
+		}
+	}
+
+	page, err := pageAfter(repository.ListingTiers, hits, 2, false, pageOptions(opts))
+	if err != nil || len(page.Memories) == 0 {
+		return page, "", err
+	}
+	for _, hit := range hits {
+		if hit.memory == page.Memories[0] {
+			return page, tiers[-int(hit.keys[0])].Step, nil
+		}
+	}
+	return page, "", nil
+}
+
+// SearchByTag retrieves memories carrying the tag or one of its sub-tags, newest first
+func (r *MemoryRepository) SearchByTag(ctx context.Context, userID int64, tag string, opts repository.SearchOptions) (*repository.MemoryPage, error) {
+	tag = entity.NormalizeTag(tag)
+	if tag == "" {
+		return nil, entity.ErrInvalidTag
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []keyed
+	for _, m := range r.userMemories(userID, opts.ContextFilter) {
+		if hasTag(m, tag) {
+			matches = append(matches, keyed{memory: r.ranked(m, 0), keys: timeKey(m)})
+		}
+	}
+
+	return pageAfter(repository.ListingTag, matches, 1, false, pageOptions(opts))
+}
+
+// FindByDateRange retrieves memories created in [from, to), newest first
+func (r *MemoryRepository) FindByDateRange(ctx context.Context, userID int64, from, to time.Time, opts repository.SearchOptions) (*repository.MemoryPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []keyed
+	for _, m := range r.userMemories(userID, nil) {
+		if !m.CreatedAt.Before(from) && m.CreatedAt.Before(to) {
+			matches = append(matches, keyed{memory: r.ranked(m, 0), keys: timeKey(m)})
+		}
+	}
+
+	return pageAfter(repository.ListingDate, matches, 1, false, pageOptions(opts))
+}
+
+// ExplainQuery describes the query; there is no full-text expression or synonym expansion
+func (r *MemoryRepository) ExplainQuery(ctx context.Context, userID int64, query string) (*entity.SearchExplanation, error) {
+	return &entity.SearchExplanation{
+		Expression: strings.TrimSpace(query),
+		Expansions: make(map[string][]string),
+	}, nil
+}
+
+// MatchesQuery reports whether a memory matches the query the same way Search would
+func (r *MemoryRepository) MatchesQuery(ctx context.Context, memoryID int, userID int64, query string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.live(memoryID)
+	if !ok || m.UserID != userID {
+		return false, nil
+	}
+	return parseQuery(query, false).score(m) > 0, nil
+}
+
+// FindRelated finds memories sharing the source's most distinctive terms and tags (TF-IDF)
+func (r *MemoryRepository) FindRelated(ctx context.Context, userID int64, memoryID int, limit int) ([]*entity.Memory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	source, ok := r.live(memoryID)
+	if !ok {
+		return nil, entity.ErrMemoryNotFound
+	}
+	if source.UserID != userID {
+		return nil, entity.ErrUnauthorized
+	}
+
+	candidates := r.userMemories(userID, nil)
+	var corpus, tagCorpus [][]string
+	for _, m := range candidates {
+		corpus = append(corpus, r.keywordExtractor.Tokenize(m.Content))
+		tagCorpus = append(tagCorpus, lowerAll(m.Tags))
+	}
+
+	terms := r.keywordExtractor.TopTerms(
+		r.keywordExtractor.Tokenize(source.Content),
+		r.keywordExtractor.DocumentFrequencies(corpus), len(corpus), relatedTermCount)
+	tags := r.keywordExtractor.TopTerms(
+		lowerAll(source.Tags),
+		r.keywordExtractor.DocumentFrequencies(tagCorpus), len(tagCorpus), relatedTagCount)
+
+	var related []*entity.Memory
+	for i, m := range candidates {
+		if m.ID == memoryID {
+			continue
+		}
+		score := countShared(terms, corpus[i]) + countShared(tags, tagCorpus[i])
+		if score > 0 {
+			related = append(related, r.ranked(m, float64(score)))
+		}
+	}
+
+	sort.SliceStable(related, func(i, j int) bool {
+		return related[i].Rank > related[j].Rank
+	})
+	return limitTo(related, limit), nil
+}
+
+// GetRecent retrieves the most recent memories for a user, newest first
+func (r *MemoryRepository) GetRecent(ctx context.Context, userID int64, opts repository.PageOptions) (*repository.MemoryPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var memories []keyed
+	for _, m := range r.userMemories(userID, nil) {
+		memories = append(memories, keyed{memory: clone(m), keys: timeKey(m)})
+	}
+	return pageAfter(repository.ListingRecent, memories, 1, false, opts)
+}
+
+// GetForReview retrieves memories last reviewed (or created) at least one interval ago,
+// longest waiting first
+func (r *MemoryRepository) GetForReview(ctx context.Context, intervals []int, opts repository.PageOptions) (*repository.MemoryPage, error) {
+	if len(intervals) == 0 {
+		return &repository.MemoryPage{Memories: []*entity.Memory{}}, nil
+	}
+	shortest := intervals[0]
+	for _, days := range intervals {
+		if days < shortest {
+			shortest = days
+		}
+	}
+	due := r.now().Add(-time.Duration(shortest) * 24 * time.Hour)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var memories []keyed
+	for _, m := range r.memories {
+		if m.DeletedAt == nil && !lastSeen(m).After(due) {
+			memories = append(memories, keyed{memory: clone(m), keys: []float64{float64(lastSeen(m).UnixMicro())}})
+		}
+	}
+	return pageAfter(repository.ListingReview, memories, 1, true, opts)
+}
+
+// Update updates the review fields of a memory
+func (r *MemoryRepository) Update(ctx context.Context, memory *entity.Memory) error {
+	if err := memory.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if m, ok := r.memories[memory.ID]; ok {
+		m.LastReviewed = copyTime(memory.LastReviewed)
+		m.ReviewCount = memory.ReviewCount
+	}
+	return nil
+}
+
+// UpdateContent stores edited content and records it as a new revision
+func (r *MemoryRepository) UpdateContent(ctx context.Context, memory *entity.Memory, source string, restoredFrom int) (*entity.MemoryRevision, error) {
+	if err := memory.Validate(); err != nil {
+		return nil, err
+	}
+	memory.Tags = normalizeTags(memory.Tags)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.live(memory.ID)
+	if !ok || stored.UserID != memory.UserID {
+		return nil, entity.ErrMemoryNotFound
+	}
+
+	// The content before the first edit becomes revision 1
+	if len(r.revisions[memory.ID]) == 0 {
+		r.addRevision(&entity.MemoryRevision{
+			MemoryID:        stored.ID,
+			Number:          1,
+			Content:         stored.Content,
+			Tags:            append([]string{}, stored.Tags...),
+			EmotionalWeight: stored.EmotionalWeight,
+			Source:          entity.RevisionSourceOriginal,
+			CreatedAt:       stored.CreatedAt,
+		})
+	}
+
+	revision := &entity.MemoryRevision{
+		MemoryID:        memory.ID,
+		Number:          len(r.revisions[memory.ID]) + 1,
+		Content:         memory.Content,
+		Tags:            append([]string{}, memory.Tags...),
+		EmotionalWeight: memory.EmotionalWeight,
+		Source:          source,
+		RestoredFrom:    restoredFrom,
+		CreatedAt:       r.now(),
+	}
+	r.addRevision(revision)
+
+	stored.Content = memory.Content
+	stored.Tags = append([]string{}, memory.Tags...)
+	stored.EmotionalWeight = memory.EmotionalWeight
+	stored.Secret = memory.Secret
+
+	copied := *revision
+	return &copied, nil
+}
+
+// SetSecret marks a user's live memory as secret or not
+func (r *MemoryRepository) SetSecret(ctx context.Context, id int, userID int64, secret bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.live(id)
+	if !ok {
+		return entity.ErrMemoryNotFound
+	}
+	if m.UserID != userID {
+		return entity.ErrUnauthorized
+	}
+	m.Secret = secret
+	return nil
+}
+
+// FindRevisions returns the recorded revisions of a user's memory, oldest first
+func (r *MemoryRepository) FindRevisions(ctx context.Context, memoryID int, userID int64) ([]*entity.MemoryRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.live(memoryID)
+	if !ok || m.UserID != userID {
+		return nil, nil
+	}
+
+	var revisions []*entity.MemoryRevision
+	for _, revision := range r.revisions[memoryID] {
+		copied := *revision
+		copied.Tags = append([]string{}, revision.Tags...)
+		revisions = append(revisions, &copied)
+	}
+	return revisions, nil
+}
+
+// FindBySourceMessage finds the live memory saved from a Telegram message
+func (r *MemoryRepository) FindBySourceMessage(ctx context.Context, chatID int64, messageID int) (*entity.Memory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found *entity.Memory
+	for _, m := range r.memories {
+		if m.DeletedAt == nil && m.ChatID == chatID && m.SourceMessageID == messageID &&
+			(found == nil || m.ID > found.ID) {
+			found = m
+		}
+	}
+	if found == nil {
+		return nil, entity.ErrMemoryNotFound
+	}
+	return clone(found), nil
+}
+
+// Delete moves a memory and its live sub-memories to the trash with the same timestamp
+func (r *MemoryRepository) Delete(ctx context.Context, id int, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, err := r.owned(id, userID)
+	if err != nil {
+		return err
+	}
+	if m.DeletedAt != nil {
+		return entity.ErrMemoryNotFound
+	}
+
+	deletedAt := r.now()
+	for _, member := range r.subtree(m, func(child *entity.Memory) bool { return child.DeletedAt == nil }) {
+		member.DeletedAt = copyTime(&deletedAt)
+	}
+	return nil
+}
+
+// Restore brings a memory back from the trash with the sub-memories trashed along with it
+// A sub-memory whose parent is still in the trash is restored as a root memory
+func (r *MemoryRepository) Restore(ctx context.Context, id int, userID int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, err := r.owned(id, userID)
+	if err != nil {
+		return 0, err
+	}
+	if m.DeletedAt == nil {
+		return 0, entity.ErrMemoryNotFound
+	}
+
+	if m.ParentID != nil {
+		if parent, ok := r.memories[int(*m.ParentID)]; ok && parent.DeletedAt != nil {
+			m.ParentID = nil
+		}
+	}
+
+	deletedAt := *m.DeletedAt
+	members := r.subtree(m, func(child *entity.Memory) bool {
+		return child.DeletedAt != nil && child.DeletedAt.Equal(deletedAt)
+	})
+	for _, member := range members {
+		member.DeletedAt = nil
+	}
+	return len(members), nil
+}
+
+// FindDeleted lists the memories in a user's trash, most recently deleted first
+func (r *MemoryRepository) FindDeleted(ctx context.Context, userID int64, limit int) ([]*entity.Memory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var memories []*entity.Memory
+	for _, m := range r.memories {
+		if m.UserID == userID && m.DeletedAt != nil {
+			memories = append(memories, clone(m))
+		}
+	}
+
+	sort.SliceStable(memories, func(i, j int) bool {
+		if !memories[i].DeletedAt.Equal(*memories[j].DeletedAt) {
+			return memories[i].DeletedAt.After(*memories[j].DeletedAt)
+		}
+		return memories[i].ID > memories[j].ID
+	})
+	return limitTo(memories, limit), nil
+}
+
+// PurgeDeleted permanently removes memories trashed before the cutoff
+func (r *MemoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := make(map[int64]bool)
+	for id, m := range r.memories {
+		if m.DeletedAt != nil && m.DeletedAt.Before(before) {
+			purged[int64(id)] = true
+			delete(r.memories, id)
+			delete(r.revisions, id)
+		}
+	}
+
+	for _, m := range r.memories {
+		if m.ParentID != nil && purged[*m.ParentID] {
+			m.ParentID = nil
+		}
+	}
+	return len(purged), nil
+}
+
+// Count returns the number of live memories of a user
+func (r *MemoryRepository) Count(ctx context.Context, userID int64) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.userMemories(userID, nil)), nil
+}
+
+// GetFragileMemories retrieves memories of the last 7 days reviewed fewer than twice, newest first
+func (r *MemoryRepository) GetFragileMemories(ctx context.Context) ([]*entity.Memory, error) {
+	since := r.now().Add(-fragileWindow)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	memories := []*entity.Memory{}
+	for _, m := range r.memories {
+		if m.DeletedAt == nil && !m.CreatedAt.Before(since) && m.ReviewCount < 2 {
+			memories = append(memories, clone(m))
+		}
+	}
+
+	sortNewestFirst(memories)
+	return memories, nil
+}
+
+// UpdateConsolidation updates consolidation-related fields
+func (r *MemoryRepository) UpdateConsolidation(ctx context.Context, memory *entity.Memory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if m, ok := r.memories[memory.ID]; ok {
+		m.LastConsolidated = memory.LastConsolidated
+		m.PriorityScore = memory.PriorityScore
+	}
+	return nil
+}
+
+// Helper functions (callers hold the lock)
+
+// live returns a memory that is not in the trash
+func (r *MemoryRepository) live(id int) (*entity.Memory, bool) {
+	m, ok := r.memories[id]
+	if !ok || m.DeletedAt != nil {
+		return nil, false
+	}
+	return m, true
+}
+
+// owned returns a memory (trashed or not) after checking its owner
+func (r *MemoryRepository) owned(id int, userID int64) (*entity.Memory, error) {
+	m, ok := r.memories[id]
+	if !ok {
+		return nil, entity.ErrMemoryNotFound
+	}
+	if m.UserID != userID {
+		return nil, entity.ErrUnauthorized
+	}
+	return m, nil
+}
+
+// userMemories returns the user's live memories matching the contextual filter, by ID
+func (r *MemoryRepository) userMemories(userID int64, filter *service.ContextualData) []*entity.Memory {
+	var memories []*entity.Memory
+	for _, m := range r.memories {
+		if m.UserID != userID || m.DeletedAt != nil {
+			continue
+		}
+		if filter != nil {
+			if filter.TimeOfDay != "" && m.TimeOfDay != filter.TimeOfDay {
+				continue
+			}
+			if filter.DayOfWeek != "" && m.DayOfWeek != filter.DayOfWeek {
+				continue
+			}
+		}
+		memories = append(memories, m)
+	}
+
+	sort.Slice(memories, func(i, j int) bool {
+		return memories[i].ID < memories[j].ID
+	})
+	return memories
+}
+
+// subtree returns the memory and its descendants accepted by follow
+func (r *MemoryRepository) subtree(root *entity.Memory, follow func(child *entity.Memory) bool) []*entity.Memory {
+	members := []*entity.Memory{root}
+	seen := map[int]bool{root.ID: true}
+
+	for i := 0; i < len(members); i++ {
+		parentID := int64(members[i].ID)
+		for _, m := range r.memories {
+			if !seen[m.ID] && m.ParentID != nil && *m.ParentID == parentID && follow(m) {
+				seen[m.ID] = true
+				members = append(members, m)
+			}
+		}
+	}
+	return members
+}
+
+// addRevision stores a revision with a new ID
+func (r *MemoryRepository) addRevision(revision *entity.MemoryRevision) {
+	r.nextRevisionID++
+	revision.ID = r.nextRevisionID
+	r.revisions[revision.MemoryID] = append(r.revisions[revision.MemoryID], revision)
+}
+
+// ranked returns a copy of the memory with the combined rank of the SQLite search:
+// relevance + emotional weight * 2 + priority * 1.5 + recency bonus
+func (r *MemoryRepository) ranked(m *entity.Memory, relevance float64) *entity.Memory {
+	copied := clone(m)
+	copied.Rank = relevance + m.EmotionalWeight*2.0 + m.PriorityScore*1.5
+
+	age := r.now().Sub(m.CreatedAt)
+	switch {
+	case age < 7*24*time.Hour:
+		copied.Rank += 1.0
+	case age < 30*24*time.Hour:
+		copied.Rank += 0.5
+	}
+	return copied
+}
+
+// sortNewestFirst orders memories by creation time, newest first
+func sortNewestFirst(memories []*entity.Memory) {
+	sort.SliceStable(memories, func(i, j int) bool {
+		return newerThan(memories[i], memories[j])
+	})
+}
+
+// newerThan orders by creation time, then by ID, newest first
+func newerThan(a, b *entity.Memory) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID > b.ID
+}
+
+// pageOptions selects the page of a search
+func pageOptions(opts repository.SearchOptions) repository.PageOptions {
+	return repository.PageOptions{Limit: opts.Limit, Cursor: opts.Cursor}
+}
+
+// lastSeen returns when the memory was last reviewed, or created if never reviewed
+func lastSeen(m *entity.Memory) time.Time {
+	if m.LastReviewed != nil {
+		return *m.LastReviewed
+	}
+	return m.CreatedAt
+}
+
+// clone copies a memory so callers cannot change the stored one
+func clone(m *entity.Memory) *entity.Memory {
+	copied := *m
+	copied.Tags = append([]string{}, m.Tags...)
+	copied.LastReviewed = copyTime(m.LastReviewed)
+	copied.DeletedAt = copyTime(m.DeletedAt)
+	if m.ParentID != nil {
+		parentID := *m.ParentID
+		copied.ParentID = &parentID
+	}
+	return &copied
+}
+
+// cloneAll copies every memory of the slice
+func cloneAll(memories []*entity.Memory) []*entity.Memory {
+	copies := make([]*entity.Memory, len(memories))
+	for i, m := range memories {
+		copies[i] = clone(m)
+	}
+	return copies
+}
+
+// copyTime copies an optional timestamp
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
+
+// normalizeTags normalizes and de-duplicates tags like the SQLite tag index
+func normalizeTags(raw []string) []string {
+	tags := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range raw {
+		tag = entity.NormalizeTag(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// lowerAll lowercases every string
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, v := range values {
+		lowered[i] = strings.ToLower(v)
+	}
+	return lowered
+}
+
+// countShared counts the terms that occur in the document
+func countShared(terms, document []string) int {
+	present := make(map[string]bool, len(document))
+	for _, word := range document {
+		present[word] = true
+	}
+
+	count := 0
+	for _, term := range terms {
+		if present[term] {
+			count++
+		}
+	}
+	return count
+}
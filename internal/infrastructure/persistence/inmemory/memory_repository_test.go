@@ -0,0 +1,14 @@
+package inmemory
+
+import (
+	"testing"
+
+	"memory-bot/internal/domain/repository"
+	"memory-bot/internal/domain/repository/repositorytest"
+)
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	repositorytest.TestMemoryRepository(t, func(t *testing.T) repository.MemoryRepository {
+		return NewMemoryRepository()
+	})
+}
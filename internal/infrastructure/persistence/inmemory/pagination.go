@@ -0,0 +1,83 @@
+package inmemory
+
+import (
+	"sort"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// keyed is a memory with the sort keys of the listing it appears in
+type keyed struct {
+	memory *entity.Memory
+	keys   []float64
+}
+
+// pageAfter returns the page after the cursor like the SQLite keyset pagination:
+// memories are ordered by their keys and ID, descending unless ascending is set
+func pageAfter(listing string, items []keyed, keyCount int, ascending bool, opts repository.PageOptions) (*repository.MemoryPage, error) {
+	position, err := opts.Cursor.Position(listing, keyCount)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		order := compareKeys(items[i].keys, items[i].memory.ID, items[j].keys, items[j].memory.ID)
+		if ascending {
+			return order < 0
+		}
+		return order > 0
+	})
+
+	memories := []*entity.Memory{}
+	var keys [][]float64
+	for _, item := range items {
+		if position != nil {
+			order := compareKeys(item.keys, item.memory.ID, position.Keys, position.ID)
+			if ascending && order <= 0 || !ascending && order >= 0 {
+				continue
+			}
+		}
+		if opts.Limit >= 0 && len(memories) > opts.Limit {
+			break
+		}
+		memories = append(memories, item.memory)
+		keys = append(keys, item.keys)
+	}
+
+	return repository.NewMemoryPage(listing, memories, opts.Limit, func(i int) []float64 {
+		return keys[i]
+	}), nil
+}
+
+// compareKeys compares two positions by their keys, then by ID
+func compareKeys(a []float64, aID int, b []float64, bID int) int {
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	switch {
+	case aID < bID:
+		return -1
+	case aID > bID:
+		return 1
+	}
+	return 0
+}
+
+// timeKey is the sort key of a timestamp (microseconds are exact in a float64)
+func timeKey(m *entity.Memory) []float64 {
+	return []float64{float64(m.CreatedAt.UnixMicro())}
+}
+
+// limitTo keeps the first limit memories (a negative limit means no limit)
+func limitTo(memories []*entity.Memory, limit int) []*entity.Memory {
+	if limit >= 0 && limit < len(memories) {
+		return memories[:limit]
+	}
+	return memories
+}
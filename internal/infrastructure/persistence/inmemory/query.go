@@ -0,0 +1,168 @@
+package inmemory
+
+import (
+	"strings"
+	"unicode"
+
+	"memory-bot/internal/domain/entity"
+)
+
+// tokenize splits text like the FTS5 unicode61 tokenizer with tokenchars '.':
+// lowercase runs of letters, digits and dots
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	})
+}
+
+// term is one condition of a query
+type term struct {
+	tag    string   // Hashtag condition (matches the tag and its sub-tags)
+	tokens []string // Consecutive tokens of the content
+	prefix bool     // The last token may be a prefix
+}
+
+// query is a parsed search query: any alternative matches if all of its terms match
+type query struct {
+	alternatives [][]term
+}
+
+// parseQuery understands the queries the search strategies send to the repository:
+// plain words (prefix matches, all required), "quoted phrases", word* prefixes,
+// #tags, "a OR b" alternatives and NEAR(a b, N), which is treated as AND.
+// Words inside OR and NEAR expressions match whole tokens, like in FTS5.
+func parseQuery(raw string, exactPhrase bool) query {
+	raw = strings.TrimSpace(raw)
+	if exactPhrase {
+		return query{alternatives: [][]term{{{tokens: tokenize(strings.Trim(raw, `"`))}}}}
+	}
+
+	operators := strings.Contains(raw, " OR ") || strings.Contains(raw, "NEAR(")
+	if strings.HasPrefix(raw, "NEAR(") && strings.HasSuffix(raw, ")") {
+		raw = strings.TrimSuffix(strings.TrimPrefix(raw, "NEAR("), ")")
+		if i := strings.LastIndex(raw, ","); i >= 0 {
+			raw = raw[:i]
+		}
+	}
+
+	var q query
+	for _, alternative := range strings.Split(raw, " OR ") {
+		var terms []term
+		for _, word := range splitWords(alternative) {
+			if t, ok := parseTerm(word, !operators); ok {
+				terms = append(terms, t)
+			}
+		}
+		if len(terms) > 0 {
+			q.alternatives = append(q.alternatives, terms)
+		}
+	}
+	return q
+}
+
+// splitWords splits on spaces but keeps "quoted phrases" together
+func splitWords(text string) []string {
+	var words []string
+	var current strings.Builder
+	quoted := false
+
+	for _, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if current.Len() > 0 {
+				words = append(words, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		words = append(words, current.String())
+	}
+	return words
+}
+
+// parseTerm turns one word of a query into a term
+func parseTerm(word string, prefixByDefault bool) (term, bool) {
+	word = strings.Trim(word, "()")
+	if strings.HasPrefix(word, "#") {
+		tag := entity.NormalizeTag(word)
+		return term{tag: tag}, tag != ""
+	}
+
+	star := strings.HasSuffix(word, "*")
+	word = strings.TrimSuffix(word, "*")
+	quoted := len(word) >= 2 && strings.HasPrefix(word, `"`) && strings.HasSuffix(word, `"`)
+	if quoted {
+		word = word[1 : len(word)-1]
+	}
+	prefix := star || (prefixByDefault && !quoted)
+
+	tokens := tokenize(word)
+	return term{tokens: tokens, prefix: prefix}, len(tokens) > 0
+}
+
+// score returns the number of matched terms of the best matching alternative (0 = no match)
+// Secret content is not indexed, like in the plaintext SQLite index: only tags match.
+func (q query) score(memory *entity.Memory) int {
+	var tokens []string
+	if !memory.Secret {
+		tokens = tokenize(memory.Content)
+	}
+	best := 0
+	for _, terms := range q.alternatives {
+		matched := 0
+		for _, t := range terms {
+			if !t.matches(memory, tokens) {
+				matched = 0
+				break
+			}
+			matched++
+		}
+		if matched > best {
+			best = matched
+		}
+	}
+	return best
+}
+
+// matches reports whether the memory satisfies the term
+func (t term) matches(memory *entity.Memory, tokens []string) bool {
+	if t.tag != "" {
+		return hasTag(memory, t.tag)
+	}
+
+	last := len(t.tokens) - 1
+	for start := 0; start+last < len(tokens); start++ {
+		found := true
+		for i, token := range t.tokens {
+			candidate := tokens[start+i]
+			if i == last && t.prefix {
+				found = strings.HasPrefix(candidate, token)
+			} else {
+				found = candidate == token
+			}
+			if !found {
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTag reports whether the memory carries the tag or one of its sub-tags
+func hasTag(memory *entity.Memory, tag string) bool {
+	for _, t := range memory.Tags {
+		if t == tag || strings.HasPrefix(t, tag+entity.TagSeparator) {
+			return true
+		}
+	}
+	return false
+}
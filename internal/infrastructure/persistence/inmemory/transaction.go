@@ -0,0 +1,72 @@
+package inmemory
+
+import (
+	"context"
+
+	"memory-bot/internal/domain/entity"
+)
+
+// txKey marks a context that already runs inside a unit of work
+type txKey struct{}
+
+// repositoryState is a copy of the repository contents to roll back to
+type repositoryState struct {
+	memories       map[int]*entity.Memory
+	revisions      map[int][]*entity.MemoryRevision
+	nextID         int
+	nextRevisionID int
+}
+
+// WithTx runs fn as one unit of work
+// The contents are copied before fn runs and put back if it fails; a nested WithTx joins
+// the outer one. Writes of concurrent callers are not isolated, so a rollback also undoes
+// what they stored in the meantime.
+func (r *MemoryRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+
+	saved := r.snapshot()
+	defer func() {
+		if p := recover(); p != nil {
+			r.restore(saved)
+			panic(p)
+		}
+		if err != nil {
+			r.restore(saved)
+		}
+	}()
+
+	return fn(context.WithValue(ctx, txKey{}, true))
+}
+
+// snapshot copies the repository contents
+func (r *MemoryRepository) snapshot() *repositoryState {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state := &repositoryState{
+		memories:       make(map[int]*entity.Memory, len(r.memories)),
+		revisions:      make(map[int][]*entity.MemoryRevision, len(r.revisions)),
+		nextID:         r.nextID,
+		nextRevisionID: r.nextRevisionID,
+	}
+	for id, m := range r.memories {
+		state.memories[id] = clone(m)
+	}
+	for id, revisions := range r.revisions {
+		state.revisions[id] = append([]*entity.MemoryRevision{}, revisions...)
+	}
+	return state
+}
+
+// restore puts back a snapshot taken by snapshot
+func (r *MemoryRepository) restore(state *repositoryState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.memories = state.memories
+	r.revisions = state.revisions
+	r.nextID = state.nextID
+	r.nextRevisionID = state.nextRevisionID
+}
@@ -0,0 +1,326 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// snapshotTimeLayout is the timestamp in snapshot file names (memories-20240315-030000.db)
+const snapshotTimeLayout = "20060102-150405"
+
+// ErrSnapshotCorrupt is returned when a snapshot fails the integrity check
+var ErrSnapshotCorrupt = errors.New("backup snapshot failed the integrity check")
+
+// ErrDatabaseInUse is returned when a restore would replace a database that is still open
+var ErrDatabaseInUse = errors.New("database is in use; stop the bot before restoring")
+
+// BackupRepository writes verified snapshots of the live database into a directory
+// Snapshots are taken with VACUUM INTO, which is consistent while the bot keeps running
+// (unlike copying the file in WAL mode), and are named <database>-<time>.db
+type BackupRepository struct {
+	conn *Connection
+	dir  string
+}
+
+// NewBackupRepository creates a backup repository storing snapshots in dir
+func NewBackupRepository(conn *Connection, dir string) *BackupRepository {
+	return &BackupRepository{
+		conn: conn,
+		dir:  dir,
+	}
+}
+
+// CreateSnapshot writes a snapshot and keeps it only if it passes the integrity check
+func (r *BackupRepository) CreateSnapshot(ctx context.Context) (*entity.BackupSnapshot, error) {
+	if isInMemoryPath(r.conn.path) {
+		return nil, fmt.Errorf("in-memory databases cannot be backed up")
+	}
+	if err := os.MkdirAll(r.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	createdAt := time.Now()
+	path := r.snapshotPath(createdAt)
+	for n := 2; fileExists(path); n++ {
+		path = strings.TrimSuffix(r.snapshotPath(createdAt), ".db") + fmt.Sprintf("-%d.db", n)
+	}
+
+	// Write under a temporary name so a failed run never leaves a snapshot behind
+	tmpPath := path + ".tmp"
+	os.Remove(tmpPath)
+	if err := r.conn.vacuumInto(ctx, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+	if err := VerifySnapshot(ctx, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+	if err := os.Chmod(tmpPath, 0o600); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to protect snapshot: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to store snapshot: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	return &entity.BackupSnapshot{Path: path, Size: info.Size(), CreatedAt: createdAt}, nil
+}
+
+// ListSnapshots returns the snapshots of this database, newest first
+func (r *BackupRepository) ListSnapshots(ctx context.Context) ([]*entity.BackupSnapshot, error) {
+	entries, err := os.ReadDir(r.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*entity.BackupSnapshot{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	prefix := r.snapshotPrefix()
+	snapshots := []*entity.BackupSnapshot{}
+	sequence := make(map[string]int) // Snapshots of the same second get -2, -3, ...
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".db") {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".db")
+		if len(stamp) < len(snapshotTimeLayout) {
+			continue
+		}
+		createdAt, err := time.ParseInLocation(snapshotTimeLayout, stamp[:len(snapshotTimeLayout)], time.Local)
+		if err != nil {
+			continue
+		}
+		n := 1
+		if suffix := stamp[len(snapshotTimeLayout):]; suffix != "" {
+			if n, err = strconv.Atoi(strings.TrimPrefix(suffix, "-")); err != nil || !strings.HasPrefix(suffix, "-") {
+				continue
+			}
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot %s: %w", name, err)
+		}
+		snapshot := &entity.BackupSnapshot{
+			Path:      filepath.Join(r.dir, name),
+			Size:      info.Size(),
+			CreatedAt: createdAt,
+		}
+		sequence[snapshot.Path] = n
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if !snapshots[i].CreatedAt.Equal(snapshots[j].CreatedAt) {
+			return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+		}
+		return sequence[snapshots[i].Path] > sequence[snapshots[j].Path]
+	})
+	return snapshots, nil
+}
+
+// DeleteSnapshot removes a snapshot file
+func (r *BackupRepository) DeleteSnapshot(ctx context.Context, snapshot *entity.BackupSnapshot) error {
+	if err := os.Remove(snapshot.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+// snapshotPrefix is the file name prefix of this database's snapshots (memories-)
+func (r *BackupRepository) snapshotPrefix() string {
+	return strings.TrimSuffix(filepath.Base(databaseFile(r.conn.path)), filepath.Ext(databaseFile(r.conn.path))) + "-"
+}
+
+// snapshotPath returns the file name of a snapshot taken at the given time
+func (r *BackupRepository) snapshotPath(createdAt time.Time) string {
+	return filepath.Join(r.dir, r.snapshotPrefix()+createdAt.Format(snapshotTimeLayout)+".db")
+}
+
+// VerifySnapshot checks that a file is an intact memory database this version can open
+func VerifySnapshot(ctx context.Context, path string) error {
+	if !fileExists(path) {
+		return fmt.Errorf("snapshot not found: %s", path)
+	}
+
+	// Not read-only: the check of the FTS5 index needs a writable connection (it changes nothing)
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSnapshotCorrupt, err)
+	}
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read integrity check: %w", err)
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrSnapshotCorrupt, err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrSnapshotCorrupt, problems[0])
+	}
+
+	snapshot := &Connection{DB: db, path: path}
+	hasMemories, err := snapshot.tableExists("memories")
+	if err != nil {
+		return err
+	}
+	if !hasMemories {
+		return fmt.Errorf("%w: no memories table", ErrSnapshotCorrupt)
+	}
+
+	migrator, err := NewMigrator(snapshot)
+	if err != nil {
+		return err
+	}
+	if _, err := migrator.Status(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RestoreSnapshot verifies a snapshot and swaps it in as the database at dbPath
+// The current database (with its WAL files) is kept as <database>.pre-restore-<time>.bak,
+// whose path is returned ("" if there was no database). The bot must be stopped.
+func RestoreSnapshot(ctx context.Context, snapshotPath, dbPath string) (string, error) {
+	if isInMemoryPath(dbPath) {
+		return "", fmt.Errorf("cannot restore into an in-memory database")
+	}
+	if err := VerifySnapshot(ctx, snapshotPath); err != nil {
+		return "", err
+	}
+
+	dbFile := databaseFile(dbPath)
+	tmpPath := dbFile + ".restore-tmp"
+	if err := copyFile(snapshotPath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+
+	asidePath := ""
+	if fileExists(dbFile) {
+		if err := checkpointIdle(ctx, dbPath); err != nil {
+			os.Remove(tmpPath)
+			return "", err
+		}
+
+		asidePath = fmt.Sprintf("%s.pre-restore-%s.bak", dbFile, time.Now().Format(snapshotTimeLayout))
+		if err := os.Rename(dbFile, asidePath); err != nil {
+			os.Remove(tmpPath)
+			return "", fmt.Errorf("failed to move the current database aside: %w", err)
+		}
+		// A leftover WAL would otherwise be replayed onto the restored database
+		for _, suffix := range []string{"-wal", "-shm"} {
+			if fileExists(dbFile + suffix) {
+				if err := os.Rename(dbFile+suffix, asidePath+suffix); err != nil {
+					return asidePath, fmt.Errorf("failed to move %s aside: %w", dbFile+suffix, err)
+				}
+			}
+		}
+	}
+
+	if err := os.Rename(tmpPath, dbFile); err != nil {
+		return asidePath, fmt.Errorf("failed to put the snapshot in place: %w", err)
+	}
+	return asidePath, nil
+}
+
+// checkpointIdle folds the WAL into the database file and fails if another connection uses it
+// Exclusive locking mode cannot be entered while any other connection has the database open
+func checkpointIdle(ctx context.Context, dbPath string) error {
+	db, err := sql.Open("sqlite3", "file:"+databaseFile(dbPath)+"?_locking=EXCLUSIVE&_busy_timeout=0")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if isBusy(err) {
+		return ErrDatabaseInUse
+	}
+	if err != nil {
+		// A damaged database cannot be opened; it is moved aside as it is
+		return nil
+	}
+	defer conn.Close()
+
+	var busy, logFrames, checkpointed int
+	err = conn.QueryRowContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)").Scan(&busy, &logFrames, &checkpointed)
+	if isBusy(err) || (err == nil && busy != 0) {
+		return ErrDatabaseInUse
+	}
+	return nil
+}
+
+// isBusy reports whether an error means that another connection holds a lock
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked)
+}
+
+// copyFile copies a file and flushes it to disk
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(to, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", to, err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return fmt.Errorf("failed to copy snapshot: %w", err)
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		return fmt.Errorf("failed to flush snapshot: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("failed to copy snapshot: %w", err)
+	}
+	return nil
+}
+
+// fileExists reports whether a file exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
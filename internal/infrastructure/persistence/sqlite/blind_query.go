@@ -0,0 +1,157 @@
+package sqlite
+
+import (
+	"strings"
+	"unicode"
+
+	"memory-bot/pkg/encryption"
+)
+
+// rewriteBlindQuery rewrites a prepared FTS5 expression so it matches blind index tokens
+// Operators (AND, OR, NOT, NEAR), grouping, column filters and NEAR distances are kept;
+// words become word tokens, "word*" becomes a prefix token and multi-word phrases
+// become AND groups (blind tokens carry no positional adjacency). Hashtags arrive as
+// "tags : ..." column filters; terms filtered to the tags column stay plaintext unless
+// blindTags is set (tags are encrypted too).
+func rewriteBlindQuery(blindIndex *encryption.BlindIndex, expr string, blindTags bool) string {
+	var out strings.Builder
+	runes := []rune(expr)
+	afterComma := false
+	plainNext := false // the next term targets the plaintext tags column
+
+	for i := 0; i < len(runes); {
+		ch := runes[i]
+
+		switch {
+		case unicode.IsSpace(ch):
+			out.WriteRune(ch)
+			i++
+
+		case ch == '(' || ch == ')' || ch == ':' || ch == '+' || ch == '^':
+			out.WriteRune(ch)
+			afterComma = false
+			i++
+
+		case ch == ',':
+			out.WriteRune(ch)
+			afterComma = true
+			i++
+
+		case ch == '"':
+			// Quoted phrase ("" escapes a quote), optionally followed by *
+			j := i + 1
+			var phrase strings.Builder
+			for j < len(runes) {
+				if runes[j] == '"' {
+					if j+1 < len(runes) && runes[j+1] == '"' {
+						phrase.WriteRune('"')
+						j += 2
+						continue
+					}
+					break
+				}
+				phrase.WriteRune(runes[j])
+				j++
+			}
+			j++ // closing quote
+
+			prefix := j < len(runes) && runes[j] == '*'
+			if prefix {
+				j++
+			}
+
+			if plainNext {
+				out.WriteString(string(runes[i:j]))
+			} else {
+				out.WriteString(blindPhrase(blindIndex, blindIndex.Words(phrase.String()), prefix))
+			}
+			afterComma = false
+			plainNext = false
+			i = j
+
+		default:
+			// Bareword up to the next delimiter
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune(`()",:`, runes[j]) {
+				j++
+			}
+			word := string(runes[i:j])
+			i = j
+
+			// Column filter ("tags : ...") - the column name stays as-is
+			if strings.HasPrefix(strings.TrimLeftFunc(string(runes[i:]), unicode.IsSpace), ":") {
+				out.WriteString(word)
+				plainNext = word == "tags" && !blindTags
+				continue
+			}
+
+			if plainNext {
+				out.WriteString(word)
+			} else {
+				out.WriteString(blindBareword(blindIndex, word, afterComma))
+			}
+			afterComma = false
+			plainNext = false
+		}
+	}
+
+	return out.String()
+}
+
+// blindBareword rewrites a single bareword, keeping operators and NEAR distances
+func blindBareword(blindIndex *encryption.BlindIndex, word string, afterComma bool) string {
+	switch word {
+	case "AND", "OR", "NOT", "NEAR":
+		return word
+	}
+
+	// NEAR(..., 10) distance
+	if afterComma && isNumber(word) {
+		return word
+	}
+
+	prefix := strings.HasSuffix(word, "*")
+	return blindPhrase(blindIndex, blindIndex.Words(strings.TrimSuffix(word, "*")), prefix)
+}
+
+// blindPhrase turns the words of a phrase into tokens; the last word becomes a prefix token if requested
+func blindPhrase(blindIndex *encryption.BlindIndex, words []string, prefix bool) string {
+	if len(words) == 0 {
+		// Nothing searchable - use a token that can never match
+		return blindIndex.Token("")
+	}
+
+	tokens := make([]string, len(words))
+	for i, word := range words {
+		var variants []string
+		if prefix && i == len(words)-1 {
+			variants = blindIndex.PrefixTokens(word)
+		} else {
+			variants = blindIndex.Tokens(word)
+		}
+
+		// During key rotation a word matches its token under any key
+		tokens[i] = variants[0]
+		if len(variants) > 1 {
+			tokens[i] = "(" + strings.Join(variants, " OR ") + ")"
+		}
+	}
+
+	if len(tokens) == 1 {
+		return tokens[0]
+	}
+	return "(" + strings.Join(tokens, " AND ") + ")"
+}
+
+// isNumber reports whether s consists of ASCII digits only
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -1,149 +1,200 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"runtime"
+	"strings"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// InMemoryPath opens an in-memory database shared by all connections of the pool
+// It lives as long as the process keeps a connection open and is never backed up
+const InMemoryPath = "file::memory:?cache=shared"
+
+const (
+	// busyTimeout is how long a connection waits for a lock held by another connection
+	busyTimeout = 5 * time.Second
+
+	// cacheSizeKiB is the page cache of each connection (a negative cache_size is in KiB)
+	cacheSizeKiB = 16 * 1024
+)
+
 // Connection manages SQLite database connection
+// Writes go through DB, a pool with a single connection, so writers queue in the
+// process instead of failing with "database is locked". Reads use ReadDB, a pool of
+// read-only connections that run next to the writer in WAL mode.
 type Connection struct {
-	DB *sql.DB
+	DB     *sql.DB
+	ReadDB *sql.DB
+	path   string
+
+	writeStmts *statementCache
+	readStmts  *statementCache
 }
 
-// NewConnection creates a new SQLite connection with optimizations
+// NewConnection opens the database and applies pending schema migrations
+// It fails with ErrSchemaTooNew if the database was migrated by a newer version
 func NewConnection(dbPath string) (*Connection, error) {
-	conn, err := sql.Open("sqlite3", dbPath)
+	connection, err := OpenConnection(dbPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
-	// Enable foreign keys and WAL mode for better performance
-	if _, err := conn.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
+	migrator, err := NewMigrator(connection)
+	if err != nil {
+		connection.Close()
+		return nil, err
+	}
+	if _, err := migrator.Migrate(context.Background()); err != nil {
+		connection.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
 	}
 
-	if _, err := conn.Exec("PRAGMA journal_mode = WAL"); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+	log.Println("SQLite database initialized successfully")
+	return connection, nil
+}
+
+// OpenConnection opens the database with optimizations but leaves the schema untouched
+// The pragmas are part of the DSN so that every connection of the pools gets them.
+func OpenConnection(dbPath string) (*Connection, error) {
+	pragmas := fmt.Sprintf("_foreign_keys=on&_busy_timeout=%d&_synchronous=NORMAL&_cache_size=-%d",
+		busyTimeout.Milliseconds(), cacheSizeKiB)
+
+	// BEGIN IMMEDIATE takes the write lock up front, where busy_timeout applies,
+	// instead of failing when a deferred transaction upgrades to a writer
+	writer, err := sql.Open("sqlite3", withParams(dbPath, pragmas+"&_journal_mode=WAL&_txlock=immediate"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := writer.Ping(); err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	connection := &Connection{DB: conn}
+	// A shared in-memory database has no WAL and reports table locks instead of waiting,
+	// so it keeps one unrestricted pool for reads and writes
+	if isInMemoryPath(dbPath) {
+		stmts := newStatementCache(writer)
+		return &Connection{DB: writer, ReadDB: writer, path: dbPath, writeStmts: stmts, readStmts: stmts}, nil
+	}
+	writer.SetMaxOpenConns(1)
 
-	if err := connection.initSchema(); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	reader, err := sql.Open("sqlite3", withParams(dbPath, pragmas+"&_query_only=on"))
+	if err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	readers := runtime.NumCPU()
+	if readers < 2 {
+		readers = 2
 	}
+	reader.SetMaxOpenConns(readers)
+	reader.SetMaxIdleConns(readers)
+
+	return &Connection{
+		DB:         writer,
+		ReadDB:     reader,
+		path:       dbPath,
+		writeStmts: newStatementCache(writer),
+		readStmts:  newStatementCache(reader),
+	}, nil
+}
 
-	log.Println("SQLite database initialized successfully")
-	return connection, nil
+// withParams appends DSN parameters to a database path that may already have some
+func withParams(dbPath, params string) string {
+	if strings.Contains(dbPath, "?") {
+		return dbPath + "&" + params
+	}
+	return dbPath + "?" + params
 }
 
-// initSchema creates the necessary tables and indexes
-func (c *Connection) initSchema() error {
-	// Main memories table with biological fields
-	createTableSQL := `
-	CREATE TABLE IF NOT EXISTS memories (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		user_id INTEGER NOT NULL,
-		chat_id INTEGER NOT NULL,
-		text_content TEXT NOT NULL,
-		search_content TEXT,
-		tags TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		last_reviewed DATETIME,
-		review_count INTEGER DEFAULT 0,
-		last_consolidated DATETIME DEFAULT CURRENT_TIMESTAMP,
-		priority_score REAL DEFAULT 0.0,
-		emotional_weight REAL DEFAULT 0.0,
-		time_of_day TEXT DEFAULT '',
-		day_of_week TEXT DEFAULT '',
-		chat_source TEXT DEFAULT 'Telegram',
-		parent_id INTEGER,
-		FOREIGN KEY(parent_id) REFERENCES memories(id) ON DELETE SET NULL
-	);`
-
-	if _, err := c.DB.Exec(createTableSQL); err != nil {
-		return fmt.Errorf("failed to create memories table: %w", err)
-	}
-
-	// Create composite index for fast user-based queries
-	createIndexSQL := `
-	CREATE INDEX IF NOT EXISTS idx_user_time 
-	ON memories (user_id, created_at DESC);`
-
-	if _, err := c.DB.Exec(createIndexSQL); err != nil {
-		return fmt.Errorf("failed to create index: %w", err)
-	}
-
-	// Create biological memory system indexes
-	biologicalIndexes := []string{
-		`CREATE INDEX IF NOT EXISTS idx_memories_time_of_day ON memories(time_of_day);`,
-		`CREATE INDEX IF NOT EXISTS idx_memories_day_of_week ON memories(day_of_week);`,
-		`CREATE INDEX IF NOT EXISTS idx_memories_emotional_weight ON memories(emotional_weight DESC);`,
-		`CREATE INDEX IF NOT EXISTS idx_memories_priority_score ON memories(priority_score DESC);`,
-	}
-
-	for _, indexSQL := range biologicalIndexes {
-		if _, err := c.DB.Exec(indexSQL); err != nil {
-			return fmt.Errorf("failed to create biological index: %w", err)
-		}
-	}
-
-	// Create FTS5 virtual table for full-text search
-	// Uses search_content which contains plain text (not encrypted)
-	createFTSSQL := `
-	CREATE VIRTUAL TABLE IF NOT EXISTS memories_fts USING fts5(
-		text_content,
-		tags,
-		content='memories',
-		content_rowid='id',
-		tokenize="unicode61 tokenchars '.'"
-	);`
-
-	if _, err := c.DB.Exec(createFTSSQL); err != nil {
-		return fmt.Errorf("failed to create FTS5 table: %w", err)
-	}
-
-	// Create triggers to keep FTS5 table in sync
-	if err := c.createTriggers(); err != nil {
-		return err
+// Backup writes a consistent copy of the database next to it and returns its path
+// The label becomes part of the file name; in-memory databases are not backed up ("" is returned)
+func (c *Connection) Backup(ctx context.Context, label string) (string, error) {
+	if isInMemoryPath(c.path) {
+		return "", nil
+	}
+
+	backupPath := fmt.Sprintf("%s.%s-%s.bak", databaseFile(c.path), label, time.Now().Format("20060102-150405"))
+	if err := c.vacuumInto(ctx, backupPath); err != nil {
+		return "", err
 	}
+	return backupPath, nil
+}
 
+// vacuumInto writes a consistent, compacted copy of the database to a new file
+// It is safe while other connections read and write (WAL mode)
+func (c *Connection) vacuumInto(ctx context.Context, path string) error {
+	if _, err := c.DB.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
 	return nil
 }
 
-// createTriggers creates database triggers for FTS5 synchronization
-func (c *Connection) createTriggers() error {
-	triggers := []string{
-		`CREATE TRIGGER IF NOT EXISTS memories_ai AFTER INSERT ON memories BEGIN
-			INSERT INTO memories_fts(rowid, text_content, tags)
-			VALUES (new.id, COALESCE(new.search_content, new.text_content), new.tags);
-		END;`,
-		`CREATE TRIGGER IF NOT EXISTS memories_au AFTER UPDATE ON memories BEGIN
-			UPDATE memories_fts 
-			SET text_content = COALESCE(new.search_content, new.text_content), tags = new.tags
-			WHERE rowid = new.id;
-		END;`,
-		`CREATE TRIGGER IF NOT EXISTS memories_ad AFTER DELETE ON memories BEGIN
-			DELETE FROM memories_fts WHERE rowid = old.id;
-		END;`,
-	}
-
-	for _, trigger := range triggers {
-		if _, err := c.DB.Exec(trigger); err != nil {
-			return fmt.Errorf("failed to create trigger: %w", err)
-		}
+// isInMemoryPath reports whether the path opens an in-memory database
+func isInMemoryPath(dbPath string) bool {
+	return dbPath == "" || dbPath == ":memory:" || strings.HasPrefix(dbPath, "file::memory:")
+}
+
+// databaseFile returns the file name of a database path without the URI prefix and options
+func databaseFile(dbPath string) string {
+	file, _, _ := strings.Cut(strings.TrimPrefix(dbPath, "file:"), "?")
+	return file
+}
+
+// tableExists reports whether the database has a table with the given name
+func (c *Connection) tableExists(table string) (bool, error) {
+	var count int
+	err := c.DB.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect schema: %w", err)
+	}
+	return count > 0, nil
+}
+
+// ensureColumn adds a column to an existing table if it is missing
+func (c *Connection) ensureColumn(table, column, definition string) error {
+	exists, err := c.columnExists(table, column)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
 	}
 
+	if _, err := c.DB.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
 	return nil
 }
 
-// Close closes the database connection
+// columnExists reports whether a table has the given column
+func (c *Connection) columnExists(table, column string) (bool, error) {
+	var count int
+	err := c.DB.QueryRow(
+		"SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// Close closes the cached statements and both connection pools
 func (c *Connection) Close() error {
+	c.readStmts.close()
+	c.writeStmts.close()
+
+	if c.ReadDB != nil && c.ReadDB != c.DB {
+		c.ReadDB.Close()
+	}
 	if c.DB != nil {
 		return c.DB.Close()
 	}
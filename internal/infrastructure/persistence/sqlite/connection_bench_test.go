@@ -0,0 +1,138 @@
+//go:build fts5
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// benchmarkSeedMemories is the number of memories the benchmarks read from
+const benchmarkSeedMemories = 1000
+
+// BenchmarkFindByID compares repeated point reads with and without the statement cache
+func BenchmarkFindByID(b *testing.B) {
+	for _, tuned := range []bool{true, false} {
+		b.Run(connectionName(tuned), func(b *testing.B) {
+			repo := seedBenchmarkRepository(b, openBenchmarkConnection(b, tuned))
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.FindByID(ctx, i%benchmarkSeedMemories+1); err != nil {
+					b.Fatalf("FindByID: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkConcurrentAccess mixes one write with four reads per operation from parallel
+// goroutines, like the bot, the schedulers and the jobs do. Operations that fail with
+// "database is locked" are reported as failed/op and left out of completed/s.
+func BenchmarkConcurrentAccess(b *testing.B) {
+	for _, tuned := range []bool{true, false} {
+		b.Run(connectionName(tuned), func(b *testing.B) {
+			repo := seedBenchmarkRepository(b, openBenchmarkConnection(b, tuned))
+			ctx := context.Background()
+			var failed, seq atomic.Int64
+
+			b.SetParallelism(4)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					n := int(seq.Add(1))
+					if err := concurrentOperation(ctx, repo, n); err != nil {
+						if !isBusy(err) {
+							b.Errorf("operation %d: %v", n, err)
+							return
+						}
+						failed.Add(1)
+					}
+				}
+			})
+			b.ReportMetric(float64(failed.Load())/float64(b.N), "failed/op")
+			b.ReportMetric(float64(int64(b.N)-failed.Load())/b.Elapsed().Seconds(), "completed/s")
+		})
+	}
+}
+
+// concurrentOperation saves one memory and reads four times
+func concurrentOperation(ctx context.Context, repo *MemoryRepository, n int) error {
+	userID := int64(n%10 + 1)
+	memory := entity.NewMemory(userID, userID, fmt.Sprintf("benchmark note %d about the deploy", n))
+	if _, err := repo.Save(ctx, memory); err != nil {
+		return err
+	}
+
+	opts := repository.SearchOptions{Limit: 10}
+	for i := 0; i < 2; i++ {
+		if _, err := repo.FindByID(ctx, (n+i)%benchmarkSeedMemories+1); err != nil {
+			return err
+		}
+		if _, err := repo.Search(ctx, userID, "budget", opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openBenchmarkConnection opens a migrated database, either tuned or configured
+// like a plain database/sql pool without busy timeout and statement cache
+func openBenchmarkConnection(b *testing.B, tuned bool) *Connection {
+	b.Helper()
+	path := filepath.Join(b.TempDir(), "bench.db")
+
+	conn, err := NewConnection(path)
+	if err != nil {
+		b.Fatalf("NewConnection: %v", err)
+	}
+	if !tuned {
+		conn.Close()
+
+		db, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_journal_mode=WAL")
+		if err != nil {
+			b.Fatalf("sql.Open: %v", err)
+		}
+		conn = &Connection{DB: db, ReadDB: db, path: path}
+	}
+	b.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// seedBenchmarkRepository stores the memories the benchmarks read
+func seedBenchmarkRepository(b *testing.B, conn *Connection) *MemoryRepository {
+	b.Helper()
+	repo := NewMemoryRepository(conn, nil, nil)
+	ctx := context.Background()
+
+	err := conn.WithTx(ctx, func(ctx context.Context) error {
+		for i := 0; i < benchmarkSeedMemories; i++ {
+			userID := int64(i%10 + 1)
+			memory := entity.NewMemory(userID, userID, fmt.Sprintf("seed note %d about the deploy and the budget", i))
+			if _, err := repo.Save(ctx, memory); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Fatalf("failed to seed memories: %v", err)
+	}
+	return repo
+}
+
+// connectionName names the benchmark variant
+func connectionName(tuned bool) string {
+	if tuned {
+		return "tuned"
+	}
+	return "untuned"
+}
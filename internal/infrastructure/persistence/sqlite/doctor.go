@@ -0,0 +1,629 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/encryption"
+)
+
+// DoctorRepository is the SQLite implementation of repository.DoctorRepository
+type DoctorRepository struct {
+	conn      *Connection
+	encryptor *encryption.Encryptor
+	fields    *fieldCipher
+}
+
+// NewDoctorRepository creates a new doctor; encryptor and policy are the ones the bot runs with
+func NewDoctorRepository(conn *Connection, encryptor *encryption.Encryptor, policy FieldPolicy) *DoctorRepository {
+	return &DoctorRepository{
+		conn:      conn,
+		encryptor: encryptor,
+		fields:    newFieldCipher(encryptor, policy),
+	}
+}
+
+// doctorCheck is one check with its optional repair
+type doctorCheck struct {
+	name   string
+	writer bool   // The check statement needs the write connection (FTS5 commands are INSERTs)
+	repair string // What fix does; empty without a repair
+	find   func(ctx context.Context, q dbtx) ([]entity.DoctorFinding, string, error)
+	fix    func(ctx context.Context, q dbtx, findings []entity.DoctorFinding) (int, error)
+}
+
+// checks lists the checks in the order they run
+func (r *DoctorRepository) checks() []doctorCheck {
+	return []doctorCheck{
+		{name: entity.CheckIntegrity, writer: true, find: r.findIntegrityErrors},
+		{name: entity.CheckFTS, writer: true, find: r.findFTSErrors,
+			repair: "rebuild the search index", fix: r.rebuildSearchIndex},
+		{name: entity.CheckFTSOrphans, find: r.findFTSOrphans,
+			repair: "rebuild the search index", fix: r.rebuildSearchIndex},
+		{name: entity.CheckFTSMissing, find: r.findFTSMissing,
+			repair: "index the missing memories", fix: r.indexMissingMemories},
+		{name: entity.CheckParents, find: r.findBrokenParents,
+			repair: "make the memories root memories", fix: r.detachParents},
+		{name: entity.CheckCiphertext, find: r.findUndecryptable,
+			repair: "move memories to the trash, delete revisions, history entries and saved searches", fix: r.removeUndecryptable},
+		{name: entity.CheckTags, find: r.findTagMismatches,
+			repair: "rebuild the tag index from the stored tags", fix: r.reindexTags},
+	}
+}
+
+// Diagnose runs every check without changing anything
+func (r *DoctorRepository) Diagnose(ctx context.Context) (*entity.DoctorReport, error) {
+	report := &entity.DoctorReport{CheckedAt: time.Now()}
+
+	for _, check := range r.checks() {
+		var q dbtx = r.conn.reader(ctx)
+		if check.writer {
+			q = r.conn.DB
+		}
+
+		findings, skipped, err := check.find(ctx, q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run %s check: %w", check.name, err)
+		}
+		report.Checks = append(report.Checks, &entity.DoctorCheck{
+			Name:     check.name,
+			Findings: findings,
+			Repair:   check.repair,
+			Skipped:  skipped,
+		})
+	}
+
+	return report, nil
+}
+
+// Repair finds the problems of one check again and repairs them in one transaction
+func (r *DoctorRepository) Repair(ctx context.Context, name string) (int, error) {
+	var check *doctorCheck
+	for _, c := range r.checks() {
+		if c.name == name {
+			check = &c
+			break
+		}
+	}
+	if check == nil {
+		return 0, entity.ErrUnknownCheck
+	}
+	if check.fix == nil {
+		return 0, nil
+	}
+
+	tx, err := r.conn.beginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	findings, _, err := check.find(ctx, tx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run %s check: %w", name, err)
+	}
+	if len(findings) == 0 {
+		return 0, nil
+	}
+
+	repaired, err := check.fix(ctx, tx, findings)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Doctor repaired %s: %d of %d finding(s)", name, repaired, len(findings))
+	return repaired, nil
+}
+
+// findIntegrityErrors runs SQLite's own check of pages, indexes and constraints
+// There is no automatic repair: a damaged file is restored from a backup
+func (r *DoctorRepository) findIntegrityErrors(ctx context.Context, q dbtx) ([]entity.DoctorFinding, string, error) {
+	rows, err := q.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return []entity.DoctorFinding{{Detail: err.Error()}}, "", nil
+	}
+	defer rows.Close()
+
+	var findings []entity.DoctorFinding
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return nil, "", fmt.Errorf("failed to scan row: %w", err)
+		}
+		if result != "ok" {
+			findings = append(findings, entity.DoctorFinding{Detail: result})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		findings = append(findings, entity.DoctorFinding{Detail: err.Error()})
+	}
+	return findings, "", nil
+}
+
+// findFTSErrors runs the FTS5 integrity check of the index structure
+// The index is compared with itself only: it holds blind tokens, not the stored ciphertext
+func (r *DoctorRepository) findFTSErrors(ctx context.Context, q dbtx) ([]entity.DoctorFinding, string, error) {
+	if _, err := q.ExecContext(ctx, "INSERT INTO memories_fts(memories_fts) VALUES('integrity-check')"); err != nil {
+		return []entity.DoctorFinding{{Table: "memories_fts", Detail: err.Error()}}, "", nil
+	}
+	return nil, "", nil
+}
+
+// findFTSOrphans finds index entries whose memory was deleted or is in the trash
+// memories_fts_docsize holds one row per indexed memory
+func (r *DoctorRepository) findFTSOrphans(ctx context.Context, q dbtx) ([]entity.DoctorFinding, string, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT d.id, m.id IS NULL
+		FROM memories_fts_docsize d
+		LEFT JOIN memories m ON m.id = d.id
+		WHERE m.id IS NULL OR m.deleted_at IS NOT NULL
+		ORDER BY d.id
+	`)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find orphaned index entries: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []entity.DoctorFinding
+	for rows.Next() {
+		var id int
+		var missing bool
+		if err := rows.Scan(&id, &missing); err != nil {
+			return nil, "", fmt.Errorf("failed to scan row: %w", err)
+		}
+		detail := "indexed, but the memory is in the trash"
+		if missing {
+			detail = "indexed, but the memory does not exist"
+		}
+		findings = append(findings, entity.DoctorFinding{Table: "memories_fts", RowID: id, Detail: detail})
+	}
+	return findings, "", rows.Err()
+}
+
+// findFTSMissing finds live memories that search cannot find
+func (r *DoctorRepository) findFTSMissing(ctx context.Context, q dbtx) ([]entity.DoctorFinding, string, error) {
+	ids, err := queryIDs(ctx, q, `
+		SELECT id FROM memories
+		WHERE deleted_at IS NULL AND id NOT IN (SELECT id FROM memories_fts_docsize)
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find unindexed memories: %w", err)
+	}
+
+	findings := make([]entity.DoctorFinding, len(ids))
+	for i, id := range ids {
+		findings[i] = entity.DoctorFinding{Table: "memories", RowID: id, Detail: "missing from the search index"}
+	}
+	return findings, "", nil
+}
+
+// findBrokenParents finds sub-memories whose parent is missing, itself or another user's memory
+func (r *DoctorRepository) findBrokenParents(ctx context.Context, q dbtx) ([]entity.DoctorFinding, string, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT c.id, c.parent_id, p.id IS NULL
+		FROM memories c
+		LEFT JOIN memories p ON p.id = c.parent_id
+		WHERE c.parent_id IS NOT NULL AND (p.id IS NULL OR p.id = c.id OR p.user_id != c.user_id)
+		ORDER BY c.id
+	`)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find broken parents: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []entity.DoctorFinding
+	for rows.Next() {
+		var id, parentID int
+		var missing bool
+		if err := rows.Scan(&id, &parentID, &missing); err != nil {
+			return nil, "", fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		detail := fmt.Sprintf("parent #%d belongs to another user", parentID)
+		switch {
+		case missing:
+			detail = fmt.Sprintf("parent #%d does not exist", parentID)
+		case parentID == id:
+			detail = "is its own parent"
+		}
+		findings = append(findings, entity.DoctorFinding{Table: "memories", RowID: id, Detail: detail})
+	}
+	return findings, "", rows.Err()
+}
+
+// findUndecryptable finds encrypted values no configured key can decrypt
+// Memories in the trash are left out; they are purged with the trash.
+func (r *DoctorRepository) findUndecryptable(ctx context.Context, q dbtx) ([]entity.DoctorFinding, string, error) {
+	if r.encryptor == nil {
+		return nil, "encryption is disabled", nil
+	}
+
+	// Every encrypted column, including the fields of the field encryption policy
+	// The queries select the row ID, the owner, the memory the values are bound to and
+	// whether it is vaulted (content and tags encrypted with a vault key, which can't be checked).
+	tables := []struct {
+		table   string
+		columns []string
+		query   string
+	}{
+		{"memories", []string{"text_content", "tags", "time_of_day", "day_of_week", "chat_source", "sealed_weight"},
+			"SELECT id, user_id, id, vaulted, %s FROM memories WHERE deleted_at IS NULL ORDER BY id"},
+		{"memory_revisions", []string{"rv.text_content", "rv.tags", "rv.sealed_weight"},
+			"SELECT rv.id, m.user_id, rv.memory_id, m.vaulted, %s FROM memory_revisions AS rv JOIN memories AS m ON m.id = rv.memory_id ORDER BY rv.id"},
+		{"query_history", []string{"query_text"},
+			"SELECT id, user_id, 0, 0, %s FROM query_history ORDER BY id"},
+		{"saved_searches", []string{"name", "query"},
+			"SELECT id, user_id, 0, 0, %s FROM saved_searches ORDER BY id"},
+	}
+
+	var findings []entity.DoctorFinding
+	for _, table := range tables {
+		rows, err := q.QueryContext(ctx, fmt.Sprintf(table.query, strings.Join(table.columns, ", ")))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read %s: %w", table.table, err)
+		}
+
+		var id, memoryID int
+		var userID int64
+		var vaulted bool
+		values := make([]sql.NullString, len(table.columns))
+		dest := []interface{}{&id, &userID, &memoryID, &vaulted}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		for rows.Next() {
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return nil, "", fmt.Errorf("failed to scan row: %w", err)
+			}
+			for i, value := range values {
+				column := strings.TrimPrefix(table.columns[i], "rv.")
+				if vaulted && (column == "text_content" || column == "tags") {
+					continue
+				}
+				if err := r.openColumn(table.table, column, value.String, userID, memoryID); err != nil {
+					findings = append(findings, entity.DoctorFinding{
+						Table:  table.table,
+						RowID:  id,
+						Detail: fmt.Sprintf("%s does not decrypt: %v", column, err),
+					})
+					break
+				}
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, "", fmt.Errorf("error iterating rows: %w", err)
+		}
+	}
+
+	return findings, "", nil
+}
+
+// openColumn decrypts a stored value of table.column
+// Memory fields may be stored in plaintext by the field encryption policy.
+func (r *DoctorRepository) openColumn(table, column, value string, userID int64, memoryID int) error {
+	aad := columnBinding(table, column, userID, memoryID)
+	if encryption.LooksLikeEnvelope(value) {
+		// Listings read an envelope of a key that is not configured as plaintext
+		_, err := r.encryptor.Decrypt(value, aad)
+		return err
+	}
+
+	var err error
+	switch column {
+	case "text_content", "query_text", "name", "query":
+		_, err = encryption.DecryptIfEnabled(r.encryptor, value, aad)
+	default:
+		_, err = r.fields.open(value, aad)
+	}
+	return err
+}
+
+// columnBinding returns the associated data a stored value of table.column is encrypted with
+func columnBinding(table, column string, userID int64, memoryID int) string {
+	switch {
+	case table == "query_history" || table == "saved_searches":
+		return queryBinding(userID)
+	case column == FieldTimeOfDay || column == FieldDayOfWeek:
+		return scope(column, userID)
+	}
+	return memoryBinding(userID, memoryID)
+}
+
+// taggedMemory is a live memory as read by the tags check
+type taggedMemory struct {
+	id        int
+	userID    int64
+	tags      []string
+	tagTokens sql.NullString
+	current   bool // The tags column is on the primary key, so its tokens are too
+}
+
+// findTagMismatches finds memories whose tag index (memory_tags and the blind tokens in
+// tag_tokens) differs from the tags column
+// The tags column holds the user's edits (/renametag, /mergetag), so it is never changed.
+func (r *DoctorRepository) findTagMismatches(ctx context.Context, q dbtx) ([]entity.DoctorFinding, string, error) {
+	memories, err := r.loadTaggedMemories(ctx, q)
+	if err != nil {
+		return nil, "", err
+	}
+
+	indexed := make(map[int][]string)
+	rows, err := q.QueryContext(ctx, "SELECT memory_id, user_id, tag FROM memory_tags ORDER BY memory_id, tag")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read tag index: %w", err)
+	}
+	for rows.Next() {
+		var id int
+		var userID int64
+		var stored string
+		if err := rows.Scan(&id, &userID, &stored); err != nil {
+			rows.Close()
+			return nil, "", fmt.Errorf("failed to scan row: %w", err)
+		}
+		tag, err := r.fields.openTag(userID, stored)
+		if err != nil {
+			rows.Close()
+			return nil, "", fmt.Errorf("memory %d: %w", id, err)
+		}
+		indexed[id] = append(indexed[id], tag)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	var findings []entity.DoctorFinding
+	for _, m := range memories {
+		switch {
+		case !sameTags(m.tags, indexed[m.id]):
+			findings = append(findings, entity.DoctorFinding{
+				Table:  "memories",
+				RowID:  m.id,
+				Detail: fmt.Sprintf("tags %s, tag index has %s", formatTags(m.tags), formatTags(indexed[m.id])),
+			})
+		case !r.tagTokensMatch(m):
+			findings = append(findings, entity.DoctorFinding{
+				Table:  "memories",
+				RowID:  m.id,
+				Detail: fmt.Sprintf("tags %s, tag search tokens are out of date", formatTags(m.tags)),
+			})
+		}
+	}
+	return findings, "", nil
+}
+
+// tagTokensMatch reports whether tag_tokens holds the blind tokens of the tags column
+// Rows the key rotation has not reached yet keep tokens of the previous key and are not compared.
+func (r *DoctorRepository) tagTokensMatch(m taggedMemory) bool {
+	want, _ := r.fields.tagTokens(m.tags).(string)
+	if want == "" {
+		return !m.tagTokens.Valid || m.tagTokens.String == ""
+	}
+	return !m.current || m.tagTokens.String == want
+}
+
+// loadTaggedMemories reads the live memories with their tags column and tag tokens
+// Memories whose tags do not decrypt are skipped; the ciphertext check reports them.
+// Vaulted memories are skipped too, they can only be read with the vault key.
+func (r *DoctorRepository) loadTaggedMemories(ctx context.Context, q dbtx) ([]taggedMemory, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, user_id, COALESCE(tags, ''), tag_tokens
+		FROM memories WHERE deleted_at IS NULL AND vaulted = 0 ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memories: %w", err)
+	}
+	defer rows.Close()
+
+	var memories []taggedMemory
+	for rows.Next() {
+		var m taggedMemory
+		var tags string
+		if err := rows.Scan(&m.id, &m.userID, &tags, &m.tagTokens); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		stored, err := r.fields.openTags(tags, memoryBinding(m.userID, m.id))
+		if err != nil {
+			continue
+		}
+		m.tags = normalizeTags(stored)
+		m.current = r.encryptor == nil || r.encryptor.Current(tags)
+		memories = append(memories, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return memories, nil
+}
+
+// rebuildSearchIndex recreates the FTS5 table with its current tokenizer and indexes
+// every live memory; a damaged index may refuse the 'delete-all' command, so it is dropped
+func (r *DoctorRepository) rebuildSearchIndex(ctx context.Context, q dbtx, findings []entity.DoctorFinding) (int, error) {
+	var createSQL string
+	err := q.QueryRowContext(ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'memories_fts'").Scan(&createSQL)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to read FTS5 table definition: %w", err)
+	}
+
+	tx := contextExecer{ctx: ctx, q: q}
+	if err := dropTriggers(tx); err != nil {
+		return 0, err
+	}
+	if _, err := q.ExecContext(ctx, "DROP TABLE IF EXISTS memories_fts"); err != nil {
+		return 0, fmt.Errorf("failed to drop FTS5 table: %w", err)
+	}
+
+	if createSQL == "" {
+		if err := createFTSTable(tx, DefaultTokenizer); err != nil {
+			return 0, err
+		}
+	} else if _, err := q.ExecContext(ctx, createSQL); err != nil {
+		return 0, fmt.Errorf("failed to create FTS5 table: %w", err)
+	}
+	if err := createTriggers(tx); err != nil {
+		return 0, err
+	}
+
+	if _, err := fillFTSTable(tx); err != nil {
+		return 0, err
+	}
+	return len(findings), nil
+}
+
+// indexMissingMemories adds the live memories missing from the FTS5 index
+func (r *DoctorRepository) indexMissingMemories(ctx context.Context, q dbtx, findings []entity.DoctorFinding) (int, error) {
+	result, err := q.ExecContext(ctx, `
+		INSERT INTO memories_fts(rowid, text_content, tags)
+		SELECT id, COALESCE(search_tokens, text_content), COALESCE(tag_tokens, tags) FROM memories
+		WHERE deleted_at IS NULL AND id NOT IN (SELECT id FROM memories_fts_docsize)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to index memories: %w", err)
+	}
+	indexed, _ := result.RowsAffected()
+	return int(indexed), nil
+}
+
+// detachParents turns sub-memories with a broken parent into root memories
+func (r *DoctorRepository) detachParents(ctx context.Context, q dbtx, findings []entity.DoctorFinding) (int, error) {
+	repaired := 0
+	for _, finding := range findings {
+		if _, err := q.ExecContext(ctx, "UPDATE memories SET parent_id = NULL WHERE id = ?", finding.RowID); err != nil {
+			return 0, fmt.Errorf("failed to detach memory %d: %w", finding.RowID, err)
+		}
+		repaired++
+	}
+	return repaired, nil
+}
+
+// removeUndecryptable moves undecryptable memories to the trash (restorable with the
+// right key until the trash is purged) and deletes undecryptable revisions, history and saved searches
+func (r *DoctorRepository) removeUndecryptable(ctx context.Context, q dbtx, findings []entity.DoctorFinding) (int, error) {
+	now := time.Now()
+	repaired := 0
+	for _, finding := range findings {
+		var result sql.Result
+		var err error
+		switch finding.Table {
+		case "memories":
+			result, err = q.ExecContext(ctx, "UPDATE memories SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", now, finding.RowID)
+		case "memory_revisions":
+			result, err = q.ExecContext(ctx, "DELETE FROM memory_revisions WHERE id = ?", finding.RowID)
+		case "query_history":
+			result, err = q.ExecContext(ctx, "DELETE FROM query_history WHERE id = ?", finding.RowID)
+		case "saved_searches":
+			result, err = q.ExecContext(ctx, "DELETE FROM saved_searches WHERE id = ?", finding.RowID)
+		default:
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to remove %s %d: %w", finding.Table, finding.RowID, err)
+		}
+		affected, _ := result.RowsAffected()
+		repaired += int(affected)
+	}
+	return repaired, nil
+}
+
+// reindexTags rebuilds the tag index and tag tokens of memories from their tags column
+func (r *DoctorRepository) reindexTags(ctx context.Context, q dbtx, findings []entity.DoctorFinding) (int, error) {
+	memories, err := r.loadTaggedMemories(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	byID := make(map[int]taggedMemory, len(memories))
+	for _, m := range memories {
+		byID[m.id] = m
+	}
+
+	repaired := 0
+	for _, finding := range findings {
+		m, ok := byID[finding.RowID]
+		if !ok {
+			continue
+		}
+		if err := storeMemoryTags(ctx, q, r.fields, m.id, m.userID, m.tags); err != nil {
+			return 0, err
+		}
+		if _, err := q.ExecContext(ctx, "DELETE FROM memory_tags WHERE memory_id = ?", m.id); err != nil {
+			return 0, fmt.Errorf("failed to clear tags of memory %d: %w", m.id, err)
+		}
+		if err := writeMemoryTags(ctx, q, r.fields, m.id, m.userID, m.tags); err != nil {
+			return 0, err
+		}
+		repaired++
+	}
+	return repaired, nil
+}
+
+// contextExecer adapts a dbtx to the execer of the schema helpers
+type contextExecer struct {
+	ctx context.Context
+	q   dbtx
+}
+
+// Exec runs the statement with the context of the repair
+func (e contextExecer) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return e.q.ExecContext(e.ctx, query, args...)
+}
+
+// queryIDs runs a query selecting one ID column
+func queryIDs(ctx context.Context, q queryer, query string, args ...interface{}) ([]int, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// sameTags compares two tag lists regardless of order
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// formatTags renders tags as hashtags ("#work #idea", "none")
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return "none"
+	}
+	hashtags := make([]string, len(tags))
+	for i, tag := range tags {
+		hashtags[i] = "#" + tag
+	}
+	return strings.Join(hashtags, " ")
+}
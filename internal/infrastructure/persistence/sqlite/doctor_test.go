@@ -0,0 +1,141 @@
+//go:build fts5
+
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+	"memory-bot/pkg/encryption"
+)
+
+func TestDoctorFindsAndRepairs(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestConnection(t)
+	encryptor := encryption.NewEncryptor("doctor-test-key-doctor-test-key!")
+	repo := NewMemoryRepository(conn, encryptor, AllFields())
+
+	var ids []int64
+	for _, content := range []string{"Deploy plan #ops", "Rollback notes #ops", "Standup agenda #team"} {
+		id, err := repo.Save(ctx, entity.NewMemory(1, 1, content))
+		if err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	foreign, err := NewMemoryRepository(conn, encryption.NewEncryptor("another-key-another-key-another!"), AllFields()).
+		Save(ctx, entity.NewMemory(2, 2, "Written with another key"))
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	for _, stmt := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{"PRAGMA foreign_keys = OFF", nil},
+		{"UPDATE memories SET parent_id = 999 WHERE id = ?", []interface{}{ids[0]}},
+		{"DELETE FROM memory_tags WHERE memory_id = ?", []interface{}{ids[1]}},
+		{"DELETE FROM memories_fts WHERE rowid = ?", []interface{}{ids[2]}},
+	} {
+		if _, err := conn.DB.Exec(stmt.query, stmt.args...); err != nil {
+			t.Fatalf("%s: %v", stmt.query, err)
+		}
+	}
+
+	doctor := NewDoctorRepository(conn, encryptor, AllFields())
+	report, err := doctor.Diagnose(ctx)
+	if err != nil {
+		t.Fatalf("Diagnose: %v", err)
+	}
+	want := map[string]int64{
+		entity.CheckParents:    ids[0],
+		entity.CheckTags:       ids[1],
+		entity.CheckFTSMissing: ids[2],
+		entity.CheckCiphertext: foreign,
+	}
+	for _, check := range report.Checks {
+		id, expected := want[check.Name]
+		switch {
+		case !expected && len(check.Findings) > 0:
+			t.Errorf("%s: unexpected findings %v", check.Name, check.Findings)
+		case expected && (len(check.Findings) != 1 || int64(check.Findings[0].RowID) != id):
+			t.Errorf("%s: findings %v, want one for #%d", check.Name, check.Findings, id)
+		}
+	}
+
+	for name := range want {
+		repaired, err := doctor.Repair(ctx, name)
+		if err != nil {
+			t.Fatalf("Repair %s: %v", name, err)
+		}
+		if repaired != 1 {
+			t.Errorf("Repair %s: repaired %d, want 1", name, repaired)
+		}
+	}
+
+	report, err = doctor.Diagnose(ctx)
+	if err != nil {
+		t.Fatalf("Diagnose: %v", err)
+	}
+	if problems := report.Problems(); problems != 0 {
+		t.Errorf("%d problem(s) left after repair: %+v", problems, report.Checks)
+	}
+	if results, err := repo.Search(ctx, 1, "standup", repository.SearchOptions{Limit: 10}); err != nil || len(results.Memories) != 1 {
+		t.Errorf("Search after reindex: %v, %v", results, err)
+	}
+}
+
+func TestDoctorKeepsRenamedTags(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestConnection(t)
+	encryptor := encryption.NewEncryptor("doctor-test-key-doctor-test-key!")
+	repo := NewMemoryRepository(conn, encryptor, AllFields())
+
+	var ids []int64
+	for _, content := range []string{"Deploy plan #ops", "Rollback notes #ops"} {
+		id, err := repo.Save(ctx, entity.NewMemory(1, 1, content))
+		if err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if _, err := NewTagRepository(conn, encryptor, AllFields()).Rename(ctx, 1, "ops", "devops"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+
+	doctor := NewDoctorRepository(conn, encryptor, AllFields())
+	report, err := doctor.Diagnose(ctx)
+	if err != nil {
+		t.Fatalf("Diagnose: %v", err)
+	}
+	if problems := report.Problems(); problems != 0 {
+		t.Fatalf("renamed tags reported: %+v", report.Checks)
+	}
+
+	// Stale search tokens are rebuilt from the renamed tags, not from the content
+	if _, err := conn.DB.Exec("UPDATE memories SET tag_tokens = NULL WHERE id = ?", ids[0]); err != nil {
+		t.Fatalf("clear tag tokens: %v", err)
+	}
+	if report, err = doctor.Diagnose(ctx); err != nil {
+		t.Fatalf("Diagnose: %v", err)
+	}
+	if problems := report.Problems(); problems != 1 {
+		t.Errorf("%d problem(s), want the stale tag tokens: %+v", problems, report.Checks)
+	}
+	if repaired, err := doctor.Repair(ctx, entity.CheckTags); err != nil || repaired != 1 {
+		t.Fatalf("Repair tags: %d, %v", repaired, err)
+	}
+
+	for _, id := range ids {
+		m, err := repo.FindByID(ctx, int(id))
+		if err != nil || len(m.Tags) != 1 || m.Tags[0] != "devops" {
+			t.Errorf("memory %d after repair: %v, %v", id, m, err)
+		}
+	}
+	if page, err := repo.SearchByTag(ctx, 1, "devops", repository.SearchOptions{Limit: 10}); err != nil || len(page.Memories) != 2 {
+		t.Errorf("SearchByTag after repair: %v, %v", page, err)
+	}
+}
@@ -0,0 +1,331 @@
+package sqlite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/encryption"
+)
+
+// Memory fields that can be encrypted next to text_content (ENCRYPTED_FIELDS)
+const (
+	FieldTags            = "tags"
+	FieldTimeOfDay       = "time_of_day"
+	FieldDayOfWeek       = "day_of_week"
+	FieldChatSource      = "chat_source"
+	FieldEmotionalWeight = "emotional_weight"
+)
+
+// encryptableFields lists every field a policy can name, in storage order
+var encryptableFields = []string{FieldTags, FieldTimeOfDay, FieldDayOfWeek, FieldChatSource, FieldEmotionalWeight}
+
+// FieldPolicy is the set of memory fields stored encrypted when an encryption key is set
+// text_content is always encrypted; a nil policy encrypts no other field
+type FieldPolicy map[string]bool
+
+// AllFields returns the policy that encrypts every field
+func AllFields() FieldPolicy {
+	policy := make(FieldPolicy, len(encryptableFields))
+	for _, field := range encryptableFields {
+		policy[field] = true
+	}
+	return policy
+}
+
+// ParseFieldPolicy parses ENCRYPTED_FIELDS: "all", "none" or a comma-separated list of fields
+func ParseFieldPolicy(spec string) (FieldPolicy, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	switch spec {
+	case "", "all":
+		return AllFields(), nil
+	case "none":
+		return FieldPolicy{}, nil
+	}
+
+	policy := make(FieldPolicy)
+	known := AllFields()
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !known[field] {
+			return nil, fmt.Errorf("unknown encrypted field %q (fields: %s)", field, strings.Join(encryptableFields, ", "))
+		}
+		policy[field] = true
+	}
+	return policy, nil
+}
+
+// Fields returns the encrypted fields in storage order
+func (p FieldPolicy) Fields() []string {
+	fields := []string{}
+	for _, field := range encryptableFields {
+		if p[field] {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// String lists the encrypted fields, or "none"
+func (p FieldPolicy) String() string {
+	if fields := p.Fields(); len(fields) > 0 {
+		return strings.Join(fields, ",")
+	}
+	return "none"
+}
+
+// fieldCipher stores memory fields according to the field policy
+// Fields that SQL compares (context filters, the tag index and aliases) are encrypted
+// deterministically per user, so equal values still match; tags are searched through
+// blind index tokens like the content. The other fields get a random nonce.
+// Random-nonce values are bound to their memory (memoryBinding) and deterministic ones
+// to their scope, so a value copied to another row or user fails to decrypt.
+type fieldCipher struct {
+	encryptor  *encryption.Encryptor
+	blindIndex *encryption.BlindIndex
+	policy     FieldPolicy               // empty when encryption is disabled
+	vault      *encryption.VaultSessions // nil when personal vaults are disabled
+}
+
+// newFieldCipher applies policy only when encryption is enabled
+func newFieldCipher(encryptor *encryption.Encryptor, policy FieldPolicy) *fieldCipher {
+	c := &fieldCipher{
+		encryptor:  encryptor,
+		blindIndex: encryption.NewBlindIndexIfEnabled(encryptor),
+		policy:     FieldPolicy{},
+	}
+	if encryptor != nil {
+		for field, encrypted := range policy {
+			c.policy[field] = encrypted
+		}
+	}
+	return c
+}
+
+// encrypted reports whether field is stored encrypted
+func (c *fieldCipher) encrypted(field string) bool {
+	return c.policy[field]
+}
+
+// scope keeps deterministic values of different users and fields apart
+// It is also their associated data.
+func scope(field string, userID int64) string {
+	return field + ":" + strconv.FormatInt(userID, 10)
+}
+
+// memoryBinding is the associated data of the encrypted values of a memory and its
+// revisions: they only decrypt in rows of the same memory and user
+func memoryBinding(userID int64, memoryID int) string {
+	return "memory:" + strconv.FormatInt(userID, 10) + ":" + strconv.Itoa(memoryID)
+}
+
+// queryBinding is the associated data of a user's query history entries
+func queryBinding(userID int64) string {
+	return "query_history:" + strconv.FormatInt(userID, 10)
+}
+
+// sealTags returns the value stored in memories.tags (and memory_revisions.tags)
+func (c *fieldCipher) sealTags(tags []string, aad string) (string, error) {
+	joined := strings.Join(tags, " ")
+	if !c.encrypted(FieldTags) {
+		return joined, nil
+	}
+	sealed, err := c.encryptor.Encrypt(joined, aad)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt tags: %w", err)
+	}
+	return sealed, nil
+}
+
+// openTags reads a stored tags value
+func (c *fieldCipher) openTags(stored, aad string) ([]string, error) {
+	tags, err := c.open(stored, aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt tags: %w", err)
+	}
+	return strings.Fields(tags), nil
+}
+
+// tagTokens returns the value stored in memories.tag_tokens, which the FTS5 tags column reads
+// With tags in plaintext the index reads memories.tags directly and nil is stored
+func (c *fieldCipher) tagTokens(tags []string) interface{} {
+	if !c.encrypted(FieldTags) {
+		return nil
+	}
+	return c.blindIndex.IndexText(strings.Join(tags, " "))
+}
+
+// sealTag returns the value stored for one tag in memory_tags and tag_aliases
+func (c *fieldCipher) sealTag(userID int64, tag string) (string, error) {
+	if !c.encrypted(FieldTags) {
+		return tag, nil
+	}
+	sealed, err := c.encryptor.EncryptDeterministic(scope(FieldTags, userID), tag)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt tag: %w", err)
+	}
+	return sealed, nil
+}
+
+// openTag reads one tag stored with sealTag
+func (c *fieldCipher) openTag(userID int64, stored string) (string, error) {
+	tag, err := c.open(stored, scope(FieldTags, userID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt tag: %w", err)
+	}
+	return tag, nil
+}
+
+// sealContext returns the stored value of time_of_day, day_of_week or chat_source
+// The values filtered on are deterministic; filters look them up with sealLookup.
+// chat_source gets a random nonce bound to the memory.
+func (c *fieldCipher) sealContext(field string, m *entity.Memory, value string) (string, error) {
+	if !c.encrypted(field) {
+		return value, nil
+	}
+
+	var sealed string
+	var err error
+	if field == FieldChatSource {
+		sealed, err = c.encryptor.Encrypt(value, memoryBinding(m.UserID, m.ID))
+	} else {
+		sealed, err = c.encryptor.EncryptDeterministic(scope(field, m.UserID), value)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", field, err)
+	}
+	return sealed, nil
+}
+
+// sealLookup returns every stored form of a deterministically encrypted value, primary
+// key first, so lookups also match rows the key rotation has not re-encrypted yet
+func (c *fieldCipher) sealLookup(field string, userID int64, value string) ([]interface{}, error) {
+	if !c.encrypted(field) {
+		return []interface{}{value}, nil
+	}
+
+	sealed, err := c.encryptor.EncryptDeterministicAll(scope(field, userID), value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt %s: %w", field, err)
+	}
+
+	args := make([]interface{}, len(sealed))
+	for i, value := range sealed {
+		args[i] = value
+	}
+	return args, nil
+}
+
+// sealWeight returns the emotional_weight and sealed_weight values to store
+// An encrypted weight is stored as 0, so it no longer counts in search ranking
+func (c *fieldCipher) sealWeight(weight float64, aad string) (float64, interface{}, error) {
+	if !c.encrypted(FieldEmotionalWeight) {
+		return weight, nil, nil
+	}
+	sealed, err := c.encryptor.Encrypt(strconv.FormatFloat(weight, 'g', -1, 64), aad)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to encrypt emotional weight: %w", err)
+	}
+	return 0, sealed, nil
+}
+
+// openWeight reads COALESCE(sealed_weight, emotional_weight)
+func (c *fieldCipher) openWeight(stored, aad string) (float64, error) {
+	plaintext, err := c.open(stored, aad)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt emotional weight: %w", err)
+	}
+	weight, err := strconv.ParseFloat(plaintext, 64)
+	if err != nil {
+		return 0, nil
+	}
+	return weight, nil
+}
+
+// open decrypts a stored field value; values of fields stored in plaintext are returned as-is
+func (c *fieldCipher) open(stored, aad string) (string, error) {
+	return encryption.DecryptOrPlaintext(c.encryptor, stored, aad)
+}
+
+// sealedFields holds the stored values of the policy-controlled fields of a memory
+type sealedFields struct {
+	tags         string
+	tagTokens    interface{}
+	weight       float64
+	sealedWeight interface{}
+	timeOfDay    string
+	dayOfWeek    string
+	chatSource   string
+}
+
+// sealMemory encrypts the policy-controlled fields of a memory for storage
+// m.ID must be set: the values are bound to the memory.
+func (c *fieldCipher) sealMemory(m *entity.Memory) (*sealedFields, error) {
+	sealed, err := c.sealContextFields(m)
+	if err != nil {
+		return nil, err
+	}
+	if m.Vaulted {
+		sealed.tags, sealed.tagTokens, err = c.sealVaultTags(m)
+	} else {
+		sealed.tags, err = c.sealTags(m.Tags, memoryBinding(m.UserID, m.ID))
+		sealed.tagTokens = c.tagTokens(m.Tags)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sealed, nil
+}
+
+// sealContextFields encrypts the fields of a memory other than its tags (the emotional
+// weight and the contextual fields), which are under the server key even when vaulted
+func (c *fieldCipher) sealContextFields(m *entity.Memory) (*sealedFields, error) {
+	sealed := &sealedFields{}
+	var err error
+	if sealed.weight, sealed.sealedWeight, err = c.sealWeight(m.EmotionalWeight, memoryBinding(m.UserID, m.ID)); err != nil {
+		return nil, err
+	}
+	if sealed.timeOfDay, err = c.sealContext(FieldTimeOfDay, m, m.TimeOfDay); err != nil {
+		return nil, err
+	}
+	if sealed.dayOfWeek, err = c.sealContext(FieldDayOfWeek, m, m.DayOfWeek); err != nil {
+		return nil, err
+	}
+	if sealed.chatSource, err = c.sealContext(FieldChatSource, m, m.ChatSource); err != nil {
+		return nil, err
+	}
+	return sealed, nil
+}
+
+// openMemory decrypts the stored tags and weight and the contextual fields of a scanned memory in place
+func (c *fieldCipher) openMemory(m *entity.Memory, tags, weight string) error {
+	aad := memoryBinding(m.UserID, m.ID)
+
+	var err error
+	if m.Tags, err = c.openMemoryTags(m.UserID, m.ID, m.Vaulted, tags); err != nil {
+		return fmt.Errorf("memory %d: %w", m.ID, err)
+	}
+	if m.EmotionalWeight, err = c.openWeight(weight, aad); err != nil {
+		return fmt.Errorf("memory %d: %w", m.ID, err)
+	}
+	return c.openContext(m)
+}
+
+// openContext decrypts the contextual fields of a scanned memory in place
+func (c *fieldCipher) openContext(m *entity.Memory) error {
+	var err error
+	if m.TimeOfDay, err = c.open(m.TimeOfDay, scope(FieldTimeOfDay, m.UserID)); err != nil {
+		return fmt.Errorf("failed to decrypt time of day of memory %d: %w", m.ID, err)
+	}
+	if m.DayOfWeek, err = c.open(m.DayOfWeek, scope(FieldDayOfWeek, m.UserID)); err != nil {
+		return fmt.Errorf("failed to decrypt day of week of memory %d: %w", m.ID, err)
+	}
+	if m.ChatSource, err = c.open(m.ChatSource, memoryBinding(m.UserID, m.ID)); err != nil {
+		return fmt.Errorf("failed to decrypt chat source of memory %d: %w", m.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"memory-bot/internal/domain/entity"
+)
+
+// MigrateFieldEncryption converts the stored memory fields to the configured policy
+// The fields the rows are encrypted with are recorded in encrypted_fields; when the
+// policy adds or drops a field, every memory, revision, tag index row and tag alias
+// is rewritten and the search index is rebuilt. Returns the number of converted memories.
+func (r *MemoryRepository) MigrateFieldEncryption(ctx context.Context) (int, error) {
+	current, err := r.encryptedFields(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	target := r.fields.policy
+	if current.String() == target.String() {
+		return 0, nil
+	}
+	if r.encryptor == nil && len(current) > 0 {
+		return 0, fmt.Errorf("memory fields are encrypted (%s) but ENCRYPTION_KEY is not set", current)
+	}
+
+	tx, err := r.conn.beginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin field encryption migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	log.Printf("🔄 Converting encrypted memory fields: %s -> %s", current, target)
+
+	// The index is rebuilt in full below, so the sync triggers are not needed meanwhile
+	if err := dropTriggers(tx); err != nil {
+		return 0, err
+	}
+
+	converted, err := r.resealMemories(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.resealRevisions(ctx, tx); err != nil {
+		return 0, err
+	}
+	if err := r.resealTagIndex(ctx, tx); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec("INSERT INTO memories_fts(memories_fts) VALUES('delete-all')"); err != nil {
+		return 0, fmt.Errorf("failed to clear FTS5 index: %w", err)
+	}
+	if _, err := fillFTSTable(tx); err != nil {
+		return 0, err
+	}
+	if err := createTriggers(tx); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM encrypted_fields"); err != nil {
+		return 0, fmt.Errorf("failed to clear encrypted fields: %w", err)
+	}
+	for _, field := range target.Fields() {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO encrypted_fields (field) VALUES (?)", field); err != nil {
+			return 0, fmt.Errorf("failed to record encrypted field: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit field encryption migration: %w", err)
+	}
+
+	log.Printf("✅ Encrypted memory fields are now %s: %d memories converted", target, converted)
+	return converted, nil
+}
+
+// encryptedFields reads the fields the stored rows are encrypted with
+func (r *MemoryRepository) encryptedFields(ctx context.Context) (FieldPolicy, error) {
+	rows, err := r.conn.query(ctx, "SELECT field FROM encrypted_fields")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encrypted fields: %w", err)
+	}
+	defer rows.Close()
+
+	policy := make(FieldPolicy)
+	for rows.Next() {
+		var field string
+		if err := rows.Scan(&field); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		policy[field] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return policy, nil
+}
+
+// resealMemories rewrites the policy-controlled fields of every memory, trash included
+func (r *MemoryRepository) resealMemories(ctx context.Context, tx dbtx) (int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, user_id, vaulted, COALESCE(tags, ''), COALESCE(time_of_day, ''), COALESCE(day_of_week, ''),
+		       COALESCE(chat_source, ''), COALESCE(sealed_weight, emotional_weight, 0)
+		FROM memories
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load memories for field encryption: %w", err)
+	}
+
+	var memories []*entity.Memory
+	for rows.Next() {
+		var m entity.Memory
+		var tags, weight string
+		if err := rows.Scan(&m.ID, &m.UserID, &m.Vaulted, &tags, &m.TimeOfDay, &m.DayOfWeek, &m.ChatSource, &weight); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if err := r.fields.openMemory(&m, tags, weight); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		memories = append(memories, &m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	for _, m := range memories {
+		// The tags of vaulted memories are encrypted with the vault key whatever the policy
+		var sealed *sealedFields
+		var err error
+		if m.Vaulted {
+			sealed, err = r.fields.sealContextFields(m)
+		} else {
+			sealed, err = r.fields.sealMemory(m)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE memories
+			SET tags = CASE WHEN vaulted = 1 THEN tags ELSE ? END,
+			    tag_tokens = CASE WHEN vaulted = 1 THEN tag_tokens ELSE ? END,
+			    emotional_weight = ?, sealed_weight = ?,
+			    time_of_day = ?, day_of_week = ?, chat_source = ?
+			WHERE id = ?
+		`, sealed.tags, sealed.tagTokens, sealed.weight, sealed.sealedWeight,
+			sealed.timeOfDay, sealed.dayOfWeek, sealed.chatSource, m.ID); err != nil {
+			return 0, fmt.Errorf("failed to convert memory %d: %w", m.ID, err)
+		}
+	}
+
+	return len(memories), nil
+}
+
+// resealRevisions rewrites the tags and emotional weight of every revision
+func (r *MemoryRepository) resealRevisions(ctx context.Context, tx dbtx) error {
+	type revisionFields struct {
+		id      int
+		aad     string
+		vaulted bool
+		tags    []string
+		stored  string // The tags as stored, kept for vaulted memories
+		weight  float64
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT rv.id, rv.memory_id, m.user_id, m.vaulted, COALESCE(rv.tags, ''), COALESCE(rv.sealed_weight, rv.emotional_weight, 0)
+		FROM memory_revisions AS rv
+		JOIN memories AS m ON m.id = rv.memory_id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to load revisions for field encryption: %w", err)
+	}
+
+	var revisions []revisionFields
+	for rows.Next() {
+		var rev revisionFields
+		var memoryID int
+		var userID int64
+		var weight string
+		if err := rows.Scan(&rev.id, &memoryID, &userID, &rev.vaulted, &rev.stored, &weight); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		rev.aad = memoryBinding(userID, memoryID)
+		if rev.tags, err = r.fields.openMemoryTags(userID, memoryID, rev.vaulted, rev.stored); err != nil {
+			rows.Close()
+			return fmt.Errorf("revision %d: %w", rev.id, err)
+		}
+		if rev.weight, err = r.fields.openWeight(weight, rev.aad); err != nil {
+			rows.Close()
+			return fmt.Errorf("revision %d: %w", rev.id, err)
+		}
+		revisions = append(revisions, rev)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	for _, rev := range revisions {
+		tags := rev.stored
+		var err error
+		if !rev.vaulted {
+			if tags, err = r.fields.sealTags(rev.tags, rev.aad); err != nil {
+				return err
+			}
+		}
+		weight, sealedWeight, err := r.fields.sealWeight(rev.weight, rev.aad)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE memory_revisions SET tags = ?, emotional_weight = ?, sealed_weight = ? WHERE id = ?",
+			tags, weight, sealedWeight, rev.id,
+		); err != nil {
+			return fmt.Errorf("failed to convert revision %d: %w", rev.id, err)
+		}
+	}
+
+	return nil
+}
+
+// resealTagIndex rewrites the tags of the tag index and the tag aliases
+func (r *MemoryRepository) resealTagIndex(ctx context.Context, tx dbtx) error {
+	type storedTag struct {
+		key    interface{} // memory_id or the stored alias
+		userID int64
+		stored []string
+	}
+
+	tables := []struct {
+		name, selectSQL, updateSQL string
+	}{
+		{
+			"memory_tags",
+			"SELECT memory_id, user_id, tag, '' FROM memory_tags",
+			"UPDATE memory_tags SET tag = ? WHERE memory_id = ? AND user_id = ? AND tag = ?",
+		},
+		{
+			"tag_aliases",
+			"SELECT alias, user_id, alias, tag FROM tag_aliases",
+			"UPDATE tag_aliases SET alias = ?, tag = ? WHERE alias = ? AND user_id = ?",
+		},
+	}
+
+	for _, table := range tables {
+		rows, err := tx.QueryContext(ctx, table.selectSQL)
+		if err != nil {
+			return fmt.Errorf("failed to load %s for field encryption: %w", table.name, err)
+		}
+
+		var tags []storedTag
+		for rows.Next() {
+			var tag storedTag
+			var first, second sql.NullString
+			if err := rows.Scan(&tag.key, &tag.userID, &first, &second); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+			tag.stored = []string{first.String, second.String}
+			tags = append(tags, tag)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating rows: %w", err)
+		}
+
+		for _, tag := range tags {
+			var args []interface{}
+			for _, stored := range tag.stored {
+				if stored == "" {
+					continue
+				}
+				name, err := r.fields.openTag(tag.userID, stored)
+				if err != nil {
+					return err
+				}
+				sealed, err := r.fields.sealTag(tag.userID, name)
+				if err != nil {
+					return err
+				}
+				args = append(args, sealed)
+			}
+			if table.name == "memory_tags" {
+				args = append(args, tag.key, tag.userID, tag.stored[0])
+			} else {
+				args = append(args, tag.key, tag.userID)
+			}
+
+			if _, err := tx.ExecContext(ctx, table.updateSQL, args...); err != nil {
+				return fmt.Errorf("failed to convert %s: %w", table.name, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,270 @@
+//go:build fts5
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+	"memory-bot/internal/domain/service"
+	"memory-bot/internal/infrastructure/job"
+	"memory-bot/pkg/encryption"
+)
+
+func TestFieldEncryptionMigration(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestConnection(t)
+	encryptor := encryption.NewEncryptor("fields-test-key-fields-test-key!")
+
+	// Rows written before the fields were encrypted
+	plain := NewMemoryRepository(conn, nil, nil)
+	for i, content := range []string{"Budget review #work/project", "Groceries #home"} {
+		m := entity.NewMemory(1, 1, content)
+		m.TimeOfDay = []string{"Morning", "Evening"}[i]
+		m.DayOfWeek = "Monday"
+		m.EmotionalWeight = 0.5
+		if _, err := plain.Save(ctx, m); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	repo := NewMemoryRepository(conn, encryptor, AllFields())
+	if _, err := repo.MigrateSearchTokens(ctx); err != nil {
+		t.Fatalf("MigrateSearchTokens: %v", err)
+	}
+	if converted, err := repo.MigrateFieldEncryption(ctx); err != nil || converted != 2 {
+		t.Fatalf("MigrateFieldEncryption: converted %d, %v", converted, err)
+	}
+	if converted, err := repo.MigrateFieldEncryption(ctx); err != nil || converted != 0 {
+		t.Fatalf("MigrateFieldEncryption again: converted %d, %v", converted, err)
+	}
+
+	var stored, index string
+	if err := conn.DB.QueryRow(`
+		SELECT group_concat(tags || time_of_day || day_of_week || chat_source || emotional_weight, ' ')
+		FROM memories
+	`).Scan(&stored); err != nil {
+		t.Fatalf("read memories: %v", err)
+	}
+	if err := conn.DB.QueryRow("SELECT group_concat(tag, ' ') FROM memory_tags").Scan(&index); err != nil {
+		t.Fatalf("read tag index: %v", err)
+	}
+	stored += " " + index
+	for _, plaintext := range []string{"work", "home", "Morning", "Monday", "Telegram", "0.5"} {
+		if strings.Contains(stored, plaintext) {
+			t.Errorf("%q is stored in plaintext: %s", plaintext, stored)
+		}
+	}
+
+	memory, err := repo.FindByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if strings.Join(memory.Tags, " ") != "work/project" || memory.TimeOfDay != "Morning" ||
+		memory.ChatSource != "Telegram" || memory.EmotionalWeight != 0.5 {
+		t.Errorf("FindByID: got %+v", memory)
+	}
+
+	if page, err := repo.SearchByTag(ctx, 1, "work", repository.SearchOptions{Limit: 10}); err != nil || len(page.Memories) != 1 {
+		t.Errorf("SearchByTag work: %v, %v", page, err)
+	}
+	if page, err := repo.Search(ctx, 1, "tags:project", repository.SearchOptions{Limit: 10}); err != nil || len(page.Memories) != 1 {
+		t.Errorf("Search tags:project: %v, %v", page, err)
+	}
+	filter := &service.ContextualData{TimeOfDay: "Evening"}
+	if page, err := repo.Search(ctx, 1, "groceries", repository.SearchOptions{Limit: 10, ContextFilter: filter}); err != nil || len(page.Memories) != 1 {
+		t.Errorf("Search with context filter: %v, %v", page, err)
+	}
+
+	if _, err := NewMemoryRepository(conn, nil, nil).MigrateFieldEncryption(ctx); err == nil {
+		t.Error("MigrateFieldEncryption without a key: want an error")
+	}
+
+	// Dropping the policy decrypts the fields again
+	if converted, err := NewMemoryRepository(conn, encryptor, FieldPolicy{}).MigrateFieldEncryption(ctx); err != nil || converted != 2 {
+		t.Fatalf("MigrateFieldEncryption to none: converted %d, %v", converted, err)
+	}
+	var tags, timeOfDay string
+	if err := conn.DB.QueryRow("SELECT tags, time_of_day FROM memories WHERE id = 1").Scan(&tags, &timeOfDay); err != nil {
+		t.Fatalf("read memory: %v", err)
+	}
+	if tags != "work/project" || timeOfDay != "Morning" {
+		t.Errorf("after decrypting: tags %q, time_of_day %q", tags, timeOfDay)
+	}
+}
+
+// An encrypted emotional weight is the documented trade-off: it no longer boosts search
+// ranking, and the consolidation boost in plaintext priority_score leaves it out
+func TestSealedWeightRanking(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name         string
+		policy       FieldPolicy
+		wantFirst    int // Index of the memory ranked first
+		wantPriority float64
+	}{
+		{"plaintext weight", FieldPolicy{FieldTags: true}, 0, 0.5 * (1 + 0.8)},
+		{"sealed weight", AllFields(), 1, 0.5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := openTestConnection(t)
+			repo := NewMemoryRepository(conn, encryption.NewEncryptor("weight-test-key-weight-test-key!"), tc.policy)
+
+			var ids []int64
+			for _, weight := range []float64{0.8, 0} {
+				m := entity.NewMemory(1, 1, "Deploy checklist")
+				m.EmotionalWeight = weight
+				id, err := repo.Save(ctx, m)
+				if err != nil {
+					t.Fatalf("Save: %v", err)
+				}
+				ids = append(ids, id)
+			}
+
+			page, err := repo.Search(ctx, 1, "deploy", repository.SearchOptions{Limit: 10})
+			if err != nil || len(page.Memories) != 2 {
+				t.Fatalf("Search: %v, %v", page, err)
+			}
+			if got := int64(page.Memories[0].ID); got != ids[tc.wantFirst] {
+				t.Errorf("ranked first: #%d, want #%d", got, ids[tc.wantFirst])
+			}
+			for _, m := range page.Memories {
+				if int64(m.ID) == ids[0] && m.EmotionalWeight != 0.8 {
+					t.Errorf("read weight = %v, want 0.8", m.EmotionalWeight)
+				}
+			}
+
+			if err := job.NewDailyConsolidationJob(repo, conn).Execute(); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			var priority float64
+			if err := conn.DB.QueryRow("SELECT priority_score FROM memories WHERE id = ?", ids[0]).Scan(&priority); err != nil {
+				t.Fatalf("read priority_score: %v", err)
+			}
+			if priority != tc.wantPriority {
+				t.Errorf("priority_score = %v, want %v", priority, tc.wantPriority)
+			}
+		})
+	}
+}
+
+func TestStoredValueBinding(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestConnection(t)
+
+	// Memories saved before encryption was turned on, one of them valid base64
+	plain := NewMemoryRepository(conn, nil, AllFields())
+	for _, content := range []string{"Budget review #work", "QmFzZTY0IGxvb2tpbmcgcGxhaW50ZXh0IG1lbW9yeQ=="} {
+		if _, err := plain.Save(ctx, entity.NewMemory(1, 1, content)); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	encryptor := encryption.NewEncryptor("binding-test-key-binding-test!!!")
+	repo := NewMemoryRepository(conn, encryptor, AllFields())
+	if m, err := repo.FindByID(ctx, 2); err != nil || m.Content != "QmFzZTY0IGxvb2tpbmcgcGxhaW50ZXh0IG1lbW9yeQ==" {
+		t.Fatalf("plaintext before the job: %v, %v", m, err)
+	}
+
+	// The job encrypts the plaintext rows once
+	rotation := NewKeyRotationRepository(conn, encryptor, AllFields())
+	progress, err := rotation.Progress(ctx)
+	if err != nil || !progress.Pending() {
+		t.Fatalf("job should be pending: %+v, %v", progress, err)
+	}
+	for !progress.Complete {
+		if progress, err = rotation.ReencryptBatch(ctx, 100); err != nil {
+			t.Fatalf("ReencryptBatch: %v", err)
+		}
+	}
+	var stored string
+	if err := conn.DB.QueryRow("SELECT text_content FROM memories WHERE id = 2").Scan(&stored); err != nil || !encryptor.Current(stored) {
+		t.Fatalf("memory not encrypted: %q, %v", stored, err)
+	}
+	if m, err := repo.FindByID(ctx, 2); err != nil || m.Content != "QmFzZTY0IGxvb2tpbmcgcGxhaW50ZXh0IG1lbW9yeQ==" {
+		t.Errorf("FindByID after the job: %v, %v", m, err)
+	}
+
+	// A value moved to another row no longer authenticates
+	if _, err := conn.DB.Exec("UPDATE memories SET text_content = ? WHERE id = 1", stored); err != nil {
+		t.Fatalf("swap: %v", err)
+	}
+	if _, err := repo.FindByID(ctx, 1); !errors.Is(err, encryption.ErrAuthentication) {
+		t.Errorf("swapped row: %v", err)
+	}
+}
+
+// Plaintext that starts like an envelope is read as plaintext with or without a key,
+// and one row that does not decrypt does not fail a whole listing
+func TestPlaintextThatLooksLikeAnEnvelope(t *testing.T) {
+	ctx := context.Background()
+	notes := []string{"v3: release checklist for launch", "v2:deadbeef: release retro"}
+	for _, tc := range []struct {
+		name      string
+		encryptor *encryption.Encryptor
+	}{
+		{"without encryption", nil},
+		{"with encryption", encryption.NewEncryptor("prefix-test-key-prefix-test-key!")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := openTestConnection(t)
+
+			// Saved before encryption was enabled; the chat source stays in plaintext
+			var ids []int
+			for _, content := range notes {
+				m := entity.NewMemory(1, 1, content)
+				m.ChatSource = "v3:cafebabe:group"
+				id, err := NewMemoryRepository(conn, nil, nil).Save(ctx, m)
+				if err != nil {
+					t.Fatalf("Save: %v", err)
+				}
+				ids = append(ids, int(id))
+			}
+			repo := NewMemoryRepository(conn, tc.encryptor, FieldPolicy{FieldTags: true})
+			if _, err := repo.MigrateSearchTokens(ctx); err != nil {
+				t.Fatalf("MigrateSearchTokens: %v", err)
+			}
+
+			for i, id := range ids {
+				if m, err := repo.FindByID(ctx, id); err != nil || m.Content != notes[i] || m.ChatSource != "v3:cafebabe:group" {
+					t.Errorf("FindByID %d: %+v, %v", id, m, err)
+				}
+			}
+			if page, err := repo.GetRecent(ctx, 1, repository.PageOptions{Limit: 10}); err != nil || len(page.Memories) != 2 {
+				t.Errorf("GetRecent: %+v, %v", page, err)
+			}
+			if page, err := repo.Search(ctx, 1, "release", repository.SearchOptions{Limit: 10}); err != nil || len(page.Memories) != 2 {
+				t.Errorf("Search: %+v, %v", page, err)
+			}
+			if tc.encryptor == nil {
+				return
+			}
+
+			// A value moved from another row is listed as undecryptable
+			id, err := repo.Save(ctx, entity.NewMemory(1, 1, "Release party venue"))
+			if err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			if _, err := conn.DB.Exec("UPDATE memories SET text_content = (SELECT text_content FROM memories WHERE id = ?) WHERE id = ?", id, ids[0]); err != nil {
+				t.Fatalf("swap: %v", err)
+			}
+			page, err := repo.Search(ctx, 1, "release", repository.SearchOptions{Limit: 10})
+			if err != nil || len(page.Memories) != 3 {
+				t.Fatalf("Search with a damaged row: %+v, %v", page, err)
+			}
+			recent, err := repo.GetRecent(ctx, 1, repository.PageOptions{Limit: 10})
+			if err != nil || len(recent.Memories) != 3 {
+				t.Fatalf("GetRecent with a damaged row: %+v, %v", recent, err)
+			}
+			for _, m := range recent.Memories {
+				if damaged := m.ID == ids[0]; damaged != (m.Locked && m.Content == entity.UndecryptableContent) {
+					t.Errorf("memory %d: locked %t, content %q", m.ID, m.Locked, m.Content)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,585 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/encryption"
+)
+
+// rotationTables are re-encrypted in this order; memories include their tag index rows
+var rotationTables = []string{"memories", "memory_revisions", "tag_aliases", "query_history", "saved_searches"}
+
+// KeyRotationRepository is the SQLite implementation of repository.KeyRotationRepository
+// Every encrypted value is decrypted with whichever key of the keyring wrote it and
+// stored again with the primary key in the current envelope format; plaintext left
+// from before encryption was enabled is encrypted. Search tokens and query keys are recomputed.
+// Rows are processed in rowid order, and the last processed rowid of each table is
+// stored in key_rotation in the same transaction as the rewritten rows.
+type KeyRotationRepository struct {
+	conn       *Connection
+	encryptor  *encryption.Encryptor
+	blindIndex *encryption.BlindIndex
+	fields     *fieldCipher
+}
+
+// NewKeyRotationRepository creates a new SQLite key rotation repository
+// encryptor must not be nil; policy is the ENCRYPTED_FIELDS policy
+func NewKeyRotationRepository(conn *Connection, encryptor *encryption.Encryptor, policy FieldPolicy) *KeyRotationRepository {
+	return &KeyRotationRepository{
+		conn:       conn,
+		encryptor:  encryptor,
+		blindIndex: encryption.NewBlindIndex(encryptor),
+		fields:     newFieldCipher(encryptor, policy),
+	}
+}
+
+// rotationCheckpoint is the stored progress of one table
+type rotationCheckpoint struct {
+	lastID    int64
+	completed bool
+	updatedAt sql.NullTime
+}
+
+// Progress reports the re-encryption progress for the primary key
+func (r *KeyRotationRepository) Progress(ctx context.Context) (*entity.KeyRotationProgress, error) {
+	return r.progress(ctx, r.conn.reader(ctx))
+}
+
+// ReencryptBatch re-encrypts up to limit rows of the first unfinished table
+func (r *KeyRotationRepository) ReencryptBatch(ctx context.Context, limit int) (*entity.KeyRotationProgress, error) {
+	tx, err := r.conn.beginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin key rotation batch: %w", err)
+	}
+	defer tx.Rollback()
+
+	checkpoints, err := r.checkpoints(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, table := range rotationTables {
+		checkpoint := checkpoints[table]
+		if checkpoint.completed {
+			continue
+		}
+
+		lastID, processed, err := r.reencrypt(ctx, tx, table, checkpoint.lastID, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO key_rotation (table_name, key_id, format, last_id, completed, updated_at)
+			VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(table_name) DO UPDATE SET
+				key_id = excluded.key_id,
+				format = excluded.format,
+				last_id = excluded.last_id,
+				completed = excluded.completed,
+				updated_at = excluded.updated_at
+		`, table, r.encryptor.KeyID(), encryption.EnvelopeVersion, lastID, processed < limit); err != nil {
+			return nil, fmt.Errorf("failed to save key rotation checkpoint: %w", err)
+		}
+		break
+	}
+
+	progress, err := r.progress(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit key rotation batch: %w", err)
+	}
+	return progress, nil
+}
+
+// checkpoints loads the checkpoints of the rotation to the primary key
+// Checkpoints of another key or envelope format belong to an earlier rotation and are ignored
+func (r *KeyRotationRepository) checkpoints(ctx context.Context, q dbtx) (map[string]rotationCheckpoint, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT table_name, last_id, completed, updated_at
+		FROM key_rotation
+		WHERE key_id = ? AND format = ?
+	`, r.encryptor.KeyID(), encryption.EnvelopeVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key rotation checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	checkpoints := make(map[string]rotationCheckpoint)
+	for rows.Next() {
+		var table string
+		var checkpoint rotationCheckpoint
+		if err := rows.Scan(&table, &checkpoint.lastID, &checkpoint.completed, &checkpoint.updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		checkpoints[table] = checkpoint
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return checkpoints, nil
+}
+
+// progress counts the rows at or before each table's checkpoint
+func (r *KeyRotationRepository) progress(ctx context.Context, q dbtx) (*entity.KeyRotationProgress, error) {
+	checkpoints, err := r.checkpoints(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &entity.KeyRotationProgress{
+		KeyID:        r.encryptor.KeyID(),
+		PreviousKeys: r.encryptor.KeyIDs()[1:],
+		Complete:     true,
+	}
+	for _, table := range rotationTables {
+		checkpoint := checkpoints[table]
+
+		var total, processed int
+		if err := q.QueryRowContext(ctx,
+			"SELECT COUNT(*), COUNT(CASE WHEN rowid <= ? THEN 1 END) FROM "+table, checkpoint.lastID,
+		).Scan(&total, &processed); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", table, err)
+		}
+		if checkpoint.completed {
+			processed = total
+		}
+
+		progress.Total += total
+		progress.Processed += processed
+		progress.Complete = progress.Complete && checkpoint.completed
+		if checkpoint.updatedAt.Valid && checkpoint.updatedAt.Time.After(progress.UpdatedAt) {
+			progress.UpdatedAt = checkpoint.updatedAt.Time
+		}
+	}
+	return progress, nil
+}
+
+// reencrypt re-encrypts up to limit rows of table after rowid after
+// Returns the last processed rowid and the number of rows read
+func (r *KeyRotationRepository) reencrypt(ctx context.Context, tx dbtx, table string, after int64, limit int) (int64, int, error) {
+	switch table {
+	case "memories":
+		return r.reencryptMemories(ctx, tx, after, limit)
+	case "memory_revisions":
+		return r.reencryptRevisions(ctx, tx, after, limit)
+	case "tag_aliases":
+		return r.reencryptAliases(ctx, tx, after, limit)
+	case "query_history":
+		return r.reencryptQueries(ctx, tx, after, limit)
+	case "saved_searches":
+		return r.reencryptSavedSearches(ctx, tx, after, limit)
+	}
+	return after, 0, fmt.Errorf("unknown key rotation table %s", table)
+}
+
+// reencryptMemories re-encrypts memories (trash included) and their tag index rows
+func (r *KeyRotationRepository) reencryptMemories(ctx context.Context, tx dbtx, after int64, limit int) (int64, int, error) {
+	type storedMemory struct {
+		memory        entity.Memory
+		content, tags string
+		weight        string
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, user_id, text_content, vaulted, secret, COALESCE(tags, ''), COALESCE(time_of_day, ''),
+		       COALESCE(day_of_week, ''), COALESCE(chat_source, ''), COALESCE(sealed_weight, emotional_weight, 0)
+		FROM memories
+		WHERE id > ?
+		ORDER BY id
+		LIMIT ?
+	`, after, limit)
+	if err != nil {
+		return after, 0, fmt.Errorf("failed to load memories for key rotation: %w", err)
+	}
+
+	var stored []storedMemory
+	for rows.Next() {
+		var s storedMemory
+		m := &s.memory
+		if err := rows.Scan(&m.ID, &m.UserID, &s.content, &m.Vaulted, &m.Secret, &s.tags, &m.TimeOfDay, &m.DayOfWeek, &m.ChatSource, &s.weight); err != nil {
+			rows.Close()
+			return after, 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+		stored = append(stored, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return after, 0, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	for _, s := range stored {
+		m := &s.memory
+		after = int64(m.ID)
+		// Rows that do not decrypt are left as they are (the doctor reports them)
+		if err := r.fields.openMemory(m, s.tags, s.weight); err != nil {
+			log.Printf("⚠️ Memory %d does not decrypt (%v), left unchanged", m.ID, err)
+			continue
+		}
+		encryptedContent, searchTokens, err := r.reencryptContent(m, s.content)
+		if err != nil {
+			log.Printf("⚠️ Memory %d does not decrypt (%v), left unchanged", m.ID, err)
+			continue
+		}
+		// The tags of vaulted memories are encrypted with the vault key and kept as they are
+		var sealed *sealedFields
+		if m.Vaulted {
+			sealed, err = r.fields.sealContextFields(m)
+		} else {
+			sealed, err = r.fields.sealMemory(m)
+		}
+		if err != nil {
+			return after, 0, err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE memories
+			SET text_content = COALESCE(?, text_content), search_tokens = COALESCE(?, search_tokens),
+			    tags = CASE WHEN vaulted = 1 THEN tags ELSE ? END,
+			    tag_tokens = CASE WHEN vaulted = 1 THEN tag_tokens ELSE ? END,
+			    emotional_weight = ?, sealed_weight = ?,
+			    time_of_day = ?, day_of_week = ?, chat_source = ?
+			WHERE id = ?
+		`, encryptedContent, searchTokens, sealed.tags, sealed.tagTokens,
+			sealed.weight, sealed.sealedWeight, sealed.timeOfDay, sealed.dayOfWeek, sealed.chatSource, m.ID); err != nil {
+			return after, 0, fmt.Errorf("failed to re-encrypt memory %d: %w", m.ID, err)
+		}
+
+		if err := r.reencryptMemoryTags(ctx, tx, m.ID, m.UserID); err != nil {
+			return after, 0, err
+		}
+	}
+
+	return after, len(stored), nil
+}
+
+// reencryptContent re-encrypts the stored content of a memory or revision with the primary key
+// Returns the new content and search tokens (none for secret content), or nils for
+// vaulted content, which is encrypted with the owner's vault key and left as it is
+func (r *KeyRotationRepository) reencryptContent(m *entity.Memory, stored string) (interface{}, interface{}, error) {
+	if m.Vaulted {
+		return nil, nil, nil
+	}
+
+	aad := memoryBinding(m.UserID, m.ID)
+	content, err := encryption.DecryptIfEnabled(r.encryptor, stored, aad)
+	if err != nil {
+		return nil, nil, err
+	}
+	encryptedContent, err := r.encryptor.Encrypt(content, aad)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encrypt content: %w", err)
+	}
+	if m.Secret {
+		return encryptedContent, "", nil
+	}
+	return encryptedContent, r.blindIndex.IndexText(content), nil
+}
+
+// reencryptMemoryTags rewrites the tag index rows of one memory
+func (r *KeyRotationRepository) reencryptMemoryTags(ctx context.Context, tx dbtx, memoryID int, userID int64) error {
+	rows, err := tx.QueryContext(ctx, "SELECT tag FROM memory_tags WHERE memory_id = ?", memoryID)
+	if err != nil {
+		return fmt.Errorf("failed to load tags of memory %d: %w", memoryID, err)
+	}
+
+	var stored []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		stored = append(stored, tag)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	tags := make([]string, 0, len(stored))
+	for _, tag := range stored {
+		name, err := r.fields.openTag(userID, tag)
+		if err != nil {
+			log.Printf("⚠️ Tag index of memory %d does not decrypt (%v), left unchanged", memoryID, err)
+			return nil
+		}
+		tags = append(tags, name)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM memory_tags WHERE memory_id = ?", memoryID); err != nil {
+		return fmt.Errorf("failed to clear tags of memory %d: %w", memoryID, err)
+	}
+	return writeMemoryTags(ctx, tx, r.fields, memoryID, userID, tags)
+}
+
+// reencryptRevisions re-encrypts the content, tags and weight of revisions
+func (r *KeyRotationRepository) reencryptRevisions(ctx context.Context, tx dbtx, after int64, limit int) (int64, int, error) {
+	type storedRevision struct {
+		id                    int64
+		memory                entity.Memory
+		content, tags, weight string
+	}
+
+	// Revisions are bound to their memory; orphaned rows cannot be bound and are skipped
+	rows, err := tx.QueryContext(ctx, `
+		SELECT rv.id, rv.memory_id, COALESCE(m.user_id, 0), COALESCE(m.vaulted, 0), rv.text_content,
+		       COALESCE(rv.tags, ''), COALESCE(rv.sealed_weight, rv.emotional_weight, 0)
+		FROM memory_revisions AS rv
+		LEFT JOIN memories AS m ON m.id = rv.memory_id
+		WHERE rv.id > ?
+		ORDER BY rv.id
+		LIMIT ?
+	`, after, limit)
+	if err != nil {
+		return after, 0, fmt.Errorf("failed to load revisions for key rotation: %w", err)
+	}
+
+	var stored []storedRevision
+	for rows.Next() {
+		var rev storedRevision
+		if err := rows.Scan(&rev.id, &rev.memory.ID, &rev.memory.UserID, &rev.memory.Vaulted, &rev.content, &rev.tags, &rev.weight); err != nil {
+			rows.Close()
+			return after, 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+		stored = append(stored, rev)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return after, 0, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	for _, rev := range stored {
+		after = rev.id
+		aad := memoryBinding(rev.memory.UserID, rev.memory.ID)
+		encryptedContent, _, err := r.reencryptContent(&rev.memory, rev.content)
+		var openTags []string
+		var openWeight float64
+		if err == nil && !rev.memory.Vaulted {
+			openTags, err = r.fields.openTags(rev.tags, aad)
+		}
+		if err == nil {
+			openWeight, err = r.fields.openWeight(rev.weight, aad)
+		}
+		if err != nil {
+			log.Printf("⚠️ Revision %d does not decrypt (%v), left unchanged", rev.id, err)
+			continue
+		}
+
+		// The tags of vaulted revisions are encrypted with the vault key and kept as they are
+		tags := rev.tags
+		if !rev.memory.Vaulted {
+			if tags, err = r.fields.sealTags(openTags, aad); err != nil {
+				return after, 0, err
+			}
+		}
+		weight, sealedWeight, err := r.fields.sealWeight(openWeight, aad)
+		if err != nil {
+			return after, 0, err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE memory_revisions
+			SET text_content = COALESCE(?, text_content), tags = ?, emotional_weight = ?, sealed_weight = ?
+			WHERE id = ?
+		`, encryptedContent, tags, weight, sealedWeight, rev.id); err != nil {
+			return after, 0, fmt.Errorf("failed to re-encrypt revision %d: %w", rev.id, err)
+		}
+	}
+
+	return after, len(stored), nil
+}
+
+// reencryptAliases re-encrypts tag aliases
+func (r *KeyRotationRepository) reencryptAliases(ctx context.Context, tx dbtx, after int64, limit int) (int64, int, error) {
+	type storedAlias struct {
+		rowID      int64
+		userID     int64
+		alias, tag string
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT rowid, user_id, alias, tag
+		FROM tag_aliases
+		WHERE rowid > ?
+		ORDER BY rowid
+		LIMIT ?
+	`, after, limit)
+	if err != nil {
+		return after, 0, fmt.Errorf("failed to load tag aliases for key rotation: %w", err)
+	}
+
+	var stored []storedAlias
+	for rows.Next() {
+		var alias storedAlias
+		if err := rows.Scan(&alias.rowID, &alias.userID, &alias.alias, &alias.tag); err != nil {
+			rows.Close()
+			return after, 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+		stored = append(stored, alias)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return after, 0, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	for _, alias := range stored {
+		after = alias.rowID
+		openAlias, err := r.fields.openTag(alias.userID, alias.alias)
+		var openTarget string
+		if err == nil {
+			openTarget, err = r.fields.openTag(alias.userID, alias.tag)
+		}
+		if err != nil {
+			log.Printf("⚠️ A tag alias of user %d does not decrypt (%v), left unchanged", alias.userID, err)
+			continue
+		}
+
+		name, err := r.fields.sealTag(alias.userID, openAlias)
+		if err != nil {
+			return after, 0, err
+		}
+		target, err := r.fields.sealTag(alias.userID, openTarget)
+		if err != nil {
+			return after, 0, err
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE tag_aliases SET alias = ?, tag = ? WHERE rowid = ?", name, target, alias.rowID,
+		); err != nil {
+			return after, 0, fmt.Errorf("failed to re-encrypt tag alias: %w", err)
+		}
+	}
+
+	return after, len(stored), nil
+}
+
+// reencryptQueries re-encrypts the query history and recomputes its grouping keys
+func (r *KeyRotationRepository) reencryptQueries(ctx context.Context, tx dbtx, after int64, limit int) (int64, int, error) {
+	type storedQuery struct {
+		id     int64
+		userID int64
+		query  string
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, user_id, query_text
+		FROM query_history
+		WHERE id > ?
+		ORDER BY id
+		LIMIT ?
+	`, after, limit)
+	if err != nil {
+		return after, 0, fmt.Errorf("failed to load query history for key rotation: %w", err)
+	}
+
+	var stored []storedQuery
+	for rows.Next() {
+		var q storedQuery
+		if err := rows.Scan(&q.id, &q.userID, &q.query); err != nil {
+			rows.Close()
+			return after, 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+		stored = append(stored, q)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return after, 0, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	for _, q := range stored {
+		after = q.id
+		aad := queryBinding(q.userID)
+		query, err := encryption.DecryptIfEnabled(r.encryptor, q.query, aad)
+		if err != nil {
+			log.Printf("⚠️ Query history entry %d does not decrypt (%v), left unchanged", q.id, err)
+			continue
+		}
+		encryptedQuery, err := r.encryptor.Encrypt(query, aad)
+		if err != nil {
+			return after, 0, fmt.Errorf("failed to encrypt query: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE query_history SET query_text = ?, query_key = ? WHERE id = ?",
+			encryptedQuery, r.blindIndex.Fingerprint(entity.NormalizeQuery(query)), q.id,
+		); err != nil {
+			return after, 0, fmt.Errorf("failed to re-encrypt query history entry %d: %w", q.id, err)
+		}
+	}
+
+	return after, len(stored), nil
+}
+
+// reencryptSavedSearches re-encrypts the names and queries of saved searches
+func (r *KeyRotationRepository) reencryptSavedSearches(ctx context.Context, tx dbtx, after int64, limit int) (int64, int, error) {
+	type storedSearch struct {
+		id          int64
+		userID      int64
+		name, query string
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, user_id, name, query
+		FROM saved_searches
+		WHERE id > ?
+		ORDER BY id
+		LIMIT ?
+	`, after, limit)
+	if err != nil {
+		return after, 0, fmt.Errorf("failed to load saved searches for key rotation: %w", err)
+	}
+
+	var stored []storedSearch
+	for rows.Next() {
+		var s storedSearch
+		if err := rows.Scan(&s.id, &s.userID, &s.name, &s.query); err != nil {
+			rows.Close()
+			return after, 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+		stored = append(stored, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return after, 0, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	for _, s := range stored {
+		after = s.id
+		aad := queryBinding(s.userID)
+		name, err := encryption.DecryptIfEnabled(r.encryptor, s.name, aad)
+		var query string
+		if err == nil {
+			query, err = encryption.DecryptIfEnabled(r.encryptor, s.query, aad)
+		}
+		if err != nil {
+			log.Printf("⚠️ Saved search %d does not decrypt (%v), left unchanged", s.id, err)
+			continue
+		}
+
+		encryptedName, err := r.encryptor.Encrypt(name, aad)
+		if err != nil {
+			return after, 0, fmt.Errorf("failed to encrypt saved search name: %w", err)
+		}
+		encryptedQuery, err := r.encryptor.Encrypt(query, aad)
+		if err != nil {
+			return after, 0, fmt.Errorf("failed to encrypt saved search query: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE saved_searches SET name = ?, query = ? WHERE id = ?", encryptedName, encryptedQuery, s.id,
+		); err != nil {
+			return after, 0, fmt.Errorf("failed to re-encrypt saved search %d: %w", s.id, err)
+		}
+	}
+
+	return after, len(stored), nil
+}
@@ -0,0 +1,118 @@
+//go:build fts5
+
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+	"memory-bot/internal/domain/service"
+	"memory-bot/pkg/encryption"
+)
+
+func TestKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestConnection(t)
+	const oldKey, newKey = "old-key-old-key-old-key-old-key!", "new-key-new-key-new-key-new-key!"
+
+	// Data written before the rotation
+	old := encryption.NewEncryptor(oldKey)
+	oldRepo := NewMemoryRepository(conn, old, AllFields())
+	for i, content := range []string{"Budget review #work", "Groceries #home", "Standup notes #work"} {
+		m := entity.NewMemory(1, 1, content)
+		m.TimeOfDay = []string{"Morning", "Evening", "Morning"}[i]
+		if _, err := oldRepo.Save(ctx, m); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	if _, err := NewTagRepository(conn, old, AllFields()).SetAlias(ctx, &entity.TagAlias{UserID: 1, Alias: "job", Tag: "work"}); err != nil {
+		t.Fatalf("SetAlias: %v", err)
+	}
+	if err := NewQueryHistoryRepository(conn, old).Record(ctx, entity.NewQueryLogEntry(1, "budget", 1, "")); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if _, err := NewSavedSearchRepository(conn, old).Save(ctx, entity.NewSavedSearch(1, 1, "bugs", "#bug")); err != nil {
+		t.Fatalf("Save saved search: %v", err)
+	}
+
+	keyring := encryption.NewKeyring(encryption.LegacyKey(newKey), encryption.LegacyKey(oldKey))
+	repo := NewMemoryRepository(conn, keyring, AllFields())
+
+	// Rows on the old key stay readable and searchable before they are converted
+	if page, err := repo.Search(ctx, 1, "budget", repository.SearchOptions{Limit: 10}); err != nil || len(page.Memories) != 1 || page.Memories[0].Content != "Budget review #work" {
+		t.Errorf("Search before rotation: %v, %v", page, err)
+	}
+	filter := &service.ContextualData{TimeOfDay: "Morning"}
+	if page, err := repo.SearchByTag(ctx, 1, "work", repository.SearchOptions{Limit: 10, ContextFilter: filter}); err != nil || len(page.Memories) != 2 {
+		t.Errorf("SearchByTag before rotation: %v, %v", page, err)
+	}
+
+	// A batch, then a fresh repository as after a crash: the checkpoint is kept
+	progress, err := NewKeyRotationRepository(conn, keyring, AllFields()).ReencryptBatch(ctx, 2)
+	if err != nil {
+		t.Fatalf("ReencryptBatch: %v", err)
+	}
+	if progress.Complete || progress.Processed != 2 || progress.Total != 6 || !progress.Pending() {
+		t.Errorf("after one batch: %+v", progress)
+	}
+
+	rotation := NewKeyRotationRepository(conn, keyring, AllFields())
+	for i := 0; !progress.Complete; i++ {
+		if i == 10 {
+			t.Fatalf("rotation did not complete: %+v", progress)
+		}
+		if progress, err = rotation.ReencryptBatch(ctx, 2); err != nil {
+			t.Fatalf("ReencryptBatch: %v", err)
+		}
+	}
+	if progress.Processed != progress.Total {
+		t.Errorf("complete: %+v", progress)
+	}
+
+	// Nothing is left on the old key
+	rows, err := conn.DB.Query(`
+		SELECT text_content FROM memories
+		UNION ALL SELECT tags FROM memories
+		UNION ALL SELECT time_of_day FROM memories
+		UNION ALL SELECT tag FROM memory_tags
+		UNION ALL SELECT alias FROM tag_aliases
+		UNION ALL SELECT query_text FROM query_history
+		UNION ALL SELECT name FROM saved_searches
+		UNION ALL SELECT query FROM saved_searches
+	`)
+	if err != nil {
+		t.Fatalf("read stored values: %v", err)
+	}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if value != "" && !keyring.Current(value) {
+			t.Errorf("value not on the new key: %s", value)
+		}
+	}
+	rows.Close()
+
+	// The old key is no longer needed
+	current := encryption.NewEncryptor(newKey)
+	repo = NewMemoryRepository(conn, current, AllFields())
+	if page, err := repo.SearchByTag(ctx, 1, "work", repository.SearchOptions{Limit: 10, ContextFilter: filter}); err != nil || len(page.Memories) != 2 {
+		t.Errorf("SearchByTag after rotation: %v, %v", page, err)
+	}
+	if page, err := repo.Search(ctx, 1, "standup", repository.SearchOptions{Limit: 10}); err != nil || len(page.Memories) != 1 {
+		t.Errorf("Search after rotation: %v, %v", page, err)
+	}
+	if aliases, err := NewTagRepository(conn, current, AllFields()).FindAliases(ctx, 1); err != nil || len(aliases) != 1 || aliases[0].Alias != "job" {
+		t.Errorf("FindAliases after rotation: %v, %v", aliases, err)
+	}
+	if queries, err := NewQueryHistoryRepository(conn, current).Recent(ctx, 1, 10); err != nil || strings.Join(queries, ",") != "budget" {
+		t.Errorf("Recent after rotation: %v, %v", queries, err)
+	}
+	if searches, err := NewSavedSearchRepository(conn, current).FindByUser(ctx, 1); err != nil || len(searches) != 1 || searches[0].Name != "bugs" || searches[0].Query != "#bug" {
+		t.Errorf("FindByUser after rotation: %v, %v", searches, err)
+	}
+}
@@ -0,0 +1,245 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"memory-bot/pkg/encryption"
+)
+
+// keyCheckSamples is the number of stored values per table tried against the keys
+// of a database that has no key check yet
+const keyCheckSamples = 20
+
+// LoadKeyring derives the encryption keys from the configured passphrases and checks
+// them against the database, so a wrong ENCRYPTION_KEY stops the bot at startup
+// instead of writing new rows under a key the stored data can't be read with.
+//
+// Passphrases are stretched with Argon2id under a salt stored in encryption_metadata,
+// together with a key check value written with the primary key on first use. A new
+// primary key is accepted when a previous key passes the check (key rotation).
+// Databases written with the older SHA-256 keys are verified against stored
+// ciphertext instead; the SHA-256 keys then stay in the keyring as previous keys
+// until the key rotation job has re-encrypted everything with the Argon2id key.
+// Once the job has finished with the primary key, values without an envelope are
+// rejected as malformed instead of being read as plaintext.
+//
+// Returns nil without a passphrase, and ErrWrongKey if the keys don't match.
+func LoadKeyring(ctx context.Context, conn *Connection, passphrase string, previous []string) (*encryption.Encryptor, error) {
+	metadata, err := encryptionMetadata(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	check := metadata["key_check"]
+	if passphrase == "" {
+		if check != "" {
+			return nil, fmt.Errorf("%w: the database is encrypted but ENCRYPTION_KEY is not set", encryption.ErrWrongKey)
+		}
+		return nil, nil
+	}
+
+	var params encryption.KDFParams
+	if metadata["kdf"] == "" {
+		params, err = encryption.NewKDFParams()
+	} else {
+		params, err = encryption.ParseKDFParams(metadata["kdf"])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	primary := encryption.DeriveKey(passphrase, params)
+	var previousKeys, legacyKeys []*encryption.Key
+	for _, secret := range previous {
+		previousKeys = append(previousKeys, encryption.DeriveKey(secret, params))
+	}
+	for _, secret := range append([]string{passphrase}, previous...) {
+		legacyKeys = append(legacyKeys, encryption.LegacyKey(secret))
+	}
+
+	updates := make(map[string]string)
+	legacy := metadata["legacy_keys"] != ""
+
+	switch {
+	case check == "":
+		// First start with key derivation: the stored data must be readable
+		samples, err := storedCiphertext(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		if len(samples) > 0 {
+			if !decryptsAny(encryption.NewKeyring(primary, append(previousKeys, legacyKeys...)...), samples) {
+				return nil, fmt.Errorf("%w: none of the configured keys decrypts the stored data", encryption.ErrWrongKey)
+			}
+			legacy = decryptsAny(encryption.NewKeyring(legacyKeys[0], legacyKeys[1:]...), samples)
+		}
+		updates["kdf"] = params.String()
+
+	case primary.Verifies(check):
+		// The key the database was set up with
+
+	default:
+		rotated := false
+		for _, k := range previousKeys {
+			rotated = rotated || k.Verifies(check)
+		}
+		if !rotated {
+			return nil, fmt.Errorf("%w: ENCRYPTION_KEY is not the key this database was set up with", encryption.ErrWrongKey)
+		}
+		log.Printf("🔑 New primary encryption key %s", primary.ID())
+	}
+
+	if check == "" || !primary.Verifies(check) {
+		if updates["key_check"], err = primary.KeyCheck(); err != nil {
+			return nil, fmt.Errorf("failed to create key check: %w", err)
+		}
+	}
+
+	// Once every row is re-encrypted with the primary key, a value without an envelope
+	// can't be plaintext or legacy ciphertext of ours
+	done, err := rotationComplete(ctx, conn, primary.ID())
+	if err != nil {
+		return nil, err
+	}
+	unenveloped := encryption.UnenvelopedPlaintext
+	if done {
+		unenveloped = encryption.UnenvelopedRejected
+	}
+	if legacy {
+		if done {
+			log.Println("🔑 Stored data no longer uses SHA-256 derived keys")
+			updates["legacy_keys"] = ""
+		} else {
+			log.Printf("🔑 Stored data uses SHA-256 derived keys; it is re-encrypted with the Argon2id key %s", primary.ID())
+			updates["legacy_keys"] = "1"
+			previousKeys = append(previousKeys, legacyKeys...)
+			unenveloped = encryption.UnenvelopedLegacy
+		}
+	}
+
+	if err := saveEncryptionMetadata(ctx, conn, metadata, updates); err != nil {
+		return nil, err
+	}
+	return encryption.NewKeyring(primary, previousKeys...).ReadUnenveloped(unenveloped), nil
+}
+
+// encryptionMetadata loads the encryption_metadata name/value pairs
+func encryptionMetadata(ctx context.Context, conn *Connection) (map[string]string, error) {
+	rows, err := conn.DB.QueryContext(ctx, "SELECT name, value FROM encryption_metadata")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption metadata: %w", err)
+	}
+	defer rows.Close()
+
+	metadata := make(map[string]string)
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		metadata[name] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return metadata, nil
+}
+
+// saveEncryptionMetadata stores the changed values; an empty value removes the entry
+func saveEncryptionMetadata(ctx context.Context, conn *Connection, current, updates map[string]string) error {
+	tx, err := conn.beginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin encryption metadata update: %w", err)
+	}
+	defer tx.Rollback()
+
+	for name, value := range updates {
+		if value == current[name] {
+			continue
+		}
+		if value == "" {
+			_, err = tx.ExecContext(ctx, "DELETE FROM encryption_metadata WHERE name = ?", name)
+		} else {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO encryption_metadata (name, value) VALUES (?, ?)
+				ON CONFLICT(name) DO UPDATE SET value = excluded.value
+			`, name, value)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to store encryption metadata %s: %w", name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit encryption metadata: %w", err)
+	}
+	return nil
+}
+
+// storedValue is a stored value with the associated data it was encrypted with
+type storedValue struct {
+	value, aad string
+}
+
+// storedCiphertext returns recent stored values that look encrypted
+func storedCiphertext(ctx context.Context, conn *Connection) ([]storedValue, error) {
+	var samples []storedValue
+	for _, query := range []string{
+		"SELECT id, user_id, text_content, 'memory' FROM memories WHERE vaulted = 0 ORDER BY id DESC LIMIT ?",
+		"SELECT id, user_id, query_text, 'query' FROM query_history ORDER BY id DESC LIMIT ?",
+	} {
+		rows, err := conn.DB.QueryContext(ctx, query, keyCheckSamples)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stored values: %w", err)
+		}
+		for rows.Next() {
+			var id int
+			var userID int64
+			var value, kind string
+			if err := rows.Scan(&id, &userID, &value, &kind); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan row: %w", err)
+			}
+			if !encryption.LooksEncrypted(value) {
+				continue
+			}
+			sample := storedValue{value: value, aad: memoryBinding(userID, id)}
+			if kind == "query" {
+				sample.aad = queryBinding(userID)
+			}
+			samples = append(samples, sample)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating rows: %w", err)
+		}
+	}
+	return samples, nil
+}
+
+// decryptsAny reports whether the keyring decrypts at least one of the values
+// Some values may be plaintext that happens to look like base64, so one is enough.
+func decryptsAny(keyring *encryption.Encryptor, values []storedValue) bool {
+	for _, v := range values {
+		if _, err := keyring.Decrypt(v.value, v.aad); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// rotationComplete reports whether the key rotation job finished every table with keyID
+func rotationComplete(ctx context.Context, conn *Connection, keyID string) (bool, error) {
+	var completed int
+	if err := conn.DB.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM key_rotation WHERE key_id = ? AND format = ? AND completed = 1",
+		keyID, encryption.EnvelopeVersion,
+	).Scan(&completed); err != nil {
+		return false, fmt.Errorf("failed to read key rotation checkpoints: %w", err)
+	}
+	return completed == len(rotationTables), nil
+}
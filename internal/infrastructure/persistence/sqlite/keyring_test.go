@@ -0,0 +1,97 @@
+//go:build fts5
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/encryption"
+)
+
+func TestLoadKeyring(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestConnection(t)
+
+	// Data written with the SHA-256 key of an older version
+	legacy := encryption.NewEncryptor("passphrase")
+	if _, err := NewMemoryRepository(conn, legacy, AllFields()).Save(ctx, entity.NewMemory(1, 1, "Budget review #work")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	if _, err := LoadKeyring(ctx, conn, "wrong", nil); !errors.Is(err, encryption.ErrWrongKey) {
+		t.Errorf("wrong key on legacy data: %v", err)
+	}
+
+	keyring, err := LoadKeyring(ctx, conn, "passphrase", nil)
+	if err != nil {
+		t.Fatalf("LoadKeyring: %v", err)
+	}
+	ids := keyring.KeyIDs()
+	if len(ids) != 2 || ids[1] != legacy.KeyID() {
+		t.Fatalf("legacy key should be a previous key: %v", ids)
+	}
+
+	// While legacy ciphertext is stored, base64 that no key decrypts is not passed through
+	forged, err := NewMemoryRepository(conn, keyring, AllFields()).Save(ctx, entity.NewMemory(1, 1, "Forged"))
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := conn.DB.Exec("UPDATE memories SET text_content = ? WHERE id = ?", "QmFzZTY0IGxvb2tpbmcgcGxhaW50ZXh0IG1lbW9yeQ==", forged); err != nil {
+		t.Fatalf("forge: %v", err)
+	}
+	if _, err := NewMemoryRepository(conn, keyring, AllFields()).FindByID(ctx, int(forged)); !errors.Is(err, encryption.ErrAuthentication) {
+		t.Errorf("undecryptable legacy value: %v", err)
+	}
+	if _, err := conn.DB.Exec("DELETE FROM memories WHERE id = ?", forged); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	// The rotation job moves the data to the derived key, after which the legacy key is dropped
+	rotation := NewKeyRotationRepository(conn, keyring, AllFields())
+	for progress := (&entity.KeyRotationProgress{}); !progress.Complete; {
+		if progress, err = rotation.ReencryptBatch(ctx, 100); err != nil {
+			t.Fatalf("ReencryptBatch: %v", err)
+		}
+	}
+	if keyring, err = LoadKeyring(ctx, conn, "passphrase", nil); err != nil || len(keyring.KeyIDs()) != 1 {
+		t.Fatalf("after rotation: %v, %v", keyring.KeyIDs(), err)
+	}
+	memory, err := NewMemoryRepository(conn, keyring, AllFields()).FindByID(ctx, 1)
+	if err != nil || memory.Content != "Budget review #work" {
+		t.Errorf("FindByID: %v, %v", memory, err)
+	}
+
+	// Every row is enveloped now, so a value without an envelope is rejected
+	var stored string
+	if err := conn.DB.QueryRow("SELECT text_content FROM memories WHERE id = 1").Scan(&stored); err != nil {
+		t.Fatalf("read memory: %v", err)
+	}
+	if _, err := conn.DB.Exec("UPDATE memories SET text_content = 'Planted' WHERE id = 1"); err != nil {
+		t.Fatalf("plant: %v", err)
+	}
+	if _, err := NewMemoryRepository(conn, keyring, AllFields()).FindByID(ctx, 1); !errors.Is(err, encryption.ErrMalformed) {
+		t.Errorf("value without an envelope: %v", err)
+	}
+	if _, err := conn.DB.Exec("UPDATE memories SET text_content = ? WHERE id = 1", stored); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	// The key check now rejects any other key, and a missing one
+	if _, err := LoadKeyring(ctx, conn, "wrong", nil); !errors.Is(err, encryption.ErrWrongKey) {
+		t.Errorf("wrong key: %v", err)
+	}
+	if _, err := LoadKeyring(ctx, conn, "", nil); !errors.Is(err, encryption.ErrWrongKey) {
+		t.Errorf("missing key: %v", err)
+	}
+
+	// A new key is accepted while the old one is configured as a previous key
+	if keyring, err = LoadKeyring(ctx, conn, "new passphrase", []string{"passphrase"}); err != nil || len(keyring.KeyIDs()) != 2 {
+		t.Fatalf("rotation to a new key: %v, %v", keyring, err)
+	}
+	if _, err := LoadKeyring(ctx, conn, "passphrase", nil); !errors.Is(err, encryption.ErrWrongKey) {
+		t.Errorf("old key after rotation: %v", err)
+	}
+}
@@ -3,26 +3,48 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"memory-bot/internal/domain/entity"
 	"memory-bot/internal/domain/repository"
+	"memory-bot/internal/domain/service"
 	"memory-bot/pkg/encryption"
 )
 
+const (
+	// relatedTermCount is the number of distinctive content terms used for related search
+	relatedTermCount = 8
+
+	// relatedTagCount is the number of distinctive tags used for related search
+	relatedTagCount = 3
+
+	// sqliteTimeLayout is the UTC timestamp format understood by SQLite date functions
+	sqliteTimeLayout = "2006-01-02 15:04:05"
+)
+
 // MemoryRepository is the SQLite implementation of repository.MemoryRepository
 type MemoryRepository struct {
-	conn      *Connection
-	encryptor *encryption.Encryptor
+	conn             *Connection
+	encryptor        *encryption.Encryptor
+	blindIndex       *encryption.BlindIndex // nil when encryption is disabled
+	fields           *fieldCipher
+	keywordExtractor *service.KeywordExtractor
+	vault            *encryption.VaultSessions // nil when personal vaults are disabled
 }
 
 // NewMemoryRepository creates a new SQLite memory repository
-func NewMemoryRepository(conn *Connection, encryptor *encryption.Encryptor) *MemoryRepository {
+// policy selects the fields encrypted next to the content (nil for none)
+func NewMemoryRepository(conn *Connection, encryptor *encryption.Encryptor, policy FieldPolicy) *MemoryRepository {
 	return &MemoryRepository{
-		conn:      conn,
-		encryptor: encryptor,
+		conn:             conn,
+		encryptor:        encryptor,
+		blindIndex:       encryption.NewBlindIndexIfEnabled(encryptor),
+		fields:           newFieldCipher(encryptor, policy),
+		keywordExtractor: service.NewKeywordExtractor(),
 	}
 }
 
@@ -32,47 +54,84 @@ func (r *MemoryRepository) Save(ctx context.Context, memory *entity.Memory) (int
 		return 0, err
 	}
 
+	tx, err := r.conn.beginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := resolveMemoryTags(ctx, tx, r.fields, memory); err != nil {
+		return 0, err
+	}
+
+	// Encrypted values are bound to the memory ID, so it is chosen before the insert
+	// (the transaction holds the write lock)
+	id, err := nextMemoryID(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	memory.Vaulted = r.vaultUnlocked(memory.UserID)
+	bound := *memory
+	bound.ID = int(id)
+
 	// Encrypt content before storing
-	encryptedContent, err := encryption.EncryptIfEnabled(r.encryptor, memory.Content)
+	encryptedContent, searchTokens, err := r.sealContent(&bound)
+	if err != nil {
+		return 0, err
+	}
+
+	sealed, err := r.fields.sealMemory(&bound)
 	if err != nil {
-		return 0, fmt.Errorf("failed to encrypt content: %w", err)
+		return 0, err
 	}
 
-	stmt, err := r.conn.DB.PrepareContext(ctx, `
+	stmt, err := tx.PrepareContext(ctx, `
 		INSERT INTO memories (
-			user_id, chat_id, text_content, search_content, tags, created_at,
-			last_consolidated, priority_score, emotional_weight,
-			time_of_day, day_of_week, chat_source, parent_id
+			id, user_id, chat_id, text_content, search_tokens, tags, tag_tokens, created_at,
+			last_consolidated, priority_score, emotional_weight, sealed_weight,
+			time_of_day, day_of_week, chat_source, parent_id, source_message_id, vaulted, secret
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return 0, fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer stmt.Close()
 
-	result, err := stmt.ExecContext(ctx,
+	_, err = stmt.ExecContext(ctx,
+		id,
 		memory.UserID,
 		memory.ChatID,
 		encryptedContent, // Encrypted version
-		memory.Content,   // Plain text for FTS5 searching
-		memory.GetTagsString(),
+		searchTokens,     // Blind index tokens (never plaintext next to ciphertext)
+		sealed.tags,
+		sealed.tagTokens,
 		memory.CreatedAt,
 		memory.LastConsolidated,
 		memory.PriorityScore,
-		memory.EmotionalWeight,
-		memory.TimeOfDay,
-		memory.DayOfWeek,
-		memory.ChatSource,
+		sealed.weight,
+		sealed.sealedWeight,
+		sealed.timeOfDay,
+		sealed.dayOfWeek,
+		sealed.chatSource,
 		memory.ParentID,
+		sourceMessageID(memory),
+		memory.Vaulted,
+		memory.Secret,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("failed to save memory: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
+	// The tags of vaulted memories stay out of the tag index, which only the server key opens
+	if !memory.Vaulted {
+		if err := writeMemoryTags(ctx, tx, r.fields, int(id), memory.UserID, memory.Tags); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit memory: %w", err)
 	}
 
 	log.Printf("Memory saved: ID=%d, UserID=%d, EmotionalWeight=%.2f, Context=%s %s",
@@ -80,34 +139,52 @@ func (r *MemoryRepository) Save(ctx context.Context, memory *entity.Memory) (int
 	return id, nil
 }
 
+// nextMemoryID returns the ID the next inserted memory gets
+// AUTOINCREMENT never reuses the IDs of deleted memories, so sqlite_sequence is consulted too.
+func nextMemoryID(ctx context.Context, q dbtx) (int64, error) {
+	var id int64
+	err := q.QueryRowContext(ctx, `
+		SELECT MAX(
+			COALESCE((SELECT seq FROM sqlite_sequence WHERE name = 'memories'), 0),
+			COALESCE((SELECT MAX(id) FROM memories), 0)
+		) + 1
+	`).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate memory ID: %w", err)
+	}
+	return id, nil
+}
+
 // FindByID retrieves a memory by its ID
 func (r *MemoryRepository) FindByID(ctx context.Context, id int) (*entity.Memory, error) {
 	query := `
-		SELECT id, user_id, chat_id, text_content, tags, 
+		SELECT id, user_id, chat_id, text_content, vaulted, secret, tags, 
 		       created_at, last_reviewed, review_count,
-		       last_consolidated, priority_score, emotional_weight,
+		       last_consolidated, priority_score, COALESCE(sealed_weight, emotional_weight),
 		       time_of_day, day_of_week, chat_source, parent_id
 		FROM memories
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	var m entity.Memory
-	var tags string
+	var tags, weight string
 	var lastReviewed sql.NullTime
 	var parentID sql.NullInt64
 
-	err := r.conn.DB.QueryRowContext(ctx, query, id).Scan(
+	err := r.conn.queryRow(ctx, query, id).Scan(
 		&m.ID,
 		&m.UserID,
 		&m.ChatID,
 		&m.Content,
+		&m.Vaulted,
+		&m.Secret,
 		&tags,
 		&m.CreatedAt,
 		&lastReviewed,
 		&m.ReviewCount,
 		&m.LastConsolidated,
 		&m.PriorityScore,
-		&m.EmotionalWeight,
+		&weight,
 		&m.TimeOfDay,
 		&m.DayOfWeek,
 		&m.ChatSource,
@@ -121,24 +198,39 @@ func (r *MemoryRepository) FindByID(ctx context.Context, id int) (*entity.Memory
 		return nil, fmt.Errorf("failed to find memory: %w", err)
 	}
 
-	m.Tags = strings.Fields(tags)
+	if err := r.fields.openMemory(&m, tags, weight); err != nil {
+		return nil, err
+	}
 	if lastReviewed.Valid {
 		m.LastReviewed = &lastReviewed.Time
 	}
+	if parentID.Valid {
+		m.ParentID = &parentID.Int64
+	}
 
 	// Decrypt content after reading
-	decryptedContent, err := encryption.DecryptIfEnabled(r.encryptor, m.Content)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt content: %w", err)
+	if err := r.openContent(&m); err != nil {
+		return nil, err
 	}
-	m.Content = decryptedContent
 
 	return &m, nil
 }
 
 // Search performs FTS5 search with ranking and optional contextual filtering
-func (r *MemoryRepository) Search(ctx context.Context, userID int64, query string, opts repository.SearchOptions) ([]*entity.Memory, error) {
-	searchTerm := prepareFTS5SearchTerm(query)
+func (r *MemoryRepository) Search(ctx context.Context, userID int64, query string, opts repository.SearchOptions) (*repository.MemoryPage, error) {
+	position, err := opts.Cursor.Position(repository.ListingSearch, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	synonyms, err := loadSynonymExpander(ctx, r.conn.reader(ctx), userID)
+	if err != nil {
+		return nil, err
+	}
+	searchTerm := r.matchExpression(userID, prepareFTS5SearchTerm(query, synonyms))
+	if opts.ExactPhrase {
+		searchTerm = r.matchExpression(userID, quoteFTS5Term(strings.TrimSpace(query)))
+	}
 
 	// Build dynamic SQL query with advanced ranking
 	// Ranking factors: BM25 score + emotional weight + priority score + recency
@@ -148,11 +240,13 @@ func (r *MemoryRepository) Search(ctx context.Context, userID int64, query strin
 			m.user_id,
 			m.chat_id,
 			m.text_content,
+			m.vaulted,
+			m.secret,
 			m.tags,
 			m.created_at,
 			m.last_reviewed,
 			m.review_count,
-			m.emotional_weight,
+			COALESCE(m.sealed_weight, m.emotional_weight),
 			m.priority_score,
 			memories_fts.rank as rank,
 			(
@@ -171,29 +265,21 @@ func (r *MemoryRepository) Search(ctx context.Context, userID int64, query strin
 			memories_fts ON m.id = memories_fts.rowid
 		WHERE 
 			m.user_id = ? AND 
+			m.deleted_at IS NULL AND
 			memories_fts MATCH ?`
 
 	args := []interface{}{userID, searchTerm}
 
 	// Apply contextual filtering directly in SQL for better performance
-	if opts.ContextFilter != nil {
-		if opts.ContextFilter.TimeOfDay != "" {
-			sqlQuery += " AND m.time_of_day = ?"
-			args = append(args, opts.ContextFilter.TimeOfDay)
-			log.Printf("Search: Applying TimeOfDay filter: %s", opts.ContextFilter.TimeOfDay)
-		}
-		if opts.ContextFilter.DayOfWeek != "" {
-			sqlQuery += " AND m.day_of_week = ?"
-			args = append(args, opts.ContextFilter.DayOfWeek)
-			log.Printf("Search: Applying DayOfWeek filter: %s", opts.ContextFilter.DayOfWeek)
-		}
+	sqlQuery, args, err = r.contextFilter(sqlQuery, args, userID, opts.ContextFilter)
+	if err != nil {
+		return nil, err
 	}
 
 	// Order by combined ranking (BM25 + emotional + priority + recency)
-	sqlQuery += ` ORDER BY combined_rank DESC LIMIT ? OFFSET ?`
-	args = append(args, opts.Limit, opts.Offset)
+	sqlQuery, args = pageQuery(sqlQuery, args, []string{"combined_rank"}, false, position, opts.Limit)
 
-	rows, err := r.conn.DB.QueryContext(ctx, sqlQuery, args...)
+	rows, err := r.conn.query(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search memories: %w", err)
 	}
@@ -201,17 +287,120 @@ func (r *MemoryRepository) Search(ctx context.Context, userID int64, query strin
 
 	memories := []*entity.Memory{}
 	for rows.Next() {
-		m, err := scanMemoryRow(rows)
+		m, err := scanMemoryRow(r.fields, rows)
 		if err != nil {
 			return nil, err
 		}
 
 		// Decrypt content after reading
-		decryptedContent, err := encryption.DecryptIfEnabled(r.encryptor, m.Content)
+		if err := r.openListed(m); err != nil {
+			return nil, err
+		}
+
+		memories = append(memories, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	page := repository.NewMemoryPage(repository.ListingSearch, memories, opts.Limit, func(i int) []float64 {
+		return []float64{memories[i].Rank}
+	})
+	log.Printf("Found %d memories for user %d with keyword '%s'", len(page.Memories), userID, query)
+	return page, nil
+}
+
+// SearchByTag retrieves memories tagged with tag or one of its sub-tags, newest first
+// The tag is resolved through the user's aliases before matching. Vaulted memories
+// are not in the tag index; they are found by their blind tag tokens while the vault
+// is unlocked, and token matches that turn out to be other tags are dropped after
+// decryption (the page then comes out short).
+func (r *MemoryRepository) SearchByTag(ctx context.Context, userID int64, tag string, opts repository.SearchOptions) (*repository.MemoryPage, error) {
+	position, err := opts.Cursor.Position(repository.ListingTag, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases, err := loadTagAliases(ctx, r.conn.reader(ctx), r.fields, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tag = entity.ResolveTagAlias(entity.NormalizeTag(tag), aliases)
+	if tag == "" {
+		return nil, entity.ErrInvalidTag
+	}
+	matches, err := tagsBelow(ctx, r.conn.reader(ctx), r.fields, userID, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `
+		SELECT
+			m.id,
+			m.user_id,
+			m.chat_id,
+			m.text_content,
+			m.vaulted,
+			m.secret,
+			m.tags,
+			m.created_at,
+			m.last_reviewed,
+			m.review_count,
+			COALESCE(m.sealed_weight, m.emotional_weight),
+			m.priority_score,
+			0.0 as rank,
+			(m.emotional_weight * 2.0) + (m.priority_score * 1.5) as combined_rank,
+			julianday(m.created_at) as created_day
+		FROM
+			memories AS m
+		WHERE
+			m.user_id = ? AND
+			m.deleted_at IS NULL AND
+			(m.id IN (
+				SELECT memory_id FROM memory_tags
+				WHERE user_id = ? AND tag IN ` + placeholders(len(matches)) + `
+			)`
+
+	args := append([]interface{}{userID, userID}, matches.args()...)
+	if vaultMatch := r.vaultTagMatch(userID, tag); vaultMatch != "" {
+		sqlQuery += ` OR m.vaulted = 1 AND m.id IN (
+				SELECT rowid FROM memories_fts WHERE memories_fts MATCH ?
+			)`
+		args = append(args, vaultMatch)
+	}
+	sqlQuery += ")"
+
+	sqlQuery, args, err = r.contextFilter(sqlQuery, args, userID, opts.ContextFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery, args = pageQuery(sqlQuery, args, []string{"created_day"}, false, position, opts.Limit)
+
+	rows, err := r.conn.query(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search memories by tag: %w", err)
+	}
+	defer rows.Close()
+
+	memories := []*entity.Memory{}
+	var days []float64
+	for rows.Next() {
+		var day float64
+		m, err := scanMemoryRow(r.fields, rows, &day)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt content: %w", err)
+			return nil, err
+		}
+		if m.Vaulted && !hasTagBelow(m.Tags, tag) {
+			continue
+		}
+		days = append(days, day)
+
+		if err := r.openListed(m); err != nil {
+			return nil, err
 		}
-		m.Content = decryptedContent
 
 		memories = append(memories, m)
 	}
@@ -220,47 +409,328 @@ func (r *MemoryRepository) Search(ctx context.Context, userID int64, query strin
 		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
-	log.Printf("Found %d memories for user %d with keyword '%s'", len(memories), userID, query)
-	return memories, nil
+	page := repository.NewMemoryPage(repository.ListingTag, memories, opts.Limit, func(i int) []float64 {
+		return []float64{days[i]}
+	})
+	log.Printf("Found %d memories for user %d with tag '%s'", len(page.Memories), userID, tag)
+	return page, nil
+}
+
+// FindByDateRange retrieves memories created in [from, to), newest first
+// Timestamps are compared as julian days, so stored time zone offsets are respected
+func (r *MemoryRepository) FindByDateRange(ctx context.Context, userID int64, from, to time.Time, opts repository.SearchOptions) (*repository.MemoryPage, error) {
+	position, err := opts.Cursor.Position(repository.ListingDate, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `
+		SELECT
+			m.id,
+			m.user_id,
+			m.chat_id,
+			m.text_content,
+			m.vaulted,
+			m.secret,
+			m.tags,
+			m.created_at,
+			m.last_reviewed,
+			m.review_count,
+			COALESCE(m.sealed_weight, m.emotional_weight),
+			m.priority_score,
+			0.0 as rank,
+			(m.emotional_weight * 2.0) + (m.priority_score * 1.5) as combined_rank,
+			julianday(m.created_at) as created_day
+		FROM
+			memories AS m
+		WHERE
+			m.user_id = ? AND
+			m.deleted_at IS NULL AND
+			julianday(m.created_at) >= julianday(?) AND
+			julianday(m.created_at) < julianday(?)`
+
+	sqlQuery, args := pageQuery(sqlQuery,
+		[]interface{}{userID, from.UTC().Format(sqliteTimeLayout), to.UTC().Format(sqliteTimeLayout)},
+		[]string{"created_day"}, false, position, opts.Limit)
+
+	rows, err := r.conn.query(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find memories by date: %w", err)
+	}
+	defer rows.Close()
+
+	memories := []*entity.Memory{}
+	var days []float64
+	for rows.Next() {
+		var day float64
+		m, err := scanMemoryRow(r.fields, rows, &day)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+
+		if err := r.openListed(m); err != nil {
+			return nil, err
+		}
+
+		memories = append(memories, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	page := repository.NewMemoryPage(repository.ListingDate, memories, opts.Limit, func(i int) []float64 {
+		return []float64{days[i]}
+	})
+	log.Printf("Found %d memories for user %d between %s and %s", len(page.Memories), userID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	return page, nil
 }
 
-// GetRecent retrieves the most recent memories for a user
-func (r *MemoryRepository) GetRecent(ctx context.Context, userID int64, limit int) ([]*entity.Memory, error) {
+// MatchesQuery reports whether a single memory matches the FTS5 query
+// Uses the same term preparation as Search so watches behave like /search
+func (r *MemoryRepository) MatchesQuery(ctx context.Context, memoryID int, userID int64, query string) (bool, error) {
+	synonyms, err := loadSynonymExpander(ctx, r.conn.reader(ctx), userID)
+	if err != nil {
+		return false, err
+	}
+
+	searchTerm := prepareFTS5SearchTerm(query, synonyms)
+	if searchTerm == "" {
+		return false, nil
+	}
+	searchTerm = r.matchExpression(userID, searchTerm)
+
+	var count int
+	err = r.conn.queryRow(ctx, `
+		SELECT COUNT(*)
+		FROM memories AS m
+		JOIN memories_fts ON m.id = memories_fts.rowid
+		WHERE m.id = ? AND m.user_id = ? AND m.deleted_at IS NULL AND memories_fts MATCH ?
+	`, memoryID, userID, searchTerm).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to match memory: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// ExplainQuery describes the full-text expression Search would use for the query
+// The expression is shown before the blind index rewrite so it stays readable
+func (r *MemoryRepository) ExplainQuery(ctx context.Context, userID int64, query string) (*entity.SearchExplanation, error) {
+	synonyms, err := loadSynonymExpander(ctx, r.conn.reader(ctx), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &entity.SearchExplanation{
+		Expression: prepareFTS5SearchTerm(query, synonyms),
+		Expansions: make(map[string][]string),
+		BlindIndex: r.blindIndex != nil,
+	}
+
+	terms := strings.Fields(query)
+	if len(terms) > 1 {
+		terms = append(terms, query)
+	}
+	for _, term := range terms {
+		if strings.HasPrefix(term, "#") {
+			continue
+		}
+		// Only report expansions that made it into the expression
+		alternatives := synonyms.Synonyms(term)
+		if len(alternatives) > 0 && strings.Contains(explanation.Expression, " OR "+quoteFTS5Term(alternatives[0])+"*") {
+			explanation.Expansions[entity.NormalizeSynonymTerm(term)] = alternatives
+		}
+	}
+
+	return explanation, nil
+}
+
+// FindRelated finds memories similar to the source memory
+// The source's most distinctive terms and tags (TF-IDF against the user's corpus)
+// are combined into an OR query ranked by BM25
+func (r *MemoryRepository) FindRelated(ctx context.Context, userID int64, memoryID int, limit int) ([]*entity.Memory, error) {
+	source, err := r.FindByID(ctx, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	if source.UserID != userID {
+		return nil, entity.ErrUnauthorized
+	}
+	if source.Locked {
+		return nil, entity.ErrVaultLocked
+	}
+
+	// Build the user's corpus for document frequencies (locked vault memories are left out)
+	rows, err := r.conn.query(ctx, `
+		SELECT id, text_content, vaulted, tags FROM memories WHERE user_id = ? AND deleted_at IS NULL
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load corpus: %w", err)
+	}
+
+	var corpus, tagCorpus [][]string
+	for rows.Next() {
+		var id int
+		var content, tags string
+		var vaulted bool
+		if err := rows.Scan(&id, &content, &vaulted, &tags); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		decryptedContent, err := r.openStoredContent(userID, id, vaulted, content)
+		if errors.Is(err, entity.ErrVaultLocked) {
+			continue
+		}
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to decrypt content: %w", err)
+		}
+		memoryTags, err := r.fields.openMemoryTags(userID, id, vaulted, tags)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		corpus = append(corpus, r.keywordExtractor.Tokenize(decryptedContent))
+		tagCorpus = append(tagCorpus, strings.Fields(strings.ToLower(strings.Join(memoryTags, " "))))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	sourceTags := make([]string, len(source.Tags))
+	for i, tag := range source.Tags {
+		sourceTags[i] = strings.ToLower(tag)
+	}
+
+	terms := r.keywordExtractor.TopTerms(
+		r.keywordExtractor.Tokenize(source.Content),
+		r.keywordExtractor.DocumentFrequencies(corpus), len(corpus), relatedTermCount)
+	tags := r.keywordExtractor.TopTerms(
+		sourceTags,
+		r.keywordExtractor.DocumentFrequencies(tagCorpus), len(tagCorpus), relatedTagCount)
+
+	var clauses []string
+	for _, term := range terms {
+		clauses = append(clauses, quoteFTS5Term(term))
+	}
+	for _, tag := range tags {
+		clauses = append(clauses, "tags : "+quoteFTS5Term(tag))
+	}
+
+	if len(clauses) == 0 {
+		return []*entity.Memory{}, nil
+	}
+
+	// BM25 rank is negative (lower is better), so order ascending
 	query := `
+		SELECT
+			m.id,
+			m.user_id,
+			m.chat_id,
+			m.text_content,
+			m.vaulted,
+			m.secret,
+			m.tags,
+			m.created_at,
+			m.last_reviewed,
+			m.review_count,
+			COALESCE(m.sealed_weight, m.emotional_weight),
+			m.priority_score,
+			memories_fts.rank as rank,
+			-memories_fts.rank as combined_rank
+		FROM
+			memories AS m
+		JOIN
+			memories_fts ON m.id = memories_fts.rowid
+		WHERE
+			m.user_id = ? AND
+			m.id != ? AND
+			m.deleted_at IS NULL AND
+			memories_fts MATCH ?
+		ORDER BY memories_fts.rank
+		LIMIT ?`
+
+	matchExpr := r.matchExpression(userID, strings.Join(clauses, " OR "))
+	relatedRows, err := r.conn.query(ctx, query, userID, memoryID, matchExpr, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find related memories: %w", err)
+	}
+	defer relatedRows.Close()
+
+	memories := []*entity.Memory{}
+	for relatedRows.Next() {
+		m, err := scanMemoryRow(r.fields, relatedRows)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := r.openListed(m); err != nil {
+			return nil, err
+		}
+
+		memories = append(memories, m)
+	}
+
+	if err := relatedRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	log.Printf("Found %d related memories for memory %d using %d terms and %d tags",
+		len(memories), memoryID, len(terms), len(tags))
+	return memories, nil
+}
+
+// GetRecent retrieves the most recent memories for a user, newest first
+func (r *MemoryRepository) GetRecent(ctx context.Context, userID int64, opts repository.PageOptions) (*repository.MemoryPage, error) {
+	position, err := opts.Cursor.Position(repository.ListingRecent, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := pageQuery(`
 		SELECT 
-			id, user_id, chat_id, text_content, tags, 
-			created_at, last_reviewed, review_count, parent_id
+			id, user_id, chat_id, text_content, vaulted, secret, tags, 
+			created_at, last_reviewed, review_count, parent_id,
+			julianday(created_at) as created_day
 		FROM memories
-		WHERE user_id = ?
-		ORDER BY created_at DESC
-		LIMIT ?
-	`
+		WHERE user_id = ? AND deleted_at IS NULL`,
+		[]interface{}{userID}, []string{"created_day"}, false, position, opts.Limit)
 
-	rows, err := r.conn.DB.QueryContext(ctx, query, userID, limit)
+	rows, err := r.conn.query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get recent memories: %w", err)
 	}
 	defer rows.Close()
 
-	memories, err := scanMemories(rows)
+	memories, days, err := scanKeyedMemories(r.fields, rows)
 	if err != nil {
 		return nil, err
 	}
 
 	// Decrypt content for each memory
 	for _, m := range memories {
-		decryptedContent, err := encryption.DecryptIfEnabled(r.encryptor, m.Content)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt content: %w", err)
+		if err := r.openListed(m); err != nil {
+			return nil, err
 		}
-		m.Content = decryptedContent
 	}
 
-	return memories, nil
+	return repository.NewMemoryPage(repository.ListingRecent, memories, opts.Limit, func(i int) []float64 {
+		return []float64{days[i]}
+	}), nil
 }
 
-// GetForReview retrieves memories that need review based on intervals
-func (r *MemoryRepository) GetForReview(ctx context.Context, intervals []int) ([]*entity.Memory, error) {
+// GetForReview retrieves memories that need review based on intervals, longest unreviewed first
+func (r *MemoryRepository) GetForReview(ctx context.Context, intervals []int, opts repository.PageOptions) (*repository.MemoryPage, error) {
+	position, err := opts.Cursor.Position(repository.ListingReview, 1)
+	if err != nil {
+		return nil, err
+	}
+
 	conditions := make([]string, 0, len(intervals))
 	args := make([]interface{}, 0, len(intervals))
 
@@ -269,37 +739,36 @@ func (r *MemoryRepository) GetForReview(ctx context.Context, intervals []int) ([
 		args = append(args, days)
 	}
 
-	query := fmt.Sprintf(`
+	query, args := pageQuery(fmt.Sprintf(`
 		SELECT 
-			id, user_id, chat_id, text_content, tags,
-			created_at, last_reviewed, review_count, parent_id
+			id, user_id, chat_id, text_content, vaulted, secret, tags,
+			created_at, last_reviewed, review_count, parent_id,
+			julianday(COALESCE(last_reviewed, created_at)) as reviewed_day
 		FROM memories
-		WHERE %s
-		ORDER BY COALESCE(last_reviewed, created_at) ASC
-		LIMIT 50
-	`, strings.Join(conditions, " OR "))
+		WHERE deleted_at IS NULL AND (%s)`, strings.Join(conditions, " OR ")),
+		args, []string{"reviewed_day"}, true, position, opts.Limit)
 
-	rows, err := r.conn.DB.QueryContext(ctx, query, args...)
+	rows, err := r.conn.query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get memories for review: %w", err)
 	}
 	defer rows.Close()
 
-	memories, err := scanMemories(rows)
+	memories, days, err := scanKeyedMemories(r.fields, rows)
 	if err != nil {
 		return nil, err
 	}
 
 	// Decrypt content for each memory
 	for _, m := range memories {
-		decryptedContent, err := encryption.DecryptIfEnabled(r.encryptor, m.Content)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt content: %w", err)
+		if err := r.openListed(m); err != nil {
+			return nil, err
 		}
-		m.Content = decryptedContent
 	}
 
-	return memories, nil
+	return repository.NewMemoryPage(repository.ListingReview, memories, opts.Limit, func(i int) []float64 {
+		return []float64{days[i]}
+	}), nil
 }
 
 // Update updates an existing memory
@@ -308,17 +777,11 @@ func (r *MemoryRepository) Update(ctx context.Context, memory *entity.Memory) er
 		return err
 	}
 
-	stmt, err := r.conn.DB.PrepareContext(ctx, `
+	_, err := r.conn.exec(ctx, `
 		UPDATE memories 
 		SET last_reviewed = ?, review_count = ?
 		WHERE id = ?
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
-	}
-	defer stmt.Close()
-
-	_, err = stmt.ExecContext(ctx, memory.LastReviewed, memory.ReviewCount, memory.ID)
+	`, memory.LastReviewed, memory.ReviewCount, memory.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update memory: %w", err)
 	}
@@ -326,66 +789,122 @@ func (r *MemoryRepository) Update(ctx context.Context, memory *entity.Memory) er
 	return nil
 }
 
-// Delete removes a memory with authorization check
-func (r *MemoryRepository) Delete(ctx context.Context, id int, userID int64) error {
-	stmt, err := r.conn.DB.PrepareContext(ctx, `
-		DELETE FROM memories 
-		WHERE id = ? AND user_id = ?
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
-	}
-	defer stmt.Close()
+// Count returns the total number of memories for a user
+func (r *MemoryRepository) Count(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.conn.queryRow(ctx,
+		"SELECT COUNT(*) FROM memories WHERE user_id = ? AND deleted_at IS NULL",
+		userID,
+	).Scan(&count)
 
-	result, err := stmt.ExecContext(ctx, id, userID)
 	if err != nil {
-		return fmt.Errorf("failed to delete memory: %w", err)
+		return 0, fmt.Errorf("failed to get memory count: %w", err)
 	}
+	return count, nil
+}
+
+// Helper functions
 
-	affected, err := result.RowsAffected()
+// resolveMemoryTags stores tags under their canonical names (#proj -> #work/projectx)
+func resolveMemoryTags(ctx context.Context, q queryer, fields *fieldCipher, memory *entity.Memory) error {
+	aliases, err := loadTagAliases(ctx, q, fields, memory.UserID)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return err
+	}
+	resolved := make([]string, len(memory.Tags))
+	for i, tag := range memory.Tags {
+		resolved[i] = entity.ResolveTagAlias(entity.NormalizeTag(tag), aliases)
 	}
+	memory.Tags = normalizeTags(resolved)
+	return nil
+}
 
-	if affected == 0 {
-		return entity.ErrUnauthorized
+// sourceMessageID returns the stored source_message_id (NULL when unknown)
+func sourceMessageID(memory *entity.Memory) interface{} {
+	if memory.SourceMessageID == 0 {
+		return nil
 	}
+	return memory.SourceMessageID
+}
 
-	log.Printf("Memory deleted: ID=%d, UserID=%d", id, userID)
-	return nil
+// searchTokens returns the value stored in search_tokens for the given content
+// With encryption enabled these are blind index tokens; otherwise the FTS5 index
+// reads the plaintext text_content directly and nil is stored
+func (r *MemoryRepository) searchTokens(content string) interface{} {
+	if r.blindIndex == nil {
+		return nil
+	}
+	return r.blindIndex.IndexText(content)
 }
 
-// Count returns the total number of memories for a user
-func (r *MemoryRepository) Count(ctx context.Context, userID int64) (int, error) {
-	var count int
-	err := r.conn.DB.QueryRowContext(ctx,
-		"SELECT COUNT(*) FROM memories WHERE user_id = ?",
-		userID,
-	).Scan(&count)
+// matchExpression adapts a prepared FTS5 expression to the index format
+// While the user's vault is unlocked, words also match the vault's blind tokens.
+func (r *MemoryRepository) matchExpression(userID int64, expr string) string {
+	_, vaultIndex := r.vault.Unlocked(userID)
+	blindTags := r.fields.encrypted(FieldTags)
+	switch {
+	case expr == "" || vaultIndex == nil && r.blindIndex == nil:
+		return expr
+	case vaultIndex == nil:
+		return rewriteBlindQuery(r.blindIndex, expr, blindTags)
+	case r.blindIndex != nil && blindTags:
+		return rewriteBlindQuery(r.blindIndex.With(vaultIndex), expr, true)
+	}
 
-	if err != nil {
-		return 0, fmt.Errorf("failed to get memory count: %w", err)
+	// Vaulted tags are always blind tokens while the server's are plaintext: one alternative each
+	server := expr
+	if r.blindIndex != nil {
+		server = rewriteBlindQuery(r.blindIndex, expr, false)
 	}
-	return count, nil
+	return unionExpression([]string{server, rewriteBlindQuery(vaultIndex, expr, true)})
 }
 
-// Helper functions
+// contextFilter appends the contextual filter conditions of a search
+// Encrypted context fields are compared by their deterministic ciphertext
+func (r *MemoryRepository) contextFilter(sqlQuery string, args []interface{}, userID int64, filter *service.ContextualData) (string, []interface{}, error) {
+	if filter == nil {
+		return sqlQuery, args, nil
+	}
+
+	for _, condition := range []struct {
+		field, value string
+	}{
+		{FieldTimeOfDay, filter.TimeOfDay},
+		{FieldDayOfWeek, filter.DayOfWeek},
+	} {
+		if condition.value == "" {
+			continue
+		}
+		stored, err := r.fields.sealLookup(condition.field, userID, condition.value)
+		if err != nil {
+			return "", nil, err
+		}
+		sqlQuery += " AND m." + condition.field + " IN " + placeholders(len(stored))
+		args = append(args, stored...)
+		log.Printf("Search: Applying %s filter: %s", condition.field, condition.value)
+	}
+
+	return sqlQuery, args, nil
+}
 
 // scanMemoryRow scans a single memory row with rank
-func scanMemoryRow(rows *sql.Rows) (*entity.Memory, error) {
+// Columns selected after combined_rank are scanned into extra
+func scanMemoryRow(fields *fieldCipher, rows *sql.Rows, extra ...interface{}) (*entity.Memory, error) {
 	var m entity.Memory
 	var tags string
 	var lastReviewed sql.NullTime
-	var emotionalWeight float64
+	var emotionalWeight string
 	var priorityScore float64
 	var rank float64
 	var combinedRank float64
 
-	err := rows.Scan(
+	dest := []interface{}{
 		&m.ID,
 		&m.UserID,
 		&m.ChatID,
 		&m.Content,
+		&m.Vaulted,
+		&m.Secret,
 		&tags,
 		&m.CreatedAt,
 		&lastReviewed,
@@ -394,67 +913,91 @@ func scanMemoryRow(rows *sql.Rows) (*entity.Memory, error) {
 		&priorityScore,
 		&rank,
 		&combinedRank,
-	)
-	if err != nil {
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
 		return nil, fmt.Errorf("failed to scan row: %w", err)
 	}
 
-	m.Tags = strings.Fields(tags)
+	aad := memoryBinding(m.UserID, m.ID)
+	var err error
+	if m.Tags, err = fields.openMemoryTags(m.UserID, m.ID, m.Vaulted, tags); err != nil && !listedUndecryptable(m.ID, err) {
+		return nil, fmt.Errorf("memory %d: %w", m.ID, err)
+	}
 	if lastReviewed.Valid {
 		m.LastReviewed = &lastReviewed.Time
 	}
-	m.EmotionalWeight = emotionalWeight
+	if m.EmotionalWeight, err = fields.openWeight(emotionalWeight, aad); err != nil && !listedUndecryptable(m.ID, err) {
+		return nil, fmt.Errorf("memory %d: %w", m.ID, err)
+	}
 	m.PriorityScore = priorityScore
 	m.Rank = combinedRank // Use combined rank for display
 
 	return &m, nil
 }
 
-// scanMemories scans multiple memory rows
-func scanMemories(rows *sql.Rows) ([]*entity.Memory, error) {
-	var memories []*entity.Memory
+// scanKeyedMemories scans memory rows followed by the sort key of a paged listing
+func scanKeyedMemories(fields *fieldCipher, rows *sql.Rows) ([]*entity.Memory, []float64, error) {
+	memories := []*entity.Memory{}
+	var keys []float64
 
 	for rows.Next() {
-		var m entity.Memory
-		var tags string
-		var lastReviewed sql.NullTime
-		var parentID sql.NullInt64
-
-		err := rows.Scan(
-			&m.ID,
-			&m.UserID,
-			&m.ChatID,
-			&m.Content,
-			&tags,
-			&m.CreatedAt,
-			&lastReviewed,
-			&m.ReviewCount,
-			&parentID,
-		)
+		var key float64
+		m, err := scanMemory(fields, rows, &key)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+			return nil, nil, err
 		}
+		memories = append(memories, m)
+		keys = append(keys, key)
+	}
 
-		m.Tags = strings.Fields(tags)
-		if lastReviewed.Valid {
-			m.LastReviewed = &lastReviewed.Time
-		}
-		if parentID.Valid {
-			m.ParentID = &parentID.Int64
-		}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating rows: %w", err)
+	}
 
-		memories = append(memories, &m)
+	return memories, keys, nil
+}
+
+// scanMemory scans one memory row of the short column list; extra receives trailing columns
+func scanMemory(fields *fieldCipher, rows *sql.Rows, extra ...interface{}) (*entity.Memory, error) {
+	var m entity.Memory
+	var tags string
+	var lastReviewed sql.NullTime
+	var parentID sql.NullInt64
+
+	dest := []interface{}{
+		&m.ID,
+		&m.UserID,
+		&m.ChatID,
+		&m.Content,
+		&m.Vaulted,
+		&m.Secret,
+		&tags,
+		&m.CreatedAt,
+		&lastReviewed,
+		&m.ReviewCount,
+		&parentID,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", err)
+	var err error
+	if m.Tags, err = fields.openMemoryTags(m.UserID, m.ID, m.Vaulted, tags); err != nil && !listedUndecryptable(m.ID, err) {
+		return nil, fmt.Errorf("memory %d: %w", m.ID, err)
+	}
+	if lastReviewed.Valid {
+		m.LastReviewed = &lastReviewed.Time
+	}
+	if parentID.Valid {
+		m.ParentID = &parentID.Int64
 	}
 
-	return memories, nil
+	return &m, nil
 }
 
 // prepareFTS5SearchTerm prepares a search term for FTS5 MATCH with wildcard support
-func prepareFTS5SearchTerm(keyword string) string {
+// Terms with synonyms become OR groups, e.g. k8s -> ("k8s"* OR "kubernetes"*)
+func prepareFTS5SearchTerm(keyword string, synonyms *service.SynonymExpander) string {
 	keyword = strings.TrimSpace(keyword)
 	if keyword == "" {
 		return keyword
@@ -465,16 +1008,16 @@ func prepareFTS5SearchTerm(keyword string) string {
 		return keyword
 	}
 
-	// Handle hashtag search (exact matching)
-	if strings.HasPrefix(keyword, "#") {
-		return keyword // Return hashtag as-is for exact tag matching
-	}
-
 	// Detect version numbers, IPs, or alphanumeric with dots/dashes
 	if isVersionOrSpecialFormat(keyword) {
 		// For version numbers like v1.24.27, 24.27, 192.168.1.1
 		// Use exact phrase match with wildcards
-		return "\"" + keyword + "\"*"
+		return withSynonyms(synonyms, keyword, "\""+keyword+"\"*")
+	}
+
+	// Multi-word synonym terms ("pull request" <-> pr) expand as a whole
+	if strings.Contains(keyword, " ") && len(synonyms.Synonyms(keyword)) > 0 {
+		return withSynonyms(synonyms, keyword, quoteFTS5Term(entity.NormalizeSynonymTerm(keyword))+"*")
 	}
 
 	words := strings.Fields(keyword)
@@ -489,12 +1032,22 @@ func prepareFTS5SearchTerm(keyword string) string {
 			continue
 		}
 
+		// Hashtags match the tags column (#work/projectx -> tags : "work projectx")
+		if strings.HasPrefix(word, "#") {
+			if tag := entity.NormalizeTag(word); tag != "" {
+				ftsTerms = append(ftsTerms, "tags : "+quoteFTS5Term(strings.ReplaceAll(tag, entity.TagSeparator, " ")))
+			}
+			continue
+		}
+
 		// Check if this word is a version/special format
 		if isVersionOrSpecialFormat(word) {
-			ftsTerms = append(ftsTerms, "\""+word+"\"*")
+			ftsTerms = append(ftsTerms, withSynonyms(synonyms, word, "\""+word+"\"*"))
 			continue
 		}
 
+		raw := word
+
 		// Escape special FTS5 characters
 		word = escapeFTS5SpecialChars(word)
 
@@ -503,10 +1056,16 @@ func prepareFTS5SearchTerm(keyword string) string {
 			word = word + "*"
 		}
 
-		ftsTerms = append(ftsTerms, word)
+		ftsTerms = append(ftsTerms, withSynonyms(synonyms, raw, word))
 	}
 
 	if len(ftsTerms) > 1 {
+		// FTS5 rejects an implicit AND after a parenthesized group
+		for _, term := range ftsTerms {
+			if strings.HasPrefix(term, "(") {
+				return strings.Join(ftsTerms, " AND ")
+			}
+		}
 		return strings.Join(ftsTerms, " ")
 	}
 
@@ -517,6 +1076,20 @@ func prepareFTS5SearchTerm(keyword string) string {
 	return ftsTerms[0]
 }
 
+// withSynonyms turns a prepared term into an OR group with the synonyms of the raw word
+func withSynonyms(synonyms *service.SynonymExpander, raw, term string) string {
+	alternatives := synonyms.Synonyms(raw)
+	if len(alternatives) == 0 {
+		return term
+	}
+
+	group := []string{term}
+	for _, synonym := range alternatives {
+		group = append(group, quoteFTS5Term(synonym)+"*")
+	}
+	return "(" + strings.Join(group, " OR ") + ")"
+}
+
 // isVersionOrSpecialFormat detects version numbers, IPs, dates, or alphanumeric with dots/dashes
 func isVersionOrSpecialFormat(word string) bool {
 	// Check for patterns like: v1.24.27, 24.27, 192.168.1.1, 2024-12-15, admin8889
@@ -539,6 +1112,11 @@ func isVersionOrSpecialFormat(word string) bool {
 	return hasNumber && (hasSpecial || (hasLetter && len(word) <= 20))
 }
 
+// quoteFTS5Term wraps a single term in double quotes so it is matched literally
+func quoteFTS5Term(term string) string {
+	return "\"" + strings.ReplaceAll(term, "\"", "\"\"") + "\""
+}
+
 // escapeFTS5SpecialChars escapes special characters in FTS5 queries
 func escapeFTS5SpecialChars(word string) string {
 	// Skip if word is already quoted or has wildcards
@@ -565,36 +1143,39 @@ func escapeFTS5SpecialChars(word string) string {
 func (r *MemoryRepository) GetFragileMemories(ctx context.Context) ([]*entity.Memory, error) {
 	query := `
 		SELECT 
-			id, user_id, chat_id, text_content, tags,
+			id, user_id, chat_id, text_content, vaulted, secret, tags,
 			created_at, last_reviewed, review_count,
-			last_consolidated, priority_score, emotional_weight,
+			last_consolidated, priority_score, COALESCE(sealed_weight, emotional_weight),
 			time_of_day, day_of_week, chat_source, parent_id
 		FROM memories
 		WHERE 
 			julianday('now') - julianday(created_at) <= 7
 			AND review_count < 2
+			AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.conn.DB.QueryContext(ctx, query)
+	rows, err := r.conn.query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get fragile memories: %w", err)
 	}
 	defer rows.Close()
 
-	memories, err := scanMemoriesWithBiologicalFields(rows)
+	memories, err := scanMemoriesWithBiologicalFields(r.fields, rows)
 	if err != nil {
 		return nil, err
 	}
 
 	// Decrypt content for each memory
+	// An encrypted emotional weight is left out: the consolidation boost derived from it is
+	// stored in plaintext priority_score and would reveal it
 	for _, m := range memories {
-		decryptedContent, err := encryption.DecryptIfEnabled(r.encryptor, m.Content)
-		if err != nil {
+		if err := r.openContent(m); err != nil {
 			log.Printf("Warning: failed to decrypt memory %d: %v", m.ID, err)
-			continue
 		}
-		m.Content = decryptedContent
+		if r.fields.encrypted(FieldEmotionalWeight) {
+			m.EmotionalWeight = 0
+		}
 	}
 
 	log.Printf("Found %d fragile memories for consolidation", len(memories))
@@ -603,17 +1184,11 @@ func (r *MemoryRepository) GetFragileMemories(ctx context.Context) ([]*entity.Me
 
 // UpdateConsolidation updates consolidation-related fields
 func (r *MemoryRepository) UpdateConsolidation(ctx context.Context, memory *entity.Memory) error {
-	stmt, err := r.conn.DB.PrepareContext(ctx, `
+	_, err := r.conn.exec(ctx, `
 		UPDATE memories
 		SET last_consolidated = ?, priority_score = ?
 		WHERE id = ?
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to prepare consolidation update: %w", err)
-	}
-	defer stmt.Close()
-
-	_, err = stmt.ExecContext(ctx, memory.LastConsolidated, memory.PriorityScore, memory.ID)
+	`, memory.LastConsolidated, memory.PriorityScore, memory.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update consolidation: %w", err)
 	}
@@ -623,12 +1198,12 @@ func (r *MemoryRepository) UpdateConsolidation(ctx context.Context, memory *enti
 }
 
 // scanMemoriesWithBiologicalFields scans memory rows including biological fields
-func scanMemoriesWithBiologicalFields(rows *sql.Rows) ([]*entity.Memory, error) {
+func scanMemoriesWithBiologicalFields(fields *fieldCipher, rows *sql.Rows) ([]*entity.Memory, error) {
 	memories := []*entity.Memory{}
 
 	for rows.Next() {
 		var m entity.Memory
-		var tags string
+		var tags, weight string
 		var lastReviewed sql.NullTime
 		var parentID sql.NullInt64
 
@@ -637,13 +1212,15 @@ func scanMemoriesWithBiologicalFields(rows *sql.Rows) ([]*entity.Memory, error)
 			&m.UserID,
 			&m.ChatID,
 			&m.Content,
+			&m.Vaulted,
+			&m.Secret,
 			&tags,
 			&m.CreatedAt,
 			&lastReviewed,
 			&m.ReviewCount,
 			&m.LastConsolidated,
 			&m.PriorityScore,
-			&m.EmotionalWeight,
+			&weight,
 			&m.TimeOfDay,
 			&m.DayOfWeek,
 			&m.ChatSource,
@@ -653,7 +1230,9 @@ func scanMemoriesWithBiologicalFields(rows *sql.Rows) ([]*entity.Memory, error)
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
-		m.Tags = strings.Fields(tags)
+		if err := fields.openMemory(&m, tags, weight); err != nil {
+			return nil, err
+		}
 		if lastReviewed.Valid {
 			m.LastReviewed = &lastReviewed.Time
 		}
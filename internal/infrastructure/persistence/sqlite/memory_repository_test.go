@@ -0,0 +1,95 @@
+//go:build fts5
+
+package sqlite
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+	"memory-bot/internal/domain/repository/repositorytest"
+	"memory-bot/pkg/encryption"
+)
+
+// TestMain keeps the repository logging out of the test output
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	repositorytest.TestMemoryRepository(t, func(t *testing.T) repository.MemoryRepository {
+		return NewMemoryRepository(openTestConnection(t), nil, nil)
+	})
+}
+
+func TestEncryptedMemoryRepositoryContract(t *testing.T) {
+	repositorytest.TestBlindIndexedMemoryRepository(t, func(t *testing.T) repository.MemoryRepository {
+		encryptor := encryption.NewEncryptor("contract-test-key-contract-test!")
+		return NewMemoryRepository(openTestConnection(t), encryptor, AllFields())
+	})
+}
+
+func TestSecretMemoriesHaveNoSearchTokens(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestConnection(t)
+	const oldKey, newKey = "old-key-old-key-old-key-old-key!", "new-key-new-key-new-key-new-key!"
+
+	repo := NewMemoryRepository(conn, encryption.NewEncryptor(oldKey), AllFields())
+	m := entity.NewMemory(1, 1, "Router login admin8889 #wifi")
+	m.Secret = true
+	id, err := repo.Save(ctx, m)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	tokens := func(step string) {
+		t.Helper()
+		var stored string
+		if err := conn.DB.QueryRow("SELECT search_tokens FROM memories WHERE id = ?", id).Scan(&stored); err != nil || stored != "" {
+			t.Errorf("%s: search tokens %q, %v", step, stored, err)
+		}
+	}
+	tokens("Save")
+
+	// Blind tokens given to a secret memory by an earlier version are dropped
+	if _, err := conn.DB.Exec("UPDATE memories SET search_tokens = ? WHERE id = ?", repo.blindIndex.IndexText(m.Content), id); err != nil {
+		t.Fatalf("plant tokens: %v", err)
+	}
+	if _, err := repo.MigrateSearchTokens(ctx); err != nil {
+		t.Fatalf("MigrateSearchTokens: %v", err)
+	}
+	tokens("MigrateSearchTokens")
+
+	keyring := encryption.NewKeyring(encryption.LegacyKey(newKey), encryption.LegacyKey(oldKey))
+	if _, err := NewKeyRotationRepository(conn, keyring, AllFields()).ReencryptBatch(ctx, 10); err != nil {
+		t.Fatalf("ReencryptBatch: %v", err)
+	}
+	tokens("ReencryptBatch")
+
+	repo = NewMemoryRepository(conn, keyring, AllFields())
+	if page, err := repo.Search(ctx, 1, "admin8889", repository.SearchOptions{Limit: 10}); err != nil || len(page.Memories) != 0 {
+		t.Errorf("Search secret: %+v, %v", page, err)
+	}
+	if err := repo.SetSecret(ctx, int(id), 1, false); err != nil {
+		t.Fatalf("SetSecret(false): %v", err)
+	}
+	if page, err := repo.Search(ctx, 1, "admin8889", repository.SearchOptions{Limit: 10}); err != nil || len(page.Memories) != 1 {
+		t.Errorf("Search public: %+v, %v", page, err)
+	}
+}
+
+// openTestConnection creates a migrated database in a temporary directory
+func openTestConnection(t *testing.T) *Connection {
+	t.Helper()
+	conn, err := NewConnection(filepath.Join(t.TempDir(), "memories.db"))
+	if err != nil {
+		t.Fatalf("NewConnection: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
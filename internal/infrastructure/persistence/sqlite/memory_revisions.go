@@ -0,0 +1,202 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+)
+
+// UpdateContent stores edited content and records it as a new revision
+// The content is encrypted and blind-indexed like on Save; the FTS5 triggers
+// reindex the memory and the tag index is rewritten from the new tags.
+// A vaulted memory can only be edited while its vault is unlocked.
+func (r *MemoryRepository) UpdateContent(ctx context.Context, memory *entity.Memory, source string, restoredFrom int) (*entity.MemoryRevision, error) {
+	if err := memory.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Revisions share the binding of their memory, so stored values can be copied between them
+	encryptedContent, searchTokens, err := r.sealContent(memory)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.conn.beginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := resolveMemoryTags(ctx, tx, r.fields, memory); err != nil {
+		return nil, err
+	}
+
+	sealed, err := r.fields.sealMemory(memory)
+	if err != nil {
+		return nil, err
+	}
+
+	// The content before the first edit becomes revision 1 (stored values are copied as they are)
+	var storedContent, storedTags string
+	var storedWeight float64
+	var storedSealedWeight sql.NullString
+	var createdAt time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT text_content, tags, emotional_weight, sealed_weight, created_at
+		FROM memories
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, memory.ID, memory.UserID).Scan(&storedContent, &storedTags, &storedWeight, &storedSealedWeight, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrMemoryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find memory: %w", err)
+	}
+
+	var latest int
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(revision), 0) FROM memory_revisions WHERE memory_id = ?", memory.ID,
+	).Scan(&latest); err != nil {
+		return nil, fmt.Errorf("failed to read revisions: %w", err)
+	}
+
+	if latest == 0 {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO memory_revisions (memory_id, revision, text_content, tags, emotional_weight, sealed_weight, source, created_at)
+			VALUES (?, 1, ?, ?, ?, ?, ?, ?)
+		`, memory.ID, storedContent, storedTags, storedWeight, storedSealedWeight, entity.RevisionSourceOriginal, createdAt); err != nil {
+			return nil, fmt.Errorf("failed to record original revision: %w", err)
+		}
+		latest = 1
+	}
+
+	revision := &entity.MemoryRevision{
+		MemoryID:        memory.ID,
+		Number:          latest + 1,
+		Content:         memory.Content,
+		Tags:            memory.Tags,
+		EmotionalWeight: memory.EmotionalWeight,
+		Source:          source,
+		RestoredFrom:    restoredFrom,
+		CreatedAt:       time.Now(),
+	}
+
+	var restored interface{}
+	if restoredFrom > 0 {
+		restored = restoredFrom
+	}
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO memory_revisions (memory_id, revision, text_content, tags, emotional_weight, sealed_weight, source, restored_from, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, memory.ID, revision.Number, encryptedContent, sealed.tags, sealed.weight, sealed.sealedWeight, source, restored, revision.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record revision: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+	revision.ID = int(id)
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE memories
+		SET text_content = ?, search_tokens = ?, tags = ?, tag_tokens = ?, emotional_weight = ?, sealed_weight = ?, secret = ?
+		WHERE id = ?
+	`, encryptedContent, searchTokens, sealed.tags, sealed.tagTokens, sealed.weight, sealed.sealedWeight, memory.Secret, memory.ID); err != nil {
+		return nil, fmt.Errorf("failed to update memory: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM memory_tags WHERE memory_id = ?", memory.ID); err != nil {
+		return nil, fmt.Errorf("failed to clear memory tags: %w", err)
+	}
+	if !memory.Vaulted {
+		if err := writeMemoryTags(ctx, tx, r.fields, memory.ID, memory.UserID, memory.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit memory edit: %w", err)
+	}
+
+	log.Printf("Memory edited: ID=%d, UserID=%d, Revision=%d, Source=%s", memory.ID, memory.UserID, revision.Number, source)
+	return revision, nil
+}
+
+// FindRevisions returns the recorded revisions of a user's memory, oldest first
+// A memory that was never edited has no recorded revisions; the revisions of a
+// vaulted memory return ErrVaultLocked while the vault is locked
+func (r *MemoryRepository) FindRevisions(ctx context.Context, memoryID int, userID int64) ([]*entity.MemoryRevision, error) {
+	rows, err := r.conn.query(ctx, `
+		SELECT rv.id, rv.memory_id, rv.revision, rv.text_content, m.vaulted, COALESCE(rv.tags, ''),
+		       COALESCE(rv.sealed_weight, rv.emotional_weight, 0), rv.source, COALESCE(rv.restored_from, 0), rv.created_at
+		FROM memory_revisions AS rv
+		JOIN memories AS m ON m.id = rv.memory_id
+		WHERE rv.memory_id = ? AND m.user_id = ? AND m.deleted_at IS NULL
+		ORDER BY rv.revision
+	`, memoryID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*entity.MemoryRevision
+	for rows.Next() {
+		var rev entity.MemoryRevision
+		var tags, weight string
+		var vaulted bool
+
+		if err := rows.Scan(&rev.ID, &rev.MemoryID, &rev.Number, &rev.Content, &vaulted, &tags,
+			&weight, &rev.Source, &rev.RestoredFrom, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		aad := memoryBinding(userID, rev.MemoryID)
+		var err error
+		if rev.Tags, err = r.fields.openMemoryTags(userID, rev.MemoryID, vaulted, tags); err != nil {
+			return nil, fmt.Errorf("revision %d: %w", rev.ID, err)
+		}
+		if rev.EmotionalWeight, err = r.fields.openWeight(weight, aad); err != nil {
+			return nil, fmt.Errorf("revision %d: %w", rev.ID, err)
+		}
+		decryptedContent, err := r.openStoredContent(userID, rev.MemoryID, vaulted, rev.Content)
+		if errors.Is(err, entity.ErrVaultLocked) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt revision: %w", err)
+		}
+		rev.Content = decryptedContent
+
+		revisions = append(revisions, &rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return revisions, nil
+}
+
+// FindBySourceMessage finds the live memory saved from a Telegram message
+func (r *MemoryRepository) FindBySourceMessage(ctx context.Context, chatID int64, messageID int) (*entity.Memory, error) {
+	var id int
+	err := r.conn.queryRow(ctx, `
+		SELECT id FROM memories
+		WHERE chat_id = ? AND source_message_id = ? AND deleted_at IS NULL
+		ORDER BY id DESC
+		LIMIT 1
+	`, chatID, messageID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrMemoryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find memory by message: %w", err)
+	}
+
+	return r.FindByID(ctx, id)
+}
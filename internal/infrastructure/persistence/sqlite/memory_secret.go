@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"memory-bot/internal/domain/entity"
+)
+
+// SetSecret marks a live memory of the user as secret or not
+// A secret memory loses its search tokens; unmarking indexes its content again, which
+// for a vaulted memory needs the vault unlocked (ErrVaultLocked otherwise).
+func (r *MemoryRepository) SetSecret(ctx context.Context, id int, userID int64, secret bool) error {
+	deletedAt, err := r.trashState(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if deletedAt.Valid {
+		return entity.ErrMemoryNotFound
+	}
+
+	m, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if m.Locked && !secret {
+		return entity.ErrVaultLocked
+	}
+	m.Secret = secret
+	tokens, err := r.contentTokens(m)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.conn.exec(ctx,
+		"UPDATE memories SET secret = ?, search_tokens = ? WHERE id = ?",
+		secret, tokens, id,
+	); err != nil {
+		return fmt.Errorf("failed to update secret flag: %w", err)
+	}
+
+	log.Printf("Memory secret flag updated: ID=%d, UserID=%d, Secret=%t", id, userID, secret)
+	return nil
+}
+
+// contentTokens returns the value stored in search_tokens for the content of m
+// Secret content gets an empty token list whether encryption is enabled or not: NULL
+// would let the FTS5 index read text_content, and blind tokens would still match its
+// words. Vaulted content is indexed with the vault's blind index.
+func (r *MemoryRepository) contentTokens(m *entity.Memory) (interface{}, error) {
+	if m.Secret {
+		return "", nil
+	}
+	if !m.Vaulted {
+		return r.searchTokens(m.Content), nil
+	}
+	_, blindIndex := r.vault.Unlocked(m.UserID)
+	if blindIndex == nil {
+		return nil, entity.ErrVaultLocked
+	}
+	return blindIndex.IndexText(m.Content), nil
+}
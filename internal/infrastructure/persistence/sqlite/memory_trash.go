@@ -0,0 +1,207 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+)
+
+// trashSubtreeCTE selects a memory and its live descendants (sub-memories of sub-memories included)
+const trashSubtreeCTE = `
+	WITH RECURSIVE subtree(id) AS (
+		SELECT id FROM memories WHERE id = ?
+		UNION
+		SELECT m.id FROM memories m JOIN subtree s ON m.parent_id = s.id
+		WHERE m.deleted_at IS NULL
+	)`
+
+// restoreSubtreeCTE selects a trashed memory and the descendants trashed together with it
+const restoreSubtreeCTE = `
+	WITH RECURSIVE subtree(id) AS (
+		SELECT id FROM memories WHERE id = ?
+		UNION
+		SELECT m.id FROM memories m JOIN subtree s ON m.parent_id = s.id
+		WHERE m.deleted_at = (SELECT deleted_at FROM memories WHERE id = ?)
+	)`
+
+// Delete moves a memory to the trash with authorization check
+// Its sub-memories are trashed with the same timestamp, so a restore brings them back together
+func (r *MemoryRepository) Delete(ctx context.Context, id int, userID int64) error {
+	deletedAt, err := r.trashState(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if deletedAt.Valid {
+		return entity.ErrMemoryNotFound
+	}
+
+	result, err := r.conn.exec(ctx, trashSubtreeCTE+`
+		UPDATE memories SET deleted_at = ?
+		WHERE id IN (SELECT id FROM subtree)
+	`, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete memory: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	log.Printf("Memory moved to trash: ID=%d, UserID=%d, Memories=%d", id, userID, affected)
+	return nil
+}
+
+// Restore brings a memory back from the trash
+// A sub-memory whose parent is still in the trash is restored as a root memory
+func (r *MemoryRepository) Restore(ctx context.Context, id int, userID int64) (int, error) {
+	deletedAt, err := r.trashState(ctx, id, userID)
+	if err != nil {
+		return 0, err
+	}
+	if !deletedAt.Valid {
+		return 0, entity.ErrMemoryNotFound
+	}
+
+	tx, err := r.conn.beginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE memories SET parent_id = NULL
+		WHERE id = ? AND parent_id IN (SELECT id FROM memories WHERE deleted_at IS NOT NULL)
+	`, id); err != nil {
+		return 0, fmt.Errorf("failed to detach memory: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, restoreSubtreeCTE+`
+		UPDATE memories SET deleted_at = NULL
+		WHERE id IN (SELECT id FROM subtree)
+	`, id, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore memory: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit restore: %w", err)
+	}
+
+	log.Printf("Memory restored: ID=%d, UserID=%d, Memories=%d", id, userID, affected)
+	return int(affected), nil
+}
+
+// FindDeleted lists the memories in a user's trash, most recently deleted first
+func (r *MemoryRepository) FindDeleted(ctx context.Context, userID int64, limit int) ([]*entity.Memory, error) {
+	rows, err := r.conn.query(ctx, `
+		SELECT id, user_id, chat_id, text_content, vaulted, secret, tags, created_at, parent_id, deleted_at
+		FROM memories
+		WHERE user_id = ? AND deleted_at IS NOT NULL
+		ORDER BY julianday(deleted_at) DESC, id DESC
+		LIMIT ?
+	`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted memories: %w", err)
+	}
+	defer rows.Close()
+
+	var memories []*entity.Memory
+	for rows.Next() {
+		var m entity.Memory
+		var tags string
+		var parentID sql.NullInt64
+		var deletedAt time.Time
+
+		if err := rows.Scan(&m.ID, &m.UserID, &m.ChatID, &m.Content, &m.Vaulted, &m.Secret, &tags, &m.CreatedAt, &parentID, &deletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if m.Tags, err = r.fields.openMemoryTags(m.UserID, m.ID, m.Vaulted, tags); err != nil {
+			return nil, fmt.Errorf("memory %d: %w", m.ID, err)
+		}
+		if parentID.Valid {
+			m.ParentID = &parentID.Int64
+		}
+		m.DeletedAt = &deletedAt
+
+		if err := r.openListed(&m); err != nil {
+			return nil, err
+		}
+
+		memories = append(memories, &m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return memories, nil
+}
+
+// PurgeDeleted permanently removes the memories trashed before the cutoff
+// Trashed memories are no longer in the FTS5 index, so only the rows, their tags and revisions are removed
+func (r *MemoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	tx, err := r.conn.beginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const expired = `SELECT id FROM memories WHERE deleted_at IS NOT NULL AND julianday(deleted_at) < julianday(?)`
+	cutoff := before.UTC().Format(sqliteTimeLayout)
+
+	// The cascade and SET NULL only run on connections with foreign keys enabled
+	if _, err := tx.ExecContext(ctx, `DELETE FROM memory_tags WHERE memory_id IN (`+expired+`)`, cutoff); err != nil {
+		return 0, fmt.Errorf("failed to purge memory tags: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM memory_revisions WHERE memory_id IN (`+expired+`)`, cutoff); err != nil {
+		return 0, fmt.Errorf("failed to purge memory revisions: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE memories SET parent_id = NULL WHERE parent_id IN (`+expired+`)`, cutoff); err != nil {
+		return 0, fmt.Errorf("failed to detach sub-memories: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM memories WHERE id IN (`+expired+`)`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge memories: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit purge: %w", err)
+	}
+	return int(affected), nil
+}
+
+// trashState checks that the memory belongs to the user and returns its deleted_at
+func (r *MemoryRepository) trashState(ctx context.Context, id int, userID int64) (sql.NullTime, error) {
+	var owner int64
+	var deletedAt sql.NullTime
+
+	err := r.conn.queryRow(ctx,
+		"SELECT user_id, deleted_at FROM memories WHERE id = ?", id,
+	).Scan(&owner, &deletedAt)
+	if err == sql.ErrNoRows {
+		return deletedAt, entity.ErrMemoryNotFound
+	}
+	if err != nil {
+		return deletedAt, fmt.Errorf("failed to find memory: %w", err)
+	}
+	if owner != userID {
+		return deletedAt, entity.ErrUnauthorized
+	}
+	return deletedAt, nil
+}
@@ -0,0 +1,168 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/encryption"
+)
+
+// UseVault enables personal vaults with the given session store
+// Memories saved while their owner's vault is unlocked are vaulted: the content and
+// tags of the memory and its revisions are encrypted with the vault key instead of
+// ENCRYPTION_KEY, and indexed with the vault's blind index.
+func (r *MemoryRepository) UseVault(sessions *encryption.VaultSessions) {
+	r.vault = sessions
+	r.fields.vault = sessions
+}
+
+// sealContent encrypts the content of m for storage and returns it with its search tokens
+// Vaulted content needs the owner's vault to be unlocked (ErrVaultLocked otherwise).
+func (r *MemoryRepository) sealContent(m *entity.Memory) (string, interface{}, error) {
+	aad := memoryBinding(m.UserID, m.ID)
+	var content string
+	var err error
+	if !m.Vaulted {
+		content, err = encryption.EncryptIfEnabled(r.encryptor, m.Content, aad)
+	} else {
+		keyring, _ := r.vault.Unlocked(m.UserID)
+		if keyring == nil {
+			return "", nil, entity.ErrVaultLocked
+		}
+		content, err = keyring.Encrypt(m.Content, aad)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encrypt content: %w", err)
+	}
+
+	tokens, err := r.contentTokens(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return content, tokens, nil
+}
+
+// openContent decrypts the stored content of m in place
+// A vaulted memory whose vault is locked is marked Locked and gets LockedContent.
+func (r *MemoryRepository) openContent(m *entity.Memory) error {
+	content, err := r.openStoredContent(m.UserID, m.ID, m.Vaulted, m.Content)
+	if errors.Is(err, entity.ErrVaultLocked) {
+		m.Locked = true
+		m.Content = entity.LockedContent
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decrypt content: %w", err)
+	}
+	m.Content = content
+	return nil
+}
+
+// openListed decrypts the content of a memory of a listing in place
+// A memory whose content does not decrypt is marked Locked and gets UndecryptableContent
+// instead of failing the whole page; /doctor reports it.
+func (r *MemoryRepository) openListed(m *entity.Memory) error {
+	err := r.openContent(m)
+	if !listedUndecryptable(m.ID, err) {
+		return err
+	}
+	m.Locked = true
+	m.Content = entity.UndecryptableContent
+	return nil
+}
+
+// listedUndecryptable reports whether err is a stored value of a listed memory that
+// does not decrypt, and logs it
+func listedUndecryptable(id int, err error) bool {
+	if !encryption.Undecryptable(err) {
+		return false
+	}
+	log.Printf("⚠️ Memory %d does not decrypt (%v), listed without it", id, err)
+	return true
+}
+
+// openStoredContent decrypts stored memory or revision content
+// Returns ErrVaultLocked for vaulted content while the owner's vault is locked
+func (r *MemoryRepository) openStoredContent(userID int64, memoryID int, vaulted bool, stored string) (string, error) {
+	aad := memoryBinding(userID, memoryID)
+	if !vaulted {
+		return encryption.DecryptIfEnabled(r.encryptor, stored, aad)
+	}
+
+	keyring, _ := r.vault.Unlocked(userID)
+	if keyring == nil {
+		return "", entity.ErrVaultLocked
+	}
+	return keyring.Decrypt(stored, aad)
+}
+
+// sealVaultTags returns the tags and tag_tokens values of a vaulted memory
+// Its tags are encrypted and indexed with the vault key whatever the field policy says,
+// and they are kept out of the tag index (memory_tags), which only the server key opens.
+func (c *fieldCipher) sealVaultTags(m *entity.Memory) (string, interface{}, error) {
+	keyring, blindIndex := c.vault.Unlocked(m.UserID)
+	if keyring == nil {
+		return "", nil, entity.ErrVaultLocked
+	}
+	joined := strings.Join(m.Tags, " ")
+	sealed, err := keyring.Encrypt(joined, memoryBinding(m.UserID, m.ID))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encrypt tags: %w", err)
+	}
+	return sealed, blindIndex.IndexText(joined), nil
+}
+
+// openMemoryTags reads the stored tags of a memory or revision
+// The tags of a vaulted memory are left out while the owner's vault is locked.
+func (c *fieldCipher) openMemoryTags(userID int64, memoryID int, vaulted bool, stored string) ([]string, error) {
+	if !vaulted {
+		return c.openTags(stored, memoryBinding(userID, memoryID))
+	}
+
+	keyring, _ := c.vault.Unlocked(userID)
+	if keyring == nil {
+		return nil, nil
+	}
+	tags, err := keyring.Decrypt(stored, memoryBinding(userID, memoryID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt tags: %w", err)
+	}
+	return strings.Fields(tags), nil
+}
+
+// vaultTagMatch returns the FTS5 expression matching the vaulted memories of the user
+// whose tags contain every word of tag, or "" while the vault is locked
+func (r *MemoryRepository) vaultTagMatch(userID int64, tag string) string {
+	_, vaultIndex := r.vault.Unlocked(userID)
+	if vaultIndex == nil {
+		return ""
+	}
+
+	var terms []string
+	for _, word := range vaultIndex.Words(tag) {
+		terms = append(terms, "("+strings.Join(vaultIndex.Tokens(word), " OR ")+")")
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+	return "tags : (" + strings.Join(terms, " AND ") + ")"
+}
+
+// hasTagBelow reports whether one of tags is tag or one of its sub-tags
+func hasTagBelow(tags []string, tag string) bool {
+	for _, t := range tags {
+		if entity.IsTagOrDescendant(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// vaultUnlocked reports whether memories of the user are vaulted when saved
+func (r *MemoryRepository) vaultUnlocked(userID int64) bool {
+	keyring, _ := r.vault.Unlocked(userID)
+	return keyring != nil
+}
@@ -0,0 +1,222 @@
+package sqlite
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles holds the numbered up-migrations (NNNN_name.sql), applied in order
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// ErrSchemaTooNew is returned when the database was migrated by a newer version of the bot
+var ErrSchemaTooNew = errors.New("database schema is newer than this version of the bot")
+
+// Migration is one embedded schema change
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrationStatus describes the schema version of a database
+type MigrationStatus struct {
+	Current int         // Highest applied version (0 for a database without migrations)
+	Latest  int         // Highest version known to this binary
+	Pending []Migration // Migrations not applied yet, in order
+}
+
+// Migrator applies the embedded migrations to a database
+// Each migration runs in its own transaction together with its schema_migrations row;
+// a backup of an existing database is written before the first pending migration.
+type Migrator struct {
+	conn       *Connection
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the embedded migrations
+func NewMigrator(conn *Connection) (*Migrator, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		conn:       conn,
+		migrations: migrations,
+	}, nil
+}
+
+// loadMigrations reads and orders the embedded migration files
+func loadMigrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		number, title, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), "_")
+		version, err := strconv.Atoi(number)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name: %s", name)
+		}
+		if other, exists := seen[version]; exists {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, other, name)
+		}
+		seen[version] = name
+
+		content, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: title, SQL: string(content)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Status reports the applied and pending migrations
+// It fails with ErrSchemaTooNew if the database has migrations this binary does not know
+func (m *Migrator) Status(ctx context.Context) (*MigrationStatus, error) {
+	tracked, err := m.conn.tableExists("schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var current int
+	if tracked {
+		if err := m.conn.DB.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+			return nil, fmt.Errorf("failed to read schema version: %w", err)
+		}
+	}
+
+	status := &MigrationStatus{Current: current}
+	if len(m.migrations) > 0 {
+		status.Latest = m.migrations[len(m.migrations)-1].Version
+	}
+	if current > status.Latest {
+		return status, fmt.Errorf("%w (database: %d, bot: %d)", ErrSchemaTooNew, current, status.Latest)
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version > current {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// Migrate applies all pending migrations and returns how many were applied
+func (m *Migrator) Migrate(ctx context.Context) (int, error) {
+	if _, err := m.conn.DB.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`); err != nil {
+		return 0, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	status, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(status.Pending) == 0 {
+		return 0, nil
+	}
+
+	hasData, err := m.conn.tableExists("memories")
+	if err != nil {
+		return 0, err
+	}
+	if hasData {
+		backupPath, err := m.conn.Backup(ctx, fmt.Sprintf("pre-migration-v%04d", status.Pending[0].Version))
+		if err != nil {
+			return 0, err
+		}
+		if backupPath != "" {
+			log.Printf("Database backup written to %s", backupPath)
+		}
+	}
+
+	// Databases created before migrations existed may predate columns of the initial schema
+	// or the FTS5 table; their memories are indexed once the table exists
+	indexLegacy := false
+	if status.Current == 0 && hasData {
+		if err := m.conn.adoptLegacySchema(); err != nil {
+			return 0, err
+		}
+		hasIndex, err := m.conn.tableExists("memories_fts")
+		if err != nil {
+			return 0, err
+		}
+		indexLegacy = !hasIndex
+	}
+
+	for _, migration := range status.Pending {
+		if err := m.apply(ctx, migration); err != nil {
+			return 0, err
+		}
+		log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+	}
+
+	if indexLegacy {
+		count, err := fillFTSTable(m.conn.DB)
+		if err != nil {
+			return 0, err
+		}
+		log.Printf("Indexed %d existing memories for full-text search", count)
+	}
+
+	return len(status.Pending), nil
+}
+
+// apply runs one migration and records it in the same transaction
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	tx, err := m.conn.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %04d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.SQL); err != nil {
+		return fmt.Errorf("failed to apply migration %04d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+		migration.Version, migration.Name, time.Now(),
+	); err != nil {
+		return fmt.Errorf("failed to record migration %04d: %w", migration.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %04d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+// adoptLegacySchema adds the columns that old databases got from hand-run scripts
+// (parent_id from the former migrate_chunking.sh) or from startup checks (search_tokens)
+func (c *Connection) adoptLegacySchema() error {
+	if err := c.ensureColumn("memories", "parent_id", "INTEGER REFERENCES memories(id) ON DELETE SET NULL"); err != nil {
+		return err
+	}
+	return c.ensureColumn("memories", "search_tokens", "TEXT")
+}
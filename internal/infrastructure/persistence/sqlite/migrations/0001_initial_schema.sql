@@ -0,0 +1,128 @@
+-- Schema as created by initSchema before versioned migrations
+-- Every statement is idempotent so databases created before migrations existed can adopt it
+
+-- Main memories table with biological fields
+CREATE TABLE IF NOT EXISTS memories (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	chat_id INTEGER NOT NULL,
+	text_content TEXT NOT NULL,
+	search_tokens TEXT,
+	tags TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	last_reviewed DATETIME,
+	review_count INTEGER DEFAULT 0,
+	last_consolidated DATETIME DEFAULT CURRENT_TIMESTAMP,
+	priority_score REAL DEFAULT 0.0,
+	emotional_weight REAL DEFAULT 0.0,
+	time_of_day TEXT DEFAULT '',
+	day_of_week TEXT DEFAULT '',
+	chat_source TEXT DEFAULT 'Telegram',
+	parent_id INTEGER,
+	FOREIGN KEY(parent_id) REFERENCES memories(id) ON DELETE SET NULL
+);
+
+-- Composite index for fast user-based queries
+CREATE INDEX IF NOT EXISTS idx_user_time ON memories (user_id, created_at DESC);
+
+-- Biological memory system indexes
+CREATE INDEX IF NOT EXISTS idx_memories_time_of_day ON memories(time_of_day);
+CREATE INDEX IF NOT EXISTS idx_memories_day_of_week ON memories(day_of_week);
+CREATE INDEX IF NOT EXISTS idx_memories_emotional_weight ON memories(emotional_weight DESC);
+CREATE INDEX IF NOT EXISTS idx_memories_priority_score ON memories(priority_score DESC);
+
+-- FTS5 index with the default tokenizer (SearchIndex.EnsureTokenizer switches to the configured one)
+-- It is fed from search_tokens (blind index tokens) when encryption is enabled, otherwise from text_content
+CREATE VIRTUAL TABLE IF NOT EXISTS memories_fts USING fts5(
+	text_content,
+	tags,
+	content='memories',
+	content_rowid='id',
+	tokenize="unicode61 tokenchars '.'"
+);
+
+-- Triggers keeping the external content FTS5 table in sync
+DROP TRIGGER IF EXISTS memories_ai;
+DROP TRIGGER IF EXISTS memories_au;
+DROP TRIGGER IF EXISTS memories_ad;
+
+CREATE TRIGGER memories_ai AFTER INSERT ON memories BEGIN
+	INSERT INTO memories_fts(rowid, text_content, tags)
+	VALUES (new.id, COALESCE(new.search_tokens, new.text_content), new.tags);
+END;
+
+CREATE TRIGGER memories_au AFTER UPDATE OF text_content, search_tokens, tags ON memories BEGIN
+	INSERT INTO memories_fts(memories_fts, rowid, text_content, tags)
+	VALUES ('delete', old.id, COALESCE(old.search_tokens, old.text_content), old.tags);
+	INSERT INTO memories_fts(rowid, text_content, tags)
+	VALUES (new.id, COALESCE(new.search_tokens, new.text_content), new.tags);
+END;
+
+CREATE TRIGGER memories_ad AFTER DELETE ON memories BEGIN
+	INSERT INTO memories_fts(memories_fts, rowid, text_content, tags)
+	VALUES ('delete', old.id, COALESCE(old.search_tokens, old.text_content), old.tags);
+END;
+
+-- Saved searches (watches) that notify users about new matching memories
+CREATE TABLE IF NOT EXISTS saved_searches (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	chat_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	query TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user ON saved_searches(user_id);
+
+-- Normalized tag index (memories.tags keeps the space-joined copy for FTS5)
+CREATE TABLE IF NOT EXISTS memory_tags (
+	memory_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (memory_id, tag),
+	FOREIGN KEY(memory_id) REFERENCES memories(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_memory_tags_user_tag ON memory_tags(user_id, tag);
+
+CREATE TABLE IF NOT EXISTS tag_aliases (
+	user_id INTEGER NOT NULL,
+	alias TEXT NOT NULL,
+	tag TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, alias)
+);
+
+-- Per-user settings and the opt-in search query history
+CREATE TABLE IF NOT EXISTS user_settings (
+	user_id INTEGER PRIMARY KEY,
+	query_history INTEGER NOT NULL DEFAULT 0,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS query_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	query_text TEXT NOT NULL,
+	query_key TEXT NOT NULL,
+	result_count INTEGER NOT NULL DEFAULT 0,
+	matched_step TEXT NOT NULL DEFAULT '',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_query_history_user ON query_history(user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_query_history_key ON query_history(query_key);
+
+-- Search synonym sets (user_id 0 holds the global sets)
+CREATE TABLE IF NOT EXISTS synonym_sets (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS synonym_terms (
+	set_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	term TEXT NOT NULL,
+	PRIMARY KEY (user_id, term),
+	FOREIGN KEY(set_id) REFERENCES synonym_sets(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_synonym_terms_set ON synonym_terms(set_id);
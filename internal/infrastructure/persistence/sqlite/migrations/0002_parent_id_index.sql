@@ -0,0 +1,3 @@
+-- Index for memory chunking (parent/child memories)
+-- Previously only created by the hand-run migrate_chunking.sh, so fresh databases lacked it
+CREATE INDEX IF NOT EXISTS idx_memories_parent_id ON memories(parent_id);
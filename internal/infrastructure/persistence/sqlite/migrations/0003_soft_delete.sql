@@ -0,0 +1,41 @@
+-- Soft delete: deleted memories stay in the trash until they are restored or purged
+ALTER TABLE memories ADD COLUMN deleted_at DATETIME;
+CREATE INDEX IF NOT EXISTS idx_memories_deleted ON memories(user_id, deleted_at);
+
+-- Memories in the trash are removed from the FTS5 index and added back on restore
+-- (same definitions as createTriggers in search_index.go)
+DROP TRIGGER IF EXISTS memories_ai;
+DROP TRIGGER IF EXISTS memories_au;
+DROP TRIGGER IF EXISTS memories_ad;
+DROP TRIGGER IF EXISTS memories_trash;
+DROP TRIGGER IF EXISTS memories_restore;
+
+CREATE TRIGGER memories_ai AFTER INSERT ON memories WHEN new.deleted_at IS NULL BEGIN
+	INSERT INTO memories_fts(rowid, text_content, tags)
+	VALUES (new.id, COALESCE(new.search_tokens, new.text_content), new.tags);
+END;
+
+CREATE TRIGGER memories_au AFTER UPDATE OF text_content, search_tokens, tags ON memories
+WHEN old.deleted_at IS NULL AND new.deleted_at IS NULL BEGIN
+	INSERT INTO memories_fts(memories_fts, rowid, text_content, tags)
+	VALUES ('delete', old.id, COALESCE(old.search_tokens, old.text_content), old.tags);
+	INSERT INTO memories_fts(rowid, text_content, tags)
+	VALUES (new.id, COALESCE(new.search_tokens, new.text_content), new.tags);
+END;
+
+CREATE TRIGGER memories_ad AFTER DELETE ON memories WHEN old.deleted_at IS NULL BEGIN
+	INSERT INTO memories_fts(memories_fts, rowid, text_content, tags)
+	VALUES ('delete', old.id, COALESCE(old.search_tokens, old.text_content), old.tags);
+END;
+
+CREATE TRIGGER memories_trash AFTER UPDATE OF deleted_at ON memories
+WHEN old.deleted_at IS NULL AND new.deleted_at IS NOT NULL BEGIN
+	INSERT INTO memories_fts(memories_fts, rowid, text_content, tags)
+	VALUES ('delete', old.id, COALESCE(old.search_tokens, old.text_content), old.tags);
+END;
+
+CREATE TRIGGER memories_restore AFTER UPDATE OF deleted_at ON memories
+WHEN old.deleted_at IS NOT NULL AND new.deleted_at IS NULL BEGIN
+	INSERT INTO memories_fts(rowid, text_content, tags)
+	VALUES (new.id, COALESCE(new.search_tokens, new.text_content), new.tags);
+END;
@@ -0,0 +1,18 @@
+-- Telegram message a memory was saved from, so edits of that message update the memory
+ALTER TABLE memories ADD COLUMN source_message_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_memories_source_message ON memories(chat_id, source_message_id);
+
+-- Content history of edited memories (text_content is encrypted like memories.text_content)
+CREATE TABLE IF NOT EXISTS memory_revisions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	memory_id INTEGER NOT NULL,
+	revision INTEGER NOT NULL,
+	text_content TEXT NOT NULL,
+	tags TEXT,
+	emotional_weight REAL DEFAULT 0.0,
+	source TEXT NOT NULL,
+	restored_from INTEGER,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(memory_id, revision),
+	FOREIGN KEY(memory_id) REFERENCES memories(id) ON DELETE CASCADE
+);
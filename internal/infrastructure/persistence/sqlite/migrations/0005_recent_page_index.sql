@@ -0,0 +1,4 @@
+-- Keyset pagination of /recent seeks on (julianday(created_at), id) per user
+-- The expression index serves the seek and the order without sorting the user's memories
+CREATE INDEX IF NOT EXISTS idx_memories_recent_page
+ON memories(user_id, julianday(created_at), id) WHERE deleted_at IS NULL;
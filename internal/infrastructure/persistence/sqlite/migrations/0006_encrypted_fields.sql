@@ -0,0 +1,50 @@
+-- Field-level encryption (ENCRYPTED_FIELDS)
+-- tag_tokens holds blind index tokens of encrypted tags and feeds the FTS5 tags column;
+-- sealed_weight holds an encrypted emotional weight (emotional_weight is then 0)
+ALTER TABLE memories ADD COLUMN tag_tokens TEXT;
+ALTER TABLE memories ADD COLUMN sealed_weight TEXT;
+ALTER TABLE memory_revisions ADD COLUMN sealed_weight TEXT;
+
+-- Fields the stored rows are currently encrypted with; the startup migration converts
+-- existing rows when the configured policy differs
+CREATE TABLE IF NOT EXISTS encrypted_fields (
+	field TEXT PRIMARY KEY,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Same definitions as createTriggers in search_index.go
+DROP TRIGGER IF EXISTS memories_ai;
+DROP TRIGGER IF EXISTS memories_au;
+DROP TRIGGER IF EXISTS memories_ad;
+DROP TRIGGER IF EXISTS memories_trash;
+DROP TRIGGER IF EXISTS memories_restore;
+
+CREATE TRIGGER memories_ai AFTER INSERT ON memories WHEN new.deleted_at IS NULL BEGIN
+	INSERT INTO memories_fts(rowid, text_content, tags)
+	VALUES (new.id, COALESCE(new.search_tokens, new.text_content), COALESCE(new.tag_tokens, new.tags));
+END;
+
+CREATE TRIGGER memories_au AFTER UPDATE OF text_content, search_tokens, tags, tag_tokens ON memories
+WHEN old.deleted_at IS NULL AND new.deleted_at IS NULL BEGIN
+	INSERT INTO memories_fts(memories_fts, rowid, text_content, tags)
+	VALUES ('delete', old.id, COALESCE(old.search_tokens, old.text_content), COALESCE(old.tag_tokens, old.tags));
+	INSERT INTO memories_fts(rowid, text_content, tags)
+	VALUES (new.id, COALESCE(new.search_tokens, new.text_content), COALESCE(new.tag_tokens, new.tags));
+END;
+
+CREATE TRIGGER memories_ad AFTER DELETE ON memories WHEN old.deleted_at IS NULL BEGIN
+	INSERT INTO memories_fts(memories_fts, rowid, text_content, tags)
+	VALUES ('delete', old.id, COALESCE(old.search_tokens, old.text_content), COALESCE(old.tag_tokens, old.tags));
+END;
+
+CREATE TRIGGER memories_trash AFTER UPDATE OF deleted_at ON memories
+WHEN old.deleted_at IS NULL AND new.deleted_at IS NOT NULL BEGIN
+	INSERT INTO memories_fts(memories_fts, rowid, text_content, tags)
+	VALUES ('delete', old.id, COALESCE(old.search_tokens, old.text_content), COALESCE(old.tag_tokens, old.tags));
+END;
+
+CREATE TRIGGER memories_restore AFTER UPDATE OF deleted_at ON memories
+WHEN old.deleted_at IS NOT NULL AND new.deleted_at IS NULL BEGIN
+	INSERT INTO memories_fts(rowid, text_content, tags)
+	VALUES (new.id, COALESCE(new.search_tokens, new.text_content), COALESCE(new.tag_tokens, new.tags));
+END;
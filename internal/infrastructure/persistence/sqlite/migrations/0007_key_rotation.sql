@@ -0,0 +1,10 @@
+-- Checkpoints of the re-encryption job that moves stored data to the primary key
+-- One row per table: rows up to last_id are re-encrypted with key_id. A checkpoint
+-- of another key id belongs to an earlier rotation and starts over.
+CREATE TABLE IF NOT EXISTS key_rotation (
+	table_name TEXT PRIMARY KEY,
+	key_id TEXT NOT NULL,
+	last_id INTEGER NOT NULL DEFAULT 0,
+	completed INTEGER NOT NULL DEFAULT 0,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
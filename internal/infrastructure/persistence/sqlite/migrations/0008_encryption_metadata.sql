@@ -0,0 +1,7 @@
+-- Settings of the encryption at rest, as name/value pairs:
+-- kdf (Argon2id parameters and salt), key_check (a value encrypted with the primary key
+-- to detect a wrong ENCRYPTION_KEY) and legacy_keys (data written with SHA-256 keys remains).
+CREATE TABLE IF NOT EXISTS encryption_metadata (
+	name TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
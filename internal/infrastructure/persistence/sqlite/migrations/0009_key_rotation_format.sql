@@ -0,0 +1,4 @@
+-- Envelope format of the rows behind a key rotation checkpoint
+-- Checkpoints written before associated data was added (format 2) start over, so the
+-- job moves every value to the current format; it also encrypts legacy plaintext.
+ALTER TABLE key_rotation ADD COLUMN format INTEGER NOT NULL DEFAULT 2;
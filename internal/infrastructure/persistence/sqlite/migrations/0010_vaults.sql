@@ -0,0 +1,12 @@
+-- Personal vaults: the Argon2id parameters and key check of each user's vault passphrase
+-- The vault key itself is never stored; it is derived again on every /unlock.
+CREATE TABLE IF NOT EXISTS vaults (
+	user_id INTEGER PRIMARY KEY,
+	kdf TEXT NOT NULL,
+	key_check TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Memories saved while the vault was unlocked store their content (and the content of
+-- their revisions) encrypted with the vault key instead of ENCRYPTION_KEY
+ALTER TABLE memories ADD COLUMN vaulted INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Secret memories are masked in listings; without encryption their content is not
+-- indexed (search_tokens is an empty token list instead of NULL)
+ALTER TABLE memories ADD COLUMN secret INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,10 @@
+-- Bot messages to delete once they are due (revealed secret memories); stored so a
+-- restart before the due time still deletes them
+CREATE TABLE IF NOT EXISTS pending_deletions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	chat_id INTEGER NOT NULL,
+	message_id INTEGER NOT NULL,
+	delete_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_deletions_delete_at ON pending_deletions(delete_at);
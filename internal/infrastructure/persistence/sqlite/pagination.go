@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"memory-bot/internal/domain/repository"
+)
+
+// pageQuery wraps a listing query so that it returns the page after the cursor position
+// The listing selects its sort keys as columns; rows are ordered by the keys and the ID,
+// all descending or all ascending, and one row beyond the limit is fetched so the page
+// can tell whether more follow. Seeking past the cursor replaces OFFSET, so later pages
+// cost the same as the first.
+func pageQuery(query string, args []interface{}, keys []string, ascending bool, position *repository.CursorPosition, limit int) (string, []interface{}) {
+	direction, seek := "DESC", "<"
+	if ascending {
+		direction, seek = "ASC", ">"
+	}
+
+	columns := append(append([]string{}, keys...), "id")
+	order := make([]string, len(columns))
+	for i, column := range columns {
+		order[i] = column + " " + direction
+	}
+
+	paged := "SELECT * FROM (" + query + ") AS page"
+	pagedArgs := append([]interface{}{}, args...)
+	if position != nil {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+		paged += fmt.Sprintf(" WHERE (%s) %s (%s)", strings.Join(columns, ", "), seek, placeholders)
+		for _, key := range position.Keys {
+			pagedArgs = append(pagedArgs, key)
+		}
+		pagedArgs = append(pagedArgs, position.ID)
+	}
+
+	if limit >= 0 {
+		limit++
+	}
+	paged += " ORDER BY " + strings.Join(order, ", ") + " LIMIT ?"
+	return paged, append(pagedArgs, limit)
+}
@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+)
+
+// PendingDeletionRepository is the SQLite implementation of repository.PendingDeletionRepository
+type PendingDeletionRepository struct {
+	conn *Connection
+}
+
+// NewPendingDeletionRepository creates a new SQLite pending deletion repository
+func NewPendingDeletionRepository(conn *Connection) *PendingDeletionRepository {
+	return &PendingDeletionRepository{
+		conn: conn,
+	}
+}
+
+// Add stores a message to delete and sets its ID
+func (r *PendingDeletionRepository) Add(ctx context.Context, deletion *entity.PendingDeletion) error {
+	result, err := r.conn.exec(ctx, `
+		INSERT INTO pending_deletions (chat_id, message_id, delete_at)
+		VALUES (?, ?, ?)
+	`, deletion.ChatID, deletion.MessageID, deletion.DeleteAt.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return fmt.Errorf("failed to add pending deletion: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get pending deletion ID: %w", err)
+	}
+	deletion.ID = int(id)
+
+	return nil
+}
+
+// FindDue returns the deletions due at the given time, oldest first
+func (r *PendingDeletionRepository) FindDue(ctx context.Context, now time.Time) ([]*entity.PendingDeletion, error) {
+	rows, err := r.conn.query(ctx, `
+		SELECT id, chat_id, message_id, delete_at
+		FROM pending_deletions
+		WHERE julianday(delete_at) <= julianday(?)
+		ORDER BY julianday(delete_at), id
+	`, now.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pending deletions: %w", err)
+	}
+	defer rows.Close()
+
+	deletions := []*entity.PendingDeletion{}
+	for rows.Next() {
+		deletion := &entity.PendingDeletion{}
+		var deleteAt string
+		if err := rows.Scan(&deletion.ID, &deletion.ChatID, &deletion.MessageID, &deleteAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		deletion.DeleteAt = parseSQLiteTime(deleteAt)
+		deletions = append(deletions, deletion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return deletions, nil
+}
+
+// Remove forgets a deletion
+func (r *PendingDeletionRepository) Remove(ctx context.Context, id int) error {
+	if _, err := r.conn.exec(ctx, `DELETE FROM pending_deletions WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to remove pending deletion: %w", err)
+	}
+	return nil
+}
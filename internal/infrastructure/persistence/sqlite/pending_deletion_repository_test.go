@@ -0,0 +1,58 @@
+//go:build fts5
+
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+)
+
+func TestPendingDeletionsSurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "memories.db")
+	conn, err := NewConnection(path)
+	if err != nil {
+		t.Fatalf("NewConnection: %v", err)
+	}
+
+	now := time.Now()
+	repo := NewPendingDeletionRepository(conn)
+	overdue := &entity.PendingDeletion{ChatID: -100, MessageID: 7, DeleteAt: now.Add(-time.Minute)}
+	later := &entity.PendingDeletion{ChatID: 42, MessageID: 8, DeleteAt: now.Add(time.Hour)}
+	for _, deletion := range []*entity.PendingDeletion{later, overdue} {
+		if err := repo.Add(ctx, deletion); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	conn.Close()
+
+	conn, err = NewConnection(path)
+	if err != nil {
+		t.Fatalf("NewConnection (restart): %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	repo = NewPendingDeletionRepository(conn)
+
+	due, err := repo.FindDue(ctx, now)
+	if err != nil {
+		t.Fatalf("FindDue: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != overdue.ID || due[0].ChatID != -100 || due[0].MessageID != 7 {
+		t.Fatalf("FindDue = %+v, want only the overdue deletion", due)
+	}
+
+	if err := repo.Remove(ctx, overdue.ID); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	due, err = repo.FindDue(ctx, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("FindDue (later): %v", err)
+	}
+	if len(due) != 1 || due[0].ID != later.ID {
+		t.Fatalf("FindDue (later) = %+v, want the remaining deletion", due)
+	}
+}
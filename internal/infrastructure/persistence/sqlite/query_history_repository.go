@@ -0,0 +1,233 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/encryption"
+)
+
+// maxQueryHistoryPerUser caps the stored history of each user
+const maxQueryHistoryPerUser = 500
+
+// QueryHistoryRepository is the SQLite implementation of repository.QueryHistoryRepository
+// Query texts are encrypted when encryption is enabled; queries are grouped by
+// query_key, which is the normalized query or its blind fingerprint
+type QueryHistoryRepository struct {
+	conn       *Connection
+	encryptor  *encryption.Encryptor
+	blindIndex *encryption.BlindIndex // nil when encryption is disabled
+}
+
+// NewQueryHistoryRepository creates a new SQLite query history repository
+func NewQueryHistoryRepository(conn *Connection, encryptor *encryption.Encryptor) *QueryHistoryRepository {
+	return &QueryHistoryRepository{
+		conn:       conn,
+		encryptor:  encryptor,
+		blindIndex: encryption.NewBlindIndexIfEnabled(encryptor),
+	}
+}
+
+// Record stores a query log entry and prunes the user's oldest entries
+func (r *QueryHistoryRepository) Record(ctx context.Context, entry *entity.QueryLogEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	encryptedQuery, err := encryption.EncryptIfEnabled(r.encryptor, entry.Query, queryBinding(entry.UserID))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt query: %w", err)
+	}
+
+	if _, err := r.conn.exec(ctx, `
+		INSERT INTO query_history (user_id, query_text, query_key, result_count, matched_step, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, entry.UserID, encryptedQuery, r.queryKey(entry.Query), entry.ResultCount, entry.MatchedStep, entry.CreatedAt); err != nil {
+		return fmt.Errorf("failed to record query: %w", err)
+	}
+
+	if _, err := r.conn.exec(ctx, `
+		DELETE FROM query_history
+		WHERE user_id = ? AND id NOT IN (
+			SELECT id FROM query_history WHERE user_id = ? ORDER BY id DESC LIMIT ?
+		)
+	`, entry.UserID, entry.UserID, maxQueryHistoryPerUser); err != nil {
+		return fmt.Errorf("failed to prune query history: %w", err)
+	}
+
+	return nil
+}
+
+// Recent retrieves the user's most recent distinct queries, newest first
+func (r *QueryHistoryRepository) Recent(ctx context.Context, userID int64, limit int) ([]string, error) {
+	rows, err := r.conn.query(ctx, `
+		SELECT query_text
+		FROM query_history
+		WHERE id IN (
+			SELECT MAX(id) FROM query_history WHERE user_id = ? GROUP BY query_key
+		)
+		ORDER BY id DESC
+		LIMIT ?
+	`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent queries: %w", err)
+	}
+	defer rows.Close()
+
+	queries := []string{}
+	for rows.Next() {
+		var query string
+		if err := rows.Scan(&query); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		decryptedQuery, err := encryption.DecryptIfEnabled(r.encryptor, query, queryBinding(userID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt query: %w", err)
+		}
+		queries = append(queries, decryptedQuery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return queries, nil
+}
+
+// Frequent retrieves the user's most frequent queries
+func (r *QueryHistoryRepository) Frequent(ctx context.Context, userID int64, limit int) ([]entity.QueryStat, error) {
+	rows, err := r.conn.query(ctx, `
+		SELECT h.user_id, h.query_text, g.runs, g.users, g.last_seen
+		FROM (
+			SELECT MAX(id) AS last_id, COUNT(*) AS runs, 1 AS users, MAX(created_at) AS last_seen
+			FROM query_history
+			WHERE user_id = ?
+			GROUP BY query_key
+		) AS g
+		JOIN query_history AS h ON h.id = g.last_id
+		ORDER BY g.runs DESC, g.last_id DESC
+		LIMIT ?
+	`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frequent queries: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanQueryStats(rows)
+}
+
+// ZeroResults retrieves the queries that most often returned no results
+func (r *QueryHistoryRepository) ZeroResults(ctx context.Context, limit int) ([]entity.QueryStat, error) {
+	rows, err := r.conn.query(ctx, `
+		SELECT h.user_id, h.query_text, g.misses, g.users, g.last_seen
+		FROM (
+			SELECT MAX(id) AS last_id, COUNT(*) AS misses, COUNT(DISTINCT user_id) AS users, MAX(created_at) AS last_seen
+			FROM query_history
+			WHERE result_count = 0
+			GROUP BY query_key
+		) AS g
+		JOIN query_history AS h ON h.id = g.last_id
+		ORDER BY g.misses DESC, g.last_id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zero-result queries: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanQueryStats(rows)
+}
+
+// StepStats counts searches per matched strategy step
+func (r *QueryHistoryRepository) StepStats(ctx context.Context) ([]entity.StepStat, error) {
+	rows, err := r.conn.query(ctx, `
+		SELECT matched_step, COUNT(*) AS runs
+		FROM query_history
+		GROUP BY matched_step
+		ORDER BY runs DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get step statistics: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []entity.StepStat{}
+	for rows.Next() {
+		var stat entity.StepStat
+		if err := rows.Scan(&stat.Step, &stat.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return stats, nil
+}
+
+// DeleteByUser removes the whole history of a user
+func (r *QueryHistoryRepository) DeleteByUser(ctx context.Context, userID int64) error {
+	if _, err := r.conn.exec(ctx, "DELETE FROM query_history WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete query history: %w", err)
+	}
+	return nil
+}
+
+// queryKey returns the grouping key of a query
+func (r *QueryHistoryRepository) queryKey(query string) string {
+	normalized := entity.NormalizeQuery(query)
+	if r.blindIndex == nil {
+		return normalized
+	}
+	return r.blindIndex.Fingerprint(normalized)
+}
+
+// scanQueryStats scans (user_id, query_text, count, users, last_seen) rows
+func (r *QueryHistoryRepository) scanQueryStats(rows *sql.Rows) ([]entity.QueryStat, error) {
+	stats := []entity.QueryStat{}
+
+	for rows.Next() {
+		var stat entity.QueryStat
+		var userID int64
+		var lastSeen string
+		if err := rows.Scan(&userID, &stat.Query, &stat.Count, &stat.Users, &lastSeen); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		decryptedQuery, err := encryption.DecryptIfEnabled(r.encryptor, stat.Query, queryBinding(userID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt query: %w", err)
+		}
+		stat.Query = decryptedQuery
+		stat.LastSeen = parseSQLiteTime(lastSeen)
+
+		stats = append(stats, stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return stats, nil
+}
+
+// parseSQLiteTime parses a timestamp returned by an aggregate (which loses the column type)
+func parseSQLiteTime(value string) time.Time {
+	for _, layout := range []string{
+		"2006-01-02 15:04:05.999999999-07:00",
+		"2006-01-02T15:04:05.999999999-07:00",
+		"2006-01-02 15:04:05",
+		time.RFC3339Nano,
+	} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
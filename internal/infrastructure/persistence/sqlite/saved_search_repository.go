@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/encryption"
+)
+
+// SavedSearchRepository is the SQLite implementation of repository.SavedSearchRepository
+// Names and queries are encrypted when encryption is enabled, bound to their owner
+// like the query history
+type SavedSearchRepository struct {
+	conn      *Connection
+	encryptor *encryption.Encryptor
+}
+
+// NewSavedSearchRepository creates a new SQLite saved search repository
+func NewSavedSearchRepository(conn *Connection, encryptor *encryption.Encryptor) *SavedSearchRepository {
+	return &SavedSearchRepository{
+		conn:      conn,
+		encryptor: encryptor,
+	}
+}
+
+// Save stores a new saved search
+func (r *SavedSearchRepository) Save(ctx context.Context, search *entity.SavedSearch) (int64, error) {
+	if err := search.Validate(); err != nil {
+		return 0, err
+	}
+
+	aad := queryBinding(search.UserID)
+	encryptedName, err := encryption.EncryptIfEnabled(r.encryptor, search.Name, aad)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encrypt saved search name: %w", err)
+	}
+	encryptedQuery, err := encryption.EncryptIfEnabled(r.encryptor, search.Query, aad)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encrypt saved search query: %w", err)
+	}
+
+	result, err := r.conn.exec(ctx, `
+		INSERT INTO saved_searches (user_id, chat_id, name, query, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, search.UserID, search.ChatID, encryptedName, encryptedQuery, search.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save saved search: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	log.Printf("Saved search stored: ID=%d, UserID=%d", id, search.UserID)
+	return id, nil
+}
+
+// FindByUser retrieves all saved searches of a user, oldest first
+func (r *SavedSearchRepository) FindByUser(ctx context.Context, userID int64) ([]*entity.SavedSearch, error) {
+	rows, err := r.conn.query(ctx, `
+		SELECT id, user_id, chat_id, name, query, created_at
+		FROM saved_searches
+		WHERE user_id = ?
+		ORDER BY created_at ASC, id ASC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	searches := []*entity.SavedSearch{}
+	for rows.Next() {
+		var s entity.SavedSearch
+		if err := rows.Scan(&s.ID, &s.UserID, &s.ChatID, &s.Name, &s.Query, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if s.Name, err = encryption.DecryptIfEnabled(r.encryptor, s.Name, queryBinding(s.UserID)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt saved search name: %w", err)
+		}
+		if s.Query, err = encryption.DecryptIfEnabled(r.encryptor, s.Query, queryBinding(s.UserID)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt saved search query: %w", err)
+		}
+		searches = append(searches, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return searches, nil
+}
+
+// Delete removes a saved search with authorization check
+func (r *SavedSearchRepository) Delete(ctx context.Context, id int, userID int64) error {
+	result, err := r.conn.exec(ctx, `
+		DELETE FROM saved_searches
+		WHERE id = ? AND user_id = ?
+	`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if affected == 0 {
+		return entity.ErrUnauthorized
+	}
+
+	log.Printf("Saved search deleted: ID=%d, UserID=%d", id, userID)
+	return nil
+}
@@ -0,0 +1,246 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+)
+
+// Supported FTS5 tokenizers (FTS_TOKENIZER)
+const (
+	// DefaultTokenizer keeps dots inside tokens so versions and IPs stay searchable
+	DefaultTokenizer = "unicode61"
+
+	// PorterTokenizer adds English stemming ("meetings" matches "meeting")
+	PorterTokenizer = "porter"
+
+	// TrigramTokenizer matches substrings, useful for scripts such as Sinhala
+	TrigramTokenizer = "trigram"
+
+	// NoDiacriticsTokenizer folds diacritics, including composed characters
+	NoDiacriticsTokenizer = "unicode61_nodiacritics"
+)
+
+// tokenizerSpecs maps tokenizer names to FTS5 tokenize options
+var tokenizerSpecs = map[string]string{
+	DefaultTokenizer:      "unicode61 tokenchars '.'",
+	PorterTokenizer:       "porter unicode61 tokenchars '.'",
+	TrigramTokenizer:      "trigram",
+	NoDiacriticsTokenizer: "unicode61 remove_diacritics 2 tokenchars '.'",
+}
+
+// tokenizeClausePattern extracts the tokenize option from the stored CREATE statement
+var tokenizeClausePattern = regexp.MustCompile(`tokenize\s*=\s*"([^"]*)"`)
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// TokenizerSpec returns the FTS5 tokenize option for a tokenizer name
+func TokenizerSpec(name string) (string, error) {
+	spec, ok := tokenizerSpecs[name]
+	if !ok {
+		return "", fmt.Errorf("unsupported FTS tokenizer: %s", name)
+	}
+	return spec, nil
+}
+
+// createFTSTable creates the FTS5 virtual table with the given tokenizer
+// The index is fed from search_tokens and tag_tokens (blind index tokens) when the content
+// and tags are encrypted, otherwise from the plaintext text_content and tags
+func createFTSTable(db execer, tokenizer string) error {
+	spec, err := TokenizerSpec(tokenizer)
+	if err != nil {
+		return err
+	}
+
+	createFTSSQL := fmt.Sprintf(`
+	CREATE VIRTUAL TABLE IF NOT EXISTS memories_fts USING fts5(
+		text_content,
+		tags,
+		content='memories',
+		content_rowid='id',
+		tokenize="%s"
+	);`, spec)
+
+	if _, err := db.Exec(createFTSSQL); err != nil {
+		return fmt.Errorf("failed to create FTS5 table: %w", err)
+	}
+	return nil
+}
+
+// dropTriggers removes the FTS5 synchronization triggers
+func dropTriggers(db execer) error {
+	for _, name := range []string{"memories_ai", "memories_au", "memories_ad", "memories_trash", "memories_restore"} {
+		if _, err := db.Exec("DROP TRIGGER IF EXISTS " + name); err != nil {
+			return fmt.Errorf("failed to drop trigger: %w", err)
+		}
+	}
+	return nil
+}
+
+// createTriggers (re)creates the triggers that keep the FTS5 table in sync
+// memories_fts is an external content table, so removals must go through the
+// 'delete' command with the previously indexed values. Memories in the trash
+// (deleted_at set) are not indexed.
+func createTriggers(db execer) error {
+	if err := dropTriggers(db); err != nil {
+		return err
+	}
+
+	triggers := []string{
+		`CREATE TRIGGER memories_ai AFTER INSERT ON memories WHEN new.deleted_at IS NULL BEGIN
+			INSERT INTO memories_fts(rowid, text_content, tags)
+			VALUES (new.id, COALESCE(new.search_tokens, new.text_content), COALESCE(new.tag_tokens, new.tags));
+		END;`,
+		`CREATE TRIGGER memories_au AFTER UPDATE OF text_content, search_tokens, tags, tag_tokens ON memories
+		WHEN old.deleted_at IS NULL AND new.deleted_at IS NULL BEGIN
+			INSERT INTO memories_fts(memories_fts, rowid, text_content, tags)
+			VALUES ('delete', old.id, COALESCE(old.search_tokens, old.text_content), COALESCE(old.tag_tokens, old.tags));
+			INSERT INTO memories_fts(rowid, text_content, tags)
+			VALUES (new.id, COALESCE(new.search_tokens, new.text_content), COALESCE(new.tag_tokens, new.tags));
+		END;`,
+		`CREATE TRIGGER memories_ad AFTER DELETE ON memories WHEN old.deleted_at IS NULL BEGIN
+			INSERT INTO memories_fts(memories_fts, rowid, text_content, tags)
+			VALUES ('delete', old.id, COALESCE(old.search_tokens, old.text_content), COALESCE(old.tag_tokens, old.tags));
+		END;`,
+		`CREATE TRIGGER memories_trash AFTER UPDATE OF deleted_at ON memories
+		WHEN old.deleted_at IS NULL AND new.deleted_at IS NOT NULL BEGIN
+			INSERT INTO memories_fts(memories_fts, rowid, text_content, tags)
+			VALUES ('delete', old.id, COALESCE(old.search_tokens, old.text_content), COALESCE(old.tag_tokens, old.tags));
+		END;`,
+		`CREATE TRIGGER memories_restore AFTER UPDATE OF deleted_at ON memories
+		WHEN old.deleted_at IS NOT NULL AND new.deleted_at IS NULL BEGIN
+			INSERT INTO memories_fts(rowid, text_content, tags)
+			VALUES (new.id, COALESCE(new.search_tokens, new.text_content), COALESCE(new.tag_tokens, new.tags));
+		END;`,
+	}
+
+	for _, trigger := range triggers {
+		if _, err := db.Exec(trigger); err != nil {
+			return fmt.Errorf("failed to create trigger: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// SearchIndex maintains the FTS5 index (tokenizer changes and reindexing)
+// It replaces the manual rebuild_fts.sh script
+type SearchIndex struct {
+	conn      *Connection
+	tokenizer string
+}
+
+// NewSearchIndex creates a search index maintainer for the configured tokenizer
+func NewSearchIndex(conn *Connection, tokenizer string) (*SearchIndex, error) {
+	if _, err := TokenizerSpec(tokenizer); err != nil {
+		return nil, err
+	}
+
+	return &SearchIndex{
+		conn:      conn,
+		tokenizer: tokenizer,
+	}, nil
+}
+
+// EnsureTokenizer reindexes when the index was built with a different tokenizer
+// Returns true if the index was rebuilt
+func (s *SearchIndex) EnsureTokenizer(ctx context.Context) (bool, error) {
+	current, err := s.currentSpec(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	wanted, _ := TokenizerSpec(s.tokenizer)
+	if current == wanted {
+		return false, nil
+	}
+
+	log.Printf("FTS tokenizer changed (%q -> %q), reindexing...", current, wanted)
+	count, err := s.Reindex(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	log.Printf("✅ FTS index rebuilt with %s tokenizer: %d memories indexed", s.tokenizer, count)
+	return true, nil
+}
+
+// Reindex drops and rebuilds the FTS5 table and its triggers inside a transaction
+// Returns the number of indexed memories
+func (s *SearchIndex) Reindex(ctx context.Context) (int, error) {
+	tx, err := s.conn.beginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin reindex transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := dropTriggers(tx); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS memories_fts`); err != nil {
+		return 0, fmt.Errorf("failed to drop FTS5 table: %w", err)
+	}
+
+	if err := createFTSTable(tx, s.tokenizer); err != nil {
+		return 0, err
+	}
+	if err := createTriggers(tx); err != nil {
+		return 0, err
+	}
+
+	count, err := fillFTSTable(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit reindex: %w", err)
+	}
+
+	return count, nil
+}
+
+// fillFTSTable indexes every memory outside the trash into an empty FTS5 table
+func fillFTSTable(db execer) (int, error) {
+	result, err := db.Exec(`
+		INSERT INTO memories_fts(rowid, text_content, tags)
+		SELECT id, COALESCE(search_tokens, text_content), COALESCE(tag_tokens, tags) FROM memories WHERE deleted_at IS NULL
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rebuild FTS5 index: %w", err)
+	}
+
+	count, _ := result.RowsAffected()
+	return int(count), nil
+}
+
+// Tokenizer returns the configured tokenizer name
+func (s *SearchIndex) Tokenizer() string {
+	return s.tokenizer
+}
+
+// currentSpec reads the tokenize option the existing FTS5 table was created with
+func (s *SearchIndex) currentSpec(ctx context.Context) (string, error) {
+	var createSQL string
+	err := s.conn.queryRow(ctx,
+		"SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'memories_fts'",
+	).Scan(&createSQL)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read FTS5 table definition: %w", err)
+	}
+
+	match := tokenizeClausePattern.FindStringSubmatch(createSQL)
+	if match == nil {
+		// FTS5 defaults to unicode61 without options
+		return "unicode61", nil
+	}
+	return match[1], nil
+}
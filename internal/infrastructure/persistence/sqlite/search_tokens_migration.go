@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"memory-bot/pkg/encryption"
+)
+
+// MigrateSearchTokens moves the search index to blind index tokens
+// Legacy databases kept a plaintext copy of every memory in search_content; that
+// column is converted and dropped. With encryption enabled, rows without tokens
+// (e.g. saved before encryption was turned on) are tokenized as well.
+// Returns the number of converted memories.
+func (r *MemoryRepository) MigrateSearchTokens(ctx context.Context) (int, error) {
+	legacy, err := r.conn.columnExists("memories", "search_content")
+	if err != nil {
+		return 0, err
+	}
+	if !legacy && r.blindIndex == nil {
+		return 0, nil
+	}
+
+	tx, err := r.conn.beginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin search token migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Vaulted memories are always indexed with their vault's tokens; secret memories
+	// that were given blind tokens lose them
+	query := `SELECT id, user_id, text_content, NULL, secret FROM memories
+		WHERE (search_tokens IS NULL OR secret = 1 AND search_tokens != '') AND vaulted = 0`
+	if legacy {
+		query = `SELECT id, user_id, text_content, search_content, secret FROM memories WHERE vaulted = 0`
+	}
+
+	type pendingRow struct {
+		id        int
+		plaintext string
+		secret    bool
+	}
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load memories for search token migration: %w", err)
+	}
+
+	var pending []pendingRow
+	for rows.Next() {
+		var id int
+		var userID int64
+		var content string
+		var searchContent sql.NullString
+		var secret bool
+		if err := rows.Scan(&id, &userID, &content, &searchContent, &secret); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		plaintext := searchContent.String
+		if !searchContent.Valid {
+			plaintext, err = encryption.DecryptIfEnabled(r.encryptor, content, memoryBinding(userID, id))
+			if err != nil {
+				rows.Close()
+				return 0, fmt.Errorf("failed to decrypt memory %d: %w", id, err)
+			}
+		}
+
+		pending = append(pending, pendingRow{id: id, plaintext: plaintext, secret: secret})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	if !legacy && len(pending) == 0 {
+		return 0, nil
+	}
+
+	log.Printf("🔄 Migrating search index to blind tokens for %d memories...", len(pending))
+
+	// The index is rebuilt in full below, so the sync triggers are not needed meanwhile
+	if err := dropTriggers(tx); err != nil {
+		return 0, err
+	}
+
+	for _, row := range pending {
+		tokens := r.searchTokens(row.plaintext)
+		if row.secret {
+			tokens = ""
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE memories SET search_tokens = ? WHERE id = ?",
+			tokens, row.id,
+		); err != nil {
+			return 0, fmt.Errorf("failed to update search tokens for memory %d: %w", row.id, err)
+		}
+	}
+
+	if legacy {
+		if _, err := tx.Exec("ALTER TABLE memories DROP COLUMN search_content"); err != nil {
+			return 0, fmt.Errorf("failed to drop search_content column: %w", err)
+		}
+	}
+
+	// Replace every plaintext entry in the FTS5 index
+	if _, err := tx.Exec("INSERT INTO memories_fts(memories_fts) VALUES('delete-all')"); err != nil {
+		return 0, fmt.Errorf("failed to clear FTS5 index: %w", err)
+	}
+	if _, err := fillFTSTable(tx); err != nil {
+		return 0, err
+	}
+	if err := createTriggers(tx); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit search token migration: %w", err)
+	}
+
+	log.Printf("✅ Search index migrated: %d memories converted", len(pending))
+	return len(pending), nil
+}
@@ -0,0 +1,151 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// maxCachedStatements bounds the statement cache of a pool; queries beyond it run unprepared
+const maxCachedStatements = 256
+
+// statementCache keeps the prepared statements of one pool for the lifetime of the connection
+// A nil cache prepares nothing and runs every query directly on the pool.
+type statementCache struct {
+	db    *sql.DB
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+// newStatementCache creates an empty statement cache for a pool
+func newStatementCache(db *sql.DB) *statementCache {
+	return &statementCache{
+		db:    db,
+		stmts: make(map[string]*sql.Stmt),
+	}
+}
+
+// prepare returns the cached statement of a query, preparing it on first use
+// It returns nil when the cache is full or disabled.
+func (s *statementCache) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if stmt, ok := s.lookup(query); ok {
+		return stmt, nil
+	}
+	if s.full() {
+		return nil, nil
+	}
+
+	// Prepare without holding the lock: it waits for a connection of the pool
+	stmt, err := s.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cached, ok := s.stmts[query]; ok {
+		stmt.Close()
+		return cached, nil
+	}
+	if len(s.stmts) >= maxCachedStatements {
+		stmt.Close()
+		return nil, nil
+	}
+	s.stmts[query] = stmt
+	return stmt, nil
+}
+
+// lookup returns the statement of a query if it is already cached
+func (s *statementCache) lookup(query string) (*sql.Stmt, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stmt, ok := s.stmts[query]
+	return stmt, ok
+}
+
+// full reports whether the cache takes no more statements
+func (s *statementCache) full() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.stmts) >= maxCachedStatements
+}
+
+// close closes every cached statement
+func (s *statementCache) close() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for query, stmt := range s.stmts {
+		stmt.Close()
+		delete(s.stmts, query)
+	}
+}
+
+// exec runs a write with a cached statement, inside the unit of work carried by ctx if any
+func (c *Connection) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		if stmt, ok := c.writeStmts.lookup(query); ok {
+			return tx.StmtContext(ctx, stmt).ExecContext(ctx, args...)
+		}
+		return tx.ExecContext(ctx, query, args...)
+	}
+
+	stmt, err := c.writeStmts.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if stmt == nil {
+		return c.DB.ExecContext(ctx, query, args...)
+	}
+	return stmt.ExecContext(ctx, args...)
+}
+
+// query runs a read with a cached statement on the read pool
+// Inside a unit of work it reads through the transaction, so it sees its uncommitted writes;
+// the transaction holds the only writer connection, so statements are not prepared there.
+func (c *Connection) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		if stmt, ok := c.writeStmts.lookup(query); ok {
+			return tx.StmtContext(ctx, stmt).QueryContext(ctx, args...)
+		}
+		return tx.QueryContext(ctx, query, args...)
+	}
+
+	stmt, err := c.readStmts.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if stmt == nil {
+		return c.ReadDB.QueryContext(ctx, query, args...)
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// queryRow runs a single-row read like query; errors are reported by Scan
+func (c *Connection) queryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		if stmt, ok := c.writeStmts.lookup(query); ok {
+			return tx.StmtContext(ctx, stmt).QueryRowContext(ctx, args...)
+		}
+		return tx.QueryRowContext(ctx, query, args...)
+	}
+
+	stmt, err := c.readStmts.prepare(ctx, query)
+	if err != nil || stmt == nil {
+		return c.ReadDB.QueryRowContext(ctx, query, args...)
+	}
+	return stmt.QueryRowContext(ctx, args...)
+}
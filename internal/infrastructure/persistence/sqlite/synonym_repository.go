@@ -0,0 +1,212 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/service"
+)
+
+// SynonymRepository is the SQLite implementation of repository.SynonymRepository
+type SynonymRepository struct {
+	conn *Connection
+}
+
+// NewSynonymRepository creates a new SQLite synonym repository
+func NewSynonymRepository(conn *Connection) *SynonymRepository {
+	return &SynonymRepository{
+		conn: conn,
+	}
+}
+
+// Add stores a synonym set, merging it with the owner's sets that share a term
+func (r *SynonymRepository) Add(ctx context.Context, set *entity.SynonymSet) (*entity.SynonymSet, error) {
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.conn.beginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(set.Terms)), ",")
+	args := []interface{}{set.UserID}
+	for _, term := range set.Terms {
+		args = append(args, term)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT set_id FROM synonym_terms
+		WHERE user_id = ? AND term IN (`+placeholders+`)
+		ORDER BY set_id ASC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find overlapping synonym sets: %w", err)
+	}
+
+	var setIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		setIDs = append(setIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	var targetID int
+	if len(setIDs) == 0 {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO synonym_sets (user_id, created_at) VALUES (?, ?)
+		`, set.UserID, set.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save synonym set: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+		}
+		targetID = int(id)
+	} else {
+		// Terms are unique per owner, so overlapping sets collapse into the oldest one
+		targetID = setIDs[0]
+		for _, id := range setIDs[1:] {
+			if _, err := tx.ExecContext(ctx, "UPDATE synonym_terms SET set_id = ? WHERE set_id = ?", targetID, id); err != nil {
+				return nil, fmt.Errorf("failed to merge synonym sets: %w", err)
+			}
+			if _, err := tx.ExecContext(ctx, "DELETE FROM synonym_sets WHERE id = ?", id); err != nil {
+				return nil, fmt.Errorf("failed to merge synonym sets: %w", err)
+			}
+		}
+	}
+
+	for _, term := range set.Terms {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO synonym_terms (set_id, user_id, term) VALUES (?, ?, ?)
+		`, targetID, set.UserID, term); err != nil {
+			return nil, fmt.Errorf("failed to save synonym %s: %w", term, err)
+		}
+	}
+
+	sets, err := findSynonymSets(ctx, tx, "s.id = ?", targetID)
+	if err != nil {
+		return nil, err
+	}
+	if len(sets) == 0 {
+		return nil, entity.ErrSynonymNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Synonym set saved: ID=%d, UserID=%d, Terms=%d", targetID, set.UserID, len(sets[0].Terms))
+	return sets[0], nil
+}
+
+// FindForUser retrieves the user's synonym sets followed by the global ones
+func (r *SynonymRepository) FindForUser(ctx context.Context, userID int64) ([]*entity.SynonymSet, error) {
+	return findSynonymSets(ctx, r.conn.reader(ctx), "s.user_id IN (?, ?)", userID, entity.GlobalSynonymOwner)
+}
+
+// RemoveTerm removes a term from the owner's synonym sets
+func (r *SynonymRepository) RemoveTerm(ctx context.Context, userID int64, term string) error {
+	term = entity.NormalizeSynonymTerm(term)
+
+	tx, err := r.conn.beginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var setID int
+	err = tx.QueryRowContext(ctx, `
+		SELECT set_id FROM synonym_terms WHERE user_id = ? AND term = ?
+	`, userID, term).Scan(&setID)
+	if err == sql.ErrNoRows {
+		return entity.ErrSynonymNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find synonym: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM synonym_terms WHERE user_id = ? AND term = ?", userID, term); err != nil {
+		return fmt.Errorf("failed to delete synonym: %w", err)
+	}
+
+	// A single remaining term is no longer a synonym set
+	var remaining int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM synonym_terms WHERE set_id = ?", setID).Scan(&remaining); err != nil {
+		return fmt.Errorf("failed to count synonyms: %w", err)
+	}
+	if remaining < 2 {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM synonym_terms WHERE set_id = ?", setID); err != nil {
+			return fmt.Errorf("failed to delete synonym set: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM synonym_sets WHERE id = ?", setID); err != nil {
+			return fmt.Errorf("failed to delete synonym set: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// loadSynonymExpander builds the synonym expander for a user's searches
+func loadSynonymExpander(ctx context.Context, q queryer, userID int64) (*service.SynonymExpander, error) {
+	sets, err := findSynonymSets(ctx, q, "s.user_id IN (?, ?)", userID, entity.GlobalSynonymOwner)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewSynonymExpander(sets), nil
+}
+
+// findSynonymSets loads synonym sets with their terms; user sets come before global ones
+func findSynonymSets(ctx context.Context, q queryer, condition string, args ...interface{}) ([]*entity.SynonymSet, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT s.id, s.user_id, s.created_at, t.term
+		FROM synonym_sets AS s
+		JOIN synonym_terms AS t ON t.set_id = s.id
+		WHERE `+condition+`
+		ORDER BY s.user_id = 0, s.id, t.term
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find synonym sets: %w", err)
+	}
+	defer rows.Close()
+
+	sets := []*entity.SynonymSet{}
+	var current *entity.SynonymSet
+	for rows.Next() {
+		var set entity.SynonymSet
+		var term string
+		if err := rows.Scan(&set.ID, &set.UserID, &set.CreatedAt, &term); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if current == nil || current.ID != set.ID {
+			current = &set
+			sets = append(sets, current)
+		}
+		current.Terms = append(current.Terms, term)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return sets, nil
+}
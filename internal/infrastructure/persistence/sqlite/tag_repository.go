@@ -0,0 +1,604 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/encryption"
+)
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// TagRepository is the SQLite implementation of repository.TagRepository
+// With tags in the field encryption policy, memory_tags and tag_aliases hold
+// deterministically encrypted tags, so tag paths are compared after decryption
+type TagRepository struct {
+	conn   *Connection
+	fields *fieldCipher
+}
+
+// NewTagRepository creates a new SQLite tag repository
+func NewTagRepository(conn *Connection, encryptor *encryption.Encryptor, policy FieldPolicy) *TagRepository {
+	return &TagRepository{
+		conn:   conn,
+		fields: newFieldCipher(encryptor, policy),
+	}
+}
+
+// ListTags returns the direct children of parent with the number of memories below each
+func (r *TagRepository) ListTags(ctx context.Context, userID int64, parent string) ([]entity.TagCount, error) {
+	parent = entity.NormalizeTag(parent)
+
+	rows, err := r.conn.query(ctx, `
+		SELECT memory_id, tag
+		FROM memory_tags
+		WHERE user_id = ? AND
+			memory_id NOT IN (SELECT id FROM memories WHERE user_id = memory_tags.user_id AND deleted_at IS NOT NULL)
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	prefix := ""
+	if parent != "" {
+		prefix = parent + entity.TagSeparator
+	}
+
+	// The first level of the tag path below parent is the child
+	children := make(map[string]*entity.TagCount)
+	memories := make(map[string]map[int]bool)
+	for rows.Next() {
+		var memoryID int
+		var stored string
+		if err := rows.Scan(&memoryID, &stored); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		tag, err := r.fields.openTag(userID, stored)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(tag, prefix) || tag == prefix {
+			continue
+		}
+		rest := strings.TrimPrefix(tag, prefix)
+		child, _, nested := strings.Cut(rest, entity.TagSeparator)
+
+		count, ok := children[child]
+		if !ok {
+			count = &entity.TagCount{Name: prefix + child}
+			children[child] = count
+			memories[child] = make(map[int]bool)
+		}
+		memories[child][memoryID] = true
+		count.Count = len(memories[child])
+		count.HasChildren = count.HasChildren || nested
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	tags := make([]entity.TagCount, 0, len(children))
+	for _, tag := range children {
+		tags = append(tags, *tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Name < tags[j].Name
+	})
+
+	return tags, nil
+}
+
+// CountTag returns the number of memories carrying the tag or one of its sub-tags
+func (r *TagRepository) CountTag(ctx context.Context, userID int64, tag string) (int, error) {
+	stored, err := tagsBelow(ctx, r.conn.reader(ctx), r.fields, userID, entity.NormalizeTag(tag))
+	if err != nil {
+		return 0, err
+	}
+	if len(stored) == 0 {
+		return 0, nil
+	}
+
+	args := append([]interface{}{userID}, stored.args()...)
+
+	var count int
+	err = r.conn.queryRow(ctx, `
+		SELECT COUNT(DISTINCT memory_id)
+		FROM memory_tags
+		WHERE user_id = ? AND tag IN `+placeholders(len(stored))+` AND
+			memory_id NOT IN (SELECT id FROM memories WHERE user_id = memory_tags.user_id AND deleted_at IS NOT NULL)
+	`, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tag: %w", err)
+	}
+
+	return count, nil
+}
+
+// Rename renames a tag and its sub-tags (#work/x -> #job/x)
+func (r *TagRepository) Rename(ctx context.Context, userID int64, from, to string) (int, error) {
+	return r.retag(ctx, userID, from, to, false)
+}
+
+// Merge moves every memory of source onto target; duplicate tags collapse into one
+func (r *TagRepository) Merge(ctx context.Context, userID int64, source, target string) (int, error) {
+	return r.retag(ctx, userID, source, target, true)
+}
+
+// retag runs a rename or merge in a single transaction
+func (r *TagRepository) retag(ctx context.Context, userID int64, from, to string, merge bool) (int, error) {
+	from = entity.NormalizeTag(from)
+	to = entity.NormalizeTag(to)
+	if from == "" || to == "" || entity.IsTagOrDescendant(to, from) {
+		return 0, entity.ErrInvalidTag
+	}
+
+	tx, err := r.conn.beginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if !merge {
+		inUse, err := tagsBelow(ctx, tx, r.fields, userID, to)
+		if err != nil {
+			return 0, err
+		}
+		if len(inUse) > 0 {
+			return 0, entity.ErrTagExists
+		}
+	}
+
+	affected, err := moveTag(ctx, tx, r.fields, userID, from, to)
+	if err != nil {
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, entity.ErrTagNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Tag moved: UserID=%d, %s -> %s (merge=%v), memories=%d", userID, from, to, merge, affected)
+	return affected, nil
+}
+
+// SetAlias stores an alias and merges memories already tagged with it into the target tag
+func (r *TagRepository) SetAlias(ctx context.Context, alias *entity.TagAlias) (int, error) {
+	name := entity.NormalizeTag(alias.Alias)
+	if name == "" {
+		return 0, entity.ErrInvalidTag
+	}
+
+	tx, err := r.conn.beginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Resolve the target through existing aliases so aliases never chain
+	aliases, err := loadTagAliases(ctx, tx, r.fields, alias.UserID)
+	if err != nil {
+		return 0, err
+	}
+	delete(aliases, name)
+
+	target := entity.ResolveTagAlias(entity.NormalizeTag(alias.Tag), aliases)
+	if target == "" || entity.IsTagOrDescendant(target, name) || entity.IsTagOrDescendant(name, target) {
+		return 0, entity.ErrInvalidTag
+	}
+
+	storedAlias, err := r.fields.sealTag(alias.UserID, name)
+	if err != nil {
+		return 0, err
+	}
+	storedTarget, err := r.fields.sealTag(alias.UserID, target)
+	if err != nil {
+		return 0, err
+	}
+
+	// Replace the alias also when it is still stored under a previous key
+	previous, err := r.fields.sealLookup(FieldTags, alias.UserID, name)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM tag_aliases WHERE user_id = ? AND alias IN "+placeholders(len(previous)),
+		append([]interface{}{alias.UserID}, previous...)...,
+	); err != nil {
+		return 0, fmt.Errorf("failed to replace tag alias: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO tag_aliases (user_id, alias, tag)
+		VALUES (?, ?, ?)
+		ON CONFLICT(user_id, alias) DO UPDATE SET tag = excluded.tag
+	`, alias.UserID, storedAlias, storedTarget); err != nil {
+		return 0, fmt.Errorf("failed to save tag alias: %w", err)
+	}
+
+	affected, err := moveTag(ctx, tx, r.fields, alias.UserID, name, target)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	alias.Alias = name
+	alias.Tag = target
+
+	log.Printf("Tag alias saved: UserID=%d, %s -> %s, memories moved=%d", alias.UserID, name, target, affected)
+	return affected, nil
+}
+
+// DeleteAlias removes an alias with authorization check
+func (r *TagRepository) DeleteAlias(ctx context.Context, userID int64, alias string) error {
+	stored, err := r.fields.sealLookup(FieldTags, userID, entity.NormalizeTag(alias))
+	if err != nil {
+		return err
+	}
+
+	result, err := r.conn.exec(ctx, `
+		DELETE FROM tag_aliases
+		WHERE user_id = ? AND alias IN `+placeholders(len(stored))+`
+	`, append([]interface{}{userID}, stored...)...)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag alias: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if affected == 0 {
+		return entity.ErrTagNotFound
+	}
+
+	return nil
+}
+
+// FindAliases retrieves all aliases of a user, sorted by alias
+func (r *TagRepository) FindAliases(ctx context.Context, userID int64) ([]*entity.TagAlias, error) {
+	rows, err := r.conn.query(ctx, `
+		SELECT user_id, alias, tag
+		FROM tag_aliases
+		WHERE user_id = ?
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tag aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := []*entity.TagAlias{}
+	for rows.Next() {
+		var alias entity.TagAlias
+		if err := rows.Scan(&alias.UserID, &alias.Alias, &alias.Tag); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		var err error
+		if alias.Alias, err = r.fields.openTag(userID, alias.Alias); err != nil {
+			return nil, err
+		}
+		if alias.Tag, err = r.fields.openTag(userID, alias.Tag); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, &alias)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	// Encrypted aliases can't be ordered in SQL
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Alias < aliases[j].Alias })
+
+	return aliases, nil
+}
+
+// MigrateTags fills memory_tags from the legacy space-joined tags column
+// Runs only while the tag table is still empty; tags are normalized on the way
+func (r *TagRepository) MigrateTags(ctx context.Context) (int, error) {
+	var existing int
+	if err := r.conn.queryRow(ctx, "SELECT COUNT(*) FROM memory_tags").Scan(&existing); err != nil {
+		return 0, fmt.Errorf("failed to count tags: %w", err)
+	}
+	if existing > 0 {
+		return 0, nil
+	}
+
+	tx, err := r.conn.beginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	type taggedMemory struct {
+		id     int
+		userID int64
+		tags   []string
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, user_id, tags FROM memories WHERE tags IS NOT NULL AND tags != '' AND vaulted = 0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to load tagged memories: %w", err)
+	}
+
+	var memories []taggedMemory
+	for rows.Next() {
+		var m taggedMemory
+		var tags string
+		if err := rows.Scan(&m.id, &m.userID, &tags); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+		stored, err := r.fields.openTags(tags, memoryBinding(m.userID, m.id))
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("memory %d: %w", m.id, err)
+		}
+		m.tags = normalizeTags(stored)
+		memories = append(memories, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	if len(memories) == 0 {
+		return 0, nil
+	}
+
+	for _, m := range memories {
+		if err := writeMemoryTags(ctx, tx, r.fields, m.id, m.userID, m.tags); err != nil {
+			return 0, err
+		}
+		if err := storeMemoryTags(ctx, tx, r.fields, m.id, m.userID, m.tags); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("✅ Tag index built for %d memories", len(memories))
+	return len(memories), nil
+}
+
+// Tag helpers shared with MemoryRepository
+
+// normalizeTags normalizes raw tags and drops empty and duplicate entries
+func normalizeTags(raw []string) []string {
+	tags := []string{}
+	seen := make(map[string]bool)
+
+	for _, tag := range raw {
+		tag = entity.NormalizeTag(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// loadTagAliases returns the user's aliases as alias -> tag
+func loadTagAliases(ctx context.Context, q queryer, fields *fieldCipher, userID int64) (map[string]string, error) {
+	rows, err := q.QueryContext(ctx, "SELECT alias, tag FROM tag_aliases WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tag aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := make(map[string]string)
+	for rows.Next() {
+		var alias, tag string
+		if err := rows.Scan(&alias, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		name, err := fields.openTag(userID, alias)
+		if err != nil {
+			return nil, err
+		}
+		if aliases[name], err = fields.openTag(userID, tag); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// writeMemoryTags stores the tags of one memory in the tag index
+func writeMemoryTags(ctx context.Context, q queryer, fields *fieldCipher, memoryID int, userID int64, tags []string) error {
+	for _, tag := range tags {
+		stored, err := fields.sealTag(userID, tag)
+		if err != nil {
+			return err
+		}
+		if _, err := q.ExecContext(ctx, `
+			INSERT OR IGNORE INTO memory_tags (memory_id, user_id, tag)
+			VALUES (?, ?, ?)
+		`, memoryID, userID, stored); err != nil {
+			return fmt.Errorf("failed to save tag %s: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// storeMemoryTags rewrites the tags column of a memory (and its blind tokens)
+func storeMemoryTags(ctx context.Context, q queryer, fields *fieldCipher, memoryID int, userID int64, tags []string) error {
+	stored, err := fields.sealTags(tags, memoryBinding(userID, memoryID))
+	if err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx,
+		"UPDATE memories SET tags = ?, tag_tokens = ? WHERE id = ?",
+		stored, fields.tagTokens(tags), memoryID,
+	); err != nil {
+		return fmt.Errorf("failed to update tags of memory %d: %w", memoryID, err)
+	}
+	return nil
+}
+
+// storedTags maps stored tag values to tag names
+type storedTags map[string]string
+
+// args returns the stored values as query arguments, in a stable order
+func (t storedTags) args() []interface{} {
+	values := make([]string, 0, len(t))
+	for value := range t {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
+
+// tagsBelow returns the user's stored tags that are tag or one of its sub-tags
+// Tag paths are compared after decryption: encrypted tags can't be matched by prefix in SQL
+func tagsBelow(ctx context.Context, q queryer, fields *fieldCipher, userID int64, tag string) (storedTags, error) {
+	rows, err := q.QueryContext(ctx, "SELECT DISTINCT tag FROM memory_tags WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check tag: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make(storedTags)
+	for rows.Next() {
+		var stored string
+		if err := rows.Scan(&stored); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		name, err := fields.openTag(userID, stored)
+		if err != nil {
+			return nil, err
+		}
+		if entity.IsTagOrDescendant(name, tag) {
+			matches[stored] = name
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return matches, nil
+}
+
+// placeholders returns "(?, ?, ...)" for n query arguments
+func placeholders(n int) string {
+	return "(" + strings.TrimSuffix(strings.Repeat("?, ", n), ", ") + ")"
+}
+
+// moveTag replaces from (and its sub-tags) with to in the tag index, the aliases
+// and the memories' tags column. Returns the number of affected memories.
+func moveTag(ctx context.Context, q queryer, fields *fieldCipher, userID int64, from, to string) (int, error) {
+	matches, err := tagsBelow(ctx, q, fields, userID, from)
+	if err != nil {
+		return 0, err
+	}
+	if len(matches) == 0 {
+		return 0, nil
+	}
+	match := "user_id = ? AND tag IN " + placeholders(len(matches))
+	matchArgs := append([]interface{}{userID}, matches.args()...)
+
+	memoryIDs, err := queryIDs(ctx, q, "SELECT DISTINCT memory_id FROM memory_tags WHERE "+match, matchArgs...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find tagged memories: %w", err)
+	}
+
+	// The part after from is kept, so sub-tags move along
+	for stored, name := range matches {
+		moved, err := fields.sealTag(userID, to+strings.TrimPrefix(name, from))
+		if err != nil {
+			return 0, err
+		}
+
+		// OR IGNORE leaves rows that would duplicate an existing tag; they are deleted next
+		if _, err := q.ExecContext(ctx,
+			"UPDATE OR IGNORE memory_tags SET tag = ? WHERE user_id = ? AND tag = ?", moved, userID, stored,
+		); err != nil {
+			return 0, fmt.Errorf("failed to move tag: %w", err)
+		}
+
+		// Aliases pointing at the old tag follow it
+		if _, err := q.ExecContext(ctx,
+			"UPDATE tag_aliases SET tag = ? WHERE user_id = ? AND tag = ?", moved, userID, stored,
+		); err != nil {
+			return 0, fmt.Errorf("failed to update tag aliases: %w", err)
+		}
+	}
+	if _, err := q.ExecContext(ctx, "DELETE FROM memory_tags WHERE "+match, matchArgs...); err != nil {
+		return 0, fmt.Errorf("failed to remove merged tag: %w", err)
+	}
+
+	for _, id := range memoryIDs {
+		tags, err := memoryTags(ctx, q, fields, id, userID)
+		if err != nil {
+			return 0, err
+		}
+		if err := storeMemoryTags(ctx, q, fields, id, userID, tags); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(memoryIDs), nil
+}
+
+// memoryTags reads the tags of one memory from the tag index, sorted
+func memoryTags(ctx context.Context, q queryer, fields *fieldCipher, memoryID int, userID int64) ([]string, error) {
+	rows, err := q.QueryContext(ctx, "SELECT tag FROM memory_tags WHERE memory_id = ?", memoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tags of memory %d: %w", memoryID, err)
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var stored string
+		if err := rows.Scan(&stored); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		tag, err := fields.openTag(userID, stored)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	sort.Strings(tags)
+	return tags, nil
+}
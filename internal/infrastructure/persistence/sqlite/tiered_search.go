@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// SearchTiers runs all tiers as one FTS5 statement
+// The index is scanned once with the union of the tier expressions and every match is joined
+// to its memory once; the tier of a row is the first tier expression that matches it.
+// Rows are ordered by tier, then by the same combined rank Search uses.
+func (r *MemoryRepository) SearchTiers(ctx context.Context, userID int64, tiers []repository.SearchTier, opts repository.SearchOptions) (*repository.MemoryPage, string, error) {
+	position, err := opts.Cursor.Position(repository.ListingTiers, 2)
+	if err != nil {
+		return nil, "", err
+	}
+
+	synonyms, err := loadSynonymExpander(ctx, r.conn.reader(ctx), userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Tiers that prepare to the same expression as an earlier tier cannot add results
+	var steps, expressions []string
+	seen := make(map[string]bool)
+	for _, tier := range tiers {
+		var alternatives []string
+		for _, query := range tier.Queries {
+			if expr := r.matchExpression(userID, prepareFTS5SearchTerm(query, synonyms)); expr != "" {
+				alternatives = append(alternatives, expr)
+			}
+		}
+		expr := unionExpression(alternatives)
+		if expr == "" || seen[expr] {
+			continue
+		}
+		seen[expr] = true
+		steps = append(steps, tier.Step)
+		expressions = append(expressions, expr)
+	}
+	if len(expressions) == 0 {
+		return &repository.MemoryPage{Memories: []*entity.Memory{}}, "", nil
+	}
+
+	tierSQL, args := tierCaseSQL(expressions)
+
+	// CROSS JOIN keeps the FTS5 table as the outer loop, so the union expression
+	// is evaluated once instead of once per memory of the user
+	sqlQuery := `
+		SELECT
+			m.id,
+			m.user_id,
+			m.chat_id,
+			m.text_content,
+			m.vaulted,
+			m.secret,
+			m.tags,
+			m.created_at,
+			m.last_reviewed,
+			m.review_count,
+			COALESCE(m.sealed_weight, m.emotional_weight),
+			m.priority_score,
+			memories_fts.rank as rank,
+			(
+				memories_fts.rank +
+				(m.emotional_weight * 2.0) +
+				(m.priority_score * 1.5) +
+				(CASE
+					WHEN julianday('now') - julianday(m.created_at) < 7 THEN 1.0
+					WHEN julianday('now') - julianday(m.created_at) < 30 THEN 0.5
+					ELSE 0.0
+				END)
+			) as combined_rank,
+			` + tierSQL + ` as tier
+		FROM
+			memories_fts
+		CROSS JOIN
+			memories AS m ON m.id = memories_fts.rowid
+		WHERE
+			memories_fts MATCH ? AND
+			m.user_id = ? AND
+			m.deleted_at IS NULL`
+
+	// Descending -tier orders by tier first, so all keys page in the same direction
+	args = append(args, unionExpression(expressions), userID)
+	sqlQuery, args = pageQuery(sqlQuery, args, []string{"-tier", "combined_rank"}, false, position, opts.Limit)
+
+	rows, err := r.conn.query(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to search memory tiers: %w", err)
+	}
+	defer rows.Close()
+
+	memories := []*entity.Memory{}
+	var memoryTiers []int
+	step := ""
+	for rows.Next() {
+		var tier int
+		m, err := scanMemoryRow(r.fields, rows, &tier)
+		if err != nil {
+			return nil, "", err
+		}
+		memoryTiers = append(memoryTiers, tier)
+
+		if err := r.openListed(m); err != nil {
+			return nil, "", err
+		}
+
+		if step == "" {
+			step = steps[tier]
+		}
+		memories = append(memories, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	page := repository.NewMemoryPage(repository.ListingTiers, memories, opts.Limit, func(i int) []float64 {
+		return []float64{-float64(memoryTiers[i]), memories[i].Rank}
+	})
+	log.Printf("Found %d memories for user %d across %d search tiers", len(page.Memories), userID, len(expressions))
+	return page, step, nil
+}
+
+// tierCaseSQL builds the expression that numbers the first matching tier of a row
+// Every row matches the union, so the last tier needs no test of its own
+func tierCaseSQL(expressions []string) (string, []interface{}) {
+	if len(expressions) == 1 {
+		return "0", nil
+	}
+
+	var sb strings.Builder
+	var args []interface{}
+	sb.WriteString("(CASE")
+	for i, expr := range expressions[:len(expressions)-1] {
+		fmt.Fprintf(&sb, " WHEN m.id IN (SELECT rowid FROM memories_fts WHERE memories_fts MATCH ?) THEN %d", i)
+		args = append(args, expr)
+	}
+	fmt.Fprintf(&sb, " ELSE %d END)", len(expressions)-1)
+	return sb.String(), args
+}
+
+// unionExpression combines FTS5 expressions into one matching any of them
+func unionExpression(expressions []string) string {
+	if len(expressions) <= 1 {
+		return strings.Join(expressions, "")
+	}
+	return "(" + strings.Join(expressions, ") OR (") + ")"
+}
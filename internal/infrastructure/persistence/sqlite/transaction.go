@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+)
+
+// txKey is the context key of the transaction a unit of work runs in
+type txKey struct{}
+
+// savepointCounter numbers the savepoints of nested units of work
+var savepointCounter atomic.Int64
+
+// dbtx is satisfied by both *sql.DB and *sql.Tx
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// WithTx runs fn as one unit of work
+// Repository calls made with the context passed to fn share one transaction, which is
+// committed when fn returns nil and rolled back otherwise. A nested WithTx joins the
+// outer transaction through a savepoint, so its failure only undoes its own writes.
+func (c *Connection) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := c.beginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx.Tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// reader returns the transaction carried by ctx, or the read pool outside a unit of work
+func (c *Connection) reader(ctx context.Context) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return c.ReadDB
+}
+
+// scopedTx is a transaction of one repository call or unit of work
+// Inside an outer unit of work it is a savepoint of the outer transaction.
+type scopedTx struct {
+	*sql.Tx
+	savepoint string
+	done      bool
+}
+
+// beginTx starts a transaction, or a savepoint if ctx carries a unit of work
+func (c *Connection) beginTx(ctx context.Context) (*scopedTx, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		savepoint := fmt.Sprintf("unit_of_work_%d", savepointCounter.Add(1))
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+			return nil, err
+		}
+		return &scopedTx{Tx: tx, savepoint: savepoint}, nil
+	}
+
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &scopedTx{Tx: tx}, nil
+}
+
+// Commit commits the transaction or releases the savepoint into the outer transaction
+func (t *scopedTx) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+
+	if t.savepoint == "" {
+		return t.Tx.Commit()
+	}
+	_, err := t.Tx.Exec("RELEASE " + t.savepoint)
+	return err
+}
+
+// Rollback undoes the transaction or everything since the savepoint
+// It does nothing after Commit, so it can always be deferred
+func (t *scopedTx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+
+	if t.savepoint == "" {
+		return t.Tx.Rollback()
+	}
+	if _, err := t.Tx.Exec("ROLLBACK TO " + t.savepoint); err != nil {
+		return err
+	}
+	_, err := t.Tx.Exec("RELEASE " + t.savepoint)
+	return err
+}
@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"memory-bot/internal/domain/entity"
+)
+
+// UserSettingsRepository is the SQLite implementation of repository.UserSettingsRepository
+type UserSettingsRepository struct {
+	conn *Connection
+}
+
+// NewUserSettingsRepository creates a new SQLite user settings repository
+func NewUserSettingsRepository(conn *Connection) *UserSettingsRepository {
+	return &UserSettingsRepository{
+		conn: conn,
+	}
+}
+
+// Get retrieves the settings of a user, falling back to the defaults
+func (r *UserSettingsRepository) Get(ctx context.Context, userID int64) (*entity.UserSettings, error) {
+	settings := entity.NewUserSettings(userID)
+
+	err := r.conn.queryRow(ctx, `
+		SELECT query_history FROM user_settings WHERE user_id = ?
+	`, userID).Scan(&settings.QueryHistory)
+	if err == sql.ErrNoRows {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// Save stores the settings of a user
+func (r *UserSettingsRepository) Save(ctx context.Context, settings *entity.UserSettings) error {
+	if settings.UserID == 0 {
+		return entity.ErrInvalidUserID
+	}
+
+	if _, err := r.conn.exec(ctx, `
+		INSERT INTO user_settings (user_id, query_history, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(user_id) DO UPDATE SET
+			query_history = excluded.query_history,
+			updated_at = excluded.updated_at
+	`, settings.UserID, settings.QueryHistory); err != nil {
+		return fmt.Errorf("failed to save user settings: %w", err)
+	}
+
+	return nil
+}
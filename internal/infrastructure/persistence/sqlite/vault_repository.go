@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/encryption"
+)
+
+// VaultRepository is the SQLite implementation of repository.VaultRepository
+// The vaults table keeps the Argon2id parameters and a key check per user; the
+// derived keys live in the session store shared with the memory repository.
+type VaultRepository struct {
+	conn     *Connection
+	sessions *encryption.VaultSessions
+}
+
+// NewVaultRepository creates a new SQLite vault repository
+func NewVaultRepository(conn *Connection, sessions *encryption.VaultSessions) *VaultRepository {
+	return &VaultRepository{
+		conn:     conn,
+		sessions: sessions,
+	}
+}
+
+// Exists reports whether the user has created a vault
+func (r *VaultRepository) Exists(ctx context.Context, userID int64) (bool, error) {
+	var count int
+	if err := r.conn.queryRow(ctx, "SELECT COUNT(*) FROM vaults WHERE user_id = ?", userID).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to find vault: %w", err)
+	}
+	return count > 0, nil
+}
+
+// Unlock derives the vault key from passphrase and starts a session
+// The first unlock creates the vault with new Argon2id parameters and its key check.
+func (r *VaultRepository) Unlock(ctx context.Context, userID int64, passphrase string) (time.Time, error) {
+	var kdf, keyCheck string
+	err := r.conn.queryRow(ctx, "SELECT kdf, key_check FROM vaults WHERE user_id = ?", userID).Scan(&kdf, &keyCheck)
+	if err == sql.ErrNoRows {
+		return r.create(ctx, userID, passphrase)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to find vault: %w", err)
+	}
+
+	params, err := encryption.ParseKDFParams(kdf)
+	if err != nil {
+		return time.Time{}, err
+	}
+	key := encryption.DeriveKey(passphrase, params)
+	if !key.Verifies(keyCheck) {
+		return time.Time{}, entity.ErrWrongPassphrase
+	}
+
+	return r.sessions.Unlock(userID, key), nil
+}
+
+// create stores a new vault for the passphrase and unlocks it
+func (r *VaultRepository) create(ctx context.Context, userID int64, passphrase string) (time.Time, error) {
+	params, err := encryption.NewKDFParams()
+	if err != nil {
+		return time.Time{}, err
+	}
+	key := encryption.DeriveKey(passphrase, params)
+	keyCheck, err := key.KeyCheck()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	result, err := r.conn.exec(ctx,
+		"INSERT OR IGNORE INTO vaults (user_id, kdf, key_check) VALUES (?, ?, ?)",
+		userID, params.String(), keyCheck,
+	)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to create vault: %w", err)
+	}
+	if created, err := result.RowsAffected(); err == nil && created == 0 {
+		// Another /unlock created the vault meanwhile; the passphrase must match it
+		return r.Unlock(ctx, userID, passphrase)
+	}
+
+	log.Printf("🔐 Vault created for user %d", userID)
+	return r.sessions.Unlock(userID, key), nil
+}
+
+// Lock ends the session; reports whether the vault was unlocked
+func (r *VaultRepository) Lock(userID int64) bool {
+	return r.sessions.Lock(userID)
+}
+
+// UnlockedUntil returns when the session of an unlocked vault ends
+func (r *VaultRepository) UnlockedUntil(userID int64) (time.Time, bool) {
+	return r.sessions.Expires(userID)
+}
+
+// ExpireSessions locks the vaults whose session timed out and returns their users
+func (r *VaultRepository) ExpireSessions() []int64 {
+	return r.sessions.Expire()
+}
@@ -0,0 +1,144 @@
+//go:build fts5
+
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+	"memory-bot/pkg/encryption"
+)
+
+func TestVaultedMemories(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestConnection(t)
+	sessions := encryption.NewVaultSessions(time.Hour)
+	repo := NewMemoryRepository(conn, encryption.NewEncryptor("vault-test-key-vault-test-key-!!"), AllFields())
+	repo.UseVault(sessions)
+	vaults := NewVaultRepository(conn, sessions)
+
+	if _, err := vaults.Unlock(ctx, 1, "correct horse"); err != nil {
+		t.Fatalf("Unlock (create): %v", err)
+	}
+	vaulted := entity.NewMemory(1, 1, "Safe combination is tangerine #home")
+	id, err := repo.Save(ctx, vaulted)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if !vaulted.Vaulted {
+		t.Fatal("memory saved while unlocked is not vaulted")
+	}
+	if _, err := repo.Save(ctx, entity.NewMemory(2, 2, "Tangerine jam recipe")); err != nil {
+		t.Fatalf("Save (other user): %v", err)
+	}
+
+	search := func(userID int64, query string) []*entity.Memory {
+		t.Helper()
+		page, err := repo.Search(ctx, userID, query, repository.SearchOptions{Limit: 10})
+		if err != nil {
+			t.Fatalf("Search %q: %v", query, err)
+		}
+		return page.Memories
+	}
+
+	if m, err := repo.FindByID(ctx, int(id)); err != nil || m.Locked || !strings.HasPrefix(m.Content, "Safe combination") {
+		t.Fatalf("FindByID unlocked: %+v, %v", m, err)
+	}
+	if found := search(1, "tangerine"); len(found) != 1 {
+		t.Fatalf("search unlocked found %d memories", len(found))
+	}
+
+	if !vaults.Lock(1) {
+		t.Fatal("Lock: vault was not unlocked")
+	}
+	m, err := repo.FindByID(ctx, int(id))
+	if err != nil || !m.Locked || m.Content != entity.LockedContent {
+		t.Fatalf("FindByID locked: %+v, %v", m, err)
+	}
+	if found := search(1, "tangerine"); len(found) != 0 {
+		t.Errorf("search locked found %d memories by their text", len(found))
+	}
+	if len(m.Tags) != 0 {
+		t.Errorf("tags shown while locked: %v", m.Tags)
+	}
+	if page, err := repo.SearchByTag(ctx, 1, "home", repository.SearchOptions{Limit: 10}); err != nil || len(page.Memories) != 0 {
+		t.Errorf("tag search locked: %+v, %v", page, err)
+	}
+	if found := search(2, "tangerine"); len(found) != 1 {
+		t.Errorf("search of another user found %d memories", len(found))
+	}
+
+	if _, err := vaults.Unlock(ctx, 1, "wrong horse"); err != entity.ErrWrongPassphrase {
+		t.Fatalf("Unlock with a wrong passphrase: %v", err)
+	}
+	if _, err := vaults.Unlock(ctx, 1, "correct horse"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if m, err := repo.FindByID(ctx, int(id)); err != nil || m.Locked || strings.Join(m.Tags, " ") != "home" {
+		t.Fatalf("FindByID after unlocking again: %+v, %v", m, err)
+	}
+	if page, err := repo.SearchByTag(ctx, 1, "home", repository.SearchOptions{Limit: 10}); err != nil || len(page.Memories) != 1 {
+		t.Errorf("tag search unlocked: %+v, %v", page, err)
+	}
+}
+
+func TestVaultedTagsNeedTheVaultKey(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestConnection(t)
+	encryptor := encryption.NewEncryptor("vault-test-key-vault-test-key-!!")
+	sessions := encryption.NewVaultSessions(time.Hour)
+	repo := NewMemoryRepository(conn, encryptor, AllFields())
+	repo.UseVault(sessions)
+	vaults := NewVaultRepository(conn, sessions)
+
+	if _, err := vaults.Unlock(ctx, 1, "correct horse"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	id, err := repo.Save(ctx, entity.NewMemory(1, 1, "Therapy notes #health/mind"))
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	vaults.Lock(1)
+
+	// Neither the tags column, its tokens nor the tag index open with the server key
+	var tags, tagTokens string
+	if err := conn.DB.QueryRow("SELECT tags, tag_tokens FROM memories WHERE id = ?", id).Scan(&tags, &tagTokens); err != nil {
+		t.Fatalf("read memory: %v", err)
+	}
+	if _, err := encryptor.Decrypt(tags, memoryBinding(1, int(id))); err == nil {
+		t.Error("tags decrypt with the server key")
+	}
+	server := newFieldCipher(encryptor, AllFields())
+	for _, token := range strings.Fields(server.tagTokens([]string{"health/mind"}).(string)) {
+		if strings.Contains(tagTokens, token) {
+			t.Errorf("tag tokens contain the server token %s", token)
+		}
+	}
+	var indexed int
+	if err := conn.DB.QueryRow("SELECT COUNT(*) FROM memory_tags WHERE memory_id = ?", id).Scan(&indexed); err != nil || indexed != 0 {
+		t.Errorf("tag index rows: %d, %v", indexed, err)
+	}
+	if counts, err := NewTagRepository(conn, encryptor, AllFields()).ListTags(ctx, 1, ""); err != nil || len(counts) != 0 {
+		t.Errorf("ListTags: %v, %v", counts, err)
+	}
+
+	// Unlocked, the tags open and #tag search finds the memory by its vault tokens
+	if _, err := vaults.Unlock(ctx, 1, "correct horse"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if m, err := repo.FindByID(ctx, int(id)); err != nil || strings.Join(m.Tags, " ") != "health/mind" {
+		t.Fatalf("FindByID: %+v, %v", m, err)
+	}
+	for tag, want := range map[string]int{"health": 1, "health/mind": 1, "mind": 0, "mind/health": 0} {
+		if page, err := repo.SearchByTag(ctx, 1, tag, repository.SearchOptions{Limit: 10}); err != nil || len(page.Memories) != want {
+			t.Errorf("SearchByTag %s: %+v, %v", tag, page, err)
+		}
+	}
+	if found, err := repo.Search(ctx, 1, "tags:health", repository.SearchOptions{Limit: 10}); err != nil || len(found.Memories) != 1 {
+		t.Errorf("Search tags:health: %+v, %v", found, err)
+	}
+}
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+)
+
+// BackupScheduler takes a database snapshot once per interval
+type BackupScheduler struct {
+	useCase  *usecase.BackupDatabaseUseCase
+	interval time.Duration
+	ticker   *time.Ticker
+	stopChan chan bool
+}
+
+// NewBackupScheduler creates a new backup scheduler
+func NewBackupScheduler(useCase *usecase.BackupDatabaseUseCase, interval time.Duration) *BackupScheduler {
+	return &BackupScheduler{
+		useCase:  useCase,
+		interval: interval,
+		stopChan: make(chan bool),
+	}
+}
+
+// Start starts the backup scheduler
+func (s *BackupScheduler) Start() {
+	log.Printf("Backup scheduler started (every %s)", s.interval)
+
+	// Catch up on start if the last snapshot is older than the interval,
+	// so frequent restarts do not produce a snapshot each
+	s.backupIfDue()
+
+	s.ticker = time.NewTicker(s.interval)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.backup()
+			case <-s.stopChan:
+				s.ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler
+func (s *BackupScheduler) Stop() {
+	log.Println("Stopping backup scheduler")
+	s.stopChan <- true
+}
+
+// backupIfDue takes a snapshot unless a recent one exists
+func (s *BackupScheduler) backupIfDue() {
+	latest, err := s.useCase.Latest(context.Background())
+	if err != nil && !errors.Is(err, entity.ErrNoBackup) {
+		log.Printf("Error listing database snapshots: %v", err)
+		return
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < s.interval {
+		return
+	}
+	s.backup()
+}
+
+// backup takes a snapshot and rotates old ones
+func (s *BackupScheduler) backup() {
+	if _, err := s.useCase.Execute(context.Background()); err != nil {
+		log.Printf("Error backing up database: %v", err)
+	}
+}
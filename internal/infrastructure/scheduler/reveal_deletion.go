@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"memory-bot/internal/application/usecase"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// RevealDeletionScheduler deletes the revealed secret memories whose deletion is overdue
+// Reveals are normally deleted on time by the secret command; this catches the ones
+// left behind by a restart or crash.
+type RevealDeletionScheduler struct {
+	api      *tgbotapi.BotAPI
+	useCase  *usecase.ManageSecretsUseCase
+	ticker   *time.Ticker
+	stopChan chan bool
+}
+
+// NewRevealDeletionScheduler creates a new reveal deletion scheduler
+func NewRevealDeletionScheduler(api *tgbotapi.BotAPI, useCase *usecase.ManageSecretsUseCase) *RevealDeletionScheduler {
+	return &RevealDeletionScheduler{
+		api:      api,
+		useCase:  useCase,
+		stopChan: make(chan bool),
+	}
+}
+
+// Start starts the reveal deletion scheduler
+func (s *RevealDeletionScheduler) Start() {
+	log.Println("Reveal deletion scheduler started")
+
+	// Run immediately on start for the reveals a restart left behind
+	s.deleteDue()
+
+	// Then check every minute for reveals that were due while the bot was restarting
+	s.ticker = time.NewTicker(1 * time.Minute)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.deleteDue()
+			case <-s.stopChan:
+				s.ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler
+func (s *RevealDeletionScheduler) Stop() {
+	log.Println("Stopping reveal deletion scheduler")
+	s.stopChan <- true
+}
+
+// deleteDue deletes the overdue reveal messages
+// A message Telegram refuses to delete (already gone, or older than 48 hours) is forgotten;
+// on other errors it is retried on the next run.
+func (s *RevealDeletionScheduler) deleteDue() {
+	ctx := context.Background()
+
+	deletions, err := s.useCase.DueRevealDeletions(ctx)
+	if err != nil {
+		log.Printf("Error finding overdue reveals: %v", err)
+		return
+	}
+
+	for _, deletion := range deletions {
+		_, err := s.api.Request(tgbotapi.NewDeleteMessage(deletion.ChatID, deletion.MessageID))
+		var apiErr *tgbotapi.Error
+		if err != nil && !errors.As(err, &apiErr) {
+			log.Printf("Error deleting reveal message %d in chat %d: %v", deletion.MessageID, deletion.ChatID, err)
+			continue
+		}
+		if err != nil {
+			log.Printf("⚠️ Telegram did not delete reveal message %d in chat %d: %v", deletion.MessageID, deletion.ChatID, err)
+		}
+
+		if err := s.useCase.RevealDeleted(ctx, deletion.ID); err != nil {
+			log.Printf("Error forgetting deleted reveal: %v", err)
+		}
+	}
+}
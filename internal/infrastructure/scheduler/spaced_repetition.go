@@ -12,6 +12,9 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// reviewBatchSize is the number of due memories fetched per page
+const reviewBatchSize = 50
+
 // SpacedRepetitionScheduler handles automatic memory review reminders
 type SpacedRepetitionScheduler struct {
 	api       *tgbotapi.BotAPI
@@ -69,28 +72,38 @@ func (s *SpacedRepetitionScheduler) checkAndSendReviews() {
 	ctx := context.Background()
 	log.Println("Checking for memories to review...")
 
+	// Page through all due memories so that every user gets a reminder
+	// Memories are grouped by user; sessions are only sent once the listing is complete
+	userMemories := make(map[int64][]*entity.Memory)
+	found := 0
 	input := usecase.ReviewMemoryInput{
 		Intervals: s.intervals,
+		Limit:     reviewBatchSize,
 	}
+	for {
+		output, err := s.useCase.Execute(ctx, input)
+		if err != nil {
+			log.Printf("Error getting memories for review: %v", err)
+			return
+		}
 
-	output, err := s.useCase.Execute(ctx, input)
-	if err != nil {
-		log.Printf("Error getting memories for review: %v", err)
-		return
+		for _, mem := range output.Memories {
+			userMemories[mem.UserID] = append(userMemories[mem.UserID], mem)
+		}
+		found += len(output.Memories)
+
+		if !output.HasMore {
+			break
+		}
+		input.Cursor = output.NextCursor
 	}
 
-	if len(output.Memories) == 0 {
+	if found == 0 {
 		log.Println("No memories need review at this time")
 		return
 	}
 
-	log.Printf("Found %d memories for review", len(output.Memories))
-
-	// Group memories by user
-	userMemories := make(map[int64][]*entity.Memory)
-	for _, mem := range output.Memories {
-		userMemories[mem.UserID] = append(userMemories[mem.UserID], mem)
-	}
+	log.Printf("Found %d memories for review", found)
 
 	// Send review reminders to each user
 	for userID, mems := range userMemories {
@@ -106,6 +119,21 @@ func (s *SpacedRepetitionScheduler) sendReviewToUser(ctx context.Context, userID
 
 	chatID := memories[0].ChatID
 
+	// Vaulted memories cannot be shown while the vault is locked; they stay due until it is unlocked
+	reviewable := make([]*entity.Memory, 0, len(memories))
+	for _, mem := range memories {
+		if !mem.Locked {
+			reviewable = append(reviewable, mem)
+		}
+	}
+	if locked := len(memories) - len(reviewable); locked > 0 {
+		s.api.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("🔒 %d memories in your vault are due for review. /unlock to review them.", locked)))
+	}
+	memories = reviewable
+	if len(memories) == 0 {
+		return
+	}
+
 	// Send header message
 	headerText := fmt.Sprintf("🔔 *Memory Review Time!*\n\nYou have %d memories to review:\n", len(memories))
 	msg := tgbotapi.NewMessage(chatID, headerText)
@@ -149,7 +177,7 @@ func (s *SpacedRepetitionScheduler) sendMemoryForReview(chatID int64, mem *entit
 			"📊 Review count: %d\n\n"+
 			"_Take a moment to recall this memory..._",
 		mem.ID,
-		mem.Content,
+		mem.Masked(),
 		mem.CreatedAt.Format("2006-01-02"),
 		daysSince,
 		mem.ReviewCount,
@@ -159,11 +187,19 @@ func (s *SpacedRepetitionScheduler) sendMemoryForReview(chatID int64, mem *entit
 	msg.ParseMode = "Markdown"
 
 	// Add inline keyboard with feedback options
+	lastRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔗 Related", fmt.Sprintf("related:%d", mem.ID)),
+	)
+	if mem.Secret {
+		// Secret memories are recalled first and revealed on request
+		lastRow = append(lastRow, tgbotapi.NewInlineKeyboardButtonData("👁 Reveal", fmt.Sprintf("secret:%d", mem.ID)))
+	}
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("✅ Remembered", fmt.Sprintf("review:remember:%d", mem.ID)),
 			tgbotapi.NewInlineKeyboardButtonData("❓ Forgot", fmt.Sprintf("review:forgot:%d", mem.ID)),
 		),
+		lastRow,
 	)
 	msg.ReplyMarkup = keyboard
 
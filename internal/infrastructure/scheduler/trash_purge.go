@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"memory-bot/internal/application/usecase"
+)
+
+// TrashPurgeScheduler permanently removes memories whose trash retention has expired
+type TrashPurgeScheduler struct {
+	useCase  *usecase.ManageTrashUseCase
+	ticker   *time.Ticker
+	stopChan chan bool
+}
+
+// NewTrashPurgeScheduler creates a new trash purge scheduler
+func NewTrashPurgeScheduler(useCase *usecase.ManageTrashUseCase) *TrashPurgeScheduler {
+	return &TrashPurgeScheduler{
+		useCase:  useCase,
+		stopChan: make(chan bool),
+	}
+}
+
+// Start starts the trash purge scheduler
+func (s *TrashPurgeScheduler) Start() {
+	log.Println("Trash purge scheduler started")
+
+	// Run immediately on start
+	s.purge()
+
+	// Then run every 6 hours
+	s.ticker = time.NewTicker(6 * time.Hour)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.purge()
+			case <-s.stopChan:
+				s.ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler
+func (s *TrashPurgeScheduler) Stop() {
+	log.Println("Stopping trash purge scheduler")
+	s.stopChan <- true
+}
+
+// purge removes the expired memories from the trash
+func (s *TrashPurgeScheduler) purge() {
+	if _, err := s.useCase.Purge(context.Background()); err != nil {
+		log.Printf("Error purging trash: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"memory-bot/internal/application/usecase"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// VaultLockScheduler locks vaults whose session timed out and tells their owners
+type VaultLockScheduler struct {
+	api      *tgbotapi.BotAPI
+	useCase  *usecase.ManageVaultUseCase
+	ticker   *time.Ticker
+	stopChan chan bool
+}
+
+// NewVaultLockScheduler creates a new vault lock scheduler
+func NewVaultLockScheduler(api *tgbotapi.BotAPI, useCase *usecase.ManageVaultUseCase) *VaultLockScheduler {
+	return &VaultLockScheduler{
+		api:      api,
+		useCase:  useCase,
+		stopChan: make(chan bool),
+	}
+}
+
+// Start starts the vault lock scheduler
+func (s *VaultLockScheduler) Start() {
+	log.Println("Vault lock scheduler started")
+
+	// Check every minute; expired sessions are unusable even before they are swept
+	s.ticker = time.NewTicker(1 * time.Minute)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.lockExpired()
+			case <-s.stopChan:
+				s.ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler
+func (s *VaultLockScheduler) Stop() {
+	log.Println("Stopping vault lock scheduler")
+	s.stopChan <- true
+}
+
+// lockExpired locks the timed out vaults and notifies their owners in their private chat
+func (s *VaultLockScheduler) lockExpired() {
+	for _, userID := range s.useCase.ExpireSessions() {
+		s.api.Send(tgbotapi.NewMessage(userID, "🔒 Your vault locked automatically. Use /unlock to open it again."))
+	}
+}
@@ -0,0 +1,59 @@
+package strategy
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"memory-bot/internal/domain/repository"
+	"memory-bot/internal/domain/service"
+)
+
+// DateBrowseStrategy lists the memories of a period given as a date expression
+// Examples: "yesterday", "last week", "2024-03-15", "March 2024"
+type DateBrowseStrategy struct {
+	repo repository.MemoryRepository
+	now  func() time.Time
+}
+
+// NewDateBrowseStrategy creates a new date browse strategy
+func NewDateBrowseStrategy(repo repository.MemoryRepository) *DateBrowseStrategy {
+	return &DateBrowseStrategy{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// Search returns the memories created in the period, newest first
+// A keyword that is not a date expression finds nothing
+func (s *DateBrowseStrategy) Search(ctx context.Context, query SearchQuery) (*repository.MemoryPage, error) {
+	period, ok := service.ParseDateExpression(query.Keyword, s.now())
+	if !ok {
+		log.Printf("DateBrowse: '%s' is not a date expression", query.Keyword)
+		query.Trace.Record(StepNone)
+		return emptyPage(), nil
+	}
+
+	page, err := s.repo.FindByDateRange(ctx, query.UserID, period.From, period.To, repository.SearchOptions{
+		Limit:  query.Limit,
+		Cursor: query.Cursor,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("DateBrowse: Found %d results for %s", len(page.Memories), period.Description)
+	query.Trace.Record(stepFor(page, StepDate))
+	return page, nil
+}
+
+// Name returns the strategy name
+func (s *DateBrowseStrategy) Name() string {
+	return "Date"
+}
+
+// isDateExpression reports whether the keyword is nothing but a date expression
+func isDateExpression(keyword string) bool {
+	_, ok := service.ParseDateExpression(keyword, time.Now())
+	return ok
+}
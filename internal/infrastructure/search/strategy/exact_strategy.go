@@ -0,0 +1,56 @@
+package strategy
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"memory-bot/internal/domain/repository"
+)
+
+// ExactSearchStrategy matches the query as one phrase, without wildcards or synonyms
+// Used for quoted input ("team meeting") and the exact: prefix
+type ExactSearchStrategy struct {
+	repo repository.MemoryRepository
+}
+
+// NewExactSearchStrategy creates a new exact phrase search strategy
+func NewExactSearchStrategy(repo repository.MemoryRepository) *ExactSearchStrategy {
+	return &ExactSearchStrategy{
+		repo: repo,
+	}
+}
+
+// Search returns the memories containing the phrase, best ranked first
+func (s *ExactSearchStrategy) Search(ctx context.Context, query SearchQuery) (*repository.MemoryPage, error) {
+	phrase := strings.TrimSpace(strings.Trim(strings.TrimSpace(query.Keyword), `"`))
+	if phrase == "" {
+		query.Trace.Record(StepNone)
+		return emptyPage(), nil
+	}
+
+	page, err := s.repo.Search(ctx, query.UserID, phrase, repository.SearchOptions{
+		Limit:       query.Limit,
+		Cursor:      query.Cursor,
+		ExactPhrase: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("ExactSearch: Found %d results for phrase '%s'", len(page.Memories), phrase)
+	query.Trace.Record(stepFor(page, StepExact))
+	return page, nil
+}
+
+// Name returns the strategy name
+func (s *ExactSearchStrategy) Name() string {
+	return "Exact"
+}
+
+// isQuoted reports whether the whole keyword is wrapped in double quotes
+func isQuoted(keyword string) bool {
+	keyword = strings.TrimSpace(keyword)
+	return len(keyword) > 2 && strings.HasPrefix(keyword, `"`) && strings.HasSuffix(keyword, `"`) &&
+		!strings.Contains(keyword[1:len(keyword)-1], `"`)
+}
@@ -0,0 +1,49 @@
+package strategy
+
+import (
+	"context"
+	"log"
+
+	"memory-bot/internal/domain/repository"
+)
+
+// FuzzySearchStrategy skips the primary search and goes straight to the fallback tiers
+// (prefix, partial and OR matching), for broad results when the exact words are unknown
+type FuzzySearchStrategy struct {
+	repo repository.MemoryRepository
+}
+
+// NewFuzzySearchStrategy creates a new fuzzy search strategy
+func NewFuzzySearchStrategy(repo repository.MemoryRepository) *FuzzySearchStrategy {
+	return &FuzzySearchStrategy{
+		repo: repo,
+	}
+}
+
+// Search runs the fallback tiers of the keyword as one combined search
+// Keywords without fallback tiers (a single short word) use the primary search
+func (s *FuzzySearchStrategy) Search(ctx context.Context, query SearchQuery) (*repository.MemoryPage, error) {
+	opts := repository.SearchOptions{
+		Limit:  query.Limit,
+		Cursor: query.Cursor,
+	}
+
+	tiers := PlanFallbackTiers(query.Keyword)
+	if len(tiers) == 0 {
+		tiers = []repository.SearchTier{{Step: StepPrimary, Queries: []string{query.Keyword}}}
+	}
+
+	page, step, err := s.repo.SearchTiers(ctx, query.UserID, tiers, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("FuzzySearch: Found %d results (best: %s)", len(page.Memories), step)
+	query.Trace.Record(stepFor(page, step))
+	return page, nil
+}
+
+// Name returns the strategy name
+func (s *FuzzySearchStrategy) Name() string {
+	return "Fuzzy"
+}
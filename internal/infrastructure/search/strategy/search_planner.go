@@ -0,0 +1,100 @@
+package strategy
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"memory-bot/internal/domain/repository"
+)
+
+// PlanFallbackTiers builds the fallback chain of the smart search as tiers, best first
+// The tiers mirror the former sequential steps after the primary search
+// (cleaned, fuzzy, AND, partial, OR, NEAR); none of them uses the contextual filter.
+// Tiers whose matches are always covered by an earlier tier are left out.
+func PlanFallbackTiers(keyword string) []repository.SearchTier {
+	var tiers []repository.SearchTier
+	add := func(step string, queries ...string) {
+		tiers = append(tiers, repository.SearchTier{Step: step, Queries: queries})
+	}
+
+	if containsDotsOrDashes(keyword) {
+		cleanQuery := strings.ReplaceAll(keyword, ".", " ")
+		cleanQuery = strings.ReplaceAll(cleanQuery, "-", " ")
+		add(StepCleaned, cleanQuery)
+	}
+
+	words := strings.Fields(strings.TrimSpace(keyword))
+	if len(words) == 1 && len(words[0]) >= 3 {
+		add(StepFuzzy, words[0][:3]+"*")
+	}
+
+	if len(words) > 1 {
+		andTerms := make([]string, len(words))
+		for i, word := range words {
+			andTerms[i] = fallbackTerm(word)
+		}
+		add(StepAND, strings.Join(andTerms, " "))
+
+		// Partial match: any word matches
+		var partialTerms []string
+		for _, word := range words {
+			if len(word) < 3 {
+				continue // Skip very short words
+			}
+			partialTerms = append(partialTerms, fallbackTerm(word))
+		}
+		if len(partialTerms) > 0 {
+			add(StepPartial, partialTerms...)
+		}
+
+		// OR and NEAR expressions are passed through unprepared, so hashtags
+		// (already covered by the tiers above) are left out
+		var textWords []string
+		for _, word := range words {
+			if !strings.HasPrefix(word, "#") {
+				textWords = append(textWords, word)
+			}
+		}
+		if len(textWords) > 1 {
+			// Every word matched by OR is matched by its partial wildcard unless it is too short
+			if len(partialTerms) < len(words) {
+				add(StepOR, strings.Join(textWords, " OR "))
+			}
+			// NEAR only narrows the AND tier unless hashtags were left out
+			if len(textWords) < len(words) {
+				add(StepNear, "NEAR("+strings.Join(textWords, " ")+", 10)")
+			}
+		}
+	}
+
+	return tiers
+}
+
+// fallbackTerm adds a wildcard to a word; hashtags are matched as whole tags
+func fallbackTerm(word string) string {
+	if strings.HasPrefix(word, "#") {
+		return word
+	}
+	return word + "*"
+}
+
+// searchEachTier runs the tiers one after the other until one has results
+// Used when the combined statement fails, so one broken tier does not hide the others.
+// The result is a single page: its cursor would continue the tier query, not the keyword.
+func (s *SmartSearchStrategy) searchEachTier(ctx context.Context, userID int64, tiers []repository.SearchTier, opts repository.SearchOptions) (*repository.MemoryPage, string) {
+	for _, tier := range tiers {
+		for _, query := range tier.Queries {
+			page, err := s.repo.Search(ctx, userID, query, opts)
+			if err != nil {
+				log.Printf("SmartSearch: %s search error: %v", tier.Step, err)
+				continue
+			}
+			if len(page.Memories) > 0 {
+				page.NextCursor, page.HasMore = "", false
+				return page, tier.Step
+			}
+		}
+	}
+	return emptyPage(), ""
+}
@@ -2,7 +2,24 @@ package strategy
 
 import (
 	"context"
+
 	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+)
+
+// Search steps reported through SearchTrace
+const (
+	StepTag     = "tag"
+	StepExact   = "exact"
+	StepDate    = "date"
+	StepPrimary = "primary"
+	StepCleaned = "cleaned"
+	StepFuzzy   = "fuzzy"
+	StepAND     = "and"
+	StepPartial = "partial"
+	StepOR      = "or"
+	StepNear    = "near"
+	StepNone    = "none"
 )
 
 // SearchQuery encapsulates search parameters
@@ -10,21 +27,40 @@ type SearchQuery struct {
 	UserID  int64
 	Keyword string
 	Limit   int
-	Offset  int
+	Cursor  repository.Cursor // Continues the listing of an earlier page; empty for the first page
+	Trace   *SearchTrace      // Optional: receives the step that produced the results
+}
+
+// SearchTrace records how a strategy answered a query (for query analytics)
+type SearchTrace struct {
+	Step string
+}
+
+// Record stores the step that produced the results; safe on a nil trace
+func (t *SearchTrace) Record(step string) {
+	if t != nil {
+		t.Step = step
+	}
 }
 
 // SearchStrategy defines the interface for different search algorithms
 // This implements the Strategy Pattern for flexible search algorithms
 type SearchStrategy interface {
-	// Search executes the search with the specific strategy
-	Search(ctx context.Context, query SearchQuery) ([]*entity.Memory, error)
+	// Search executes the search with the specific strategy and returns one page of results
+	Search(ctx context.Context, query SearchQuery) (*repository.MemoryPage, error)
 
 	// Name returns the strategy name for logging/debugging
 	Name() string
 }
 
+// emptyPage is the result of a search without matches
+func emptyPage() *repository.MemoryPage {
+	return &repository.MemoryPage{Memories: []*entity.Memory{}}
+}
+
 // SearchStrategyFactory creates search strategies
 type SearchStrategyFactory interface {
 	// CreateStrategy creates a strategy based on the search query
-	CreateStrategy(query SearchQuery) SearchStrategy
+	// The returned query is the one to run, without a strategy prefix such as "exact:"
+	CreateStrategy(query SearchQuery) (SearchStrategy, SearchQuery)
 }
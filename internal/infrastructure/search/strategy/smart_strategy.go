@@ -5,7 +5,6 @@ import (
 	"log"
 	"strings"
 
-	"memory-bot/internal/domain/entity"
 	"memory-bot/internal/domain/repository"
 	"memory-bot/internal/domain/service"
 )
@@ -14,8 +13,7 @@ import (
 // Enhanced with biological contextual recall (Hippocampus function)
 // 1. Try contextual search if user provides time/day cues
 // 2. Try primary FTS5 search
-// 3. If no results, try AND search with wildcards
-// 4. If still no results, try OR search for broader results
+// 3. If no results, run the fallback tiers (AND, partial, OR, ...) as one combined search
 type SmartSearchStrategy struct {
 	repo           repository.MemoryRepository
 	contextService *service.ContextualMetadataService
@@ -30,141 +28,97 @@ func NewSmartSearchStrategy(repo repository.MemoryRepository) *SmartSearchStrate
 }
 
 // Search executes smart search with contextual awareness and fallback strategies
-func (s *SmartSearchStrategy) Search(ctx context.Context, query SearchQuery) ([]*entity.Memory, error) {
+// A query with a cursor continues the step that produced the first page
+func (s *SmartSearchStrategy) Search(ctx context.Context, query SearchQuery) (*repository.MemoryPage, error) {
 	log.Printf("SmartSearch: Starting search for userID=%d, keyword='%s'", query.UserID, query.Keyword)
 
 	opts := repository.SearchOptions{
 		Limit:  query.Limit,
-		Offset: query.Offset,
+		Cursor: query.Cursor,
+	}
+	if query.Cursor != "" {
+		return s.searchNextPage(ctx, query, opts)
 	}
 
 	// Step 1: Check for hashtag search (exact tag matching)
-	if strings.HasPrefix(strings.TrimSpace(query.Keyword), "#") {
+	// A single tag uses the normalized tag index (sub-tags and aliases included);
+	// hashtags mixed with words are matched against the tags column by FTS5
+	if keywordFields := strings.Fields(query.Keyword); len(keywordFields) == 1 && strings.HasPrefix(keywordFields[0], "#") {
 		log.Printf("SmartSearch: Detected hashtag search")
-		// Tag search is handled by FTS5 with high precision
-		memories, err := s.repo.Search(ctx, query.UserID, query.Keyword, opts)
-		if err == nil && len(memories) > 0 {
-			log.Printf("SmartSearch: Found %d results with tag search", len(memories))
-			return memories, nil
+		page, err := s.repo.SearchByTag(ctx, query.UserID, keywordFields[0], opts)
+		if err == nil && len(page.Memories) > 0 {
+			log.Printf("SmartSearch: Found %d results with tag search", len(page.Memories))
+			query.Trace.Record(StepTag)
+			return page, nil
 		}
 	}
 
 	// Step 2: Check for contextual cues (Biological principle: Associative recall)
-	contextData, hasContext := s.contextService.ExtractContextCue(query.Keyword)
-	if hasContext {
-		log.Printf("SmartSearch: Detected contextual cue - %s", s.contextService.GetContextDescription(contextData))
-		// Apply context filter directly at SQL level for better performance
-		opts.ContextFilter = &contextData
-	}
+	// Applied directly at SQL level for better performance
+	opts.ContextFilter = s.contextFilter(query.Keyword)
 
 	// Step 3: Try primary FTS5 search with wildcard (with context filter if applicable)
-	memories, err := s.repo.Search(ctx, query.UserID, query.Keyword, opts)
+	page, err := s.repo.Search(ctx, query.UserID, query.Keyword, opts)
 	if err != nil {
 		log.Printf("SmartSearch: Primary search error: %v", err)
 	}
-	if err == nil && len(memories) > 0 {
-		log.Printf("SmartSearch: Found %d results with primary search", len(memories))
-		return memories, nil
+	if err == nil && len(page.Memories) > 0 {
+		log.Printf("SmartSearch: Found %d results with primary search", len(page.Memories))
+		query.Trace.Record(StepPrimary)
+		return page, nil
 	}
 
 	// For fallbacks, reset context filter to broaden search
 	opts.ContextFilter = nil
 
-	// Step 3.5: If primary search failed and query looks like version/number, try without quotes
-	if containsDotsOrDashes(query.Keyword) {
-		// Try searching without exact phrase matching
-		cleanQuery := strings.ReplaceAll(query.Keyword, ".", " ")
-		cleanQuery = strings.ReplaceAll(cleanQuery, "-", " ")
-		log.Printf("SmartSearch: Trying cleaned version search: %s", cleanQuery)
-		memories, err = s.repo.Search(ctx, query.UserID, cleanQuery, opts)
-		if err == nil && len(memories) > 0 {
-			log.Printf("SmartSearch: Found %d results with cleaned version search", len(memories))
-			return memories, nil
-		}
-	}
-
-	// Step 4: Try fuzzy search with shorter substrings (3+ chars)
-	words := strings.Fields(strings.TrimSpace(query.Keyword))
-	if len(words) == 1 && len(words[0]) >= 3 {
-		word := words[0]
-		// Try searching with first 3 characters for fuzzy matching
-		fuzzyQuery := word[:3] + "*"
-		log.Printf("SmartSearch: Trying fuzzy search with term: %s", fuzzyQuery)
-		memories, err = s.repo.Search(ctx, query.UserID, fuzzyQuery, opts)
-		if err == nil && len(memories) > 0 {
-			log.Printf("SmartSearch: Found %d results with fuzzy search", len(memories))
-			return memories, nil
-		}
-	}
-
-	// Step 5: Try AND search (all words must match)
-	if len(words) > 1 {
-		andTerms := make([]string, len(words))
-		for i, word := range words {
-			andTerms[i] = word + "*"
-		}
-		fallbackQuery := strings.Join(andTerms, " ")
-
-		log.Printf("SmartSearch: Trying AND fallback with term: %s", fallbackQuery)
-		memories, err = s.repo.Search(ctx, query.UserID, fallbackQuery, opts)
+	// Step 4: Run all fallback tiers (cleaned, fuzzy, AND, partial, OR, NEAR) in one pass
+	// Results are merged without duplicates, best tier first
+	tiers := PlanFallbackTiers(query.Keyword)
+	if len(tiers) > 0 {
+		page, step, err := s.repo.SearchTiers(ctx, query.UserID, tiers, opts)
 		if err != nil {
-			log.Printf("SmartSearch: AND fallback error: %v", err)
-		}
-		if err == nil && len(memories) > 0 {
-			log.Printf("SmartSearch: Found %d results with AND fallback", len(memories))
-			return memories, nil
+			log.Printf("SmartSearch: Tiered search error, trying tiers one by one: %v", err)
+			page, step = s.searchEachTier(ctx, query.UserID, tiers, opts)
 		}
-	}
-
-	// Step 6: Try partial match (any word matches)
-	if len(words) > 1 {
-		// Try each word individually with wildcards
-		for _, word := range words {
-			if len(word) < 3 {
-				continue // Skip very short words
-			}
-			partialQuery := word + "*"
-			log.Printf("SmartSearch: Trying partial match with term: %s", partialQuery)
-			memories, err = s.repo.Search(ctx, query.UserID, partialQuery, opts)
-			if err == nil && len(memories) > 0 {
-				log.Printf("SmartSearch: Found %d results with partial match", len(memories))
-				return memories, nil
-			}
+		if len(page.Memories) > 0 {
+			log.Printf("SmartSearch: Found %d results with tiered fallback (best: %s)", len(page.Memories), step)
+			query.Trace.Record(step)
+			return page, nil
 		}
 	}
 
-	// Step 7: Try OR search (broader search - any word matches)
-	if len(words) > 1 {
-		// FTS5 OR search without wildcards for maximum recall
-		orTerms := make([]string, len(words))
-		copy(orTerms, words)
-		fallbackQuery := strings.Join(orTerms, " OR ")
+	// No results found
+	log.Printf("SmartSearch: No results found for keyword '%s'", query.Keyword)
+	query.Trace.Record(StepNone)
+	return emptyPage(), nil
+}
 
-		log.Printf("SmartSearch: Trying OR fallback with term: %s", fallbackQuery)
-		memories, err = s.repo.Search(ctx, query.UserID, fallbackQuery, opts)
-		if err != nil {
-			log.Printf("SmartSearch: OR fallback error: %v", err)
-		}
-		if err == nil && len(memories) > 0 {
-			log.Printf("SmartSearch: Found %d results with OR fallback", len(memories))
-			return memories, nil
-		}
+// searchNextPage continues the listing named by the cursor
+// Unlike the first page there is no fallback: errors such as an invalid cursor are returned
+func (s *SmartSearchStrategy) searchNextPage(ctx context.Context, query SearchQuery, opts repository.SearchOptions) (*repository.MemoryPage, error) {
+	switch query.Cursor.Listing() {
+	case repository.ListingTag:
+		query.Trace.Record(StepTag)
+		return s.repo.SearchByTag(ctx, query.UserID, strings.TrimSpace(query.Keyword), opts)
+	case repository.ListingTiers:
+		page, step, err := s.repo.SearchTiers(ctx, query.UserID, PlanFallbackTiers(query.Keyword), opts)
+		query.Trace.Record(step)
+		return page, err
+	default:
+		opts.ContextFilter = s.contextFilter(query.Keyword)
+		query.Trace.Record(StepPrimary)
+		return s.repo.Search(ctx, query.UserID, query.Keyword, opts)
 	}
+}
 
-	// Step 8: Last resort - search with NEAR operator for proximity matching
-	if len(words) > 1 {
-		nearQuery := "NEAR(" + strings.Join(words, " ") + ", 10)"
-		log.Printf("SmartSearch: Trying NEAR proximity search with term: %s", nearQuery)
-		memories, err = s.repo.Search(ctx, query.UserID, nearQuery, opts)
-		if err == nil && len(memories) > 0 {
-			log.Printf("SmartSearch: Found %d results with NEAR search", len(memories))
-			return memories, nil
-		}
+// contextFilter returns the contextual cue of the keyword, nil without one
+func (s *SmartSearchStrategy) contextFilter(keyword string) *service.ContextualData {
+	contextData, hasContext := s.contextService.ExtractContextCue(keyword)
+	if !hasContext {
+		return nil
 	}
-
-	// No results found
-	log.Printf("SmartSearch: No results found for keyword '%s'", query.Keyword)
-	return []*entity.Memory{}, nil
+	log.Printf("SmartSearch: Detected contextual cue - %s", s.contextService.GetContextDescription(contextData))
+	return &contextData
 }
 
 // Name returns the strategy name
@@ -0,0 +1,206 @@
+//go:build fts5
+
+package strategy
+
+import (
+	"context"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/repository"
+	"memory-bot/internal/infrastructure/persistence/sqlite"
+)
+
+const (
+	// benchmarkMemories is the size of the benchmark fixture
+	benchmarkMemories = 100000
+
+	// benchmarkUsers is the number of users the fixture memories are spread over
+	benchmarkUsers = 100
+
+	// benchmarkVocabulary is the number of generated words besides the common ones
+	benchmarkVocabulary = 5000
+)
+
+var (
+	benchmarkOnce sync.Once
+	benchmarkDir  string
+	benchmarkRepo *sqlite.MemoryRepository
+	benchmarkErr  error
+)
+
+// TestMain removes the benchmark fixture after the run
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if benchmarkDir != "" {
+		os.RemoveAll(benchmarkDir)
+	}
+	os.Exit(code)
+}
+
+// benchmarkWords are frequent words that every user writes about
+var benchmarkWords = strings.Fields(`meeting project budget client deploy release review team
+	planning sprint backlog invoice travel dentist birthday dinner recipe workout garden
+	kubernetes database migration backup server network laptop phone contract design`)
+
+// loadBenchmarkRepository builds a 100k-memory database once per test binary
+func loadBenchmarkRepository(b *testing.B) *sqlite.MemoryRepository {
+	b.Helper()
+
+	benchmarkOnce.Do(func() {
+		log.SetOutput(io.Discard)
+
+		dir, err := os.MkdirTemp("", "memory-bot-bench")
+		if err != nil {
+			benchmarkErr = err
+			return
+		}
+		benchmarkDir = dir
+
+		conn, err := sqlite.NewConnection(filepath.Join(dir, "bench.db"))
+		if err != nil {
+			benchmarkErr = err
+			return
+		}
+
+		benchmarkErr = fillBenchmarkFixture(conn)
+		benchmarkRepo = sqlite.NewMemoryRepository(conn, nil, nil)
+	})
+
+	if benchmarkErr != nil {
+		b.Fatalf("failed to build fixture: %v", benchmarkErr)
+	}
+	return benchmarkRepo
+}
+
+// fillBenchmarkFixture inserts random memories in one transaction
+// Words follow a Zipf distribution over a generated vocabulary, mixed with common words
+func fillBenchmarkFixture(conn *sqlite.Connection) error {
+	tx, err := conn.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO memories (user_id, chat_id, text_content, tags, created_at, time_of_day, day_of_week)
+		VALUES (?, ?, ?, ?, ?, 'Morning', 'Monday')
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	rng := rand.New(rand.NewSource(1))
+	vocabulary := benchmarkVocabularyWords(rng)
+	zipf := rand.NewZipf(rng, 1.1, 1, uint64(len(vocabulary)-1))
+	start := time.Now().AddDate(-2, 0, 0)
+
+	for i := 0; i < benchmarkMemories; i++ {
+		words := make([]string, 6+rng.Intn(10))
+		for j := range words {
+			if rng.Intn(4) == 0 {
+				words[j] = benchmarkWords[rng.Intn(len(benchmarkWords))]
+			} else {
+				words[j] = vocabulary[zipf.Uint64()]
+			}
+		}
+		tag := benchmarkWords[rng.Intn(len(benchmarkWords))]
+		content := strings.Join(words, " ") + " #" + tag
+		userID := int64(i%benchmarkUsers + 1)
+
+		if _, err := stmt.Exec(userID, userID, content, tag, start.Add(time.Duration(i)*time.Minute)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// benchmarkVocabularyWords generates pronounceable words without digits
+func benchmarkVocabularyWords(rng *rand.Rand) []string {
+	consonants := "bdfgklmnprstvz"
+	vowels := "aeiou"
+
+	seen := make(map[string]bool)
+	var words []string
+	for len(words) < benchmarkVocabulary {
+		var word strings.Builder
+		for i := 0; i < 2+rng.Intn(3); i++ {
+			word.WriteByte(consonants[rng.Intn(len(consonants))])
+			word.WriteByte(vowels[rng.Intn(len(vowels))])
+		}
+		if !seen[word.String()] {
+			seen[word.String()] = true
+			words = append(words, word.String())
+		}
+	}
+	return words
+}
+
+// BenchmarkSmartSearch compares the combined fallback tiers with the former sequential chain
+// The newcomer cases search as a user without memories, so every tier scans matches
+// that belong to other users only
+func BenchmarkSmartSearch(b *testing.B) {
+	repo := loadBenchmarkRepository(b)
+	strategy := NewSmartSearchStrategy(repo)
+	ctx := context.Background()
+
+	queries := []struct {
+		name    string
+		userID  int64
+		keyword string
+	}{
+		{"primary", 1, "kubernetes backup"},
+		{"partial", 1, "kubernetes quokka"},
+		{"fuzzy", 1, "kubernetics"},
+		{"none", 1, "zebra quokka"},
+		{"newcomer-words", benchmarkUsers + 1, "meeting budget"},
+		{"newcomer-version", benchmarkUsers + 1, "release-2.4 server"},
+	}
+
+	for _, q := range queries {
+		query := SearchQuery{UserID: q.userID, Keyword: q.keyword, Limit: 11}
+
+		b.Run(q.name+"/sequential", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := searchSequentially(ctx, repo, query); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(q.name+"/tiered", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := strategy.Search(ctx, query); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// searchSequentially is the former chain: the primary search, then one fallback tier
+// after the other until one has results
+func searchSequentially(ctx context.Context, repo repository.MemoryRepository, query SearchQuery) ([]*entity.Memory, error) {
+	opts := repository.SearchOptions{Limit: query.Limit}
+	tiers := append([]repository.SearchTier{{Step: StepPrimary, Queries: []string{query.Keyword}}}, PlanFallbackTiers(query.Keyword)...)
+
+	for _, tier := range tiers {
+		for _, q := range tier.Queries {
+			page, err := repo.Search(ctx, query.UserID, q, opts)
+			if err == nil && len(page.Memories) > 0 {
+				return page.Memories, nil
+			}
+		}
+	}
+	return []*entity.Memory{}, nil
+}
@@ -0,0 +1,64 @@
+package strategy
+
+import (
+	"strings"
+
+	"memory-bot/internal/domain/repository"
+)
+
+// StrategyFactory picks the search strategy for each query (Factory Pattern)
+// - "#tag"               -> Tag
+// - "quoted phrase"      -> Exact
+// - a date expression    -> Date ("yesterday", "2024-03", "March 2024")
+// - anything else        -> SmartSearch
+// A prefix forces a strategy: tag:, exact:, date:, fuzzy:, smart:
+type StrategyFactory struct {
+	smart  *SmartSearchStrategy
+	tag    *TagSearchStrategy
+	exact  *ExactSearchStrategy
+	date   *DateBrowseStrategy
+	fuzzy  *FuzzySearchStrategy
+	byName map[string]SearchStrategy
+}
+
+// NewStrategyFactory creates the strategies once; they are shared between queries
+func NewStrategyFactory(repo repository.MemoryRepository) *StrategyFactory {
+	f := &StrategyFactory{
+		smart: NewSmartSearchStrategy(repo),
+		tag:   NewTagSearchStrategy(repo),
+		exact: NewExactSearchStrategy(repo),
+		date:  NewDateBrowseStrategy(repo),
+		fuzzy: NewFuzzySearchStrategy(repo),
+	}
+	f.byName = map[string]SearchStrategy{
+		"smart": f.smart,
+		"tag":   f.tag,
+		"exact": f.exact,
+		"date":  f.date,
+		"fuzzy": f.fuzzy,
+	}
+	return f
+}
+
+// CreateStrategy selects the strategy for the query and strips a forcing prefix
+func (f *StrategyFactory) CreateStrategy(query SearchQuery) (SearchStrategy, SearchQuery) {
+	keyword := strings.TrimSpace(query.Keyword)
+
+	if prefix, rest, ok := strings.Cut(keyword, ":"); ok {
+		if forced, ok := f.byName[strings.ToLower(prefix)]; ok && strings.TrimSpace(rest) != "" {
+			query.Keyword = strings.TrimSpace(rest)
+			return forced, query
+		}
+	}
+
+	switch fields := strings.Fields(keyword); {
+	case len(fields) == 1 && strings.HasPrefix(keyword, "#"):
+		return f.tag, query
+	case isQuoted(keyword):
+		return f.exact, query
+	case isDateExpression(keyword):
+		return f.date, query
+	default:
+		return f.smart, query
+	}
+}
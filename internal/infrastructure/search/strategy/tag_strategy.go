@@ -0,0 +1,55 @@
+package strategy
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"memory-bot/internal/domain/repository"
+)
+
+// TagSearchStrategy looks up a single tag in the normalized tag index
+// Sub-tags and aliases are included (#work finds #work/projectx)
+type TagSearchStrategy struct {
+	repo repository.MemoryRepository
+}
+
+// NewTagSearchStrategy creates a new tag search strategy
+func NewTagSearchStrategy(repo repository.MemoryRepository) *TagSearchStrategy {
+	return &TagSearchStrategy{
+		repo: repo,
+	}
+}
+
+// Search returns the memories carrying the tag, newest first
+func (s *TagSearchStrategy) Search(ctx context.Context, query SearchQuery) (*repository.MemoryPage, error) {
+	tag := strings.TrimSpace(query.Keyword)
+	if !strings.HasPrefix(tag, "#") {
+		tag = "#" + tag
+	}
+
+	page, err := s.repo.SearchByTag(ctx, query.UserID, tag, repository.SearchOptions{
+		Limit:  query.Limit,
+		Cursor: query.Cursor,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("TagSearch: Found %d results for %s", len(page.Memories), tag)
+	query.Trace.Record(stepFor(page, StepTag))
+	return page, nil
+}
+
+// Name returns the strategy name
+func (s *TagSearchStrategy) Name() string {
+	return "Tag"
+}
+
+// stepFor reports the step of a single-step strategy (StepNone without results)
+func stepFor(page *repository.MemoryPage, step string) string {
+	if len(page.Memories) == 0 {
+		return StepNone
+	}
+	return step
+}
@@ -0,0 +1,23 @@
+package command
+
+// AdminPolicy decides which users may run operator commands (ADMIN_USER_IDS)
+type AdminPolicy struct {
+	adminIDs map[int64]bool
+}
+
+// NewAdminPolicy creates an admin policy for the given Telegram user IDs
+func NewAdminPolicy(adminIDs []int64) *AdminPolicy {
+	ids := make(map[int64]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		ids[id] = true
+	}
+
+	return &AdminPolicy{
+		adminIDs: ids,
+	}
+}
+
+// IsAdmin reports whether the user is an administrator
+func (p *AdminPolicy) IsAdmin(userID int64) bool {
+	return p.adminIDs[userID]
+}
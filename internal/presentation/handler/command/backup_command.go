@@ -0,0 +1,101 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxDocumentSize is the largest file a bot may upload to Telegram
+const maxDocumentSize = 50 * 1024 * 1024
+
+// BackupCommand handles the admin-only /backup command
+type BackupCommand struct {
+	useCase *usecase.BackupDatabaseUseCase
+	admins  *AdminPolicy
+}
+
+// NewBackupCommand creates a new backup command
+func NewBackupCommand(useCase *usecase.BackupDatabaseUseCase, admins *AdminPolicy) *BackupCommand {
+	return &BackupCommand{
+		useCase: useCase,
+		admins:  admins,
+	}
+}
+
+// Name returns the command name
+func (c *BackupCommand) Name() string {
+	return "backup"
+}
+
+// Description returns the command description
+func (c *BackupCommand) Description() string {
+	return "Send the latest database backup (admin)"
+}
+
+// Execute sends the latest snapshot; "/backup now" takes a fresh one first
+func (c *BackupCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	if !c.admins.IsAdmin(message.From.ID) {
+		_, err := bot.Send(tgbotapi.NewMessage(message.Chat.ID, "⛔ This command is only available to administrators."))
+		return err
+	}
+
+	args := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if args != "" && args != "now" {
+		_, err := bot.Send(tgbotapi.NewMessage(message.Chat.ID, "Usage: /backup or /backup now"))
+		return err
+	}
+
+	snapshot, err := c.useCase.Latest(ctx)
+	if args == "now" || errors.Is(err, entity.ErrNoBackup) {
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "💾 Taking a database snapshot..."))
+
+		var output *usecase.BackupDatabaseOutput
+		output, err = c.useCase.Execute(ctx)
+		if output != nil {
+			snapshot = output.Snapshot
+		}
+	}
+	if err != nil {
+		log.Printf("Error preparing backup: %v", err)
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Backup failed. Check the bot logs for details."))
+		return err
+	}
+
+	if snapshot.Size > maxDocumentSize {
+		response := fmt.Sprintf("💾 The latest snapshot is too large for Telegram (%s).\n\nIt is stored on the server as `%s`.",
+			formatBytes(snapshot.Size), snapshot.Path)
+		msg := tgbotapi.NewMessage(message.Chat.ID, response)
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+
+	doc := tgbotapi.NewDocument(message.Chat.ID, tgbotapi.FilePath(snapshot.Path))
+	doc.Caption = fmt.Sprintf("💾 %s\n📅 %s • %s",
+		filepath.Base(snapshot.Path), snapshot.CreatedAt.Format("2006-01-02 15:04"), formatBytes(snapshot.Size))
+	_, err = bot.Send(doc)
+	return err
+}
+
+// formatBytes renders a file size for humans (1.4 MB)
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, exp := float64(size)/unit, 0
+	for value >= unit && exp < 3 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", value, "KMGT"[exp])
+}
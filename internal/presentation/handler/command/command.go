@@ -2,6 +2,7 @@ package command
 
 import (
 	"context"
+	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -18,6 +19,32 @@ type Command interface {
 	Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error
 }
 
+// CallbackCommand is a command that also handles its own inline keyboard callbacks
+// Callback data has the form "<prefix>:<arg1>:<arg2>..."
+type CallbackCommand interface {
+	Command
+
+	// CallbackPrefix returns the callback data prefix handled by the command
+	CallbackPrefix() string
+
+	// HandleCallback handles a callback query; args are the colon-separated parts after the prefix
+	HandleCallback(ctx context.Context, bot BotAPI, query *tgbotapi.CallbackQuery, args []string) error
+}
+
+// InputCommand is a command that can wait for the user's next text message (interactive flows)
+type InputCommand interface {
+	Command
+
+	// AwaitsInput reports whether the command waits for a message from the user
+	AwaitsInput(userID int64) bool
+
+	// HandleInput handles the awaited message
+	HandleInput(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error
+
+	// CancelInput stops waiting for a message from the user
+	CancelInput(userID int64)
+}
+
 // BotAPI defines the interface for bot operations
 type BotAPI interface {
 	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
@@ -25,19 +52,29 @@ type BotAPI interface {
 
 // CommandRegistry manages and executes commands
 type CommandRegistry struct {
-	commands map[string]Command
+	commands  map[string]Command
+	callbacks map[string]CallbackCommand
+	inputs    []InputCommand
 }
 
 // NewCommandRegistry creates a new command registry
 func NewCommandRegistry() *CommandRegistry {
 	return &CommandRegistry{
-		commands: make(map[string]Command),
+		commands:  make(map[string]Command),
+		callbacks: make(map[string]CallbackCommand),
 	}
 }
 
 // Register registers a new command
 func (r *CommandRegistry) Register(cmd Command) {
 	r.commands[cmd.Name()] = cmd
+
+	if callbackCmd, ok := cmd.(CallbackCommand); ok {
+		r.callbacks[callbackCmd.CallbackPrefix()] = callbackCmd
+	}
+	if inputCmd, ok := cmd.(InputCommand); ok {
+		r.inputs = append(r.inputs, inputCmd)
+	}
 }
 
 // Get retrieves a command by name
@@ -56,6 +93,35 @@ func (r *CommandRegistry) Execute(ctx context.Context, name string, bot BotAPI,
 	return cmd.Execute(ctx, bot, message)
 }
 
+// ExecuteCallback dispatches a callback query to the command owning its prefix
+func (r *CommandRegistry) ExecuteCallback(ctx context.Context, bot BotAPI, query *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(query.Data, ":")
+
+	cmd, exists := r.callbacks[parts[0]]
+	if !exists {
+		return ErrCommandNotFound
+	}
+
+	return cmd.HandleCallback(ctx, bot, query, parts[1:])
+}
+
+// PendingInput returns the command waiting for the user's next text message
+func (r *CommandRegistry) PendingInput(userID int64) (InputCommand, bool) {
+	for _, cmd := range r.inputs {
+		if cmd.AwaitsInput(userID) {
+			return cmd, true
+		}
+	}
+	return nil, false
+}
+
+// CancelPendingInput stops all commands from waiting for the user's next text message
+func (r *CommandRegistry) CancelPendingInput(userID int64) {
+	for _, cmd := range r.inputs {
+		cmd.CancelInput(userID)
+	}
+}
+
 // GetAll returns all registered commands
 func (r *CommandRegistry) GetAll() []Command {
 	commands := make([]Command, 0, len(r.commands))
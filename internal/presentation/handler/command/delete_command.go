@@ -0,0 +1,67 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// DeleteCommand handles the /delete command, which moves a memory to the trash
+type DeleteCommand struct {
+	useCase *usecase.ManageTrashUseCase
+}
+
+// NewDeleteCommand creates a new delete command
+func NewDeleteCommand(useCase *usecase.ManageTrashUseCase) *DeleteCommand {
+	return &DeleteCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *DeleteCommand) Name() string {
+	return "delete"
+}
+
+// Description returns the command description
+func (c *DeleteCommand) Description() string {
+	return "Move a memory to the trash"
+}
+
+// Execute executes the delete command (/delete <memory id>)
+func (c *DeleteCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	memoryID, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(message.CommandArguments()), "#"))
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "🗑 *Delete Memory*\n\nUsage: `/delete <memory id>`\n\nDeleted memories stay in the /trash until they are purged.")
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+
+	err = c.useCase.Delete(ctx, memoryID, message.From.ID)
+	if err == entity.ErrMemoryNotFound || err == entity.ErrUnauthorized {
+		_, sendErr := bot.Send(tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("❌ Memory #%d not found.", memoryID)))
+		return sendErr
+	}
+	if err != nil {
+		log.Printf("Error deleting memory: %v", err)
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Failed to delete memory. Please try again."))
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("🗑 Memory #%d moved to the trash.\n\nUse /trash to see deleted memories.", memoryID))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Undo", fmt.Sprintf("restore:%d", memoryID)),
+		),
+	)
+	_, err = bot.Send(msg)
+	return err
+}
@@ -0,0 +1,179 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// doctorFindingsShown is the number of findings listed per check
+const doctorFindingsShown = 5
+
+// DoctorCommand handles the admin-only /doctor command and its repair buttons
+type DoctorCommand struct {
+	useCase *usecase.DiagnoseDatabaseUseCase
+	admins  *AdminPolicy
+}
+
+// NewDoctorCommand creates a new doctor command
+func NewDoctorCommand(useCase *usecase.DiagnoseDatabaseUseCase, admins *AdminPolicy) *DoctorCommand {
+	return &DoctorCommand{
+		useCase: useCase,
+		admins:  admins,
+	}
+}
+
+// Name returns the command name
+func (c *DoctorCommand) Name() string {
+	return "doctor"
+}
+
+// Description returns the command description
+func (c *DoctorCommand) Description() string {
+	return "Check the database for inconsistencies (admin)"
+}
+
+// CallbackPrefix returns the callback prefix for repair buttons
+func (c *DoctorCommand) CallbackPrefix() string {
+	return "doctor"
+}
+
+// Execute runs the checks (/doctor) or repairs (/doctor repair <check...|all>)
+func (c *DoctorCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	if !c.admins.IsAdmin(message.From.ID) {
+		_, err := bot.Send(tgbotapi.NewMessage(message.Chat.ID, "⛔ This command is only available to administrators."))
+		return err
+	}
+
+	args := strings.Fields(strings.ToLower(message.CommandArguments()))
+	switch {
+	case len(args) == 0:
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "🩺 Checking the database..."))
+
+		report, err := c.useCase.Execute(ctx)
+		if err != nil {
+			log.Printf("Error checking database: %v", err)
+			bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Database check failed. Check the bot logs for details."))
+			return err
+		}
+		_, err = bot.Send(c.reportMessage(message.Chat.ID, "", report))
+		return err
+
+	case args[0] == "repair" && len(args) > 1:
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "🔧 Repairing..."))
+		msg, err := c.repair(ctx, message.Chat.ID, args[1:])
+		bot.Send(msg)
+		return err
+
+	default:
+		msg := tgbotapi.NewMessage(message.Chat.ID, "🩺 *Doctor*\n\nUsage:\n`/doctor` - run all checks\n`/doctor repair <check> [...]` - repair the findings of checks\n`/doctor repair all` - repair everything that can be repaired")
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+}
+
+// HandleCallback handles "doctor:repair:<check|all>"
+func (c *DoctorCommand) HandleCallback(ctx context.Context, bot BotAPI, query *tgbotapi.CallbackQuery, args []string) error {
+	if !c.admins.IsAdmin(query.From.ID) {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Only administrators can repair the database"))
+		return nil
+	}
+	if len(args) != 2 || args[0] != "repair" {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	bot.Send(tgbotapi.NewCallback(query.ID, "Repairing..."))
+	msg, err := c.repair(ctx, query.Message.Chat.ID, args[1:])
+	bot.Send(msg)
+	return err
+}
+
+// repair runs the repairs and builds the message with the report of what is left
+func (c *DoctorCommand) repair(ctx context.Context, chatID int64, checks []string) (tgbotapi.MessageConfig, error) {
+	output, err := c.useCase.Repair(ctx, checks)
+	if errors.Is(err, entity.ErrUnknownCheck) {
+		return tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ Unknown check. Checks: %s", strings.Join(entity.DoctorChecks(), ", "))), nil
+	}
+	if err != nil {
+		log.Printf("Error repairing database: %v", err)
+		return tgbotapi.NewMessage(chatID, "❌ Repair failed. Check the bot logs for details."), err
+	}
+
+	summary := "🔧 *Repair*\n\nNothing needed repairing.\n\n"
+	if len(output.Repaired) > 0 {
+		names := make([]string, 0, len(output.Repaired))
+		for name := range output.Repaired {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		summary = "🔧 *Repaired*\n\n"
+		for _, name := range names {
+			summary += fmt.Sprintf("• `%s`: %d\n", name, output.Repaired[name])
+		}
+		summary += "\n"
+	}
+	return c.reportMessage(chatID, summary, output.Report), nil
+}
+
+// reportMessage renders the report with one repair button per repairable check
+func (c *DoctorCommand) reportMessage(chatID int64, header string, report *entity.DoctorReport) tgbotapi.MessageConfig {
+	response := header + "🩺 *Database Doctor*\n\n"
+
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for _, check := range report.Checks {
+		switch {
+		case check.Skipped != "":
+			response += fmt.Sprintf("⏭ `%s`: skipped, %s\n", check.Name, markdown.Escape(check.Skipped))
+			continue
+		case len(check.Findings) == 0:
+			response += fmt.Sprintf("✅ `%s`\n", check.Name)
+			continue
+		}
+
+		response += fmt.Sprintf("\n❌ `%s`: %d problem(s)\n", check.Name, len(check.Findings))
+		for i, finding := range check.Findings {
+			if i == doctorFindingsShown {
+				response += fmt.Sprintf("   _…and %d more_\n", len(check.Findings)-doctorFindingsShown)
+				break
+			}
+			response += "   • " + markdown.Escape(truncate(finding.String(), 150)) + "\n"
+		}
+
+		if check.Repairable() {
+			response += "   🔧 Repair: " + check.Repair + "\n"
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🔧 Repair "+check.Name, "doctor:repair:"+check.Name),
+			))
+		} else if check.Name == entity.CheckIntegrity {
+			response += "   No automatic repair: restore the latest backup (`-restore`)\n"
+		}
+		response += "\n"
+	}
+
+	if report.Problems() == 0 {
+		response += "\nEverything looks healthy."
+	} else if len(rows) > 1 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔧 Repair all", "doctor:repair:"+usecase.RepairAll),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, response)
+	msg.ParseMode = "Markdown"
+	if len(rows) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+	return msg
+}
@@ -0,0 +1,197 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// EditCommand handles the /edit command and "✏️ Edit" buttons
+// "/edit <id> <new text>" edits right away; "/edit <id>" shows the memory and
+// waits for the new text as the next message
+type EditCommand struct {
+	useCase *usecase.EditMemoryUseCase
+	pending map[int64]int // User ID -> memory waiting for its new text
+}
+
+// NewEditCommand creates a new edit command
+func NewEditCommand(useCase *usecase.EditMemoryUseCase) *EditCommand {
+	return &EditCommand{
+		useCase: useCase,
+		pending: make(map[int64]int),
+	}
+}
+
+// Name returns the command name
+func (c *EditCommand) Name() string {
+	return "edit"
+}
+
+// Description returns the command description
+func (c *EditCommand) Description() string {
+	return "Edit a memory"
+}
+
+// CallbackPrefix returns the callback prefix for edit buttons
+func (c *EditCommand) CallbackPrefix() string {
+	return "edit"
+}
+
+// Execute executes the edit command (/edit <memory id> [new text])
+func (c *EditCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	idArg, content, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+	memoryID, err := strconv.Atoi(strings.TrimPrefix(idArg, "#"))
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "✏️ *Edit Memory*\n\n"+
+			"Usage:\n"+
+			"• `/edit <memory id>` - show the memory and send the new text next\n"+
+			"• `/edit <memory id> <new text>` - replace the text right away\n\n"+
+			"You can also edit the Telegram message a memory was saved from.")
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return c.prompt(ctx, bot, message.Chat.ID, message.From.ID, memoryID)
+	}
+	return c.edit(ctx, bot, message.Chat.ID, message.From.ID, memoryID, content)
+}
+
+// HandleCallback handles "edit:<memory id>" and "edit:cancel" buttons
+func (c *EditCommand) HandleCallback(ctx context.Context, bot BotAPI, query *tgbotapi.CallbackQuery, args []string) error {
+	if len(args) < 1 {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	if args[0] == "cancel" {
+		c.CancelInput(query.From.ID)
+		bot.Send(tgbotapi.NewCallback(query.ID, "Edit cancelled"))
+		bot.Send(tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, "✖️ Edit cancelled."))
+		return nil
+	}
+
+	memoryID, err := strconv.Atoi(args[0])
+	if err != nil {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	bot.Send(tgbotapi.NewCallback(query.ID, "Send the new text"))
+	return c.prompt(ctx, bot, query.Message.Chat.ID, query.From.ID, memoryID)
+}
+
+// AwaitsInput reports whether the user was asked for the new text of a memory
+func (c *EditCommand) AwaitsInput(userID int64) bool {
+	_, ok := c.pending[userID]
+	return ok
+}
+
+// HandleInput applies the awaited new text
+func (c *EditCommand) HandleInput(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	memoryID, ok := c.pending[message.From.ID]
+	if !ok {
+		return nil
+	}
+	delete(c.pending, message.From.ID)
+
+	return c.edit(ctx, bot, message.Chat.ID, message.From.ID, memoryID, message.Text)
+}
+
+// CancelInput forgets a pending edit
+func (c *EditCommand) CancelInput(userID int64) {
+	delete(c.pending, userID)
+}
+
+// prompt shows the current text of the memory and waits for the new one
+func (c *EditCommand) prompt(ctx context.Context, bot BotAPI, chatID, userID int64, memoryID int) error {
+	memory, err := c.useCase.Get(ctx, memoryID, userID)
+	if err == entity.ErrMemoryNotFound || err == entity.ErrUnauthorized {
+		_, sendErr := bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ Memory #%d not found.", memoryID)))
+		return sendErr
+	}
+	if err != nil {
+		log.Printf("Error loading memory for edit: %v", err)
+		bot.Send(tgbotapi.NewMessage(chatID, "❌ Failed to load memory. Please try again."))
+		return err
+	}
+	if memory.Locked {
+		_, err := bot.Send(lockedMemoryMessage(chatID, memoryID))
+		return err
+	}
+
+	c.pending[userID] = memoryID
+
+	response := fmt.Sprintf("✏️ *Editing Memory #%d*\n\n%s\n\n"+
+		"Send the new text as your next message. Tags and emotional weight are updated from it.",
+		memoryID, markdown.Escape(memory.Masked()))
+	buttons := []tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardButtonData("✖️ Cancel", "edit:cancel")}
+	if memory.Secret {
+		buttons = append(buttons, revealButton("👁 Reveal", memoryID))
+	}
+	msg := tgbotapi.NewMessage(chatID, response)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(buttons)
+	_, err = bot.Send(msg)
+	return err
+}
+
+// edit replaces the text of the memory and reports the new revision
+func (c *EditCommand) edit(ctx context.Context, bot BotAPI, chatID, userID int64, memoryID int, content string) error {
+	output, err := c.useCase.Execute(ctx, usecase.EditMemoryInput{
+		UserID:   userID,
+		MemoryID: memoryID,
+		Content:  content,
+		Source:   entity.RevisionSourceEdit,
+	})
+	switch err {
+	case nil:
+	case entity.ErrMemoryNotFound, entity.ErrUnauthorized:
+		_, sendErr := bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ Memory #%d not found.", memoryID)))
+		return sendErr
+	case entity.ErrContentUnchanged:
+		_, sendErr := bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("ℹ️ Memory #%d already has this text.", memoryID)))
+		return sendErr
+	case entity.ErrEmptyContent:
+		_, sendErr := bot.Send(tgbotapi.NewMessage(chatID, "❌ A memory cannot be empty."))
+		return sendErr
+	case entity.ErrVaultLocked:
+		_, sendErr := bot.Send(lockedMemoryMessage(chatID, memoryID))
+		return sendErr
+	default:
+		log.Printf("Error editing memory: %v", err)
+		bot.Send(tgbotapi.NewMessage(chatID, "❌ Failed to edit memory. Please try again."))
+		return err
+	}
+
+	_, err = bot.Send(EditedMessage(chatID, output))
+	return err
+}
+
+// EditedMessage builds the confirmation for an edited memory (also sent for edited Telegram messages)
+func EditedMessage(chatID int64, output *usecase.EditMemoryOutput) tgbotapi.MessageConfig {
+	response := fmt.Sprintf("✏️ *Memory #%d updated* (revision v%d)\n\n", output.Memory.ID, output.Revision.Number)
+	response += fmt.Sprintf("😊 *Emotional Weight:* %.0f%%\n", output.Memory.EmotionalWeight*100)
+	if len(output.Memory.Tags) > 0 {
+		response += fmt.Sprintf("🏷️ *Tags:* %s\n", markdown.Escape(strings.Join(output.Memory.Tags, " ")))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, response)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📜 Revisions", fmt.Sprintf("revisions:%d", output.Memory.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🔗 Related", fmt.Sprintf("related:%d", output.Memory.ID)),
+		),
+	)
+	return msg
+}
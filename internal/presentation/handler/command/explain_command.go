@@ -0,0 +1,101 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/pkg/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ExplainCommand handles the /explain command
+type ExplainCommand struct {
+	useCase *usecase.ExplainSearchUseCase
+}
+
+// NewExplainCommand creates a new explain command
+func NewExplainCommand(useCase *usecase.ExplainSearchUseCase) *ExplainCommand {
+	return &ExplainCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *ExplainCommand) Name() string {
+	return "explain"
+}
+
+// Description returns the command description
+func (c *ExplainCommand) Description() string {
+	return "Explain how a search is processed"
+}
+
+// Execute executes the explain command (/explain <query>)
+func (c *ExplainCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	query := strings.TrimSpace(message.CommandArguments())
+	if query == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "🔬 *Explain Search*\n\nUsage: `/explain <query>`")
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+
+	output, err := c.useCase.Execute(ctx, message.From.ID, query, PageSize)
+	if err != nil {
+		log.Printf("Error explaining search: %v", err)
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Failed to explain the search."))
+		return err
+	}
+
+	response := fmt.Sprintf("🔬 *Search Explanation:* %s\n\n", markdown.Escape(output.Query))
+	response += fmt.Sprintf("🧭 Strategy: *%s*\n", markdown.Escape(output.Strategy))
+
+	if output.Tag != "" {
+		response += fmt.Sprintf("🏷 Tag search: #%s (including sub-tags)\n", markdown.Escape(output.Tag))
+	}
+	if output.Period != "" {
+		response += fmt.Sprintf("📅 Period: %s\n", markdown.Escape(output.Period))
+	}
+	if output.ContextCue != "" {
+		response += fmt.Sprintf("🕐 Context cue: %s\n", markdown.Escape(output.ContextCue))
+	}
+
+	if output.Explanation != nil {
+		if len(output.Explanation.Expansions) > 0 {
+			terms := make([]string, 0, len(output.Explanation.Expansions))
+			for term := range output.Explanation.Expansions {
+				terms = append(terms, term)
+			}
+			sort.Strings(terms)
+
+			response += "🔤 Synonyms:\n"
+			for _, term := range terms {
+				response += fmt.Sprintf("• %s → %s\n", markdown.Escape(term), formatSynonymTerms(output.Explanation.Expansions[term]))
+			}
+		}
+
+		response += fmt.Sprintf("🔎 Expression: `%s`\n", strings.ReplaceAll(output.Explanation.Expression, "`", "'"))
+		if output.Explanation.BlindIndex {
+			response += "🔐 Terms are matched as blind index tokens\n"
+		}
+	}
+
+	step := output.MatchedStep
+	if step == "" {
+		step = "none"
+	}
+	response += fmt.Sprintf("\n📊 Matched by step *%s* with %d results", markdown.Escape(step), output.ResultCount)
+	if output.ResultCount >= PageSize {
+		response += " (first page)"
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, response)
+	msg.ParseMode = "Markdown"
+	_, err = bot.Send(msg)
+	return err
+}
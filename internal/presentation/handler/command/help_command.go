@@ -54,9 +54,13 @@ func (c *HelpCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi
 ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 
 *Basic:* ` + "`/search keyword`" + `
-*Tags:* ` + "`/search #work`" + `
+*Tags:* ` + "`/search #work`" + ` (includes sub-tags like ` + "`#work/projectx`" + `)
 *Multiple:* ` + "`/search project meeting`" + `
 *Context:* ` + "`/search Monday`" + ` or ` + "`/search morning`" + `
+*Phrase:* ` + "`/search \"team meeting\"`" + ` (exact words in order)
+*Dates:* ` + "`/search yesterday`" + `, ` + "`/search last week`" + `, ` + "`/search 2024-03`" + `
+*Force:* ` + "`exact:`" + `, ` + "`fuzzy:`" + `, ` + "`tag:`" + `, ` + "`date:`" + ` or ` + "`smart:`" + ` before the query
+*Inline:* ` + "`@bot keyword`" + ` in any chat to insert a memory
 
 *🎯 Smart Features:*
 • Wildcard matching (` + "`tele*`" + ` finds telegram, telephone)
@@ -69,6 +73,24 @@ func (c *HelpCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi
 ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 
 ` + "`/recent`" + ` - View latest 10 memories
+` + "`/related id`" + ` - Find memories similar to a memory
+` + "`/edit id`" + ` - Change a memory (or edit the message you saved)
+` + "`/revisions id`" + ` - Edit history with diffs and rollback
+` + "`/delete id`" + ` - Move a memory to the trash
+` + "`/trash`" + ` - List deleted memories
+` + "`/restore id`" + ` - Bring a memory back from the trash
+` + "`/secret text`" + ` - Save a masked secret (` + "`/secret on id`" + ` to mark one)
+` + "`/unlock passphrase`" + ` - Open your personal vault
+` + "`/lock`" + ` - Lock your vault
+` + "`/watch query`" + ` - Get notified about new matches
+` + "`/watches`" + ` - List and delete saved searches
+` + "`/tags`" + ` - Browse your tags
+` + "`/renametag old new`" + ` - Rename a tag
+` + "`/mergetag source target`" + ` - Merge two tags
+` + "`/tagalias alias tag`" + ` - File one tag under another
+` + "`/history on`" + ` - Remember searches for suggestions
+` + "`/synonym add k8s kubernetes`" + ` - Search terms that mean the same
+` + "`/explain query`" + ` - Show how a search is processed
 ` + "`/stats`" + ` - Memory statistics & insights
 ` + "`/start`" + ` - Welcome & feature overview
 ` + "`/help`" + ` - This guide
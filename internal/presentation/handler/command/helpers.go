@@ -1,9 +1,47 @@
 package command
 
-import "os"
+import (
+	"fmt"
+	"os"
+
+	"memory-bot/internal/domain/entity"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
 
 // fileExists checks if a file exists
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
+
+// lockedMemoryMessage is sent when a vaulted memory is changed or inspected while the vault is locked
+func lockedMemoryMessage(chatID int64, memoryID int) tgbotapi.MessageConfig {
+	return tgbotapi.NewMessage(chatID, fmt.Sprintf("🔒 Memory #%d is in your vault. Use /unlock first.", memoryID))
+}
+
+// revealButton builds the button that reveals the content of a secret memory
+func revealButton(label string, memoryID int) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("secret:%d", memoryID))
+}
+
+// revealRows builds a Reveal button for each secret memory of a listing numbered from first
+// Returns nil if no memory is secret
+func revealRows(memories []*entity.Memory, first int) [][]tgbotapi.InlineKeyboardButton {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for i, mem := range memories {
+		if !mem.Secret || mem.Locked {
+			continue
+		}
+		row = append(row, revealButton(fmt.Sprintf("👁 %d", first+i), mem.ID))
+		if len(row) == 5 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return rows
+}
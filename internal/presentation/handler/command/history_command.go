@@ -0,0 +1,104 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// historySuggestionCount is the number of past queries shown by /history
+const historySuggestionCount = 10
+
+// HistoryCommand handles the /history command (query history opt-in)
+type HistoryCommand struct {
+	useCase *usecase.QueryHistoryUseCase
+}
+
+// NewHistoryCommand creates a new history command
+func NewHistoryCommand(useCase *usecase.QueryHistoryUseCase) *HistoryCommand {
+	return &HistoryCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *HistoryCommand) Name() string {
+	return "history"
+}
+
+// Description returns the command description
+func (c *HistoryCommand) Description() string {
+	return "Search history & suggestions"
+}
+
+// Execute executes the history command (/history [on|off|clear])
+func (c *HistoryCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	userID := message.From.ID
+	var response string
+
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "on":
+		if err := c.useCase.SetEnabled(ctx, userID, true); err != nil {
+			return c.fail(bot, message.Chat.ID, err)
+		}
+		response = "✅ *Search history enabled*\n\nYour searches are now remembered and offered as suggestions when you tap 🔍 Search."
+
+	case "off":
+		if err := c.useCase.SetEnabled(ctx, userID, false); err != nil {
+			return c.fail(bot, message.Chat.ID, err)
+		}
+		response = "🚫 *Search history disabled*\n\nYour stored searches were deleted."
+
+	case "clear":
+		if err := c.useCase.Clear(ctx, userID); err != nil {
+			return c.fail(bot, message.Chat.ID, err)
+		}
+		response = "🧹 Your search history was cleared."
+
+	case "":
+		enabled, err := c.useCase.IsEnabled(ctx, userID)
+		if err != nil {
+			return c.fail(bot, message.Chat.ID, err)
+		}
+
+		if !enabled {
+			response = "🕘 *Search History*\n\nSearch history is *off*. Nothing you search is stored.\n\n" +
+				"Use `/history on` to get suggestions of your recent and frequent searches."
+			break
+		}
+
+		suggestions, err := c.useCase.Suggestions(ctx, userID, historySuggestionCount)
+		if err != nil {
+			return c.fail(bot, message.Chat.ID, err)
+		}
+
+		response = "🕘 *Search History* (on)\n\n"
+		if len(suggestions) == 0 {
+			response += "No searches yet.\n"
+		}
+		for _, query := range suggestions {
+			response += fmt.Sprintf("• `%s`\n", strings.ReplaceAll(query, "`", "'"))
+		}
+		response += "\n`/history clear` - delete history\n`/history off` - stop recording"
+
+	default:
+		response = "Usage: `/history`, `/history on`, `/history off` or `/history clear`"
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, response)
+	msg.ParseMode = "Markdown"
+	_, err := bot.Send(msg)
+	return err
+}
+
+// fail reports an error to the user
+func (c *HistoryCommand) fail(bot BotAPI, chatID int64, err error) error {
+	log.Printf("Error managing search history: %v", err)
+	bot.Send(tgbotapi.NewMessage(chatID, "❌ Failed to update search history. Please try again."))
+	return err
+}
@@ -0,0 +1,89 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// KeyRotationCommand handles the admin-only /keyrotation command
+// Keys are rotated in the configuration; the command reports the re-encryption progress
+type KeyRotationCommand struct {
+	useCase *usecase.RotateKeyUseCase
+	admins  *AdminPolicy
+}
+
+// NewKeyRotationCommand creates a new key rotation command
+func NewKeyRotationCommand(useCase *usecase.RotateKeyUseCase, admins *AdminPolicy) *KeyRotationCommand {
+	return &KeyRotationCommand{
+		useCase: useCase,
+		admins:  admins,
+	}
+}
+
+// Name returns the command name
+func (c *KeyRotationCommand) Name() string {
+	return "keyrotation"
+}
+
+// Description returns the command description
+func (c *KeyRotationCommand) Description() string {
+	return "Show encryption key rotation progress (admin)"
+}
+
+// Execute shows the encryption keys and how far re-encryption has come
+func (c *KeyRotationCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	if !c.admins.IsAdmin(message.From.ID) {
+		_, err := bot.Send(tgbotapi.NewMessage(message.Chat.ID, "⛔ This command is only available to administrators."))
+		return err
+	}
+
+	progress, err := c.useCase.Progress(ctx)
+	if err != nil {
+		log.Printf("Error reading key rotation progress: %v", err)
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Could not read the key rotation progress."))
+		return err
+	}
+
+	previous := "none"
+	if len(progress.PreviousKeys) > 0 {
+		previous = "`" + strings.Join(progress.PreviousKeys, "`, `") + "`"
+	}
+	response := fmt.Sprintf("🔑 *Encryption Keys*\n\n"+
+		"• Primary key: `%s`\n"+
+		"• Previous keys: %s\n\n", progress.KeyID, previous)
+
+	switch {
+	case progress.Complete:
+		response += "✅ All stored data uses the primary key."
+		if len(progress.PreviousKeys) > 0 {
+			response += " The previous keys can be removed from `ENCRYPTION_OLD_KEYS`, but keep them to restore older backups."
+		} else {
+			response += " To rotate, set a new `ENCRYPTION_KEY`, move the old one to `ENCRYPTION_OLD_KEYS` and restart."
+		}
+	default:
+		state := "⏸ paused, resumes on the next start"
+		if c.useCase.Running() {
+			state = "🔄 running"
+		}
+		if len(progress.PreviousKeys) == 0 {
+			// No rotation: plaintext or older-format rows are moved to the current format
+			response += "Encrypting stored data in the current format.\n"
+		}
+		response += fmt.Sprintf("Re-encryption %s\n• Progress: `%d/%d` rows (%d%%)",
+			state, progress.Processed, progress.Total, progress.Percent())
+		if !progress.UpdatedAt.IsZero() {
+			response += fmt.Sprintf("\n• Last checkpoint: %s", progress.UpdatedAt.Local().Format("2006-01-02 15:04:05"))
+		}
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, response)
+	msg.ParseMode = "Markdown"
+	_, err = bot.Send(msg)
+	return err
+}
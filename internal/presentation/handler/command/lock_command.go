@@ -0,0 +1,41 @@
+package command
+
+import (
+	"context"
+
+	"memory-bot/internal/application/usecase"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// LockCommand handles the /lock command
+type LockCommand struct {
+	useCase *usecase.ManageVaultUseCase
+}
+
+// NewLockCommand creates a new lock command
+func NewLockCommand(useCase *usecase.ManageVaultUseCase) *LockCommand {
+	return &LockCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *LockCommand) Name() string {
+	return "lock"
+}
+
+// Description returns the command description
+func (c *LockCommand) Description() string {
+	return "Lock your personal vault"
+}
+
+// Execute locks the vault of the user
+func (c *LockCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	response := "🔒 Your vault is already locked."
+	if c.useCase.Lock(message.From.ID) {
+		response = "🔒 Vault locked. New memories are saved normally until you /unlock again."
+	}
+	_, err := bot.Send(tgbotapi.NewMessage(message.Chat.ID, response))
+	return err
+}
@@ -0,0 +1,67 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// MergeTagCommand handles the /mergetag command
+type MergeTagCommand struct {
+	useCase *usecase.ManageTagsUseCase
+}
+
+// NewMergeTagCommand creates a new merge tag command
+func NewMergeTagCommand(useCase *usecase.ManageTagsUseCase) *MergeTagCommand {
+	return &MergeTagCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *MergeTagCommand) Name() string {
+	return "mergetag"
+}
+
+// Description returns the command description
+func (c *MergeTagCommand) Description() string {
+	return "Merge one tag into another"
+}
+
+// Execute executes the merge tag command (/mergetag <source> <target>)
+func (c *MergeTagCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "🔀 *Merge Tags*\n\n"+
+			"Usage: `/mergetag <source> <target>`\n\n"+
+			"*Example:* `/mergetag #meetings #meeting`\n"+
+			"All memories tagged with the source get the target tag instead.")
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+
+	count, err := c.useCase.Merge(ctx, message.From.ID, args[0], args[1])
+	if err != nil {
+		if err != entity.ErrTagNotFound && err != entity.ErrInvalidTag {
+			log.Printf("Error merging tags: %v", err)
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, tagErrorMessage(err))
+		msg.ParseMode = "Markdown"
+		bot.Send(msg)
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("✅ Merged *#%s* into *#%s* (%d memories).",
+		markdown.Escape(entity.NormalizeTag(args[0])), markdown.Escape(entity.NormalizeTag(args[1])), count))
+	msg.ParseMode = "Markdown"
+	_, err = bot.Send(msg)
+	return err
+}
@@ -0,0 +1,47 @@
+package command
+
+import (
+	"memory-bot/internal/domain/repository"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// nextPageAction is the callback argument of the Next button of a paged listing
+const nextPageAction = "next"
+
+// listingPage remembers where the last listing message of a user continues
+// Cursors are kept here rather than in the callback data, which is limited to 64 bytes.
+type listingPage struct {
+	messageID int
+	query     string
+	cursor    repository.Cursor
+	number    int // Page shown in the message, from 1
+}
+
+// pageOf returns the listing the Next button belongs to
+// Only the newest listing of a user can be continued; older messages have expired.
+func pageOf(pages map[int64]*listingPage, query *tgbotapi.CallbackQuery) (*listingPage, bool) {
+	page, ok := pages[query.From.ID]
+	if !ok || query.Message == nil || page.messageID != query.Message.MessageID {
+		return nil, false
+	}
+	return page, true
+}
+
+// nextPageRow returns the keyboard row with the Next button of a listing
+func nextPageRow(prefix string) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Next ⏩", prefix+":"+nextPageAction),
+	)
+}
+
+// showPage replaces the listing message with another page
+func showPage(bot BotAPI, query *tgbotapi.CallbackQuery, text string, rows [][]tgbotapi.InlineKeyboardButton) {
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	edit.ParseMode = "Markdown"
+	if len(rows) > 0 {
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+		edit.ReplyMarkup = &keyboard
+	}
+	bot.Send(edit)
+}
@@ -2,23 +2,30 @@ package command
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 
 	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// recentPageSize is the number of memories per /recent page
+const recentPageSize = 10
+
 // RecentCommand handles the /recent command
 type RecentCommand struct {
 	useCase *usecase.GetRecentMemoriesUseCase
+	pages   map[int64]*listingPage // User ID -> last /recent message with older memories
 }
 
 // NewRecentCommand creates a new recent command
 func NewRecentCommand(useCase *usecase.GetRecentMemoriesUseCase) *RecentCommand {
 	return &RecentCommand{
 		useCase: useCase,
+		pages:   make(map[int64]*listingPage),
 	}
 }
 
@@ -32,11 +39,16 @@ func (c *RecentCommand) Description() string {
 	return "Recent memories"
 }
 
+// CallbackPrefix returns the callback prefix for the Next button
+func (c *RecentCommand) CallbackPrefix() string {
+	return "recent"
+}
+
 // Execute executes the recent command
 func (c *RecentCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
 	input := usecase.GetRecentMemoriesInput{
 		UserID: message.From.ID,
-		Limit:  10,
+		Limit:  recentPageSize,
 	}
 
 	output, err := c.useCase.Execute(ctx, input)
@@ -48,24 +60,101 @@ func (c *RecentCommand) Execute(ctx context.Context, bot BotAPI, message *tgbota
 	}
 
 	if len(output.Memories) == 0 {
+		delete(c.pages, message.From.ID)
 		msg := tgbotapi.NewMessage(message.Chat.ID, "You don't have any memories yet. Start saving some!")
 		_, err := bot.Send(msg)
 		return err
 	}
 
+	response, rows := c.render(output, 1)
+	msg := tgbotapi.NewMessage(message.Chat.ID, response)
+	msg.ParseMode = "Markdown"
+	if len(rows) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+
+	sent, err := bot.Send(msg)
+	if err != nil {
+		return err
+	}
+	c.remember(message.From.ID, sent.MessageID, output, 1)
+	return nil
+}
+
+// HandleCallback handles "recent:next", which shows older memories
+func (c *RecentCommand) HandleCallback(ctx context.Context, bot BotAPI, query *tgbotapi.CallbackQuery, args []string) error {
+	if len(args) != 1 || args[0] != nextPageAction {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	page, ok := pageOf(c.pages, query)
+	if !ok {
+		bot.Send(tgbotapi.NewCallback(query.ID, "This list has expired, please send /recent again"))
+		return nil
+	}
+
+	output, err := c.useCase.Execute(ctx, usecase.GetRecentMemoriesInput{
+		UserID: query.From.ID,
+		Limit:  recentPageSize,
+		Cursor: page.cursor,
+	})
+	if errors.Is(err, entity.ErrInvalidCursor) {
+		delete(c.pages, query.From.ID)
+		bot.Send(tgbotapi.NewCallback(query.ID, "This list has expired, please send /recent again"))
+		return nil
+	}
+	if err != nil {
+		log.Printf("Error getting recent memories: %v", err)
+		bot.Send(tgbotapi.NewCallback(query.ID, "Failed to retrieve memories"))
+		return err
+	}
+	if len(output.Memories) == 0 {
+		delete(c.pages, query.From.ID)
+		bot.Send(tgbotapi.NewCallback(query.ID, "No older memories"))
+		return nil
+	}
+
+	bot.Send(tgbotapi.NewCallback(query.ID, ""))
+	response, rows := c.render(output, page.number+1)
+	showPage(bot, query, response, rows)
+	c.remember(query.From.ID, page.messageID, output, page.number+1)
+	return nil
+}
+
+// remember keeps where the list continues, or forgets it after the last page
+func (c *RecentCommand) remember(userID int64, messageID int, output *usecase.GetRecentMemoriesOutput, number int) {
+	if !output.HasMore {
+		delete(c.pages, userID)
+		return
+	}
+	c.pages[userID] = &listingPage{
+		messageID: messageID,
+		cursor:    output.NextCursor,
+		number:    number,
+	}
+}
+
+// render formats one page of recent memories, numbered across pages
+func (c *RecentCommand) render(output *usecase.GetRecentMemoriesOutput, number int) (string, [][]tgbotapi.InlineKeyboardButton) {
 	response := "📋 *Your Recent Memories:*\n\n"
+	if number > 1 {
+		response = fmt.Sprintf("📋 *Your Recent Memories (page %d):*\n\n", number)
+	}
 
+	first := (number-1)*recentPageSize + 1
 	for i, mem := range output.Memories {
-		content := mem.Content
+		content := mem.Masked()
 		if len(content) > 100 {
 			content = content[:100] + "..."
 		}
 
-		response += fmt.Sprintf("%d. %s\n\n", i+1, content)
+		response += fmt.Sprintf("%d. %s\n\n", first+i, content)
 	}
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, response)
-	msg.ParseMode = "Markdown"
-	_, err = bot.Send(msg)
-	return err
+	rows := revealRows(output.Memories, first)
+	if output.HasMore {
+		rows = append(rows, nextPageRow(c.CallbackPrefix()))
+	}
+	return response, rows
 }
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"memory-bot/internal/application/usecase"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ReindexCommand handles the admin-only /reindex command
+type ReindexCommand struct {
+	useCase *usecase.ReindexSearchUseCase
+	admins  *AdminPolicy
+}
+
+// NewReindexCommand creates a new reindex command
+func NewReindexCommand(useCase *usecase.ReindexSearchUseCase, admins *AdminPolicy) *ReindexCommand {
+	return &ReindexCommand{
+		useCase: useCase,
+		admins:  admins,
+	}
+}
+
+// Name returns the command name
+func (c *ReindexCommand) Name() string {
+	return "reindex"
+}
+
+// Description returns the command description
+func (c *ReindexCommand) Description() string {
+	return "Rebuild search index (admin)"
+}
+
+// Execute executes the reindex command
+func (c *ReindexCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	if !c.admins.IsAdmin(message.From.ID) {
+		_, err := bot.Send(tgbotapi.NewMessage(message.Chat.ID, "⛔ This command is only available to administrators."))
+		return err
+	}
+
+	bot.Send(tgbotapi.NewMessage(message.Chat.ID, "🔄 Rebuilding search index..."))
+
+	output, err := c.useCase.Execute(ctx)
+	if err != nil {
+		log.Printf("Error rebuilding search index: %v", err)
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Reindex failed. The previous index was kept."))
+		return err
+	}
+
+	response := fmt.Sprintf("✅ *Search index rebuilt*\n\n"+
+		"• Tokenizer: `%s`\n"+
+		"• Memories indexed: `%d`\n"+
+		"• Duration: `%s`",
+		output.Tokenizer, output.IndexedMemories, output.Duration.Round(time.Millisecond))
+	msg := tgbotapi.NewMessage(message.Chat.ID, response)
+	msg.ParseMode = "Markdown"
+	_, err = bot.Send(msg)
+	return err
+}
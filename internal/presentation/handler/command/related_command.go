@@ -0,0 +1,128 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// RelatedCommand handles the /related command and "🔗 Related" buttons
+type RelatedCommand struct {
+	useCase *usecase.FindRelatedMemoriesUseCase
+}
+
+// NewRelatedCommand creates a new related command
+func NewRelatedCommand(useCase *usecase.FindRelatedMemoriesUseCase) *RelatedCommand {
+	return &RelatedCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *RelatedCommand) Name() string {
+	return "related"
+}
+
+// Description returns the command description
+func (c *RelatedCommand) Description() string {
+	return "Find related memories"
+}
+
+// CallbackPrefix returns the callback prefix for related buttons
+func (c *RelatedCommand) CallbackPrefix() string {
+	return "related"
+}
+
+// Execute executes the related command (/related <memory id>)
+func (c *RelatedCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	memoryID, err := strconv.Atoi(strings.TrimSpace(message.CommandArguments()))
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "🔗 *Related Memories*\n\nUsage: `/related <memory id>`")
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+
+	return c.sendRelated(ctx, bot, message.Chat.ID, message.From.ID, memoryID)
+}
+
+// HandleCallback handles "related:<memory id>" buttons
+func (c *RelatedCommand) HandleCallback(ctx context.Context, bot BotAPI, query *tgbotapi.CallbackQuery, args []string) error {
+	if len(args) < 1 {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	memoryID, err := strconv.Atoi(args[0])
+	if err != nil {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	bot.Send(tgbotapi.NewCallback(query.ID, "Finding related memories..."))
+	return c.sendRelated(ctx, bot, query.Message.Chat.ID, query.From.ID, memoryID)
+}
+
+// sendRelated finds and sends memories related to the given memory
+func (c *RelatedCommand) sendRelated(ctx context.Context, bot BotAPI, chatID, userID int64, memoryID int) error {
+	input := usecase.FindRelatedMemoriesInput{
+		UserID:   userID,
+		MemoryID: memoryID,
+		Limit:    PageSize,
+	}
+
+	output, err := c.useCase.Execute(ctx, input)
+	if err == entity.ErrMemoryNotFound || err == entity.ErrUnauthorized {
+		_, sendErr := bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ Memory #%d not found.", memoryID)))
+		return sendErr
+	}
+	if err == entity.ErrVaultLocked {
+		_, sendErr := bot.Send(lockedMemoryMessage(chatID, memoryID))
+		return sendErr
+	}
+	if err != nil {
+		log.Printf("Error finding related memories: %v", err)
+		bot.Send(tgbotapi.NewMessage(chatID, "❌ Failed to find related memories. Please try again."))
+		return err
+	}
+
+	if len(output.Memories) == 0 {
+		_, err := bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("🔗 No related memories found for #%d.", memoryID)))
+		return err
+	}
+
+	response := fmt.Sprintf("🔗 *Related to Memory #%d*\n\n━━━━━━━━━━━━━━━\n", memoryID)
+	buttons := []tgbotapi.InlineKeyboardButton{}
+
+	for i, mem := range output.Memories {
+		content := mem.Masked()
+		if len(content) > 200 {
+			content = content[:200] + "..."
+		}
+
+		response += fmt.Sprintf("%d. %s\n🆔 #%d – %s\n\n",
+			i+1,
+			content,
+			mem.ID,
+			mem.CreatedAt.Format("2006-01-02"))
+
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("🔗 %d", i+1),
+			fmt.Sprintf("related:%d", mem.ID),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, response)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(append([][]tgbotapi.InlineKeyboardButton{buttons}, revealRows(output.Memories, 1)...)...)
+
+	_, err = bot.Send(msg)
+	return err
+}
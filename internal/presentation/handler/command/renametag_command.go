@@ -0,0 +1,67 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// RenameTagCommand handles the /renametag command
+type RenameTagCommand struct {
+	useCase *usecase.ManageTagsUseCase
+}
+
+// NewRenameTagCommand creates a new rename tag command
+func NewRenameTagCommand(useCase *usecase.ManageTagsUseCase) *RenameTagCommand {
+	return &RenameTagCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *RenameTagCommand) Name() string {
+	return "renametag"
+}
+
+// Description returns the command description
+func (c *RenameTagCommand) Description() string {
+	return "Rename a tag"
+}
+
+// Execute executes the rename tag command (/renametag <old> <new>)
+func (c *RenameTagCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "✏️ *Rename Tag*\n\n"+
+			"Usage: `/renametag <old> <new>`\n\n"+
+			"*Example:* `/renametag #job #work`\n"+
+			"Sub-tags move along (`#job/projectx` → `#work/projectx`).")
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+
+	count, err := c.useCase.Rename(ctx, message.From.ID, args[0], args[1])
+	if err != nil {
+		if err != entity.ErrTagNotFound && err != entity.ErrTagExists && err != entity.ErrInvalidTag {
+			log.Printf("Error renaming tag: %v", err)
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, tagErrorMessage(err))
+		msg.ParseMode = "Markdown"
+		bot.Send(msg)
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("✅ Renamed *#%s* → *#%s* on %d memories.",
+		markdown.Escape(entity.NormalizeTag(args[0])), markdown.Escape(entity.NormalizeTag(args[1])), count))
+	msg.ParseMode = "Markdown"
+	_, err = bot.Send(msg)
+	return err
+}
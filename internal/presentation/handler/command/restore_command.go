@@ -0,0 +1,103 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// RestoreCommand handles the /restore command and "↩️ Restore" buttons
+type RestoreCommand struct {
+	useCase *usecase.ManageTrashUseCase
+}
+
+// NewRestoreCommand creates a new restore command
+func NewRestoreCommand(useCase *usecase.ManageTrashUseCase) *RestoreCommand {
+	return &RestoreCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *RestoreCommand) Name() string {
+	return "restore"
+}
+
+// Description returns the command description
+func (c *RestoreCommand) Description() string {
+	return "Restore a memory from the trash"
+}
+
+// CallbackPrefix returns the callback prefix for restore buttons
+func (c *RestoreCommand) CallbackPrefix() string {
+	return "restore"
+}
+
+// Execute executes the restore command (/restore <memory id>)
+func (c *RestoreCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	memoryID, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(message.CommandArguments()), "#"))
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "↩️ *Restore Memory*\n\nUsage: `/restore <memory id>`\n\nUse /trash to see deleted memories.")
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+
+	text, err := c.restore(ctx, memoryID, message.From.ID)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, text))
+		return err
+	}
+
+	_, err = bot.Send(tgbotapi.NewMessage(message.Chat.ID, text))
+	return err
+}
+
+// HandleCallback restores a memory from a "restore:<memory id>" button
+func (c *RestoreCommand) HandleCallback(ctx context.Context, bot BotAPI, query *tgbotapi.CallbackQuery, args []string) error {
+	if len(args) < 1 {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	memoryID, err := strconv.Atoi(args[0])
+	if err != nil {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	text, err := c.restore(ctx, memoryID, query.From.ID)
+	bot.Send(tgbotapi.NewCallback(query.ID, text))
+	if err != nil {
+		return err
+	}
+
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	bot.Send(edit)
+	return nil
+}
+
+// restore restores the memory and returns the text for the user
+// Memories that are not in the user's trash are reported without an error
+func (c *RestoreCommand) restore(ctx context.Context, memoryID int, userID int64) (string, error) {
+	restored, err := c.useCase.Restore(ctx, memoryID, userID)
+	if err == entity.ErrMemoryNotFound || err == entity.ErrUnauthorized {
+		return fmt.Sprintf("❌ Memory #%d is not in the trash.", memoryID), nil
+	}
+	if err != nil {
+		log.Printf("Error restoring memory: %v", err)
+		return "❌ Failed to restore memory. Please try again.", err
+	}
+
+	if restored > 1 {
+		return fmt.Sprintf("↩️ Memory #%d restored with %d sub-memories.", memoryID, restored-1), nil
+	}
+	return fmt.Sprintf("↩️ Memory #%d restored.", memoryID), nil
+}
@@ -0,0 +1,272 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/internal/domain/service"
+	"memory-bot/pkg/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// revisionListLimit is the number of most recent revisions shown by /revisions
+	revisionListLimit = 8
+
+	// diffContextWords is the number of unchanged words kept around each change
+	diffContextWords = 5
+)
+
+// RevisionsCommand handles the /revisions command and its rollback buttons
+type RevisionsCommand struct {
+	useCase *usecase.EditMemoryUseCase
+}
+
+// NewRevisionsCommand creates a new revisions command
+func NewRevisionsCommand(useCase *usecase.EditMemoryUseCase) *RevisionsCommand {
+	return &RevisionsCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *RevisionsCommand) Name() string {
+	return "revisions"
+}
+
+// Description returns the command description
+func (c *RevisionsCommand) Description() string {
+	return "Show the edit history of a memory"
+}
+
+// CallbackPrefix returns the callback prefix for revision buttons
+func (c *RevisionsCommand) CallbackPrefix() string {
+	return "revisions"
+}
+
+// Execute executes the revisions command (/revisions <memory id>)
+func (c *RevisionsCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	memoryID, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(message.CommandArguments()), "#"))
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "📜 *Revisions*\n\nUsage: `/revisions <memory id>`")
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+
+	msg, err := c.render(ctx, message.Chat.ID, message.From.ID, memoryID)
+	if err != nil {
+		bot.Send(msg)
+		return err
+	}
+
+	_, err = bot.Send(msg)
+	return err
+}
+
+// HandleCallback handles "revisions:<memory id>" (show) and "revisions:<memory id>:<revision>" (roll back)
+func (c *RevisionsCommand) HandleCallback(ctx context.Context, bot BotAPI, query *tgbotapi.CallbackQuery, args []string) error {
+	if len(args) < 1 {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	memoryID, err := strconv.Atoi(args[0])
+	if err != nil {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+	chatID := query.Message.Chat.ID
+
+	if len(args) < 2 {
+		bot.Send(tgbotapi.NewCallback(query.ID, ""))
+		msg, err := c.render(ctx, chatID, query.From.ID, memoryID)
+		bot.Send(msg)
+		return err
+	}
+
+	number, err := strconv.Atoi(args[1])
+	if err != nil {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	output, err := c.useCase.Rollback(ctx, memoryID, query.From.ID, number)
+	switch err {
+	case nil:
+	case entity.ErrContentUnchanged:
+		bot.Send(tgbotapi.NewCallback(query.ID, fmt.Sprintf("v%d is the current text", number)))
+		return nil
+	case entity.ErrMemoryNotFound, entity.ErrUnauthorized, entity.ErrRevisionNotFound:
+		bot.Send(tgbotapi.NewCallback(query.ID, "Revision not found"))
+		return nil
+	case entity.ErrVaultLocked:
+		bot.Send(tgbotapi.NewCallback(query.ID, "🔒 Use /unlock first"))
+		return nil
+	default:
+		log.Printf("Error rolling back memory %d: %v", memoryID, err)
+		bot.Send(tgbotapi.NewCallback(query.ID, "Rollback failed"))
+		return err
+	}
+
+	bot.Send(tgbotapi.NewCallback(query.ID, fmt.Sprintf("Restored v%d as v%d", number, output.Revision.Number)))
+
+	// Refresh the history in place
+	msg, err := c.render(ctx, chatID, query.From.ID, memoryID)
+	if err != nil {
+		return err
+	}
+	edit := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, msg.Text)
+	edit.ParseMode = msg.ParseMode
+	if keyboard, ok := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup); ok {
+		edit.ReplyMarkup = &keyboard
+	}
+	bot.Send(edit)
+	return nil
+}
+
+// render builds the revision history with a word diff per revision and rollback buttons
+// The text of secret memories is left out; their revisions can still be rolled back.
+func (c *RevisionsCommand) render(ctx context.Context, chatID, userID int64, memoryID int) (tgbotapi.MessageConfig, error) {
+	memory, err := c.useCase.Get(ctx, memoryID, userID)
+	var revisions []*entity.MemoryRevision
+	if err == nil {
+		revisions, err = c.useCase.Revisions(ctx, memoryID, userID)
+	}
+	if err == entity.ErrMemoryNotFound || err == entity.ErrUnauthorized {
+		return tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ Memory #%d not found.", memoryID)), nil
+	}
+	if err == entity.ErrVaultLocked {
+		return lockedMemoryMessage(chatID, memoryID), nil
+	}
+	if err != nil {
+		log.Printf("Error loading revisions: %v", err)
+		return tgbotapi.NewMessage(chatID, "❌ Failed to load revisions. Please try again."), err
+	}
+
+	current := revisions[len(revisions)-1]
+	response := fmt.Sprintf("📜 *Revisions of Memory #%d*\n\n", memoryID)
+	if len(revisions) == 1 {
+		response += "This memory was never edited.\n\n"
+	} else if memory.Secret {
+		response += "🤫 _The text of this secret memory is hidden._\n\n"
+	} else {
+		response += "_Changes:_ \\[-removed-] {+added+}\n\n"
+	}
+
+	first := 0
+	if len(revisions) > revisionListLimit {
+		first = len(revisions) - revisionListLimit
+		response += fmt.Sprintf("_%d older revisions not shown._\n\n", first)
+	}
+
+	buttons := []tgbotapi.InlineKeyboardButton{}
+	for i := first; i < len(revisions); i++ {
+		rev := revisions[i]
+
+		response += fmt.Sprintf("*v%d* – %s, %s", rev.Number, revisionSourceLabel(rev), rev.CreatedAt.Format("2006-01-02 15:04"))
+		if rev == current {
+			response += " (current)"
+		}
+		response += "\n"
+
+		if memory.Secret {
+			response += "\n"
+		} else if i == 0 {
+			response += markdown.Escape(truncate(rev.Content, 300)) + "\n\n"
+		} else {
+			response += renderDiff(service.DiffWords(revisions[i-1].Content, rev.Content)) + "\n\n"
+		}
+
+		if rev.Content != current.Content {
+			buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("↩️ v%d", rev.Number),
+				fmt.Sprintf("revisions:%d:%d", memoryID, rev.Number),
+			))
+		}
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for len(buttons) > 0 {
+		n := len(buttons)
+		if n > 4 {
+			n = 4
+		}
+		rows = append(rows, buttons[:n])
+		buttons = buttons[n:]
+	}
+	lastRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✏️ Edit", fmt.Sprintf("edit:%d", memoryID)),
+	)
+	if memory.Secret {
+		lastRow = append(lastRow, revealButton("👁 Reveal", memoryID))
+	}
+	rows = append(rows, lastRow)
+
+	msg := tgbotapi.NewMessage(chatID, response)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return msg, nil
+}
+
+// revisionSourceLabel describes how a revision was created
+func revisionSourceLabel(rev *entity.MemoryRevision) string {
+	switch rev.Source {
+	case entity.RevisionSourceOriginal:
+		return "original"
+	case entity.RevisionSourceMessage:
+		return "message edited"
+	case entity.RevisionSourceRollback:
+		return fmt.Sprintf("restored v%d", rev.RestoredFrom)
+	default:
+		return "edited"
+	}
+}
+
+// renderDiff formats a word diff as [-removed-] {+added+}, shortening long unchanged runs
+func renderDiff(segments []service.DiffSegment) string {
+	var parts []string
+	for i, segment := range segments {
+		switch segment.Op {
+		case service.DiffDelete:
+			parts = append(parts, "\\[-"+markdown.Escape(segment.Text)+"-]")
+		case service.DiffInsert:
+			parts = append(parts, "{+"+markdown.Escape(segment.Text)+"+}")
+		default:
+			words := strings.Fields(segment.Text)
+			keepBefore, keepAfter := diffContextWords, diffContextWords
+			if i == 0 {
+				keepBefore = 0
+			}
+			if i == len(segments)-1 {
+				keepAfter = 0
+			}
+			if len(words) > keepBefore+keepAfter+1 {
+				shortened := append([]string{}, words[:keepBefore]...)
+				shortened = append(shortened, "…")
+				words = append(shortened, words[len(words)-keepAfter:]...)
+			}
+			parts = append(parts, markdown.Escape(strings.Join(words, " ")))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// truncate shortens text to at most limit bytes without splitting a character
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "..."
+}
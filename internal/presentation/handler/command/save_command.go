@@ -69,9 +69,10 @@ func (c *SaveCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi
 
 	// Save the memory
 	input := usecase.SaveMemoryInput{
-		UserID:  message.From.ID,
-		ChatID:  message.Chat.ID,
-		Content: args,
+		UserID:    message.From.ID,
+		ChatID:    message.Chat.ID,
+		Content:   args,
+		MessageID: message.MessageID,
 	}
 
 	output, err := c.useCase.Execute(ctx, input)
@@ -107,6 +108,10 @@ func (c *SaveCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi
 	// Memory ID for reference
 	response += fmt.Sprintf("🆔 *Memory ID:* `%d`\n\n", output.MemoryID)
 
+	if output.Secret {
+		response += "🤫 *Saved as secret:* masked in search, /recent and reviews. Consider deleting your message.\n\n"
+	}
+
 	// Review schedule info
 	response += "🔄 *Next Steps:*\n" +
 		"• Sleep consolidation will strengthen this memory tonight\n" +
@@ -126,6 +131,9 @@ func (c *SaveCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi
 			tgbotapi.NewInlineKeyboardButtonData("📊 My Stats", "cmd_stats"),
 			tgbotapi.NewInlineKeyboardButtonData("📚 Recent", "cmd_recent"),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔗 Related Memories", fmt.Sprintf("related:%d", output.MemoryID)),
+		),
 	)
 	msg.ReplyMarkup = keyboard
 
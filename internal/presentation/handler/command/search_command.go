@@ -2,10 +2,12 @@ package command
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 
 	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -15,12 +17,14 @@ const PageSize = 5
 // SearchCommand handles the /search command
 type SearchCommand struct {
 	useCase *usecase.SearchMemoryUseCase
+	pages   map[int64]*listingPage // User ID -> last search message with more pages
 }
 
 // NewSearchCommand creates a new search command
 func NewSearchCommand(useCase *usecase.SearchMemoryUseCase) *SearchCommand {
 	return &SearchCommand{
 		useCase: useCase,
+		pages:   make(map[int64]*listingPage),
 	}
 }
 
@@ -34,13 +38,18 @@ func (c *SearchCommand) Description() string {
 	return "Search memories"
 }
 
+// CallbackPrefix returns the callback prefix for the Next button
+func (c *SearchCommand) CallbackPrefix() string {
+	return "search"
+}
+
 // Execute executes the search command
 func (c *SearchCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
 	keyword := message.CommandArguments()
 
 	if keyword == "" {
 		// Prompt user for input
-		response := "🔍 *Search Memories*\n\nSend your search keywords now:\n\n*Examples:*\n• `Milan` - find memories with \"Milan\"\n• `doctor health` - both words\n• `#work` - all work memories\n\n*Tips:*\n• Partial words work (\"tele\" finds \"telegram\")"
+		response := "🔍 *Search Memories*\n\nSend your search keywords now:\n\n*Examples:*\n• `Milan` - find memories with \"Milan\"\n• `doctor health` - both words\n• `#work` - all work memories\n• `\"team meeting\"` - exact phrase\n• `last week` - memories from a period\n\n*Tips:*\n• Partial words work (\"tele\" finds \"telegram\")\n• Force a strategy with `exact:`, `fuzzy:`, `tag:`, `date:` or `smart:`"
 		msg := tgbotapi.NewMessage(message.Chat.ID, response)
 		msg.ParseMode = "Markdown"
 		_, err := bot.Send(msg)
@@ -52,7 +61,6 @@ func (c *SearchCommand) Execute(ctx context.Context, bot BotAPI, message *tgbota
 		UserID:  message.From.ID,
 		Keyword: keyword,
 		Limit:   PageSize,
-		Offset:  0,
 	}
 
 	output, err := c.useCase.Execute(ctx, input)
@@ -64,6 +72,7 @@ func (c *SearchCommand) Execute(ctx context.Context, bot BotAPI, message *tgbota
 	}
 
 	if len(output.Memories) == 0 {
+		delete(c.pages, message.From.ID)
 		response := fmt.Sprintf("🔍 No memories found for: `%s`\n\n💡 *Tips:*\n• Try partial words (e.g., \"tele\" finds \"telegram\")\n• Use fewer words\n• Check spelling", keyword)
 		msg := tgbotapi.NewMessage(message.Chat.ID, response)
 		msg.ParseMode = "Markdown"
@@ -71,19 +80,91 @@ func (c *SearchCommand) Execute(ctx context.Context, bot BotAPI, message *tgbota
 		return err
 	}
 
-	// Format results (no image, just text)
-	response := fmt.Sprintf("🔍 *Search:* `%s`\n*Found:* %d\n\n━━━━━━━━━━━━━━━\n", keyword, len(output.Memories))
+	response, rows := c.render(keyword, output, 1)
+	msg := tgbotapi.NewMessage(message.Chat.ID, response)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	sent, err := bot.Send(msg)
+	if err != nil {
+		return err
+	}
+	c.remember(message.From.ID, sent.MessageID, keyword, output, 1)
+	return nil
+}
+
+// HandleCallback handles "search:next", which shows the next page of the user's last search
+func (c *SearchCommand) HandleCallback(ctx context.Context, bot BotAPI, query *tgbotapi.CallbackQuery, args []string) error {
+	if len(args) != 1 || args[0] != nextPageAction {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	page, ok := pageOf(c.pages, query)
+	if !ok {
+		bot.Send(tgbotapi.NewCallback(query.ID, "This search has expired, please search again"))
+		return nil
+	}
+
+	output, err := c.useCase.Execute(ctx, usecase.SearchMemoryInput{
+		UserID:  query.From.ID,
+		Keyword: page.query,
+		Limit:   PageSize,
+		Cursor:  page.cursor,
+	})
+	if errors.Is(err, entity.ErrInvalidCursor) {
+		delete(c.pages, query.From.ID)
+		bot.Send(tgbotapi.NewCallback(query.ID, "This search has expired, please search again"))
+		return nil
+	}
+	if err != nil {
+		log.Printf("Error searching memories: %v", err)
+		bot.Send(tgbotapi.NewCallback(query.ID, "Search failed"))
+		return err
+	}
+	if len(output.Memories) == 0 {
+		delete(c.pages, query.From.ID)
+		bot.Send(tgbotapi.NewCallback(query.ID, "No more results"))
+		return nil
+	}
+
+	bot.Send(tgbotapi.NewCallback(query.ID, ""))
+	response, rows := c.render(page.query, output, page.number+1)
+	showPage(bot, query, response, rows)
+	c.remember(query.From.ID, page.messageID, page.query, output, page.number+1)
+	return nil
+}
+
+// remember keeps where the search message continues, or forgets it after the last page
+func (c *SearchCommand) remember(userID int64, messageID int, keyword string, output *usecase.SearchMemoryOutput, number int) {
+	if !output.HasMore {
+		delete(c.pages, userID)
+		return
+	}
+	c.pages[userID] = &listingPage{
+		messageID: messageID,
+		query:     keyword,
+		cursor:    output.NextCursor,
+		number:    number,
+	}
+}
+
+// render formats one page of results with "more like this" buttons and the Next button
+func (c *SearchCommand) render(keyword string, output *usecase.SearchMemoryOutput, number int) (string, [][]tgbotapi.InlineKeyboardButton) {
+	response := fmt.Sprintf("🔍 *Search:* `%s`\n*Page:* %d\n\n━━━━━━━━━━━━━━━\n", keyword, number)
 
 	numEmoji := []string{"1️⃣", "2️⃣", "3️⃣", "4️⃣", "5️⃣"}
+	relatedButtons := []tgbotapi.InlineKeyboardButton{}
+	first := (number-1)*PageSize + 1
 	for i, mem := range output.Memories {
-		content := mem.Content
+		content := mem.Masked()
 		if len(content) > 200 {
 			content = content[:200] + "..."
 		}
 
-		numberDisplay := numEmoji[i]
-		if i >= len(numEmoji) {
-			numberDisplay = fmt.Sprintf("%d.", i+1)
+		numberDisplay := fmt.Sprintf("%d.", first+i)
+		if number == 1 && i < len(numEmoji) {
+			numberDisplay = numEmoji[i]
 		}
 
 		response += fmt.Sprintf("%s %s\n🕒 %s – %s\n\n",
@@ -91,24 +172,26 @@ func (c *SearchCommand) Execute(ctx context.Context, bot BotAPI, message *tgbota
 			content,
 			mem.CreatedAt.Format("2006-01-02"),
 			mem.CreatedAt.Format("03:04 PM"))
+
+		relatedButtons = append(relatedButtons, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("🔗 %d", first+i),
+			fmt.Sprintf("related:%d", mem.ID),
+		))
 	}
 
-	response += fmt.Sprintf("━━━━━━━━━━━━━━━\n\n📌 *Total Results:* %d", len(output.Memories))
+	response += fmt.Sprintf("━━━━━━━━━━━━━━━\n\n📌 *Results:* %d–%d", first, first+len(output.Memories)-1)
+	if output.HasMore {
+		response += " (more available)"
+	}
+	response += fmt.Sprintf("\n🧭 *Strategy:* %s", output.Strategy)
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, response)
-	msg.ParseMode = "Markdown"
+	// "More like this" buttons for each result
+	rows := [][]tgbotapi.InlineKeyboardButton{relatedButtons}
+	rows = append(rows, revealRows(output.Memories, first)...)
 
 	// Add pagination if needed
 	if output.HasMore {
-		nextCallback := fmt.Sprintf("search:%s:%d", keyword, 1)
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("Next ⏩", nextCallback),
-			),
-		)
-		msg.ReplyMarkup = keyboard
+		rows = append(rows, nextPageRow(c.CallbackPrefix()))
 	}
-
-	_, err = bot.Send(msg)
-	return err
+	return response, rows
 }
@@ -0,0 +1,186 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SecretCommand handles the /secret command and the Reveal buttons of secret memories
+// Revealed content is sent in a message that is deleted after revealFor; it is never logged.
+// The deletion is recorded first so the reveal scheduler still deletes it after a restart.
+type SecretCommand struct {
+	saveUseCase    *usecase.SaveMemoryUseCase
+	secretsUseCase *usecase.ManageSecretsUseCase
+	revealFor      time.Duration
+}
+
+// NewSecretCommand creates a new secret command showing revealed memories for revealFor
+func NewSecretCommand(saveUseCase *usecase.SaveMemoryUseCase, secretsUseCase *usecase.ManageSecretsUseCase, revealFor time.Duration) *SecretCommand {
+	return &SecretCommand{
+		saveUseCase:    saveUseCase,
+		secretsUseCase: secretsUseCase,
+		revealFor:      revealFor,
+	}
+}
+
+// Name returns the command name
+func (c *SecretCommand) Name() string {
+	return "secret"
+}
+
+// Description returns the command description
+func (c *SecretCommand) Description() string {
+	return "Save a secret memory"
+}
+
+// CallbackPrefix returns the callback prefix for Reveal buttons
+func (c *SecretCommand) CallbackPrefix() string {
+	return "secret"
+}
+
+// Execute saves a secret memory (/secret <text>) or marks one (/secret on|off <memory id>)
+func (c *SecretCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	args := strings.TrimSpace(message.CommandArguments())
+
+	if args == "" {
+		response := "🤫 *Secret Memories*\n\n" +
+			"Secret memories are masked in search, /recent and reviews. Tap 👁 to reveal one; " +
+			fmt.Sprintf("the message disappears after %d seconds ", int(c.revealFor.Seconds())) +
+			"(or when the bot is back if it was offline; Telegram only allows this for 48 hours).\n\n" +
+			"*Usage:*\n" +
+			"• `/secret wifi password: hunter2` - save a secret (your message is deleted)\n" +
+			"• `/secret on 42` - make memory #42 secret\n" +
+			"• `/secret off 42` - show memory #42 again\n\n" +
+			"Memories tagged `#secret` and memories that look like credentials are secret automatically."
+		msg := tgbotapi.NewMessage(message.Chat.ID, response)
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+
+	if fields := strings.Fields(args); len(fields) == 2 && (fields[0] == "on" || fields[0] == "off") {
+		if memoryID, err := strconv.Atoi(strings.TrimPrefix(fields[1], "#")); err == nil {
+			return c.mark(ctx, bot, message.Chat.ID, message.From.ID, memoryID, fields[0] == "on")
+		}
+	}
+
+	// Do not leave the secret in the chat history
+	bot.Send(tgbotapi.NewDeleteMessage(message.Chat.ID, message.MessageID))
+
+	output, err := c.saveUseCase.Execute(ctx, usecase.SaveMemoryInput{
+		UserID:  message.From.ID,
+		ChatID:  message.Chat.ID,
+		Content: args,
+		Secret:  true,
+	})
+	if err != nil {
+		log.Printf("Error saving secret memory: %v", err)
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Failed to save memory. Please try again."))
+		return err
+	}
+
+	response := fmt.Sprintf("🤫 *Secret memory #%d saved*\n\n", output.MemoryID) +
+		"Your message was deleted. The memory is masked in search, /recent and reviews."
+	if len(output.Tags) > 0 {
+		response += fmt.Sprintf("\n\n🏷️ *Tags:* %s", strings.Join(output.Tags, " "))
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, response)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(revealButton("👁 Reveal", int(output.MemoryID))),
+	)
+	_, err = bot.Send(msg)
+	return err
+}
+
+// HandleCallback handles "secret:<memory id>" (reveal)
+// In group chats the content is sent to the owner's private chat instead.
+func (c *SecretCommand) HandleCallback(ctx context.Context, bot BotAPI, query *tgbotapi.CallbackQuery, args []string) error {
+	if len(args) < 1 {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+	memoryID, err := strconv.Atoi(args[0])
+	if err != nil {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	memory, err := c.secretsUseCase.Reveal(ctx, memoryID, query.From.ID)
+	switch err {
+	case nil:
+	case entity.ErrMemoryNotFound, entity.ErrUnauthorized:
+		bot.Send(tgbotapi.NewCallback(query.ID, "Memory not found"))
+		return nil
+	case entity.ErrVaultLocked:
+		bot.Send(tgbotapi.NewCallback(query.ID, "🔒 Use /unlock first"))
+		return nil
+	default:
+		log.Printf("Error revealing memory %d: %v", memoryID, err)
+		bot.Send(tgbotapi.NewCallback(query.ID, "Failed to reveal memory"))
+		return err
+	}
+
+	chatID := query.From.ID
+	notice := "👁 Sent to our private chat"
+	if query.Message != nil && query.Message.Chat.IsPrivate() {
+		chatID, notice = query.Message.Chat.ID, ""
+	}
+
+	seconds := int(c.revealFor.Seconds())
+	text := fmt.Sprintf("🤫 Memory #%d (disappears in %d seconds)\n\n%s", memory.ID, seconds, memory.Content)
+	sent, err := bot.Send(tgbotapi.NewMessage(chatID, text))
+	if err != nil {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Failed to reveal memory"))
+		return fmt.Errorf("failed to send revealed memory %d: %w", memory.ID, err)
+	}
+	bot.Send(tgbotapi.NewCallback(query.ID, notice))
+
+	deletion, err := c.secretsUseCase.ScheduleRevealDeletion(ctx, chatID, sent.MessageID, time.Now().Add(c.revealFor))
+	if err != nil {
+		log.Printf("⚠️ Failed to record the deletion of revealed memory %d: %v", memory.ID, err)
+	}
+	time.AfterFunc(c.revealFor, func() {
+		bot.Send(tgbotapi.NewDeleteMessage(chatID, sent.MessageID))
+		if deletion != nil {
+			if err := c.secretsUseCase.RevealDeleted(context.Background(), deletion.ID); err != nil {
+				log.Printf("Error forgetting deleted reveal of memory %d: %v", memory.ID, err)
+			}
+		}
+	})
+	return nil
+}
+
+// mark makes a memory secret or public again
+func (c *SecretCommand) mark(ctx context.Context, bot BotAPI, chatID, userID int64, memoryID int, secret bool) error {
+	err := c.secretsUseCase.SetSecret(ctx, memoryID, userID, secret)
+	if err == entity.ErrMemoryNotFound || err == entity.ErrUnauthorized {
+		_, sendErr := bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ Memory #%d not found.", memoryID)))
+		return sendErr
+	}
+	if err == entity.ErrVaultLocked {
+		_, sendErr := bot.Send(lockedMemoryMessage(chatID, memoryID))
+		return sendErr
+	}
+	if err != nil {
+		log.Printf("Error updating secret flag of memory %d: %v", memoryID, err)
+		bot.Send(tgbotapi.NewMessage(chatID, "❌ Failed to update the memory. Please try again."))
+		return err
+	}
+
+	response := fmt.Sprintf("👁 Memory #%d is no longer secret.", memoryID)
+	if secret {
+		response = fmt.Sprintf("🤫 Memory #%d is now secret. It is masked in search, /recent and reviews.", memoryID)
+	}
+	_, err = bot.Send(tgbotapi.NewMessage(chatID, response))
+	return err
+}
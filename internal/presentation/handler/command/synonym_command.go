@@ -0,0 +1,177 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SynonymCommand handles the /synonym command
+type SynonymCommand struct {
+	useCase *usecase.ManageSynonymsUseCase
+	admins  *AdminPolicy
+}
+
+// NewSynonymCommand creates a new synonym command
+func NewSynonymCommand(useCase *usecase.ManageSynonymsUseCase, admins *AdminPolicy) *SynonymCommand {
+	return &SynonymCommand{
+		useCase: useCase,
+		admins:  admins,
+	}
+}
+
+// Name returns the command name
+func (c *SynonymCommand) Name() string {
+	return "synonym"
+}
+
+// Description returns the command description
+func (c *SynonymCommand) Description() string {
+	return "Manage search synonyms"
+}
+
+// Execute executes the synonym command
+// /synonym lists sets, /synonym add <terms> and /synonym remove <term> edit them;
+// the same actions prefixed with "global" edit the synonyms of all users (admins only)
+func (c *SynonymCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	args := strings.TrimSpace(message.CommandArguments())
+	action, rest := splitFirstWord(args)
+
+	owner := message.From.ID
+	if strings.EqualFold(action, "global") {
+		if !c.admins.IsAdmin(message.From.ID) {
+			_, err := bot.Send(tgbotapi.NewMessage(message.Chat.ID, "⛔ This command is only available to administrators."))
+			return err
+		}
+		owner = entity.GlobalSynonymOwner
+		action, rest = splitFirstWord(rest)
+	}
+
+	switch strings.ToLower(action) {
+	case "", "list":
+		return c.list(ctx, bot, message)
+
+	case "add":
+		set, err := c.useCase.Add(ctx, owner, parseSynonymTerms(rest))
+		if err != nil {
+			if err == entity.ErrInvalidSynonym {
+				_, sendErr := bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ A synonym set needs at least two different terms."))
+				return sendErr
+			}
+			log.Printf("Error saving synonyms: %v", err)
+			bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Failed to save synonyms. Please try again."))
+			return err
+		}
+
+		scope := "Your"
+		if set.IsGlobal() {
+			scope = "Global"
+		}
+
+		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("✅ %s synonyms saved: %s\n\nSearching for any of them now finds all of them.",
+			scope, formatSynonymTerms(set.Terms)))
+		msg.ParseMode = "Markdown"
+		_, err = bot.Send(msg)
+		return err
+
+	case "remove":
+		if rest == "" {
+			return c.sendUsage(bot, message)
+		}
+
+		if err := c.useCase.Remove(ctx, owner, rest); err != nil {
+			if err == entity.ErrSynonymNotFound {
+				_, sendErr := bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Synonym not found. Use /synonym to see your synonyms."))
+				return sendErr
+			}
+			log.Printf("Error removing synonym: %v", err)
+			bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Failed to remove synonym. Please try again."))
+			return err
+		}
+
+		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("🗑 Synonym *%s* removed.", markdown.Escape(entity.NormalizeSynonymTerm(rest))))
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+
+	default:
+		return c.sendUsage(bot, message)
+	}
+}
+
+// list sends the user's and the global synonym sets
+func (c *SynonymCommand) list(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	sets, err := c.useCase.List(ctx, message.From.ID)
+	if err != nil {
+		log.Printf("Error listing synonyms: %v", err)
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Failed to retrieve synonyms."))
+		return err
+	}
+
+	if len(sets) == 0 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "🔤 You have no synonyms yet.\n\nUse `/synonym add k8s kubernetes` to create one.")
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+
+	response := "🔤 *Search Synonyms:*\n\n"
+	for _, set := range sets {
+		marker := "•"
+		if set.IsGlobal() {
+			marker = "🌐"
+		}
+		response += fmt.Sprintf("%s %s\n", marker, formatSynonymTerms(set.Terms))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, response)
+	msg.ParseMode = "Markdown"
+	_, err = bot.Send(msg)
+	return err
+}
+
+// sendUsage sends the synonym command help
+func (c *SynonymCommand) sendUsage(bot BotAPI, message *tgbotapi.Message) error {
+	msg := tgbotapi.NewMessage(message.Chat.ID, "🔤 *Search Synonyms*\n\n"+
+		"• `/synonym` - list synonyms\n"+
+		"• `/synonym add <term> <term> ...` - e.g. `/synonym add k8s kube kubernetes`\n"+
+		"• `/synonym add pr, pull request` - separate multi-word terms with commas\n"+
+		"• `/synonym remove <term>` - remove a term\n"+
+		"• `/synonym global add|remove ...` - synonyms for all users (admins)")
+	msg.ParseMode = "Markdown"
+	_, err := bot.Send(msg)
+	return err
+}
+
+// splitFirstWord splits s into its first word and the trimmed remainder
+func splitFirstWord(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexFunc(s, func(r rune) bool { return r == ' ' || r == '\t' || r == '\n' }); i >= 0 {
+		return s[:i], strings.TrimSpace(s[i+1:])
+	}
+	return s, ""
+}
+
+// parseSynonymTerms splits terms on commas if present, otherwise on whitespace
+func parseSynonymTerms(s string) []string {
+	if strings.Contains(s, ",") {
+		return strings.Split(s, ",")
+	}
+	return strings.Fields(s)
+}
+
+// formatSynonymTerms renders the terms of a set as "a ↔ b ↔ c"
+func formatSynonymTerms(terms []string) string {
+	escaped := make([]string, len(terms))
+	for i, term := range terms {
+		escaped[i] = markdown.Escape(term)
+	}
+	return strings.Join(escaped, " ↔ ")
+}
@@ -0,0 +1,122 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// TagAliasCommand handles the /tagalias command
+type TagAliasCommand struct {
+	useCase *usecase.ManageTagsUseCase
+}
+
+// NewTagAliasCommand creates a new tag alias command
+func NewTagAliasCommand(useCase *usecase.ManageTagsUseCase) *TagAliasCommand {
+	return &TagAliasCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *TagAliasCommand) Name() string {
+	return "tagalias"
+}
+
+// Description returns the command description
+func (c *TagAliasCommand) Description() string {
+	return "Manage tag aliases"
+}
+
+// Execute executes the tag alias command
+// /tagalias lists aliases, /tagalias <alias> <tag> sets one, /tagalias remove <alias> deletes one
+func (c *TagAliasCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	args := strings.Fields(message.CommandArguments())
+
+	switch {
+	case len(args) == 0:
+		return c.list(ctx, bot, message)
+
+	case len(args) == 2 && strings.EqualFold(args[0], "remove"):
+		if err := c.useCase.RemoveAlias(ctx, message.From.ID, args[1]); err != nil {
+			if err == entity.ErrTagNotFound {
+				_, sendErr := bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Alias not found."))
+				return sendErr
+			}
+			log.Printf("Error removing tag alias: %v", err)
+			bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Failed to remove alias. Please try again."))
+			return err
+		}
+
+		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("🗑 Alias *#%s* removed.", markdown.Escape(entity.NormalizeTag(args[1]))))
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+
+	case len(args) == 2:
+		alias, merged, err := c.useCase.SetAlias(ctx, message.From.ID, args[0], args[1])
+		if err != nil {
+			if err != entity.ErrInvalidTag {
+				log.Printf("Error saving tag alias: %v", err)
+			}
+			msg := tgbotapi.NewMessage(message.Chat.ID, tagErrorMessage(err))
+			msg.ParseMode = "Markdown"
+			bot.Send(msg)
+			return err
+		}
+
+		response := fmt.Sprintf("✅ *#%s* is now an alias of *#%s*.\nNew memories tagged #%s are filed under #%s.",
+			markdown.Escape(alias.Alias), markdown.Escape(alias.Tag), markdown.Escape(alias.Alias), markdown.Escape(alias.Tag))
+		if merged > 0 {
+			response += fmt.Sprintf("\n\n🔀 %d existing memories were moved.", merged)
+		}
+
+		msg := tgbotapi.NewMessage(message.Chat.ID, response)
+		msg.ParseMode = "Markdown"
+		_, err = bot.Send(msg)
+		return err
+
+	default:
+		msg := tgbotapi.NewMessage(message.Chat.ID, "🔁 *Tag Aliases*\n\n"+
+			"• `/tagalias` - list aliases\n"+
+			"• `/tagalias <alias> <tag>` - e.g. `/tagalias #px #work/projectx`\n"+
+			"• `/tagalias remove <alias>` - delete an alias")
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+}
+
+// list sends the user's aliases
+func (c *TagAliasCommand) list(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	aliases, err := c.useCase.ListAliases(ctx, message.From.ID)
+	if err != nil {
+		log.Printf("Error listing tag aliases: %v", err)
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Failed to retrieve aliases."))
+		return err
+	}
+
+	if len(aliases) == 0 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, "🔁 You have no tag aliases.\n\nUse `/tagalias <alias> <tag>` to create one.")
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+
+	response := "🔁 *Your Tag Aliases:*\n\n"
+	for _, alias := range aliases {
+		response += fmt.Sprintf("• #%s → #%s\n", markdown.Escape(alias.Alias), markdown.Escape(alias.Tag))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, response)
+	msg.ParseMode = "Markdown"
+	_, err = bot.Send(msg)
+	return err
+}
@@ -0,0 +1,234 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+	"memory-bot/pkg/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// maxTagButtons limits the drill-down buttons per level (the cloud text lists every tag)
+	maxTagButtons = 20
+
+	// maxCallbackData is Telegram's limit for inline button callback data
+	maxCallbackData = 64
+
+	// tagMemoriesLimit is the number of memories shown for a tag
+	tagMemoriesLimit = 10
+)
+
+// TagsCommand handles the /tags tag cloud and its drill-down buttons
+// Callback data: "tags:open:<tag>" shows a level, "tags:show:<tag>" lists its memories
+type TagsCommand struct {
+	useCase *usecase.ManageTagsUseCase
+}
+
+// NewTagsCommand creates a new tags command
+func NewTagsCommand(useCase *usecase.ManageTagsUseCase) *TagsCommand {
+	return &TagsCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *TagsCommand) Name() string {
+	return "tags"
+}
+
+// Description returns the command description
+func (c *TagsCommand) Description() string {
+	return "Browse your tags"
+}
+
+// CallbackPrefix returns the callback prefix for tag buttons
+func (c *TagsCommand) CallbackPrefix() string {
+	return "tags"
+}
+
+// Execute executes the tags command (/tags or /tags <tag>)
+func (c *TagsCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	text, keyboard, err := c.renderLevel(ctx, message.From.ID, message.CommandArguments())
+	if err != nil {
+		log.Printf("Error listing tags: %v", err)
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Failed to retrieve tags."))
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "Markdown"
+	if keyboard != nil {
+		msg.ReplyMarkup = keyboard
+	}
+	_, err = bot.Send(msg)
+	return err
+}
+
+// HandleCallback navigates the tag hierarchy in place
+func (c *TagsCommand) HandleCallback(ctx context.Context, bot BotAPI, query *tgbotapi.CallbackQuery, args []string) error {
+	if len(args) < 2 {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	var text string
+	var keyboard *tgbotapi.InlineKeyboardMarkup
+	var err error
+
+	switch args[0] {
+	case "open":
+		text, keyboard, err = c.renderLevel(ctx, query.From.ID, args[1])
+	case "show":
+		text, keyboard, err = c.renderMemories(ctx, query.From.ID, args[1])
+	default:
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	if err != nil {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Failed to load tags"))
+		return err
+	}
+
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	edit.ParseMode = "Markdown"
+	edit.ReplyMarkup = keyboard
+	bot.Send(edit)
+
+	bot.Send(tgbotapi.NewCallback(query.ID, ""))
+	return nil
+}
+
+// renderLevel builds the tag cloud for one level of the hierarchy
+func (c *TagsCommand) renderLevel(ctx context.Context, userID int64, tag string) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	output, err := c.useCase.Browse(ctx, userID, tag)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if output.Tag == "" && len(output.Children) == 0 {
+		return "🏷️ You have no tags yet.\n\nAdd #hashtags when saving, e.g. `/save Sprint review #work/projectx`", nil, nil
+	}
+
+	var text string
+	if output.Tag == "" {
+		text = "🏷️ *Your Tags*\n\n"
+	} else {
+		text = fmt.Sprintf("🏷️ *#%s* — %d memories\n\n", markdown.Escape(output.Tag), output.Count)
+	}
+
+	// Tag cloud: the most used tags are shown in bold
+	maxCount := 0
+	for _, child := range output.Children {
+		if child.Count > maxCount {
+			maxCount = child.Count
+		}
+	}
+
+	cloud := make([]string, len(output.Children))
+	for i, child := range output.Children {
+		name := "#" + markdown.Escape(child.Label())
+		if child.HasChildren {
+			name += "/…"
+		}
+		if child.Count*3 >= maxCount*2 {
+			name = "*" + name + "*"
+		}
+		cloud[i] = fmt.Sprintf("%s (%d)", name, child.Count)
+	}
+	text += strings.Join(cloud, "  ·  ")
+
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	row := []tgbotapi.InlineKeyboardButton{}
+	for i, child := range output.Children {
+		if i >= maxTagButtons {
+			break
+		}
+
+		action := "show"
+		label := fmt.Sprintf("🏷 %s (%d)", child.Label(), child.Count)
+		if child.HasChildren {
+			action = "open"
+			label += " ›"
+		}
+
+		data := fmt.Sprintf("tags:%s:%s", action, child.Name)
+		if len(data) > maxCallbackData {
+			continue
+		}
+
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, data))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = []tgbotapi.InlineKeyboardButton{}
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	if output.Tag != "" {
+		navigation := []tgbotapi.InlineKeyboardButton{}
+		if data := "tags:show:" + output.Tag; len(data) <= maxCallbackData {
+			navigation = append(navigation, tgbotapi.NewInlineKeyboardButtonData("📄 Show memories", data))
+		}
+		navigation = append(navigation, tgbotapi.NewInlineKeyboardButtonData("⬅️ Back", "tags:open:"+entity.TagParent(output.Tag)))
+		rows = append(rows, navigation)
+	}
+
+	if len(rows) == 0 {
+		return text, nil, nil
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return text, &keyboard, nil
+}
+
+// renderMemories lists the newest memories carrying a tag
+func (c *TagsCommand) renderMemories(ctx context.Context, userID int64, tag string) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	memories, err := c.useCase.Memories(ctx, userID, tag, tagMemoriesLimit)
+	if err != nil {
+		return "", nil, err
+	}
+
+	text := fmt.Sprintf("🏷️ *#%s*\n\n", markdown.Escape(tag))
+	if len(memories) == 0 {
+		text += "No memories with this tag."
+	}
+
+	for i, mem := range memories {
+		content := mem.Masked()
+		if len(content) > 100 {
+			content = content[:100] + "..."
+		}
+
+		text += fmt.Sprintf("%d. %s\n🆔 #%d – %s\n\n", i+1, markdown.Escape(content), mem.ID, mem.CreatedAt.Format("2006-01-02"))
+	}
+
+	rows := revealRows(memories, 1)
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Back", "tags:open:"+entity.TagParent(tag)),
+	))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return text, &keyboard, nil
+}
+
+// tagErrorMessage returns the user-facing message for a tag management error
+func tagErrorMessage(err error) string {
+	switch err {
+	case entity.ErrTagNotFound:
+		return "❌ Tag not found. Use /tags to see your tags."
+	case entity.ErrTagExists:
+		return "❌ That tag is already in use. Use /mergetag to combine both tags."
+	case entity.ErrInvalidTag:
+		return "❌ Invalid tag. Tags may contain letters, digits, `_`, `-` and `/` for sub-tags, and a tag can't be moved into its own sub-tag."
+	default:
+		return "❌ Failed to update tags. Please try again."
+	}
+}
@@ -0,0 +1,83 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"memory-bot/internal/application/usecase"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// TrashCommand handles the /trash command
+type TrashCommand struct {
+	useCase *usecase.ManageTrashUseCase
+}
+
+// NewTrashCommand creates a new trash command
+func NewTrashCommand(useCase *usecase.ManageTrashUseCase) *TrashCommand {
+	return &TrashCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *TrashCommand) Name() string {
+	return "trash"
+}
+
+// Description returns the command description
+func (c *TrashCommand) Description() string {
+	return "List deleted memories"
+}
+
+// Execute lists the deleted memories with one restore button each
+func (c *TrashCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	memories, err := c.useCase.List(ctx, message.From.ID)
+	if err != nil {
+		log.Printf("Error listing trash: %v", err)
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Failed to retrieve the trash."))
+		return err
+	}
+
+	if len(memories) == 0 {
+		_, err := bot.Send(tgbotapi.NewMessage(message.Chat.ID, "🗑 Your trash is empty."))
+		return err
+	}
+
+	response := "🗑 *Trash*\n\n━━━━━━━━━━━━━━━\n"
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+
+	for i, mem := range memories {
+		content := mem.Masked()
+		if len(content) > 200 {
+			content = content[:200] + "..."
+		}
+
+		response += fmt.Sprintf("%d. %s\n🆔 #%d – deleted %s, purged after %s\n\n",
+			i+1,
+			content,
+			mem.ID,
+			mem.DeletedAt.Format("2006-01-02"),
+			c.useCase.PurgeExpiresAt(mem).Format("2006-01-02"))
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("↩️ Restore %d. #%d", i+1, mem.ID),
+				fmt.Sprintf("restore:%d", mem.ID),
+			),
+		))
+	}
+
+	if len(memories) == usecase.TrashListLimit {
+		response += fmt.Sprintf("_Showing the %d most recently deleted memories._\n", usecase.TrashListLimit)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, response)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	_, err = bot.Send(msg)
+	return err
+}
@@ -0,0 +1,115 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/internal/domain/entity"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// UnlockCommand handles the /unlock command
+// The message with the passphrase is deleted right away and the passphrase is never logged
+type UnlockCommand struct {
+	useCase *usecase.ManageVaultUseCase
+}
+
+// NewUnlockCommand creates a new unlock command
+func NewUnlockCommand(useCase *usecase.ManageVaultUseCase) *UnlockCommand {
+	return &UnlockCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *UnlockCommand) Name() string {
+	return "unlock"
+}
+
+// Description returns the command description
+func (c *UnlockCommand) Description() string {
+	return "Unlock your personal vault"
+}
+
+// Execute unlocks (or creates) the vault of the user
+func (c *UnlockCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	passphrase := strings.TrimSpace(message.CommandArguments())
+	chatID := message.Chat.ID
+	userID := message.From.ID
+
+	if passphrase == "" {
+		return c.status(ctx, bot, chatID, userID)
+	}
+
+	// Do not leave the passphrase in the chat history
+	bot.Send(tgbotapi.NewDeleteMessage(chatID, message.MessageID))
+
+	if !message.Chat.IsPrivate() {
+		_, err := bot.Send(tgbotapi.NewMessage(chatID, "🔒 Unlock your vault in a private chat with me. Your message was deleted; consider choosing a new passphrase."))
+		return err
+	}
+
+	output, err := c.useCase.Unlock(ctx, userID, passphrase)
+	switch err {
+	case nil:
+	case entity.ErrWrongPassphrase:
+		_, sendErr := bot.Send(tgbotapi.NewMessage(chatID, "❌ Wrong passphrase. Your vault stays locked."))
+		return sendErr
+	case entity.ErrWeakPassphrase:
+		_, sendErr := bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ Choose a passphrase of at least %d characters.", entity.MinVaultPassphraseLength)))
+		return sendErr
+	default:
+		log.Printf("Error unlocking vault for user %d: %v", userID, err)
+		bot.Send(tgbotapi.NewMessage(chatID, "❌ Failed to unlock your vault. Please try again."))
+		return err
+	}
+
+	var response string
+	if output.Created {
+		response = fmt.Sprintf("🔐 *Vault created and unlocked until %s*\n\n", output.Until.Format("15:04")) +
+			"Memories you save while the vault is unlocked are encrypted with your passphrase. " +
+			"Keep it safe: it cannot be recovered, and neither can your vaulted memories without it."
+	} else {
+		response = fmt.Sprintf("🔓 *Vault unlocked until %s*\n\n", output.Until.Format("15:04")) +
+			"Your vaulted memories can be read and new memories are saved to the vault."
+	}
+	response += "\n\nUse /lock when you're done."
+
+	msg := tgbotapi.NewMessage(chatID, response)
+	msg.ParseMode = "Markdown"
+	_, err = bot.Send(msg)
+	return err
+}
+
+// status shows the usage and whether the vault is unlocked
+func (c *UnlockCommand) status(ctx context.Context, bot BotAPI, chatID, userID int64) error {
+	status, err := c.useCase.Status(ctx, userID)
+	if err != nil {
+		log.Printf("Error reading vault status for user %d: %v", userID, err)
+		bot.Send(tgbotapi.NewMessage(chatID, "❌ Failed to read your vault. Please try again."))
+		return err
+	}
+
+	response := "🔐 *Personal Vault*\n\n"
+	switch {
+	case status.Unlocked:
+		response += fmt.Sprintf("🔓 Unlocked until %s. Use /lock to lock it now.\n\n", status.Until.Format("15:04"))
+	case status.Exists:
+		response += "🔒 Locked.\n\n"
+	default:
+		response += fmt.Sprintf("You have no vault yet. The first `/unlock` creates it; "+
+			"choose a passphrase of at least %d characters.\n\n", entity.MinVaultPassphraseLength)
+	}
+	response += "*Usage:* `/unlock <passphrase>`\n\n" +
+		"Your message is deleted right away. Memories saved while the vault is unlocked " +
+		"can only be read after unlocking it again."
+
+	msg := tgbotapi.NewMessage(chatID, response)
+	msg.ParseMode = "Markdown"
+	_, err = bot.Send(msg)
+	return err
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/pkg/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// WatchCommand handles the /watch command
+type WatchCommand struct {
+	useCase *usecase.WatchMemoryUseCase
+}
+
+// NewWatchCommand creates a new watch command
+func NewWatchCommand(useCase *usecase.WatchMemoryUseCase) *WatchCommand {
+	return &WatchCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *WatchCommand) Name() string {
+	return "watch"
+}
+
+// Description returns the command description
+func (c *WatchCommand) Description() string {
+	return "Get notified about new matches"
+}
+
+// Execute executes the watch command
+func (c *WatchCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	args := strings.TrimSpace(message.CommandArguments())
+
+	if args == "" {
+		response := "👀 *Watch a Search*\n\n" +
+			"Get notified whenever a new memory matches a query.\n\n" +
+			"*Usage:*\n" +
+			"• `/watch #bug` - watch a tag\n" +
+			"• `/watch release` - watch a keyword\n" +
+			"• `/watch Releases | release deploy` - watch with a name\n\n" +
+			"Use /watches to see and delete your watches."
+		msg := tgbotapi.NewMessage(message.Chat.ID, response)
+		msg.ParseMode = "Markdown"
+		_, err := bot.Send(msg)
+		return err
+	}
+
+	// Optional "name | query" syntax
+	name, query := "", args
+	if parts := strings.SplitN(args, "|", 2); len(parts) == 2 {
+		name, query = parts[0], parts[1]
+	}
+
+	input := usecase.AddWatchInput{
+		UserID: message.From.ID,
+		ChatID: message.Chat.ID,
+		Name:   name,
+		Query:  query,
+	}
+
+	watch, err := c.useCase.Add(ctx, input)
+	if err != nil {
+		log.Printf("Error saving watch: %v", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, "❌ Failed to save watch. Please try again.")
+		bot.Send(msg)
+		return err
+	}
+
+	response := fmt.Sprintf("✅ *Watch saved:* %s\n\nI'll notify you when a new memory matches %s.",
+		markdown.Escape(watch.Name), markdown.Escape(watch.Query))
+	msg := tgbotapi.NewMessage(message.Chat.ID, response)
+	msg.ParseMode = "Markdown"
+	_, err = bot.Send(msg)
+	return err
+}
@@ -0,0 +1,120 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"memory-bot/internal/application/usecase"
+	"memory-bot/pkg/markdown"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// WatchesCommand handles the /watches command and its delete buttons
+type WatchesCommand struct {
+	useCase *usecase.WatchMemoryUseCase
+}
+
+// NewWatchesCommand creates a new watches command
+func NewWatchesCommand(useCase *usecase.WatchMemoryUseCase) *WatchesCommand {
+	return &WatchesCommand{
+		useCase: useCase,
+	}
+}
+
+// Name returns the command name
+func (c *WatchesCommand) Name() string {
+	return "watches"
+}
+
+// Description returns the command description
+func (c *WatchesCommand) Description() string {
+	return "List saved searches"
+}
+
+// CallbackPrefix returns the callback prefix for delete buttons
+func (c *WatchesCommand) CallbackPrefix() string {
+	return "unwatch"
+}
+
+// Execute executes the watches command
+func (c *WatchesCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	text, keyboard, err := c.render(ctx, message.From.ID)
+	if err != nil {
+		log.Printf("Error listing watches: %v", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, "❌ Failed to retrieve watches.")
+		bot.Send(msg)
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "Markdown"
+	if keyboard != nil {
+		msg.ReplyMarkup = keyboard
+	}
+	_, err = bot.Send(msg)
+	return err
+}
+
+// HandleCallback deletes a watch and refreshes the list ("unwatch:<id>")
+func (c *WatchesCommand) HandleCallback(ctx context.Context, bot BotAPI, query *tgbotapi.CallbackQuery, args []string) error {
+	if len(args) < 1 {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Invalid request"))
+		return nil
+	}
+
+	if err := c.useCase.Remove(ctx, id, query.From.ID); err != nil {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Watch not found"))
+		return err
+	}
+
+	text, keyboard, err := c.render(ctx, query.From.ID)
+	if err != nil {
+		bot.Send(tgbotapi.NewCallback(query.ID, "Watch deleted"))
+		return err
+	}
+
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	edit.ParseMode = "Markdown"
+	edit.ReplyMarkup = keyboard
+	bot.Send(edit)
+
+	bot.Send(tgbotapi.NewCallback(query.ID, "Watch deleted"))
+	return nil
+}
+
+// render builds the watch list text with one delete button per watch
+func (c *WatchesCommand) render(ctx context.Context, userID int64) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	watches, err := c.useCase.List(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(watches) == 0 {
+		return "👀 You have no saved searches.\n\nUse `/watch <query>` to create one.", nil, nil
+	}
+
+	text := "👀 *Your Saved Searches:*\n\n"
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+
+	for i, watch := range watches {
+		text += fmt.Sprintf("%d. *%s*\n   🔍 %s\n\n", i+1, markdown.Escape(watch.Name), markdown.Escape(watch.Query))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("🗑 Delete %d. %s", i+1, watch.Name),
+				fmt.Sprintf("unwatch:%d", watch.ID),
+			),
+		))
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return text, &keyboard, nil
+}
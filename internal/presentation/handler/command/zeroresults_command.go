@@ -0,0 +1,84 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"memory-bot/internal/application/usecase"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// zeroResultReportSize is the number of failing queries listed in the report
+const zeroResultReportSize = 15
+
+// ZeroResultsCommand handles the admin-only /zeroresults report
+type ZeroResultsCommand struct {
+	useCase *usecase.QueryHistoryUseCase
+	admins  *AdminPolicy
+}
+
+// NewZeroResultsCommand creates a new zero results command
+func NewZeroResultsCommand(useCase *usecase.QueryHistoryUseCase, admins *AdminPolicy) *ZeroResultsCommand {
+	return &ZeroResultsCommand{
+		useCase: useCase,
+		admins:  admins,
+	}
+}
+
+// Name returns the command name
+func (c *ZeroResultsCommand) Name() string {
+	return "zeroresults"
+}
+
+// Description returns the command description
+func (c *ZeroResultsCommand) Description() string {
+	return "Searches without results (admin)"
+}
+
+// Execute executes the zero results command
+func (c *ZeroResultsCommand) Execute(ctx context.Context, bot BotAPI, message *tgbotapi.Message) error {
+	if !c.admins.IsAdmin(message.From.ID) {
+		_, err := bot.Send(tgbotapi.NewMessage(message.Chat.ID, "⛔ This command is only available to administrators."))
+		return err
+	}
+
+	output, err := c.useCase.ZeroResultReport(ctx, zeroResultReportSize)
+	if err != nil {
+		log.Printf("Error building zero-result report: %v", err)
+		bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ Failed to build the report."))
+		return err
+	}
+
+	response := "📉 *Zero-Result Searches*\n_(users with search history enabled)_\n\n"
+	if len(output.Queries) == 0 {
+		response += "No failing searches recorded.\n"
+	}
+	for i, stat := range output.Queries {
+		response += fmt.Sprintf("%d. `%s` — %d× by %d users, last %s\n",
+			i+1, strings.ReplaceAll(stat.Query, "`", "'"), stat.Count, stat.Users, stat.LastSeen.Format("2006-01-02"))
+	}
+
+	if len(output.Steps) > 0 {
+		total := 0
+		for _, step := range output.Steps {
+			total += step.Count
+		}
+
+		response += "\n🧭 *Matched By Strategy Step:*\n"
+		for _, step := range output.Steps {
+			name := step.Step
+			if name == "" {
+				name = "unknown"
+			}
+			response += fmt.Sprintf("• %s: %d (%.0f%%)\n", name, step.Count, float64(step.Count)*100/float64(total))
+		}
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, response)
+	msg.ParseMode = "Markdown"
+	_, err = bot.Send(msg)
+	return err
+}
@@ -9,10 +9,21 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	TelegramBotToken string
-	DBPath           string
-	ReviewIntervals  []int  // in days
-	EncryptionKey    string // Optional: for encrypting sensitive memory data
+	TelegramBotToken  string
+	DBPath            string
+	ReviewIntervals   []int    // in days
+	EncryptionKey     string   // Optional: for encrypting sensitive memory data
+	EncryptionOldKeys []string // Previous keys, still used for decrypting after a key rotation
+	EncryptedFields   string   // fields encrypted next to the content: all (default), none or a comma-separated list
+	FTSTokenizer      string   // unicode61 (default), porter, trigram or unicode61_nodiacritics
+	AdminUserIDs      []int64
+	TrashRetention    int // days a deleted memory stays in the trash before it is purged
+	BackupDir         string
+	BackupInterval    int // hours between database snapshots (0 disables scheduled backups)
+	BackupKeep        int // number of snapshots kept
+	BackupRetention   int // days a snapshot is kept (0 keeps the newest BackupKeep regardless of age)
+	VaultTimeout      int // minutes a personal vault stays unlocked after /unlock
+	SecretReveal      int // seconds a revealed secret memory stays in the chat
 }
 
 // LoadConfig loads configuration from environment variables
@@ -22,7 +33,7 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("TELEGRAM_BOT_TOKEN is required")
 	}
 
-	dbPath := getEnv("DB_PATH", "./memories.db")
+	dbPath := DBPath()
 
 	// Load review intervals
 	intervals := []int{1, 3, 7, 14, 30} // default intervals
@@ -33,14 +44,106 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	// Load admin user IDs for operator commands
+	var adminIDs []int64
+	if adminStr := os.Getenv("ADMIN_USER_IDS"); adminStr != "" {
+		parsedIDs, err := parseUserIDs(adminStr)
+		if err != nil {
+			return nil, err
+		}
+		adminIDs = parsedIDs
+	}
+
+	// Load trash retention
+	retention := 30
+	if retentionStr := os.Getenv("TRASH_RETENTION_DAYS"); retentionStr != "" {
+		days, err := strconv.Atoi(strings.TrimSpace(retentionStr))
+		if err != nil || days < 1 {
+			return nil, fmt.Errorf("invalid TRASH_RETENTION_DAYS: %s", retentionStr)
+		}
+		retention = days
+	}
+
+	// Load backup settings
+	backupInterval, err := parseNonNegative("BACKUP_INTERVAL_HOURS", 24)
+	if err != nil {
+		return nil, err
+	}
+	backupKeep, err := parseNonNegative("BACKUP_KEEP", 7)
+	if err != nil {
+		return nil, err
+	}
+	if backupKeep < 1 {
+		return nil, fmt.Errorf("invalid BACKUP_KEEP: must be at least 1")
+	}
+	backupRetention, err := parseNonNegative("BACKUP_RETENTION_DAYS", 30)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load vault session timeout
+	vaultTimeout, err := parseNonNegative("VAULT_SESSION_MINUTES", 15)
+	if err != nil {
+		return nil, err
+	}
+	if vaultTimeout < 1 {
+		return nil, fmt.Errorf("invalid VAULT_SESSION_MINUTES: must be at least 1")
+	}
+
+	// Load how long revealed secrets stay visible
+	secretReveal, err := parseNonNegative("SECRET_REVEAL_SECONDS", 30)
+	if err != nil {
+		return nil, err
+	}
+	if secretReveal < 1 {
+		return nil, fmt.Errorf("invalid SECRET_REVEAL_SECONDS: must be at least 1")
+	}
+
 	return &Config{
-		TelegramBotToken: token,
-		DBPath:           dbPath,
-		ReviewIntervals:  intervals,
-		EncryptionKey:    getEnv("ENCRYPTION_KEY", ""),
+		TelegramBotToken:  token,
+		DBPath:            dbPath,
+		ReviewIntervals:   intervals,
+		EncryptionKey:     EncryptionKey(),
+		EncryptionOldKeys: EncryptionOldKeys(),
+		EncryptedFields:   EncryptedFields(),
+		FTSTokenizer:      getEnv("FTS_TOKENIZER", "unicode61"),
+		AdminUserIDs:      adminIDs,
+		TrashRetention:    retention,
+		BackupDir:         getEnv("BACKUP_DIR", "./backups"),
+		BackupInterval:    backupInterval,
+		BackupKeep:        backupKeep,
+		BackupRetention:   backupRetention,
+		VaultTimeout:      vaultTimeout,
+		SecretReveal:      secretReveal,
 	}, nil
 }
 
+// DBPath returns the database path (DB_PATH); it does not require the rest of the configuration
+func DBPath() string {
+	return getEnv("DB_PATH", "./memories.db")
+}
+
+// EncryptionKey returns the encryption key (ENCRYPTION_KEY); empty when encryption is disabled
+func EncryptionKey() string {
+	return getEnv("ENCRYPTION_KEY", "")
+}
+
+// EncryptionOldKeys returns the previous encryption keys (ENCRYPTION_OLD_KEYS, comma-separated)
+func EncryptionOldKeys() []string {
+	var keys []string
+	for _, key := range strings.Split(getEnv("ENCRYPTION_OLD_KEYS", ""), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// EncryptedFields returns the memory fields encrypted next to the content (ENCRYPTED_FIELDS)
+func EncryptedFields() string {
+	return getEnv("ENCRYPTED_FIELDS", "all")
+}
+
 // getEnv gets an environment variable with a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -49,6 +152,19 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// parseNonNegative reads a whole number of at least zero from the environment
+func parseNonNegative(key string, defaultValue int) (int, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid %s: %s", key, raw)
+	}
+	return value, nil
+}
+
 // parseIntervals parses comma-separated interval string
 func parseIntervals(s string) ([]int, error) {
 	parts := strings.Split(s, ",")
@@ -65,3 +181,23 @@ func parseIntervals(s string) ([]int, error) {
 
 	return intervals, nil
 }
+
+// parseUserIDs parses comma-separated Telegram user IDs
+func parseUserIDs(s string) ([]int64, error) {
+	parts := strings.Split(s, ",")
+	ids := make([]int64, 0, len(parts))
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID: %s", part)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
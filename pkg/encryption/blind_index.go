@@ -0,0 +1,144 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+const (
+	// blindTokenLength is the number of hex characters kept from each HMAC (64 bits)
+	blindTokenLength = 16
+
+	// maxPrefixLength caps the number of prefix tokens generated per word
+	maxPrefixLength = 20
+)
+
+// BlindIndex turns words into keyed HMAC tokens so the search index never holds plaintext
+// Each word is indexed as a word token plus one prefix token per leading substring,
+// so wildcard queries ("tele*") keep working without revealing the text
+type BlindIndex struct {
+	keys [][]byte // One per encryptor key, primary first
+}
+
+// NewBlindIndex creates a blind index whose keys are derived from the encryptor's keys
+// Separate keys are used so tokens can't be turned into ciphertext and vice versa.
+// Text is indexed with the primary key only; see Tokens for queries during key rotation.
+func NewBlindIndex(encryptor *Encryptor) *BlindIndex {
+	b := &BlindIndex{}
+	for _, k := range encryptor.keys {
+		b.keys = append(b.keys, derive(k.secret, "memory-bot blind index v1"))
+	}
+	return b
+}
+
+// NewBlindIndexIfEnabled creates a blind index only if encryptor is not nil
+func NewBlindIndexIfEnabled(encryptor *Encryptor) *BlindIndex {
+	if encryptor == nil {
+		return nil
+	}
+	return NewBlindIndex(encryptor)
+}
+
+// With returns an index that also queries the tokens of other's keys
+// Text is still indexed with b's primary key; used to search a user's vault memories too.
+func (b *BlindIndex) With(other *BlindIndex) *BlindIndex {
+	keys := append([][]byte{}, b.keys...)
+	return &BlindIndex{keys: append(keys, other.keys...)}
+}
+
+// Words splits text into case-folded words the same way the FTS5 tokenizer does
+// (letters and digits, with dots kept inside words)
+func (b *BlindIndex) Words(text string) []string {
+	var words []string
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	}) {
+		word = strings.Trim(word, ".")
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+// Token returns the blind token for an exact word
+func (b *BlindIndex) Token(word string) string {
+	return b.token(b.keys[0], "w:"+strings.ToLower(word))
+}
+
+// PrefixToken returns the blind token matching every word starting with prefix
+func (b *BlindIndex) PrefixToken(prefix string) string {
+	return b.token(b.keys[0], "p:"+truncatePrefix(prefix))
+}
+
+// Tokens returns the token of an exact word under every key, primary first
+// Queries match any of them, so rows indexed before a key rotation stay searchable
+// until they are re-encrypted.
+func (b *BlindIndex) Tokens(word string) []string {
+	return b.tokens("w:" + strings.ToLower(word))
+}
+
+// PrefixTokens returns the prefix token under every key, primary first
+func (b *BlindIndex) PrefixTokens(prefix string) []string {
+	return b.tokens("p:" + truncatePrefix(prefix))
+}
+
+// truncatePrefix case-folds a prefix and caps it at the longest indexed prefix
+func truncatePrefix(prefix string) string {
+	prefix = strings.ToLower(prefix)
+	if runes := []rune(prefix); len(runes) > maxPrefixLength {
+		prefix = string(runes[:maxPrefixLength])
+	}
+	return prefix
+}
+
+// IndexText returns the space-separated tokens to store in the search index for text
+func (b *BlindIndex) IndexText(text string) string {
+	var tokens []string
+	seen := make(map[string]bool)
+
+	add := func(token string) {
+		if !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+
+	for _, word := range b.Words(text) {
+		add(b.Token(word))
+
+		runes := []rune(word)
+		for i := 1; i <= len(runes) && i <= maxPrefixLength; i++ {
+			add(b.PrefixToken(string(runes[:i])))
+		}
+	}
+
+	return strings.Join(tokens, " ")
+}
+
+// tokens computes the token of value under every key
+func (b *BlindIndex) tokens(value string) []string {
+	tokens := make([]string, len(b.keys))
+	for i, key := range b.keys {
+		tokens[i] = b.token(key, value)
+	}
+	return tokens
+}
+
+// token computes a truncated hex HMAC; the leading letter keeps tokens FTS5 barewords
+func (b *BlindIndex) token(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return "t" + hex.EncodeToString(mac.Sum(nil))[:blindTokenLength]
+}
+
+// Fingerprint returns a deterministic token for a whole value, so equal values
+// (e.g. normalized search queries) can be grouped without storing them in plaintext
+func (b *BlindIndex) Fingerprint(value string) string {
+	return b.token(b.keys[0], "f:"+value)
+}
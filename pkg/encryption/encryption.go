@@ -3,124 +3,446 @@ package encryption
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 )
 
+// EnvelopeVersion is the format of the values written by Encrypt (the "v3:" envelope)
+const EnvelopeVersion = 3
+
+const (
+	// nonceSize is the standard GCM nonce size
+	nonceSize = 12
+
+	// minCiphertextSize is the size of an encrypted empty value: the GCM nonce and tag
+	minCiphertextSize = nonceSize + 16
+
+	// keyIDSize is the number of bytes of a key identifier (hex encoded in envelopes)
+	keyIDSize = 4
+
+	// envelopePrefix marks every value encrypted by this version: "v3:<keyid>:<base64 nonce+ciphertext>"
+	// The GCM associated data binds the value to where it is stored (see Encrypt).
+	envelopePrefix = "v3:"
+
+	// unboundPrefix marks values of the previous version, encrypted without associated data
+	// Older ciphertext is bare base64 and is tried with every key of the keyring.
+	unboundPrefix = "v2:"
+)
+
+// Errors returned when a stored value can't be decrypted
+var (
+	// ErrUnknownKey is returned when the key named by an envelope is not configured
+	ErrUnknownKey = errors.New("value is encrypted with a key that is not configured")
+
+	// ErrMalformed is returned for an envelope that can't be parsed
+	ErrMalformed = errors.New("malformed ciphertext")
+
+	// ErrAuthentication is returned when GCM rejects a value: the wrong key, the
+	// associated data of another row, or damaged data
+	ErrAuthentication = errors.New("ciphertext failed authentication")
+)
+
+// Key is one encryption key of a keyring; create it with DeriveKey or LegacyKey
+type Key struct {
+	id       string // Short public identifier stored in the envelope
+	secret   []byte
+	nonceKey []byte // Derives the nonces of deterministic encryption
+}
+
+// newKey derives the identifier and subkeys of 32 bytes of key material
+func newKey(secret []byte) *Key {
+	return &Key{
+		id:       hex.EncodeToString(derive(secret, "memory-bot key id v1")[:keyIDSize]),
+		secret:   secret,
+		nonceKey: derive(secret, "memory-bot synthetic nonce v1"),
+	}
+}
+
+// LegacyKey derives a key the way older versions did: a single SHA-256 of the passphrase
+// It is only kept to read and migrate data written before passphrases were stretched.
+func LegacyKey(passphrase string) *Key {
+	hash := sha256.Sum256([]byte(passphrase))
+	return newKey(hash[:])
+}
+
+// ID returns the short public identifier of the key
+func (k *Key) ID() string {
+	return k.id
+}
+
+// derive computes an HMAC of label, so derived keys and identifiers reveal nothing of the key
+func derive(secret []byte, label string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
 // Encryptor handles encryption and decryption operations
+// It is a keyring: it always encrypts with the primary key and decrypts with any of its keys
 type Encryptor struct {
-	key []byte
+	primary     *Key
+	keys        []*Key // Primary first, then the previous keys
+	unenveloped Unenveloped
+}
+
+// Unenveloped says how DecryptIfEnabled reads stored values without an envelope prefix
+type Unenveloped int
+
+const (
+	// UnenvelopedPlaintext reads them as legacy ciphertext if a key authenticates them and
+	// as plaintext stored before encryption was turned on otherwise (the default)
+	UnenvelopedPlaintext Unenveloped = iota
+
+	// UnenvelopedLegacy reads base64 values as legacy ciphertext and returns their
+	// decryption errors; the database holds ciphertext of the SHA-256 keys
+	UnenvelopedLegacy
+
+	// UnenvelopedRejected returns ErrMalformed for them: every stored value has been
+	// re-encrypted, so a value without an envelope was put there by something else
+	UnenvelopedRejected
+)
+
+// NewEncryptor creates a new encryptor with a legacy key hashed from secret
+// The bot derives its keys with DeriveKey; this is meant for tests and tools.
+func NewEncryptor(secret string) *Encryptor {
+	return NewKeyring(LegacyKey(secret))
 }
 
-// NewEncryptor creates a new encryptor with the given key
-// The key will be hashed to ensure it's 32 bytes (256 bits)
-func NewEncryptor(key string) *Encryptor {
-	hash := sha256.Sum256([]byte(key))
-	return &Encryptor{
-		key: hash[:],
+// NewKeyring creates an encryptor that encrypts with primary and still decrypts
+// values encrypted with any of the previous keys (key rotation)
+func NewKeyring(primary *Key, previous ...*Key) *Encryptor {
+	e := &Encryptor{primary: primary}
+	e.keys = append(e.keys, e.primary)
+
+	for _, k := range previous {
+		if e.key(k.id) == nil {
+			e.keys = append(e.keys, k)
+		}
 	}
+	return e
 }
 
-// Encrypt encrypts plaintext using AES-256-GCM
-// Returns base64 encoded ciphertext
-func (e *Encryptor) Encrypt(plaintext string) (string, error) {
-	if plaintext == "" {
-		return "", nil
+// ReadUnenveloped returns a copy of the keyring that reads values without an envelope prefix as mode says
+func (e *Encryptor) ReadUnenveloped(mode Unenveloped) *Encryptor {
+	keyring := *e
+	keyring.unenveloped = mode
+	return &keyring
+}
+
+// KeyID returns the identifier of the primary key
+func (e *Encryptor) KeyID() string {
+	return e.primary.id
+}
+
+// KeyIDs returns the identifiers of every key, primary first
+func (e *Encryptor) KeyIDs() []string {
+	ids := make([]string, len(e.keys))
+	for i, k := range e.keys {
+		ids[i] = k.id
 	}
+	return ids
+}
 
-	block, err := aes.NewCipher(e.key)
-	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
+// Current reports whether a stored value is encrypted with the primary key in the current format
+// Legacy ciphertext, and envelopes without associated data, are never current.
+func (e *Encryptor) Current(ciphertext string) bool {
+	return strings.HasPrefix(ciphertext, envelopePrefix+e.primary.id+":")
+}
+
+// key returns the key with the given identifier, or nil
+func (e *Encryptor) key(id string) *Key {
+	for _, k := range e.keys {
+		if k.id == id {
+			return k
+		}
 	}
+	return nil
+}
 
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+// Encrypt encrypts plaintext using AES-256-GCM with the primary key
+// aad is authenticated but not stored: the value only decrypts with the same aad, so
+// binding it to the owner and row means a value copied to another row fails to decrypt.
+// Returns the ciphertext envelope ("v3:<keyid>:<base64>")
+func (e *Encryptor) Encrypt(plaintext, aad string) (string, error) {
+	if plaintext == "" {
+		return "", nil
 	}
 
 	// Create a nonce (number used once)
-	nonce := make([]byte, gcm.NonceSize())
+	nonce := make([]byte, nonceSize)
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return "", fmt.Errorf("failed to generate nonce: %w", err)
 	}
 
+	return e.primary.seal(envelopePrefix, nonce, plaintext, []byte(aad))
+}
+
+// EncryptDeterministic encrypts so that the same value in the same scope always
+// gives the same ciphertext, which can then be compared and indexed like a keyed token
+// The nonce is an HMAC of scope and value (synthetic nonce) and scope is the
+// associated data, so Decrypt(ciphertext, scope) still works.
+// It reveals which rows share a value: use it only where lookups by value are needed.
+func (e *Encryptor) EncryptDeterministic(scope, plaintext string) (string, error) {
+	return e.primary.sealDeterministic(scope, plaintext)
+}
+
+// EncryptDeterministicAll returns every stored form of the deterministic ciphertext of
+// plaintext: under each key, primary first, and in the previous unbound format too,
+// so lookups also match rows not yet re-encrypted
+func (e *Encryptor) EncryptDeterministicAll(scope, plaintext string) ([]string, error) {
+	sealed := make([]string, 0, 2*len(e.keys))
+	for _, k := range e.keys {
+		ciphertext, err := k.sealDeterministic(scope, plaintext)
+		if err != nil {
+			return nil, err
+		}
+		sealed = append(sealed, ciphertext)
+	}
+	for _, k := range e.keys {
+		ciphertext, err := k.sealUnbound(scope, plaintext)
+		if err != nil {
+			return nil, err
+		}
+		sealed = append(sealed, ciphertext)
+	}
+	return sealed, nil
+}
+
+// sealDeterministic encrypts with a nonce derived from scope and plaintext, bound to scope
+func (k *Key) sealDeterministic(scope, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	// The format is part of the nonce input, so the unbound form never shares a nonce with it
+	return k.seal(envelopePrefix, k.syntheticNonce(envelopePrefix+scope, plaintext), plaintext, []byte(scope))
+}
+
+// sealUnbound computes the deterministic ciphertext of the previous format, for lookups only
+func (k *Key) sealUnbound(scope, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	return k.seal(unboundPrefix, k.syntheticNonce(scope, plaintext), plaintext, nil)
+}
+
+// syntheticNonce derives a nonce from scope and plaintext
+func (k *Key) syntheticNonce(scope, plaintext string) []byte {
+	mac := hmac.New(sha256.New, k.nonceKey)
+	mac.Write([]byte(scope))
+	mac.Write([]byte{0})
+	mac.Write([]byte(plaintext))
+	return mac.Sum(nil)[:nonceSize]
+}
+
+// seal encrypts plaintext with the given nonce and returns the envelope
+func (k *Key) seal(prefix string, nonce []byte, plaintext string, aad []byte) (string, error) {
+	gcm, err := k.gcm()
+	if err != nil {
+		return "", err
+	}
+
 	// Encrypt and prepend nonce
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), aad)
 
 	// Encode to base64 for safe storage
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+	return prefix + k.id + ":" + base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
-// Decrypt decrypts base64 encoded ciphertext using AES-256-GCM
-func (e *Encryptor) Decrypt(ciphertext string) (string, error) {
-	if ciphertext == "" {
-		return "", nil
+// open decrypts base64 decoded nonce and ciphertext
+func (k *Key) open(data, aad []byte) (string, error) {
+	gcm, err := k.gcm()
+	if err != nil {
+		return "", err
 	}
 
-	// Decode from base64
-	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if len(data) < minCiphertextSize {
+		return "", fmt.Errorf("%w: ciphertext too short", ErrMalformed)
+	}
+
+	// Extract nonce and encrypted data
+	nonce, encryptedData := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, encryptedData, aad)
 	if err != nil {
-		return "", fmt.Errorf("failed to decode base64: %w", err)
+		return "", ErrAuthentication
 	}
 
-	block, err := aes.NewCipher(e.key)
+	return string(plaintext), nil
+}
+
+// gcm creates the AES-256-GCM cipher of the key
+func (k *Key) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k.secret)
 	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
 	}
 
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
+
+// Decrypt decrypts a ciphertext envelope with the key it names and the aad it was encrypted with
+// Envelopes of the previous format have no associated data; legacy base64 ciphertext
+// without a key identifier is tried with every key.
+func (e *Encryptor) Decrypt(ciphertext, aad string) (string, error) {
+	if ciphertext == "" {
+		return "", nil
 	}
 
-	nonceSize := gcm.NonceSize()
-	if len(data) < nonceSize {
-		return "", fmt.Errorf("ciphertext too short")
+	prefix, id, data, ok := parseEnvelope(ciphertext)
+	if !ok {
+		if strings.HasPrefix(ciphertext, envelopePrefix) || strings.HasPrefix(ciphertext, unboundPrefix) {
+			return "", fmt.Errorf("%w: invalid envelope", ErrMalformed)
+		}
+		return e.decryptLegacy(ciphertext)
 	}
 
-	// Extract nonce and encrypted data
-	nonce, encryptedData := data[:nonceSize], data[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, encryptedData, nil)
+	k := e.key(id)
+	if k == nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	if prefix == unboundPrefix {
+		return k.open(data, nil)
+	}
+	return k.open(data, []byte(aad))
+}
+
+// decryptLegacy decrypts bare base64 ciphertext written before values named their key
+func (e *Encryptor) decryptLegacy(ciphertext string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
 	if err != nil {
-		return "", fmt.Errorf("failed to decrypt: %w", err)
+		return "", fmt.Errorf("%w: %v", ErrMalformed, err)
 	}
 
-	return string(plaintext), nil
+	for _, k := range e.keys {
+		plaintext, openErr := k.open(data, nil)
+		if openErr == nil {
+			return plaintext, nil
+		}
+		err = openErr
+	}
+	return "", err
 }
 
-// EncryptIfEnabled encrypts data only if encryptor is not nil
-func EncryptIfEnabled(encryptor *Encryptor, plaintext string) (string, error) {
+// EncryptIfEnabled encrypts data bound to aad only if encryptor is not nil
+func EncryptIfEnabled(encryptor *Encryptor, plaintext, aad string) (string, error) {
 	if encryptor == nil {
 		return plaintext, nil
 	}
-	return encryptor.Encrypt(plaintext)
+	return encryptor.Encrypt(plaintext, aad)
 }
 
-// DecryptIfEnabled decrypts data only if encryptor is not nil
-// If data is not valid base64 (old unencrypted data), returns it as-is
-func DecryptIfEnabled(encryptor *Encryptor, ciphertext string) (string, error) {
-	if encryptor == nil {
-		return ciphertext, nil
+// DecryptIfEnabled reads a stored value that may be encrypted
+// Envelopes of the keyring's keys are decrypted strictly: failures are returned as
+// ErrMalformed or ErrAuthentication. Other values were stored as plaintext (also when
+// they happen to start with "v3:") or by older versions, and are read as the
+// keyring's Unenveloped mode says. Without encryption every value is plaintext.
+func DecryptIfEnabled(encryptor *Encryptor, stored, aad string) (string, error) {
+	if encryptor == nil || stored == "" {
+		return stored, nil
+	}
+	if encryptor.IsEnvelope(stored) {
+		return encryptor.Decrypt(stored, aad)
 	}
 
-	// Check if data looks like base64 encoded encrypted data
-	// If it's not valid base64, it's probably old unencrypted data
-	_, err := base64.StdEncoding.DecodeString(ciphertext)
-	if err != nil {
-		// Not valid base64, assume it's old unencrypted data
-		return ciphertext, nil
+	switch encryptor.unenveloped {
+	case UnenvelopedRejected:
+		return "", fmt.Errorf("%w: value has no envelope", ErrMalformed)
+	case UnenvelopedLegacy:
+		if looksLegacy(stored) {
+			return encryptor.decryptLegacy(stored)
+		}
+		return stored, nil
 	}
+	return DecryptOrPlaintext(encryptor, stored, aad)
+}
 
-	// Try to decrypt - if it fails, return original (might be unencrypted)
-	decrypted, err := encryptor.Decrypt(ciphertext)
-	if err != nil {
-		// Decryption failed, might be old unencrypted data
-		// Return original without error to maintain backward compatibility
-		return ciphertext, nil
+// DecryptOrPlaintext reads a stored value that may be plaintext by design (a memory
+// field the policy leaves out): values that are not an envelope of the keyring are
+// legacy ciphertext if a key authenticates them and plaintext otherwise
+func DecryptOrPlaintext(encryptor *Encryptor, stored, aad string) (string, error) {
+	if encryptor == nil || stored == "" {
+		return stored, nil
+	}
+	if encryptor.IsEnvelope(stored) {
+		return encryptor.Decrypt(stored, aad)
+	}
+	if looksLegacy(stored) {
+		if plaintext, err := encryptor.decryptLegacy(stored); err == nil {
+			return plaintext, nil
+		}
+	}
+	return stored, nil
+}
+
+// Undecryptable reports whether err is a decryption failure of a stored value
+// (as opposed to a database or configuration error)
+func Undecryptable(err error) bool {
+	return errors.Is(err, ErrMalformed) || errors.Is(err, ErrAuthentication) || errors.Is(err, ErrUnknownKey)
+}
+
+// IsEnvelope reports whether a stored value is an envelope of one of the keyring's keys
+// The value must fully parse and name a configured key, so plaintext that starts
+// with "v3:" or "v2:" is not one. Always false without encryption.
+func (e *Encryptor) IsEnvelope(value string) bool {
+	if e == nil {
+		return false
+	}
+	_, id, _, ok := parseEnvelope(value)
+	return ok && e.key(id) != nil
+}
+
+// parseEnvelope splits a "vN:<keyid>:<base64>" value into its prefix, key identifier and data
+// ok is false unless the key identifier has the form of Key.ID and the data holds at
+// least a nonce and tag.
+func parseEnvelope(value string) (prefix, id string, data []byte, ok bool) {
+	switch {
+	case strings.HasPrefix(value, envelopePrefix):
+		prefix = envelopePrefix
+	case strings.HasPrefix(value, unboundPrefix):
+		prefix = unboundPrefix
+	default:
+		return "", "", nil, false
+	}
+
+	id, encoded, found := strings.Cut(value[len(prefix):], ":")
+	if !found || len(id) != 2*keyIDSize || strings.ToLower(id) != id {
+		return "", "", nil, false
 	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return "", "", nil, false
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < minCiphertextSize {
+		return "", "", nil, false
+	}
+	return prefix, id, data, true
+}
+
+// LooksLikeEnvelope reports whether a stored value fully parses as an envelope,
+// whether or not the key it names is configured
+func LooksLikeEnvelope(value string) bool {
+	_, _, _, ok := parseEnvelope(value)
+	return ok
+}
+
+// LooksEncrypted reports whether a stored value may be ciphertext: an envelope of
+// any key, or bare base64 long enough to hold a nonce and tag (legacy ciphertext)
+func LooksEncrypted(value string) bool {
+	return LooksLikeEnvelope(value) || looksLegacy(value)
+}
 
-	return decrypted, nil
+// looksLegacy reports whether a value may be legacy ciphertext: bare base64 of at least a nonce and tag
+func looksLegacy(value string) bool {
+	data, err := base64.StdEncoding.DecodeString(value)
+	return err == nil && len(data) >= minCiphertextSize
 }
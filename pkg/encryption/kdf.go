@@ -0,0 +1,101 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// saltSize is the size of the random salt passphrases are derived with
+	saltSize = 16
+
+	// keyCheckValue is encrypted with the key a database was set up with, to recognise it later
+	keyCheckValue = "memory-bot key check v1"
+
+	// keyCheckBinding is the associated data of the key check
+	keyCheckBinding = "key_check"
+)
+
+// ErrWrongKey is returned when a passphrase does not derive the key the stored data uses
+var ErrWrongKey = errors.New("encryption key does not match the stored data")
+
+// KDFParams are the Argon2id cost parameters and salt a passphrase is derived with
+type KDFParams struct {
+	Salt    []byte
+	Time    uint32
+	Memory  uint32 // KiB
+	Threads uint8
+}
+
+// NewKDFParams returns the default cost parameters (RFC 9106) with a fresh random salt
+func NewKDFParams() (KDFParams, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return KDFParams{}, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	return KDFParams{
+		Salt:    salt,
+		Time:    3,
+		Memory:  64 * 1024,
+		Threads: 4,
+	}, nil
+}
+
+// String encodes the parameters for storage: "argon2id$v=19$m=65536,t=3,p=4$<base64 salt>"
+func (p KDFParams) String() string {
+	return fmt.Sprintf("argon2id$v=%d$m=%d,t=%d,p=%d$%s",
+		argon2.Version, p.Memory, p.Time, p.Threads, base64.RawStdEncoding.EncodeToString(p.Salt))
+}
+
+// ParseKDFParams decodes parameters encoded with String
+func ParseKDFParams(s string) (KDFParams, error) {
+	parts := strings.Split(s, "$")
+	if len(parts) != 4 || parts[0] != "argon2id" {
+		return KDFParams{}, fmt.Errorf("unsupported key derivation %q", s)
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[1], "v=%d", &version); err != nil || version != argon2.Version {
+		return KDFParams{}, fmt.Errorf("unsupported argon2id version %q", parts[1])
+	}
+
+	var p KDFParams
+	if _, err := fmt.Sscanf(parts[2], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return KDFParams{}, fmt.Errorf("invalid argon2id parameters %q: %w", parts[2], err)
+	}
+	if p.Memory == 0 || p.Time == 0 || p.Threads == 0 {
+		return KDFParams{}, fmt.Errorf("invalid argon2id parameters %q", parts[2])
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil || len(salt) == 0 {
+		return KDFParams{}, fmt.Errorf("invalid argon2id salt %q", parts[3])
+	}
+	p.Salt = salt
+
+	return p, nil
+}
+
+// DeriveKey stretches a passphrase into a key with Argon2id
+// This is deliberately slow and memory hard, so derive each key once at startup.
+func DeriveKey(passphrase string, params KDFParams) *Key {
+	return newKey(argon2.IDKey([]byte(passphrase), params.Salt, params.Time, params.Memory, params.Threads, 32))
+}
+
+// KeyCheck returns a value that only the same key verifies, stored to detect a wrong key
+func (k *Key) KeyCheck() (string, error) {
+	return NewKeyring(k).Encrypt(keyCheckValue, keyCheckBinding)
+}
+
+// Verifies reports whether check was created by KeyCheck with this key
+func (k *Key) Verifies(check string) bool {
+	plaintext, err := NewKeyring(k).Decrypt(check, keyCheckBinding)
+	return err == nil && plaintext == keyCheckValue
+}
@@ -0,0 +1,121 @@
+package encryption
+
+import (
+	"sync"
+	"time"
+)
+
+// VaultSessions keeps the vault keys of unlocked users in memory
+// A session ends with Lock or when its timeout passes; vault keys are never stored
+// and are dropped when their session ends. A nil VaultSessions has every vault locked.
+type VaultSessions struct {
+	mu       sync.Mutex
+	timeout  time.Duration
+	sessions map[int64]*vaultSession
+}
+
+// vaultSession is the key material of one unlocked vault
+type vaultSession struct {
+	keyring    *Encryptor
+	blindIndex *BlindIndex
+	expires    time.Time
+}
+
+// NewVaultSessions creates a session store whose sessions last timeout after unlocking
+func NewVaultSessions(timeout time.Duration) *VaultSessions {
+	return &VaultSessions{
+		timeout:  timeout,
+		sessions: make(map[int64]*vaultSession),
+	}
+}
+
+// Unlock starts the session of a user with the vault key, replacing any open one
+// Returns when the session ends.
+func (s *VaultSessions) Unlock(userID int64, key *Key) time.Time {
+	keyring := NewKeyring(key)
+	session := &vaultSession{
+		keyring:    keyring,
+		blindIndex: NewBlindIndex(keyring),
+		expires:    time.Now().Add(s.timeout),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[userID] = session
+	return session.expires
+}
+
+// Lock ends the session of a user; reports whether the vault was unlocked
+func (s *VaultSessions) Lock(userID int64) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.end(userID, time.Now())
+}
+
+// Expires returns when the session of an unlocked user ends
+func (s *VaultSessions) Expires(userID int64) (time.Time, bool) {
+	session := s.session(userID)
+	if session == nil {
+		return time.Time{}, false
+	}
+	return session.expires, true
+}
+
+// Unlocked returns the vault keyring and blind index of an unlocked user, or nils while
+// the vault is locked. Vault memories are indexed with this blind index instead of the server's.
+func (s *VaultSessions) Unlocked(userID int64) (*Encryptor, *BlindIndex) {
+	if session := s.session(userID); session != nil {
+		return session.keyring, session.blindIndex
+	}
+	return nil, nil
+}
+
+// Expire ends the sessions whose timeout passed and returns their users
+func (s *VaultSessions) Expire() []int64 {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var expired []int64
+	for userID, session := range s.sessions {
+		if !now.Before(session.expires) {
+			s.end(userID, now)
+			expired = append(expired, userID)
+		}
+	}
+	return expired
+}
+
+// session returns the open session of a user, ending it if it timed out
+func (s *VaultSessions) session(userID int64) *vaultSession {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[userID]
+	if !ok || !time.Now().Before(session.expires) {
+		return nil
+	}
+	return session
+}
+
+// end removes the session of a user and reports whether it was still open; the caller holds mu
+func (s *VaultSessions) end(userID int64, now time.Time) bool {
+	session, ok := s.sessions[userID]
+	if !ok {
+		return false
+	}
+	delete(s.sessions, userID)
+	return now.Before(session.expires)
+}
@@ -0,0 +1,12 @@
+// Package markdown formats text for Telegram's legacy Markdown parse mode
+package markdown
+
+import "strings"
+
+var escaper = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+
+// Escape escapes the characters that have a meaning in Telegram's legacy Markdown
+// The result is meant for text outside entities; code spans don't take escapes.
+func Escape(text string) string {
+	return escaper.Replace(text)
+}
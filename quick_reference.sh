@@ -21,7 +21,8 @@ echo "   ./verify_functionality.sh"
 echo ""
 
 echo "📊 DATABASE MIGRATION (if upgrading):"
-echo "   ./migrate_chunking.sh"
+echo "   ./memory-bot --migrate-status   # show schema version"
+echo "   ./memory-bot --migrate-only     # apply migrations (also done at startup)"
 echo ""
 
 echo "💬 BOT COMMANDS:"